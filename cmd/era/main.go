@@ -80,7 +80,7 @@ var (
 	verifyCommand = &cli.Command{
 		Name:      "verify",
 		ArgsUsage: "<expected>",
-		Usage:     "verifies each era1 against expected accumulator root",
+		Usage:     "verifies each era1 or eral2 file against expected accumulator root",
 		Action:    verify,
 	}
 )
@@ -146,30 +146,44 @@ func info(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("error reading accumulator: %w", err)
 	}
-	td, err := e.InitialTD()
-	if err != nil {
-		return fmt.Errorf("error reading total difficulty: %w", err)
+	// L2 era files don't track the total difficulty.
+	var td *big.Int
+	if !e.IsL2() {
+		if td, err = e.InitialTD(); err != nil {
+			return fmt.Errorf("error reading total difficulty: %w", err)
+		}
 	}
 	info := struct {
 		Accumulator     common.Hash `json:"accumulator"`
-		TotalDifficulty *big.Int    `json:"totalDifficulty"`
+		TotalDifficulty *big.Int    `json:"totalDifficulty,omitempty"`
+		L2              bool        `json:"l2,omitempty"`
 		StartBlock      uint64      `json:"startBlock"`
 		Count           uint64      `json:"count"`
 	}{
-		acc, td, e.Start(), e.Count(),
+		acc, td, e.IsL2(), e.Start(), e.Count(),
 	}
 	b, _ := json.MarshalIndent(info, "", "  ")
 	fmt.Println(string(b))
 	return nil
 }
 
-// open opens an era1 file at a certain epoch.
+// readDir reads the era1 files of the network in the era directory, falling
+// back to eral2 files if there are none.
+func readDir(dir, network string) ([]string, error) {
+	entries, err := era.ReadDir(dir, network)
+	if err != nil || len(entries) > 0 {
+		return entries, err
+	}
+	return era.ReadL2Dir(dir, network)
+}
+
+// open opens an era1 or eral2 file at a certain epoch.
 func open(ctx *cli.Context, epoch uint64) (*era.Era, error) {
 	var (
 		dir     = ctx.String(dirFlag.Name)
 		network = ctx.String(networkFlag.Name)
 	)
-	entries, err := era.ReadDir(dir, network)
+	entries, err := readDir(dir, network)
 	if err != nil {
 		return nil, fmt.Errorf("error reading era dir: %w", err)
 	}
@@ -179,8 +193,8 @@ func open(ctx *cli.Context, epoch uint64) (*era.Era, error) {
 	return era.Open(filepath.Join(dir, entries[epoch]))
 }
 
-// verify checks each era1 file in a directory to ensure it is well-formed and
-// that the accumulator matches the expected value.
+// verify checks each era1 or eral2 file in a directory to ensure it is
+// well-formed and that the accumulator matches the expected value.
 func verify(ctx *cli.Context) error {
 	if ctx.Args().Len() != 1 {
 		return errors.New("missing accumulators file")
@@ -198,13 +212,13 @@ func verify(ctx *cli.Context) error {
 		reported = time.Now()
 	)
 
-	entries, err := era.ReadDir(dir, network)
+	entries, err := readDir(dir, network)
 	if err != nil {
 		return fmt.Errorf("error reading %s: %w", dir, err)
 	}
 
 	if len(entries) != len(roots) {
-		return errors.New("number of era1 files should match the number of accumulator hashes")
+		return errors.New("number of era files should match the number of accumulator hashes")
 	}
 
 	// Verify each epoch matches the expected root.
@@ -214,7 +228,7 @@ func verify(ctx *cli.Context) error {
 			name := entries[i]
 			e, err := era.Open(filepath.Join(dir, name))
 			if err != nil {
-				return fmt.Errorf("error opening era1 file %s: %w", name, err)
+				return fmt.Errorf("error opening era file %s: %w", name, err)
 			}
 			defer e.Close()
 			// Read accumulator and check against expected.
@@ -225,11 +239,11 @@ func verify(ctx *cli.Context) error {
 			}
 			// Recompute accumulator.
 			if err := checkAccumulator(e); err != nil {
-				return fmt.Errorf("error verify era1 file %s: %w", name, err)
+				return fmt.Errorf("error verify era file %s: %w", name, err)
 			}
 			// Give the user some feedback that something is happening.
 			if time.Since(reported) >= 8*time.Second {
-				fmt.Printf("Verifying Era1 files \t\t verified=%d,\t elapsed=%s\n", i, common.PrettyDuration(time.Since(start)))
+				fmt.Printf("Verifying Era files \t\t verified=%d,\t elapsed=%s\n", i, common.PrettyDuration(time.Since(start)))
 				reported = time.Now()
 			}
 			return nil
@@ -245,17 +259,20 @@ func verify(ctx *cli.Context) error {
 // checkAccumulator verifies the accumulator matches the data in the Era.
 func checkAccumulator(e *era.Era) error {
 	var (
-		err    error
-		want   common.Hash
-		td     *big.Int
-		tds    = make([]*big.Int, 0)
-		hashes = make([]common.Hash, 0)
+		err     error
+		want    common.Hash
+		td      *big.Int
+		tds     = make([]*big.Int, 0)
+		origins = make([]*era.L1Origin, 0)
+		hashes  = make([]common.Hash, 0)
 	)
 	if want, err = e.Accumulator(); err != nil {
 		return fmt.Errorf("error reading accumulator: %w", err)
 	}
-	if td, err = e.InitialTD(); err != nil {
-		return fmt.Errorf("error reading total difficulty: %w", err)
+	if !e.IsL2() {
+		if td, err = e.InitialTD(); err != nil {
+			return fmt.Errorf("error reading total difficulty: %w", err)
+		}
 	}
 	it, err := era.NewIterator(e)
 	if err != nil {
@@ -269,6 +286,9 @@ func checkAccumulator(e *era.Era) error {
 	//   5) the accumulator is correct by recomputing it locally, which verifies
 	//      the blocks are all correct (via hash)
 	//
+	// L2 era files carry no total difficulty, so 4) is skipped for them. Their
+	// accumulator commits to the L1 origins instead, which 5) covers as well.
+	//
 	// The attributes 1), 2), and 3) are checked for each block. 4) and 5) require
 	// accumulation across the entire set and are verified at the end.
 	for it.Next() {
@@ -291,11 +311,27 @@ func checkAccumulator(e *era.Era) error {
 			return fmt.Errorf("receipt root in block %d mismatch: want %s, got %s", block.NumberU64(), block.ReceiptHash(), rr)
 		}
 		hashes = append(hashes, block.Hash())
+		if e.IsL2() {
+			origin, err := it.L1Origin()
+			if err != nil {
+				return fmt.Errorf("error reading l1 origin %d: %w", it.Number(), err)
+			}
+			origins = append(origins, origin)
+			continue
+		}
 		td.Add(td, block.Difficulty())
 		tds = append(tds, new(big.Int).Set(td))
 	}
+	if it.Error() != nil {
+		return fmt.Errorf("error reading block %d: %w", it.Number(), it.Error())
+	}
 	// 4+5) Verify accumulator and total difficulty.
-	got, err := era.ComputeAccumulator(hashes, tds)
+	var got common.Hash
+	if e.IsL2() {
+		got, err = era.ComputeL2Accumulator(hashes, origins)
+	} else {
+		got, err = era.ComputeAccumulator(hashes, tds)
+	}
 	if err != nil {
 		return fmt.Errorf("error computing accumulator: %w", err)
 	}
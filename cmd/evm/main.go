@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/eth/tracers/sourcemap"
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/urfave/cli/v2"
@@ -87,6 +88,21 @@ var (
 		Value:    "json",
 		Category: traceCategory,
 	}
+	TraceTracerFlag = &cli.StringFlag{
+		Name:     "trace.tracer",
+		Usage:    "Named tracer to run instead of the trace logger (e.g. callTracer, gasProfiler), printing its result",
+		Category: traceCategory,
+	}
+	TraceTracerConfigFlag = &cli.StringFlag{
+		Name:     "trace.tracerconfig",
+		Usage:    "JSON configuration of the named tracer",
+		Category: traceCategory,
+	}
+	TraceArtifactsFlag = &cli.StringSliceFlag{
+		Name:     "trace.artifacts",
+		Usage:    "Solidity compiler artifacts (standard-JSON output or build info) to annotate traces with source locations",
+		Category: traceCategory,
+	}
 	TraceDisableMemoryFlag = &cli.BoolFlag{
 		Name:     "trace.nomemory",
 		Aliases:  []string{"nomemory"},
@@ -189,6 +205,9 @@ var (
 var traceFlags = []cli.Flag{
 	TraceFlag,
 	TraceFormatFlag,
+	TraceTracerFlag,
+	TraceTracerConfigFlag,
+	TraceArtifactsFlag,
 	TraceDisableStackFlag,
 	TraceDisableMemoryFlag,
 	TraceDisableStorageFlag,
@@ -237,6 +256,7 @@ func tracerFromFlags(ctx *cli.Context) *tracing.Hooks {
 		DisableStack:     ctx.Bool(TraceDisableStackFlag.Name),
 		DisableStorage:   ctx.Bool(TraceDisableStorageFlag.Name),
 		EnableReturnData: !ctx.Bool(TraceDisableReturnDataFlag.Name),
+		Artifacts:        artifactsFromFlags(ctx),
 	}
 	switch {
 	case ctx.Bool(TraceFlag.Name):
@@ -262,6 +282,34 @@ func tracerFromFlags(ctx *cli.Context) *tracing.Hooks {
 	}
 }
 
+// artifactsFromFlags loads the compiler artifacts given on the command line,
+// reading the sources they reference relative to the artifact files.
+func artifactsFromFlags(ctx *cli.Context) *sourcemap.Registry {
+	paths := ctx.StringSlice(TraceArtifactsFlag.Name)
+	if len(paths) == 0 {
+		return nil
+	}
+	registry := sourcemap.NewRegistry()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read artifacts: %v\n", err)
+			os.Exit(1)
+		}
+		readSource := func(source string) ([]byte, error) {
+			if data, err := os.ReadFile(filepath.Join(filepath.Dir(path), source)); err == nil {
+				return data, nil
+			}
+			return os.ReadFile(source)
+		}
+		if err := registry.Add(data, readSource); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load artifacts %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+	return registry
+}
+
 // collectFiles walks the given path. If the path is a directory, it will
 // return a list of all accumulates all files with json extension.
 // Otherwise (if path points to a file), it will return the path.
@@ -39,6 +39,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/sourcemap"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/triedb"
@@ -294,6 +296,28 @@ func runCmd(ctx *cli.Context) error {
 		runtimeConfig.ChainConfig = params.AllEthashProtocolChanges
 	}
 
+	// A named tracer replaces the trace logger, its result is printed once the
+	// execution completes
+	var namedTracer *tracers.Tracer
+	if name := ctx.String(TraceTracerFlag.Name); name != "" {
+		var err error
+		cfg := json.RawMessage(ctx.String(TraceTracerConfigFlag.Name))
+		if artifacts := artifactsFromFlags(ctx); artifacts != nil {
+			cfg, err = withArtifacts(cfg, artifacts)
+			if err != nil {
+				fmt.Printf("Invalid tracer config: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		namedTracer, err = tracers.DefaultDirectory.New(name, new(tracers.Context), cfg, runtimeConfig.ChainConfig)
+		if err != nil {
+			fmt.Printf("Failed to create tracer %q: %v\n", name, err)
+			os.Exit(1)
+		}
+		tracer = namedTracer.Hooks
+		runtimeConfig.EVMConfig.Tracer = tracer
+	}
+
 	var hexInput []byte
 	if inputFileFlag := ctx.String(InputFileFlag.Name); inputFileFlag != "" {
 		var err error
@@ -369,6 +393,14 @@ allocated bytes: %d
 			fmt.Printf(" error: %v\n", err)
 		}
 	}
+	if namedTracer != nil {
+		res, err := namedTracer.GetResult()
+		if err != nil {
+			fmt.Printf("Failed to retrieve tracer result: %v\n", err)
+			return err
+		}
+		fmt.Println(string(res))
+	}
 
 	return nil
 }
@@ -385,3 +417,20 @@ func writeLogs(writer io.Writer, logs []*types.Log) {
 		fmt.Fprintln(writer)
 	}
 }
+
+// withArtifacts adds the compiler artifacts to the JSON configuration of a
+// named tracer, for the tracers supporting source locations.
+func withArtifacts(cfg json.RawMessage, artifacts *sourcemap.Registry) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &fields); err != nil {
+			return nil, err
+		}
+	}
+	enc, err := json.Marshal(artifacts)
+	if err != nil {
+		return nil, err
+	}
+	fields["artifacts"] = enc
+	return json.Marshal(fields)
+}
@@ -17,13 +17,18 @@
 package main
 
 import (
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -34,8 +39,12 @@ import (
 	"github.com/ethereum/go-ethereum/core/history"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/live"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/internal/era"
@@ -46,6 +55,29 @@ import (
 )
 
 var (
+	stateDiffPreimagesFlag = &cli.BoolFlag{
+		Name:  "preimages",
+		Usage: "Annotate changed storage slots with the preimages of their keys",
+	}
+	opStatsWindowFlag = &cli.Uint64Flag{
+		Name:  "window",
+		Usage: "Number of blocks aggregated by each stats record",
+		Value: 1000,
+	}
+	opStatsWorkersFlag = &cli.IntFlag{
+		Name:  "workers",
+		Usage: "Number of block windows replayed in parallel",
+		Value: runtime.NumCPU(),
+	}
+	eraL2Flag = &cli.BoolFlag{
+		Name:  "l2",
+		Usage: "Export history in the L2 era format, without total difficulty",
+	}
+	eraL1OriginsFlag = &cli.StringFlag{
+		Name:  "l1origins",
+		Usage: "File of JSON objects with the L1 origins to attach to L2 era blocks",
+	}
+
 	initCommand = &cli.Command{
 		Action:    initGenesis,
 		Name:      "init",
@@ -106,6 +138,7 @@ if one is set.  Otherwise it prints the genesis from the datadir.`,
 			utils.TxLookupLimitFlag,
 			utils.VMTraceFlag,
 			utils.VMTraceJsonConfigFlag,
+			utils.VMTraceTracersFlag,
 			utils.TransactionHistoryFlag,
 			utils.LogHistoryFlag,
 			utils.LogNoHistoryFlag,
@@ -135,6 +168,35 @@ Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
+be gzipped.`,
+	}
+	exportStateDiffCommand = &cli.Command{
+		Action:    exportStateDiff,
+		Name:      "export-statediff",
+		Usage:     "Export the net state diff of a range of blocks into a JSONL file",
+		ArgsUsage: "<filename> <blockNumFirst> <blockNumLast>",
+		Flags:     slices.Concat([]cli.Flag{utils.CacheFlag, stateDiffPreimagesFlag}, utils.DatabaseFlags),
+		Description: `
+The export-statediff command re-executes a range of blocks and writes the net
+state diff of every block, the balance, nonce, code and storage changes of the
+accounts it touched, as a JSON line per block. The state of the parent of the
+first block must be available. If the file ends with .gz, the output will be
+gzipped.`,
+	}
+	opcodeStatsCommand = &cli.Command{
+		Action:    opcodeStats,
+		Name:      "opcode-stats",
+		Usage:     "Export the opcode and precompile usage of a range of blocks",
+		ArgsUsage: "<filename> <blockNumFirst> <blockNumLast>",
+		Flags:     slices.Concat([]cli.Flag{utils.CacheFlag, opStatsWindowFlag, opStatsWorkersFlag}, utils.DatabaseFlags),
+		Description: `
+The opcode-stats command re-executes a range of blocks and writes the opcode and
+precompile usage of every window of blocks. The windows are replayed in parallel,
+each starting from the state of its parent block, which is reconstructed from the
+state histories if it is no longer available in the path-based state scheme.
+
+If the file ends with .csv (or .csv.gz), the stats are written as CSV records,
+otherwise as a JSON line per window. If the file ends with .gz, the output will
 be gzipped.`,
 	}
 	importHistoryCommand = &cli.Command{
@@ -145,7 +207,7 @@ be gzipped.`,
 		Flags:     slices.Concat([]cli.Flag{utils.TxLookupLimitFlag, utils.TransactionHistoryFlag}, utils.DatabaseFlags, utils.NetworkFlags),
 		Description: `
 The import-history command will import blocks and their corresponding receipts
-from Era archives.
+from Era archives. Both Era1 and L2 era archives are supported.
 `,
 	}
 	exportHistoryCommand = &cli.Command{
@@ -153,10 +215,15 @@ from Era archives.
 		Name:      "export-history",
 		Usage:     "Export blockchain history to Era archives",
 		ArgsUsage: "<dir> <first> <last>",
-		Flags:     utils.DatabaseFlags,
+		Flags:     slices.Concat([]cli.Flag{eraL2Flag, eraL1OriginsFlag}, utils.DatabaseFlags),
 		Description: `
 The export-history command will export blocks and their corresponding receipts
 into Era archives. Eras are typically packaged in steps of 8192 blocks.
+
+With --l2, the blocks are exported into L2 era archives (.eral2), which don't
+carry the total difficulty. The blocks can optionally be annotated with the L1
+block they were derived from, read from the --l1origins file of JSON objects
+like {"number": 1, "l1Number": 100, "l1Hash": "0x..."}.
 `,
 	}
 	importPreimagesCommand = &cli.Command{
@@ -410,6 +477,244 @@ func exportChain(ctx *cli.Context) error {
 	return nil
 }
 
+func exportStateDiff(ctx *cli.Context) error {
+	if ctx.Args().Len() != 3 {
+		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
+	}
+	first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+	}
+	if first == 0 || first > last {
+		utils.Fatalf("Export error: invalid block range %d..%d, genesis is not diffable\n", first, last)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	if head := chain.CurrentBlock(); last > head.Number.Uint64() {
+		utils.Fatalf("Export error: block number %d larger than head block %d\n", last, head.Number.Uint64())
+	}
+	fh, err := os.OpenFile(ctx.Args().First(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(ctx.Args().First(), ".gz") {
+		gz := gzip.NewWriter(writer)
+		defer gz.Close()
+		writer = gz
+	}
+	var (
+		start     = time.Now()
+		reported  = time.Now()
+		preimages = ctx.Bool(stateDiffPreimagesFlag.Name)
+		encoder   = json.NewEncoder(writer)
+		config    = chain.Config()
+	)
+	for number := first; number <= last; number++ {
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		parent := chain.GetHeader(block.ParentHash(), number-1)
+		if parent == nil {
+			return fmt.Errorf("parent of block #%d not found", number)
+		}
+		statedb, err := chain.StateAt(parent.Root)
+		if err != nil {
+			return fmt.Errorf("state of block #%d unavailable: %w", number-1, err)
+		}
+		recorder := tracers.NewStateDiffRecorder()
+		if _, err := chain.Processor().Process(block, statedb, vm.Config{Tracer: recorder.Hooks(), EnablePreimageRecording: preimages}); err != nil {
+			return fmt.Errorf("failed to process block #%d: %w", number, err)
+		}
+		statedb.Finalise(config.IsEIP158(block.Number()))
+
+		var resolve func(common.Hash) []byte
+		if preimages {
+			recorded := statedb.Preimages()
+			resolve = func(hash common.Hash) []byte {
+				if preimage, ok := recorded[hash]; ok {
+					return preimage
+				}
+				return rawdb.ReadPreimage(db, hash)
+			}
+		}
+		if err := encoder.Encode(recorder.Diff(block, statedb, resolve)); err != nil {
+			return err
+		}
+		if time.Since(reported) > 8*time.Second {
+			log.Info("Exporting state diffs", "number", number, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	log.Info("Exported state diffs", "first", first, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func opcodeStats(ctx *cli.Context) error {
+	if ctx.Args().Len() != 3 {
+		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
+	}
+	first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+	}
+	if first == 0 || first > last {
+		utils.Fatalf("Export error: invalid block range %d..%d, genesis can't be replayed\n", first, last)
+	}
+	window := ctx.Uint64(opStatsWindowFlag.Name)
+	if window == 0 {
+		utils.Fatalf("Export error: window must be positive\n")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	if head := chain.CurrentBlock(); last > head.Number.Uint64() {
+		utils.Fatalf("Export error: block number %d larger than head block %d\n", last, head.Number.Uint64())
+	}
+	fh, err := os.OpenFile(ctx.Args().First(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var (
+		filename = strings.TrimSuffix(ctx.Args().First(), ".gz")
+		writer   = io.Writer(fh)
+		csvw     *csv.Writer
+	)
+	if strings.HasSuffix(ctx.Args().First(), ".gz") {
+		gz := gzip.NewWriter(writer)
+		defer gz.Close()
+		writer = gz
+	}
+	if strings.HasSuffix(filename, ".csv") {
+		csvw = csv.NewWriter(writer)
+		if err := csvw.Write(live.OpcodeStatsCSVHeader); err != nil {
+			return err
+		}
+	}
+	// Split the range into windows aligned on multiples of their size and
+	// replay them in parallel, writing the stats in order.
+	type task struct {
+		first, last uint64
+		stats       *live.OpcodeStats
+		err         error
+		done        chan struct{}
+	}
+	var tasks []*task
+	for start := first; start <= last; start = (start/window + 1) * window {
+		tasks = append(tasks, &task{
+			first: start,
+			last:  min(last, (start/window+1)*window-1),
+			done:  make(chan struct{}),
+		})
+	}
+	var (
+		start   = time.Now()
+		next    atomic.Int64
+		abort   atomic.Bool
+		wg      sync.WaitGroup
+		workers = max(1, ctx.Int(opStatsWorkersFlag.Name))
+	)
+	for range min(workers, len(tasks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n := int(next.Add(1) - 1)
+				if n >= len(tasks) {
+					return
+				}
+				t := tasks[n]
+				if !abort.Load() {
+					t.stats, t.err = replayOpcodeStats(chain, db, t.first, t.last)
+				}
+				close(t.done)
+			}
+		}()
+	}
+	// Skip the outstanding windows on failure, waiting for the ones being
+	// replayed before the chain is stopped.
+	defer func() {
+		abort.Store(true)
+		wg.Wait()
+	}()
+
+	total := new(live.OpcodeStats)
+	for _, t := range tasks {
+		<-t.done
+		if t.err != nil {
+			return t.err
+		}
+		if csvw != nil {
+			err = t.stats.WriteCSV(csvw)
+		} else {
+			err = json.NewEncoder(writer).Encode(t.stats)
+		}
+		if err != nil {
+			return err
+		}
+		total.Merge(t.stats)
+		log.Info("Exported opcode stats", "first", t.first, "last", t.last, "txs", t.stats.Transactions, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	if csvw != nil {
+		csvw.Flush()
+		if err := csvw.Error(); err != nil {
+			return err
+		}
+	}
+	log.Info("Exported opcode stats", "first", first, "last", last, "blocks", total.Blocks, "txs", total.Transactions, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// replayOpcodeStats re-executes a range of blocks on top of the state of the
+// parent of the first one, gathering their opcode and precompile usage.
+func replayOpcodeStats(chain *core.BlockChain, db ethdb.Database, first, last uint64) (*live.OpcodeStats, error) {
+	parent := chain.GetHeaderByNumber(first - 1)
+	if parent == nil {
+		return nil, fmt.Errorf("block #%d not found", first-1)
+	}
+	statedb, err := chain.StateAt(parent.Root)
+	if err != nil {
+		// Fall back to reconstructing the state from the state histories
+		statedb, err = state.New(parent.Root, state.NewHistoricDatabase(db, chain.TrieDB()))
+		if err != nil {
+			return nil, fmt.Errorf("state of block #%d unavailable: %w", first-1, err)
+		}
+	}
+	var (
+		config    = chain.Config()
+		collector = live.NewOpcodeStatsCollector(config)
+		hooks     = collector.Hooks()
+	)
+	for number := first; number <= last; number++ {
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		hooks.OnBlockStart(tracing.BlockEvent{Block: block})
+		if _, err := chain.Processor().Process(block, statedb, vm.Config{Tracer: hooks}); err != nil {
+			return nil, fmt.Errorf("failed to process block #%d: %w", number, err)
+		}
+		statedb.Finalise(config.IsEIP158(block.Number()))
+	}
+	return collector.Stats(), nil
+}
+
 func importHistory(ctx *cli.Context) error {
 	if ctx.Args().Len() != 1 {
 		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
@@ -448,12 +753,16 @@ func importHistory(ctx *cli.Context) error {
 			if err != nil {
 				return fmt.Errorf("error reading %s: %w", dir, err)
 			}
-			if len(entries) > 0 {
+			l2entries, err := era.ReadL2Dir(dir, n)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", dir, err)
+			}
+			if len(entries) > 0 || len(l2entries) > 0 {
 				networks = append(networks, n)
 			}
 		}
 		if len(networks) == 0 {
-			return fmt.Errorf("no era1 files found in %s", dir)
+			return fmt.Errorf("no era files found in %s", dir)
 		}
 		if len(networks) > 1 {
 			return errors.New("multiple networks found, use a network flag to specify desired network")
@@ -495,7 +804,21 @@ func exportHistory(ctx *cli.Context) error {
 	if head := chain.CurrentSnapBlock(); uint64(last) > head.Number.Uint64() {
 		utils.Fatalf("Export error: block number %d larger than head block %d\n", uint64(last), head.Number.Uint64())
 	}
-	err := utils.ExportHistory(chain, dir, uint64(first), uint64(last), uint64(era.MaxEra1Size))
+	var err error
+	if ctx.Bool(eraL2Flag.Name) {
+		var origins map[uint64]*era.L1Origin
+		if fn := ctx.String(eraL1OriginsFlag.Name); fn != "" {
+			if origins, err = utils.ReadL1Origins(fn); err != nil {
+				utils.Fatalf("Export error: failed to read l1 origins: %v\n", err)
+			}
+		}
+		err = utils.ExportL2History(chain, dir, uint64(first), uint64(last), uint64(era.MaxEra1Size), origins)
+	} else {
+		if ctx.IsSet(eraL1OriginsFlag.Name) {
+			utils.Fatalf("Export error: --%s requires --%s\n", eraL1OriginsFlag.Name, eraL2Flag.Name)
+		}
+		err = utils.ExportHistory(chain, dir, uint64(first), uint64(last), uint64(era.MaxEra1Size))
+	}
 	if err != nil {
 		utils.Fatalf("Export error: %v\n", err)
 	}
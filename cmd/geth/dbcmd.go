@@ -18,6 +18,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -32,6 +34,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/core/dbverify"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -78,11 +82,14 @@ Remove blockchain and state databases`,
 			dbPutCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
+			dbCompressFreezerCmd,
+			dbFreezerLayoutCmd,
 			dbImportCmd,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbVerifyCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -163,6 +170,60 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		Flags:       slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command displays information about the freezer index.",
 	}
+	dbCompressFreezerCmd = &cli.Command{
+		Action:    freezerCompress,
+		Name:      "freezer-compress",
+		Usage:     "Rewrite a freezer table with a different compression codec",
+		ArgsUsage: "<freezer-type> <table-type>",
+		Flags: slices.Concat([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "codec",
+				Usage: "Compression codec of the table (snappy, zstd)",
+				Value: "zstd",
+			},
+			&cli.IntFlag{
+				Name:  "dict",
+				Usage: "Size of the zstd dictionary trained on the table items, zero disables the dictionary",
+				Value: 64 * 1024,
+			},
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command rewrites all the items of a freezer table (e.g. 'chain bodies')
+with the given compression codec. For zstd, a dictionary is trained on a sample
+of the items and stored in the table metadata. The node must be stopped.
+
+Tables rewritten with zstd can't be opened by older releases. Rewrite them with
+snappy before downgrading.`,
+	}
+	dbFreezerLayoutCmd = &cli.Command{
+		Action: freezerLayout,
+		Name:   "freezer-layout",
+		Usage:  "Show or change the placement of the chain freezer tables on the disk",
+		Flags: slices.Concat([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "table",
+				Usage: "Directory of a table as <table>=<dir>, the freezer directory if <dir> is empty",
+			},
+			&cli.StringFlag{
+				Name:  "cold",
+				Usage: "Directory of the old data files of all the tables, tiering is disabled if empty",
+			},
+			&cli.UintFlag{
+				Name:  "hot-files",
+				Usage: "Number of the most recent data files of each table kept out of the cold directory",
+			},
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command shows the disk usage of the chain freezer tables in each storage
+tier. If any flag is given, the layout is changed and the table files are moved
+to their new locations. The node must be stopped.
+
+Each table can be placed in its own directory, e.g. on a separate volume. With a
+cold directory, the data files of the tables (2GB each) are moved to the cold
+directory once they are older than the given number of recent files. The node
+moves the aging files in the background, and reads them from either location.
+
+Note that 'geth removedb' doesn't delete the directories outside of the ancient
+directory.`,
+	}
 	dbImportCmd = &cli.Command{
 		Action:      importLDBdata,
 		Name:        "import",
@@ -207,6 +268,34 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command queries the history of the account or storage slot within the specified block range",
 	}
+	dbVerifyCmd = &cli.Command{
+		Action: dbVerify,
+		Name:   "verify",
+		Usage:  "Check the integrity of the chain data, the derived indexes and the state",
+		Flags: slices.Concat([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "checks",
+				Usage: "Checks to run (" + strings.Join(dbverify.AllChecks, ", ") + "), all of them if unset",
+			},
+			&cli.IntFlag{
+				Name:  "workers",
+				Usage: "Number of parallel workers, the number of CPUs if zero",
+			},
+			&cli.BoolFlag{
+				Name:  "repair",
+				Usage: "Repair the damaged derived indexes (header numbers, tx lookups, log index, snapshot)",
+			},
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command cross-checks the chain freezer, the canonical chain, the block
+bodies and receipts, the transaction and log indexes and the state snapshot.
+The checks run in parallel. Every found issue and the summary of every check is
+printed to stdout as a JSON object per line, with the "type" field set to
+"issue" or "result" respectively.
+
+With --repair, the derived indexes are fixed where possible. The log index and
+the snapshot are dropped when damaged, and are rebuilt on the next start. The
+command fails if any issue is left unrepaired.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -605,6 +694,75 @@ func freezerInspect(ctx *cli.Context) error {
 	return rawdb.InspectFreezerTable(ancient, freezer, table, start, end)
 }
 
+func freezerCompress(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	var (
+		freezer = ctx.Args().Get(0)
+		table   = ctx.Args().Get(1)
+		codec   = ctx.String("codec")
+		dict    = ctx.Int("dict")
+	)
+	if codec != "zstd" {
+		dict = 0
+	}
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	stack.Close()
+	return rawdb.RecompressFreezerTable(ancient, freezer, table, codec, dict)
+}
+
+func freezerLayout(ctx *cli.Context) error {
+	if ctx.NArg() > 0 {
+		return fmt.Errorf("no arguments required")
+	}
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	stack.Close()
+
+	if ctx.IsSet("table") || ctx.IsSet("cold") || ctx.IsSet("hot-files") {
+		layout, err := rawdb.ReadFreezerLayout(ancient, rawdb.ChainFreezerName)
+		if err != nil {
+			return err
+		}
+		for _, spec := range ctx.StringSlice("table") {
+			table, dir, ok := strings.Cut(spec, "=")
+			if !ok {
+				return fmt.Errorf("invalid table placement %q, want <table>=<dir>", spec)
+			}
+			if layout.Tables == nil {
+				layout.Tables = make(map[string]string)
+			}
+			if dir == "" {
+				delete(layout.Tables, table)
+			} else {
+				layout.Tables[table] = dir
+			}
+		}
+		if ctx.IsSet("cold") {
+			layout.ColdDir = ctx.String("cold")
+		}
+		if ctx.IsSet("hot-files") {
+			layout.HotFiles = uint32(ctx.Uint("hot-files"))
+		}
+		if err := rawdb.RebalanceFreezer(ancient, rawdb.ChainFreezerName, layout); err != nil {
+			return err
+		}
+	}
+	usages, err := rawdb.InspectFreezerTiers(ancient, rawdb.ChainFreezerName)
+	if err != nil {
+		return err
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Table", "Directory", "Size", "Cold directory", "Cold size"})
+	for _, usage := range usages {
+		table.Append([]string{usage.Table, usage.Dir, common.StorageSize(usage.Hot).String(), usage.ColdDir, common.StorageSize(usage.Cold).String()})
+	}
+	table.Render()
+	return nil
+}
+
 func importLDBdata(ctx *cli.Context) error {
 	start := 0
 	switch ctx.NArg() {
@@ -907,3 +1065,73 @@ func inspectHistory(ctx *cli.Context) error {
 	}
 	return inspectStorage(triedb, start, end, address, slot, ctx.Bool("raw"))
 }
+
+// verifyIssue and verifyResult are the lines of the JSON output of the verify
+// command.
+type (
+	verifyIssue struct {
+		Type string `json:"type"`
+		*dbverify.Issue
+	}
+	verifyResult struct {
+		Type string `json:"type"`
+		*dbverify.Result
+	}
+)
+
+func dbVerify(ctx *cli.Context) error {
+	if ctx.NArg() > 0 {
+		return fmt.Errorf("no arguments required")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	repair := ctx.Bool("repair")
+	db := utils.MakeChainDatabase(ctx, stack, !repair)
+	defer db.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, db, false, !repair, false)
+	defer triedb.Close()
+
+	chain := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if chain == nil {
+		return errors.New("chain config not found")
+	}
+	var checks []string
+	for _, check := range ctx.StringSlice("checks") {
+		for _, name := range strings.Split(check, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				checks = append(checks, name)
+			}
+		}
+	}
+	verifier, err := dbverify.New(db, triedb, chain, dbverify.Config{
+		Checks:         checks,
+		Workers:        ctx.Int("workers"),
+		Repair:         repair,
+		LogIndexParams: filtermaps.DefaultParams,
+	})
+	if err != nil {
+		return err
+	}
+	var (
+		enc     = json.NewEncoder(os.Stdout)
+		results = verifier.Run(func(issue *dbverify.Issue) {
+			enc.Encode(verifyIssue{Type: "issue", Issue: issue})
+		})
+		unrepaired uint64
+		failed     bool
+	)
+	for _, result := range results {
+		enc.Encode(verifyResult{Type: "result", Result: result})
+		unrepaired += result.Issues - result.Repaired
+		failed = failed || result.Error != ""
+	}
+	switch {
+	case failed:
+		return errors.New("database verification failed")
+	case unrepaired > 0:
+		return fmt.Errorf("found %d unrepaired issues", unrepaired)
+	}
+	return nil
+}
@@ -57,6 +57,7 @@ var (
 		utils.PasswordFileFlag,
 		utils.BootnodesFlag,
 		utils.MinFreeDiskSpaceFlag,
+		utils.DBServerFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
 		utils.NoUSBFlag, // deprecated
@@ -76,9 +77,21 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolThrottleSenderFlag,
+		utils.TxPoolThrottleContractFlag,
+		utils.TxPoolThrottleBurstFlag,
+		utils.TxPoolThrottleStrikesFlag,
+		utils.TxPoolThrottlePenaltyFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
+		utils.BlobArchiveFlag,
+		utils.BlobArchiveRetentionFlag,
+		utils.BlobArchiveMaxSizeFlag,
+		utils.BundlePoolFlag,
+		utils.BundlePoolMaxBundlesFlag,
+		utils.BundlePoolMaxTxsFlag,
+		utils.BundlePoolBlocksAheadFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
 		utils.ExitWhenSyncedFlag,
@@ -87,10 +100,14 @@ var (
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
 		utils.ChainHistoryFlag,
+		utils.ChainHistoryBlocksFlag,
+		utils.ChainHistoryAgeFlag,
+		utils.ChainHistoryEraFlag,
 		utils.LogHistoryFlag,
 		utils.LogNoHistoryFlag,
 		utils.LogExportCheckpointsFlag,
 		utils.StateHistoryFlag,
+		utils.StateIndexFlag,
 		utils.LightServeFlag,    // deprecated
 		utils.LightIngressFlag,  // deprecated
 		utils.LightEgressFlag,   // deprecated
@@ -139,6 +156,7 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.VMTraceFlag,
 		utils.VMTraceJsonConfigFlag,
+		utils.VMTraceTracersFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
 		utils.GpoBlocksFlag,
@@ -219,6 +237,8 @@ func init() {
 		initCommand,
 		importCommand,
 		exportCommand,
+		exportStateDiffCommand,
+		opcodeStatsCommand,
 		importHistoryCommand,
 		exportHistoryCommand,
 		importPreimagesCommand,
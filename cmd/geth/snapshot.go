@@ -22,7 +22,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"slices"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
@@ -33,13 +36,20 @@ import (
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/urfave/cli/v2"
 )
 
 var (
+	pruneTargetFlag = &cli.StringFlag{
+		Name:  "target",
+		Usage: "Directory of a new key-value database to copy the pruned state into (path scheme only)",
+	}
+
 	snapshotCommand = &cli.Command{
 		Name:        "snapshot",
 		Usage:       "A set of commands based on the snapshot",
@@ -52,6 +62,7 @@ var (
 				Action:    pruneState,
 				Flags: slices.Concat([]cli.Flag{
 					utils.BloomFilterSizeFlag,
+					pruneTargetFlag,
 				}, utils.NetworkFlags, utils.DatabaseFlags),
 				Description: `
 geth snapshot prune-state <state-root>
@@ -62,7 +73,14 @@ two version states are available: genesis and the specific one.
 
 The default pruning target is the HEAD-127 state.
 
-WARNING: it's only supported in hash mode(--state.scheme=hash)".
+In path mode(--state.scheme=path), the state root can't be specified.
+The state of the head block is flattened into the persistent state, all
+state histories are dropped, and all trie nodes not reachable from the
+persistent state, as well as leftovers of the legacy hash scheme, are
+deleted. With --target, the live data is copied into a new key-value
+database instead, which replaces the chaindata directory afterwards (the
+ancient store is not copied). The pruning can be interrupted and resumes
+from the last checkpoint when rerun.
 `,
 			},
 			{
@@ -148,6 +166,35 @@ as the backend data source, making this command a lot faster.
 
 The argument is interpreted as block number or hash. If none is provided, the latest
 block is used.
+`,
+			},
+			{
+				Name:      "export",
+				Usage:     "Export the state of a block into a file",
+				ArgsUsage: "<dumpfile> [? <blockHash> | <blockNum>]",
+				Action:    snapshotExport,
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot export <dumpfile> [? <blockHash> | <blockNum>]
+will export the flat state of the given block, or the head block if none is
+provided, into a chunked, checksummed and compressed file. The state is read
+from the snapshot if it's available, or from the state trie otherwise.
+`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import the state from an exported file",
+				ArgsUsage: "<dumpfile>",
+				Action:    snapshotImport,
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot import <dumpfile>
+will load the state from a file created by 'geth snapshot export', rebuild the
+state tries and the snapshot from it, and verify the state root. If the block of
+the state is already in the database, e.g. imported with 'geth import-history',
+it's made the head block.
+
+The state can only be imported into a freshly initialized database.
 `,
 			},
 			{
@@ -174,8 +221,16 @@ func pruneState(ctx *cli.Context) error {
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
 	defer chaindb.Close()
 
-	if rawdb.ReadStateScheme(chaindb) != rawdb.HashScheme {
-		log.Crit("Offline pruning is not required for path scheme")
+	if rawdb.ReadStateScheme(chaindb) == rawdb.PathScheme {
+		if ctx.NArg() > 0 {
+			log.Error("Pruning target can't be specified in path scheme")
+			return errors.New("too many arguments")
+		}
+		return prunePathState(ctx, stack, chaindb)
+	}
+	if ctx.IsSet(pruneTargetFlag.Name) {
+		log.Error("Pruning into target database is only supported in path scheme")
+		return errors.New("target database not supported")
 	}
 	prunerconfig := pruner.Config{
 		Datadir:   stack.ResolvePath(""),
@@ -205,6 +260,48 @@ func pruneState(ctx *cli.Context) error {
 	return nil
 }
 
+// prunePathState compacts the path-based state in place or into a new
+// database. It stops at the next checkpoint when interrupted.
+func prunePathState(ctx *cli.Context, stack *node.Node, chaindb ethdb.Database) error {
+	var config pruner.PathConfig
+	if ctx.IsSet(pruneTargetFlag.Name) {
+		var (
+			cache   = ctx.Int(utils.CacheFlag.Name) * ctx.Int(utils.CacheDatabaseFlag.Name) / 100
+			handles = utils.MakeDatabaseHandles(ctx.Int(utils.FDLimitFlag.Name))
+		)
+		target, err := stack.OpenDatabase(ctx.String(pruneTargetFlag.Name), cache, handles, "", false)
+		if err != nil {
+			log.Error("Failed to open target database", "err", err)
+			return err
+		}
+		defer target.Close()
+		config.Target = target
+	}
+	p, err := pruner.NewPathPruner(chaindb, config)
+	if err != nil {
+		log.Error("Failed to create state pruner", "err", err)
+		return err
+	}
+	var (
+		interrupt = make(chan os.Signal, 1)
+		stop      = make(chan struct{})
+	)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	defer close(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			log.Info("Interrupted during state pruning, stopping at next checkpoint")
+		}
+		close(stop)
+	}()
+	if err := p.Prune(stop); err != nil {
+		log.Error("Failed to prune state", "err", err)
+		return err
+	}
+	return nil
+}
+
 func verifyState(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -661,6 +758,193 @@ func snapshotExportPreimages(ctx *cli.Context) error {
 	return utils.ExportSnapshotPreimages(chaindb, snaptree, ctx.Args().First(), root)
 }
 
+// snapshotExport exports the state of a block into a file.
+func snapshotExport(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		utils.Fatalf("This command requires one or two arguments.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, chaindb, false, true, false)
+	defer triedb.Close()
+
+	var header *types.Header
+	if ctx.NArg() > 1 {
+		arg := ctx.Args().Get(1)
+		if hashish(arg) {
+			hash := common.HexToHash(arg)
+			if number := rawdb.ReadHeaderNumber(chaindb, hash); number != nil {
+				header = rawdb.ReadHeader(chaindb, hash, *number)
+			}
+		} else {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return err
+			}
+			header = rawdb.ReadHeader(chaindb, rawdb.ReadCanonicalHash(chaindb, number), number)
+		}
+	} else {
+		header = rawdb.ReadHeadHeader(chaindb)
+	}
+	if header == nil {
+		return errors.New("block not found")
+	}
+	root := header.Root
+
+	// Prefer the snapshot as the source of the flat state, falling back to
+	// iterating the state trie
+	var (
+		accIt     snapshot.AccountIterator
+		storageIt func(account common.Hash) (snapshot.StorageIterator, error)
+	)
+	snaptree, err := snapshot.New(snapshot.Config{CacheSize: 256, NoBuild: true}, chaindb, triedb, root)
+	if err == nil {
+		accIt, err = snaptree.AccountIterator(root, common.Hash{})
+	}
+	if err == nil {
+		storageIt = func(account common.Hash) (snapshot.StorageIterator, error) {
+			return snaptree.StorageIterator(root, account, common.Hash{})
+		}
+	} else {
+		log.Info("Snapshot unavailable, exporting from the state trie", "err", err)
+
+		tr, err := trie.NewStateTrie(trie.StateTrieID(root), triedb)
+		if err != nil {
+			return err
+		}
+		nodeIt, err := tr.NodeIterator(nil)
+		if err != nil {
+			return err
+		}
+		trieIt := &trieAccountIterator{it: trie.NewIterator(nodeIt)}
+		accIt = trieIt
+		storageIt = func(account common.Hash) (snapshot.StorageIterator, error) {
+			tr, err := trie.NewStateTrie(trie.StorageTrieID(root, account, trieIt.account.Root), triedb)
+			if err != nil {
+				return nil, err
+			}
+			nodeIt, err := tr.NodeIterator(nil)
+			if err != nil {
+				return nil, err
+			}
+			return &trieStorageIterator{it: trie.NewIterator(nodeIt)}, nil
+		}
+	}
+	defer accIt.Release()
+
+	fh, err := os.OpenFile(ctx.Args().First(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	log.Info("Exporting state", "number", header.Number, "hash", header.Hash(), "root", root, "file", ctx.Args().First())
+	exportHeader := &snapshot.ExportHeader{
+		Number: header.Number.Uint64(),
+		Hash:   header.Hash(),
+		Root:   root,
+	}
+	if err := snapshot.Export(fh, exportHeader, accIt, storageIt, func(hash common.Hash) []byte {
+		return rawdb.ReadCode(chaindb, hash)
+	}); err != nil {
+		return err
+	}
+	return fh.Sync()
+}
+
+// snapshotImport imports the state from an exported file.
+func snapshotImport(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	defer chaindb.Close()
+
+	// The existing trie nodes are not wiped, refuse to overlay the state onto
+	// the state of any block but genesis.
+	if head := rawdb.ReadHeadHeader(chaindb); head != nil && head.Number.Sign() > 0 {
+		return fmt.Errorf("database has chain head at block %d, state import requires a fresh database", head.Number)
+	}
+	scheme, err := rawdb.ParseStateScheme(ctx.String(utils.StateSchemeFlag.Name), chaindb)
+	if err != nil {
+		return err
+	}
+	fh, err := os.Open(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	header, err := snapshot.Import(chaindb, scheme, fh)
+	if err != nil {
+		return err
+	}
+	// Reset the path database onto the imported state
+	if scheme == rawdb.PathScheme {
+		triedb := utils.MakeTrieDatabase(ctx, chaindb, false, false, false)
+		if err := triedb.Enable(header.Root); err != nil {
+			triedb.Close()
+			return err
+		}
+		triedb.Close()
+	}
+	if rawdb.ReadCanonicalHash(chaindb, header.Number) != header.Hash {
+		log.Warn("Block of the imported state not found, import the chain history separately", "number", header.Number, "hash", header.Hash)
+		return nil
+	}
+	rawdb.WriteHeadHeaderHash(chaindb, header.Hash)
+	rawdb.WriteHeadFastBlockHash(chaindb, header.Hash)
+	rawdb.WriteHeadBlockHash(chaindb, header.Hash)
+	log.Info("Set head block to the imported state", "number", header.Number, "hash", header.Hash)
+	return nil
+}
+
+// trieAccountIterator is an account iterator over a state trie, for exporting
+// the state without a snapshot.
+type trieAccountIterator struct {
+	it      *trie.Iterator
+	account types.StateAccount
+	err     error
+}
+
+func (it *trieAccountIterator) Next() bool {
+	if it.err != nil || !it.it.Next() {
+		return false
+	}
+	it.err = rlp.DecodeBytes(it.it.Value, &it.account)
+	return it.err == nil
+}
+
+func (it *trieAccountIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Err
+}
+
+func (it *trieAccountIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+func (it *trieAccountIterator) Account() []byte   { return types.SlimAccountRLP(it.account) }
+func (it *trieAccountIterator) Release()          {}
+
+// trieStorageIterator is a storage iterator over a storage trie, for exporting
+// the state without a snapshot.
+type trieStorageIterator struct {
+	it *trie.Iterator
+}
+
+func (it *trieStorageIterator) Next() bool        { return it.it.Next() }
+func (it *trieStorageIterator) Error() error      { return it.it.Err }
+func (it *trieStorageIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+func (it *trieStorageIterator) Slot() []byte      { return it.it.Value }
+func (it *trieStorageIterator) Release()          {}
+
 // checkAccount iterates the snap data layers, and looks up the given account
 // across all layers.
 func checkAccount(ctx *cli.Context) error {
@@ -22,6 +22,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -36,6 +37,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/history"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -245,9 +247,9 @@ func readList(filename string) ([]string, error) {
 	return strings.Split(string(b), "\n"), nil
 }
 
-// ImportHistory imports Era1 files containing historical block information,
-// starting from genesis. The assumption is held that the provided chain
-// segment in Era1 file should all be canonical and verified.
+// ImportHistory imports Era1 or L2 era files containing historical block
+// information, starting from genesis. The assumption is held that the provided
+// chain segment in era files should all be canonical and verified.
 func ImportHistory(chain *core.BlockChain, dir string, network string) error {
 	if chain.CurrentSnapBlock().Number.BitLen() != 0 {
 		return errors.New("history import only supported when starting from genesis")
@@ -256,6 +258,12 @@ func ImportHistory(chain *core.BlockChain, dir string, network string) error {
 	if err != nil {
 		return fmt.Errorf("error reading %s: %w", dir, err)
 	}
+	// Fall back to L2 era files if there are no Era1 files.
+	if len(entries) == 0 {
+		if entries, err = era.ReadL2Dir(dir, network); err != nil {
+			return fmt.Errorf("error reading %s: %w", dir, err)
+		}
+	}
 	checksums, err := readList(filepath.Join(dir, "checksums.txt"))
 	if err != nil {
 		return fmt.Errorf("unable to read checksums.txt: %w", err)
@@ -403,6 +411,57 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 // ExportHistory exports blockchain history into the specified directory,
 // following the Era format.
 func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) error {
+	td := new(big.Int)
+	for i := uint64(0); i < first; i++ {
+		td.Add(td, bc.GetHeaderByNumber(i).Difficulty)
+	}
+	newBuilder := func(w io.Writer) historyBuilder {
+		return &era1Builder{Builder: era.NewBuilder(w), td: td}
+	}
+	return exportHistory(bc, dir, first, last, step, era.Filename, newBuilder)
+}
+
+// ExportL2History exports blockchain history into the specified directory,
+// following the L2 era format which doesn't track the total difficulty. The
+// blocks are annotated with their L1 origins from the given set, if any.
+func ExportL2History(bc *core.BlockChain, dir string, first, last, step uint64, origins map[uint64]*era.L1Origin) error {
+	newBuilder := func(w io.Writer) historyBuilder {
+		return &l2Builder{L2Builder: era.NewL2Builder(w), origins: origins}
+	}
+	return exportHistory(bc, dir, first, last, step, era.L2Filename, newBuilder)
+}
+
+// historyBuilder is the common interface of the era builders used by the
+// history export.
+type historyBuilder interface {
+	add(block *types.Block, receipts types.Receipts) error
+	Finalize() (common.Hash, error)
+}
+
+// era1Builder adds blocks to an Era1 archive, accumulating the total difficulty.
+type era1Builder struct {
+	*era.Builder
+	td *big.Int
+}
+
+func (b *era1Builder) add(block *types.Block, receipts types.Receipts) error {
+	b.td.Add(b.td, block.Difficulty())
+	return b.Add(block, receipts, new(big.Int).Set(b.td))
+}
+
+// l2Builder adds blocks to an L2 era archive, along with their L1 origins.
+type l2Builder struct {
+	*era.L2Builder
+	origins map[uint64]*era.L1Origin
+}
+
+func (b *l2Builder) add(block *types.Block, receipts types.Receipts) error {
+	return b.Add(block, receipts, b.origins[block.NumberU64()])
+}
+
+// exportHistory exports blockchain history into era archives created by the
+// given builder constructor.
+func exportHistory(bc *core.BlockChain, dir string, first, last, step uint64, filenameFn func(string, int, common.Hash) string, newBuilder func(io.Writer) historyBuilder) error {
 	log.Info("Exporting blockchain history", "dir", dir)
 	if head := bc.CurrentBlock().Number.Uint64(); head < last {
 		log.Warn("Last block beyond head, setting last = head", "head", head, "last", last)
@@ -421,21 +480,18 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 		h         = sha256.New()
 		buf       = bytes.NewBuffer(nil)
 		checksums []string
+		manifest  []string // checksums in the sha256sum format, for serving over HTTP
 	)
-	td := new(big.Int)
-	for i := uint64(0); i < first; i++ {
-		td.Add(td, bc.GetHeaderByNumber(i).Difficulty)
-	}
 	for i := first; i <= last; i += step {
 		err := func() error {
-			filename := filepath.Join(dir, era.Filename(network, int(i/step), common.Hash{}))
+			filename := filepath.Join(dir, filenameFn(network, int(i/step), common.Hash{}))
 			f, err := os.Create(filename)
 			if err != nil {
 				return fmt.Errorf("could not create era file: %w", err)
 			}
 			defer f.Close()
 
-			w := era.NewBuilder(f)
+			w := newBuilder(f)
 			for j := uint64(0); j < step && j <= last-i; j++ {
 				var (
 					n     = i + j
@@ -448,8 +504,7 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 				if receipts == nil {
 					return fmt.Errorf("export failed on #%d: receipts not found", n)
 				}
-				td.Add(td, block.Difficulty())
-				if err := w.Add(block, receipts, new(big.Int).Set(td)); err != nil {
+				if err := w.add(block, receipts); err != nil {
 					return err
 				}
 			}
@@ -458,7 +513,8 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 				return fmt.Errorf("export failed to finalize %d: %w", step/i, err)
 			}
 			// Set correct filename with root.
-			os.Rename(filename, filepath.Join(dir, era.Filename(network, int(i/step), root)))
+			name := filenameFn(network, int(i/step), root)
+			os.Rename(filename, filepath.Join(dir, name))
 
 			// Compute checksum of entire Era1.
 			if _, err := f.Seek(0, io.SeekStart); err != nil {
@@ -467,7 +523,9 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 			if _, err := io.Copy(h, f); err != nil {
 				return fmt.Errorf("unable to calculate checksum: %w", err)
 			}
-			checksums = append(checksums, common.BytesToHash(h.Sum(buf.Bytes()[:])).Hex())
+			sum := common.BytesToHash(h.Sum(buf.Bytes()[:]))
+			checksums = append(checksums, sum.Hex())
+			manifest = append(manifest, fmt.Sprintf("%x  %s", sum, name))
 			h.Reset()
 			buf.Reset()
 			return nil
@@ -482,12 +540,44 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 	}
 
 	os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte(strings.Join(checksums, "\n")), os.ModePerm)
+	os.WriteFile(filepath.Join(dir, history.EraChecksumsFile), []byte(strings.Join(manifest, "\n")+"\n"), os.ModePerm)
 
 	log.Info("Exported blockchain to", "dir", dir)
 
 	return nil
 }
 
+// ReadL1Origins reads the L1 origins of L2 blocks from a file of JSON objects,
+// each holding the block number along with the L1 block number and hash:
+//
+//	{"number": 1, "l1Number": 100, "l1Hash": "0x..."}
+func ReadL1Origins(fn string) (map[uint64]*era.L1Origin, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		dec     = json.NewDecoder(f)
+		origins = make(map[uint64]*era.L1Origin)
+	)
+	for {
+		var entry struct {
+			Number uint64 `json:"number"`
+			era.L1Origin
+		}
+		if err := dec.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid l1 origin entry: %w", err)
+		}
+		origin := entry.L1Origin
+		origins[entry.Number] = &origin
+	}
+	return origins, nil
+}
+
 // ImportPreimages imports a batch of exported hash preimages into the database.
 // It's a part of the deprecated functionality, should be removed in the future.
 func ImportPreimages(db ethdb.Database, fn string) error {
@@ -42,8 +42,11 @@ import (
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/blobarchive"
+	"github.com/ethereum/go-ethereum/core/history"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -106,6 +109,16 @@ var (
 		Value:    node.DefaultConfig.DBEngine,
 		Category: flags.EthCategory,
 	}
+	DBReplicaFlag = &cli.StringFlag{
+		Name:     "db.replica",
+		Usage:    "Address of the chain database served by a primary node, to run as its read-only replica (recent states require an archive primary)",
+		Category: flags.EthCategory,
+	}
+	DBServerFlag = &cli.StringFlag{
+		Name:     "db.server",
+		Usage:    "Listening address to serve the chain database on for the replica nodes",
+		Category: flags.EthCategory,
+	}
 	AncientFlag = &flags.DirectoryFlag{
 		Name:     "datadir.ancient",
 		Usage:    "Root directory for ancient data (default = inside chaindata)",
@@ -271,6 +284,11 @@ var (
 		Value:    ethconfig.Defaults.StateHistory,
 		Category: flags.StateCategory,
 	}
+	StateIndexFlag = &cli.BoolFlag{
+		Name:     "history.state.index",
+		Usage:    "Index the retained state histories to serve historic state queries, only relevant in state.scheme=path",
+		Category: flags.StateCategory,
+	}
 	TransactionHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.transactions",
 		Usage:    "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
@@ -279,10 +297,25 @@ var (
 	}
 	ChainHistoryFlag = &cli.StringFlag{
 		Name:     "history.chain",
-		Usage:    `Blockchain history retention ("all" or "postmerge")`,
+		Usage:    `Blockchain history retention ("all", "postmerge" or "recent")`,
 		Value:    ethconfig.Defaults.HistoryMode.String(),
 		Category: flags.StateCategory,
 	}
+	ChainHistoryBlocksFlag = &cli.Uint64Flag{
+		Name:     "history.chain.blocks",
+		Usage:    `Number of recent blocks to retain the bodies and receipts of in the "recent" history mode (0 = no limit)`,
+		Category: flags.StateCategory,
+	}
+	ChainHistoryAgeFlag = &cli.DurationFlag{
+		Name:     "history.chain.age",
+		Usage:    `Maximum age of the blocks to retain the bodies and receipts of in the "recent" history mode (0 = no limit)`,
+		Category: flags.StateCategory,
+	}
+	ChainHistoryEraFlag = &cli.StringFlag{
+		Name:     "history.chain.era",
+		Usage:    "Directory or HTTP URL of era files to serve the pruned chain history from",
+		Category: flags.StateCategory,
+	}
 	LogHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.logs",
 		Usage:    "Number of recent blocks to maintain log search index for (default = about one year, 0 = entire chain)",
@@ -422,6 +455,36 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolThrottleSenderFlag = &cli.Uint64Flag{
+		Name:     "txpool.throttle.sender",
+		Usage:    "Maximum gas per second promotable from a single sender (0 = unlimited)",
+		Value:    ethconfig.Defaults.TxPool.Throttle.SenderGas,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolThrottleContractFlag = &cli.Uint64Flag{
+		Name:     "txpool.throttle.contract",
+		Usage:    "Maximum gas per second promotable towards a single contract (0 = unlimited)",
+		Value:    ethconfig.Defaults.TxPool.Throttle.ContractGas,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolThrottleBurstFlag = &cli.DurationFlag{
+		Name:     "txpool.throttle.burst",
+		Usage:    "Time window of unused gas budget allowed to accumulate",
+		Value:    ethconfig.Defaults.TxPool.Throttle.Burst,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolThrottleStrikesFlag = &cli.Uint64Flag{
+		Name:     "txpool.throttle.strikes",
+		Usage:    "Number of new transactions over the gas budget before an offender is penalized (0 = never)",
+		Value:    ethconfig.Defaults.TxPool.Throttle.PenaltyStrikes,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolThrottlePenaltyFlag = &cli.DurationFlag{
+		Name:     "txpool.throttle.penalty",
+		Usage:    "Time a gas budget offender spends in the penalty box",
+		Value:    ethconfig.Defaults.TxPool.Throttle.PenaltyTime,
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -441,6 +504,48 @@ var (
 		Value:    ethconfig.Defaults.BlobPool.PriceBump,
 		Category: flags.BlobPoolCategory,
 	}
+	BlobArchiveFlag = &cli.BoolFlag{
+		Name:     "blobarchive",
+		Usage:    "Archive the blobs of finalized blob transactions instead of dropping them",
+		Value:    ethconfig.Defaults.BlobArchive.Enabled,
+		Category: flags.BlobPoolCategory,
+	}
+	BlobArchiveRetentionFlag = &cli.DurationFlag{
+		Name:     "blobarchive.retention",
+		Usage:    "Maximum age of archived blobs by inclusion block time (0 = unlimited)",
+		Value:    ethconfig.Defaults.BlobArchive.RetainTime,
+		Category: flags.BlobPoolCategory,
+	}
+	BlobArchiveMaxSizeFlag = &cli.Uint64Flag{
+		Name:     "blobarchive.maxsize",
+		Usage:    "Maximum disk space to allocate for archived blobs in bytes (0 = unlimited)",
+		Value:    ethconfig.Defaults.BlobArchive.RetainSize,
+		Category: flags.BlobPoolCategory,
+	}
+	BundlePoolFlag = &cli.BoolFlag{
+		Name:     "bundlepool",
+		Usage:    "Accept transaction bundles over RPC and include them atomically into built blocks",
+		Value:    ethconfig.Defaults.BundlePool.Enabled,
+		Category: flags.MinerCategory,
+	}
+	BundlePoolMaxBundlesFlag = &cli.IntFlag{
+		Name:     "bundlepool.maxbundles",
+		Usage:    "Maximum number of bundles tracked by the bundle pool",
+		Value:    ethconfig.Defaults.BundlePool.MaxBundles,
+		Category: flags.MinerCategory,
+	}
+	BundlePoolMaxTxsFlag = &cli.IntFlag{
+		Name:     "bundlepool.maxtxs",
+		Usage:    "Maximum number of transactions within a single bundle",
+		Value:    ethconfig.Defaults.BundlePool.MaxTxs,
+		Category: flags.MinerCategory,
+	}
+	BundlePoolBlocksAheadFlag = &cli.Uint64Flag{
+		Name:     "bundlepool.blocksahead",
+		Usage:    "Maximum number of blocks ahead of the chain head a bundle may target",
+		Value:    ethconfig.Defaults.BundlePool.BlocksAhead,
+		Category: flags.MinerCategory,
+	}
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
 		Name:     "cache",
@@ -560,6 +665,11 @@ var (
 		Value:    "{}",
 		Category: flags.VMCategory,
 	}
+	VMTraceTracersFlag = &cli.StringFlag{
+		Name:     "vmtrace.tracers",
+		Usage:    `Live tracers to run concurrently, as a JSON list (e.g. [{"name": "supply", "config": {...}}])`,
+		Category: flags.VMCategory,
+	}
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
 		Name:     "rpc.gascap",
@@ -979,6 +1089,7 @@ var (
 		AncientFlag,
 		RemoteDBFlag,
 		DBEngineFlag,
+		DBReplicaFlag,
 		StateSchemeFlag,
 		HttpHeaderFlag,
 	}
@@ -1405,6 +1516,20 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
 		cfg.DBEngine = dbEngine
 	}
+	if ctx.IsSet(DBReplicaFlag.Name) {
+		if ctx.IsSet(DBServerFlag.Name) {
+			Fatalf("Flags --%s and --%s can't be used at the same time", DBReplicaFlag.Name, DBServerFlag.Name)
+		}
+		cfg.DBReplica = ctx.String(DBReplicaFlag.Name)
+
+		// Replicas follow the chain of the primary instead of syncing it.
+		log.Info("Disabling networking of the database replica")
+		cfg.P2P.MaxPeers = 0
+		cfg.P2P.NoDiscovery = true
+	}
+	if ctx.IsSet(DBServerFlag.Name) {
+		cfg.DBServer = ctx.String(DBServerFlag.Name)
+	}
 	// deprecation notice for log debug flags (TODO: find a more appropriate place to put these?)
 	if ctx.IsSet(LogBacktraceAtFlag.Name) {
 		log.Warn("log.backtrace flag is deprecated")
@@ -1505,6 +1630,21 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolThrottleSenderFlag.Name) {
+		cfg.Throttle.SenderGas = ctx.Uint64(TxPoolThrottleSenderFlag.Name)
+	}
+	if ctx.IsSet(TxPoolThrottleContractFlag.Name) {
+		cfg.Throttle.ContractGas = ctx.Uint64(TxPoolThrottleContractFlag.Name)
+	}
+	if ctx.IsSet(TxPoolThrottleBurstFlag.Name) {
+		cfg.Throttle.Burst = ctx.Duration(TxPoolThrottleBurstFlag.Name)
+	}
+	if ctx.IsSet(TxPoolThrottleStrikesFlag.Name) {
+		cfg.Throttle.PenaltyStrikes = ctx.Uint64(TxPoolThrottleStrikesFlag.Name)
+	}
+	if ctx.IsSet(TxPoolThrottlePenaltyFlag.Name) {
+		cfg.Throttle.PenaltyTime = ctx.Duration(TxPoolThrottlePenaltyFlag.Name)
+	}
 }
 
 func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
@@ -1519,6 +1659,33 @@ func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
 	}
 }
 
+func setBlobArchive(ctx *cli.Context, cfg *blobarchive.Config) {
+	if ctx.IsSet(BlobArchiveFlag.Name) {
+		cfg.Enabled = ctx.Bool(BlobArchiveFlag.Name)
+	}
+	if ctx.IsSet(BlobArchiveRetentionFlag.Name) {
+		cfg.RetainTime = ctx.Duration(BlobArchiveRetentionFlag.Name)
+	}
+	if ctx.IsSet(BlobArchiveMaxSizeFlag.Name) {
+		cfg.RetainSize = ctx.Uint64(BlobArchiveMaxSizeFlag.Name)
+	}
+}
+
+func setBundlePool(ctx *cli.Context, cfg *bundlepool.Config) {
+	if ctx.IsSet(BundlePoolFlag.Name) {
+		cfg.Enabled = ctx.Bool(BundlePoolFlag.Name)
+	}
+	if ctx.IsSet(BundlePoolMaxBundlesFlag.Name) {
+		cfg.MaxBundles = ctx.Int(BundlePoolMaxBundlesFlag.Name)
+	}
+	if ctx.IsSet(BundlePoolMaxTxsFlag.Name) {
+		cfg.MaxTxs = ctx.Int(BundlePoolMaxTxsFlag.Name)
+	}
+	if ctx.IsSet(BundlePoolBlocksAheadFlag.Name) {
+		cfg.BlocksAhead = ctx.Uint64(BundlePoolBlocksAheadFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.Bool(MiningEnabledFlag.Name) {
 		log.Warn("The flag --mine is deprecated and will be removed")
@@ -1580,6 +1747,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
 	setBlobPool(ctx, &cfg.BlobPool)
+	setBlobArchive(ctx, &cfg.BlobArchive)
+	setBundlePool(ctx, &cfg.BundlePool)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1619,6 +1788,18 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			Fatalf("--%s: %v", ChainHistoryFlag.Name, err)
 		}
 	}
+	if ctx.IsSet(ChainHistoryBlocksFlag.Name) {
+		cfg.HistoryWindow.Blocks = ctx.Uint64(ChainHistoryBlocksFlag.Name)
+	}
+	if ctx.IsSet(ChainHistoryAgeFlag.Name) {
+		cfg.HistoryWindow.Age = ctx.Duration(ChainHistoryAgeFlag.Name)
+	}
+	if ctx.IsSet(ChainHistoryEraFlag.Name) {
+		cfg.HistoryEraStore = ctx.String(ChainHistoryEraFlag.Name)
+	}
+	if cfg.HistoryMode == history.KeepRecent && !cfg.HistoryWindow.IsValid() {
+		Fatalf("--%s %s requires --%s or --%s", ChainHistoryFlag.Name, history.KeepRecent, ChainHistoryBlocksFlag.Name, ChainHistoryAgeFlag.Name)
+	}
 
 	if ctx.IsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.Uint64(NetworkIdFlag.Name)
@@ -1649,6 +1830,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateHistoryFlag.Name) {
 		cfg.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
 	}
+	if ctx.IsSet(StateIndexFlag.Name) {
+		cfg.StateIndex = ctx.Bool(StateIndexFlag.Name)
+	}
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
@@ -1865,6 +2049,32 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.VMTraceJsonConfig = ctx.String(VMTraceJsonConfigFlag.Name)
 		}
 	}
+	if ctx.IsSet(VMTraceTracersFlag.Name) {
+		live, err := liveTracersFromFlags(ctx)
+		if err != nil {
+			Fatalf("Invalid %s: %v", VMTraceTracersFlag.Name, err)
+		}
+		cfg.LiveTracers = live
+	}
+}
+
+// liveTracersFromFlags parses the live tracers configured on the command line.
+func liveTracersFromFlags(ctx *cli.Context) ([]tracers.LiveTracerConfig, error) {
+	var specs []struct {
+		Name   string          `json:"name"`
+		Config json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(ctx.String(VMTraceTracersFlag.Name)), &specs); err != nil {
+		return nil, err
+	}
+	live := make([]tracers.LiveTracerConfig, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, errors.New("missing tracer name")
+		}
+		live = append(live, tracers.LiveTracerConfig{Name: spec.Name, Config: string(spec.Config)})
+	}
+	return live, nil
 }
 
 // MakeBeaconLightConfig constructs a beacon light client config based on the
@@ -1980,6 +2190,10 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (*eth.EthAPIBac
 		Fatalf("Failed to register the Ethereum service: %v", err)
 	}
 	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+
+	jobs := tracers.NewTraceJobManager(backend.APIBackend, stack.ResolvePath("tracejobs"))
+	stack.RegisterAPIs(jobs.APIs())
+	stack.RegisterLifecycle(jobs)
 	return backend.APIBackend, backend
 }
 
@@ -2202,6 +2416,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
 		StateScheme:         scheme,
 		StateHistory:        ctx.Uint64(StateHistoryFlag.Name),
+		StateIndex:          ctx.Bool(StateIndexFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -2226,7 +2441,20 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	vmcfg := vm.Config{
 		EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name),
 	}
-	if ctx.IsSet(VMTraceFlag.Name) {
+	if ctx.IsSet(VMTraceTracersFlag.Name) {
+		live, err := liveTracersFromFlags(ctx)
+		if err != nil {
+			Fatalf("Invalid %s: %v", VMTraceTracersFlag.Name, err)
+		}
+		if name := ctx.String(VMTraceFlag.Name); name != "" {
+			live = append([]tracers.LiveTracerConfig{{Name: name, Config: ctx.String(VMTraceJsonConfigFlag.Name)}}, live...)
+		}
+		t, err := tracers.LiveDirectory.NewMux(live)
+		if err != nil {
+			Fatalf("Failed to create live tracers: %v", err)
+		}
+		vmcfg.Tracer = t
+	} else if ctx.IsSet(VMTraceFlag.Name) {
 		if name := ctx.String(VMTraceFlag.Name); name != "" {
 			config := json.RawMessage(ctx.String(VMTraceJsonConfigFlag.Name))
 			t, err := tracers.LiveDirectory.New(name, config)
@@ -21,14 +21,18 @@ import (
 	"crypto/sha256"
 	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/history"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -44,7 +48,9 @@ var (
 	step  uint64 = 16
 )
 
-func TestHistoryImportAndExport(t *testing.T) {
+// newHistoryChain generates a chain of count blocks with a transaction in each,
+// returning the blockchain along with its genesis.
+func newHistoryChain(t *testing.T) (*core.BlockChain, *core.Genesis) {
 	var (
 		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		address = crypto.PubkeyToAddress(key.PublicKey)
@@ -86,6 +92,30 @@ func TestHistoryImportAndExport(t *testing.T) {
 		t.Fatalf("error inserting chain: %v", err)
 	}
 
+	return chain, genesis
+}
+
+// newImportChain creates an empty blockchain with the given genesis to import
+// history into.
+func newImportChain(t *testing.T, genesis *core.Genesis) *core.BlockChain {
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	genesis.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+	chain, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	return chain
+}
+
+func TestHistoryImportAndExport(t *testing.T) {
+	chain, genesis := newHistoryChain(t)
+
 	// Make temp directory for era files.
 	dir := t.TempDir()
 
@@ -157,20 +187,101 @@ func TestHistoryImportAndExport(t *testing.T) {
 		}()
 	}
 
-	// Now import Era.
-	db2, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	// The exported files must be servable as an era history store.
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+	store, err := history.NewEraStore(history.EraStoreConfig{Source: server.URL, Network: "mainnet", CacheDir: t.TempDir(), EraSize: step}, chain.Config())
 	if err != nil {
-		panic(err)
+		t.Fatalf("failed to create era store: %v", err)
+	}
+	for _, n := range []uint64{1, step, count} {
+		want := chain.GetBlockByNumber(n)
+		if block, err := store.Block(want.Header()); err != nil || block.Hash() != want.Hash() {
+			t.Fatalf("failed to serve block %d from era store: %v", n, err)
+		}
+		if receipts, err := store.Receipts(want.Header()); err != nil || len(receipts) != len(want.Transactions()) {
+			t.Fatalf("failed to serve receipts %d from era store: %v", n, err)
+		}
 	}
-	t.Cleanup(func() {
-		db2.Close()
-	})
 
-	genesis.MustCommit(db2, triedb.NewDatabase(db2, triedb.HashDefaults))
-	imported, err := core.NewBlockChain(db2, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil)
+	// Now import Era.
+	imported := newImportChain(t, genesis)
+	if err := ImportHistory(imported, dir, "mainnet"); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	if have, want := imported.CurrentHeader(), chain.CurrentHeader(); have.Hash() != want.Hash() {
+		t.Fatalf("imported chain does not match expected, have (%d, %s) want (%d, %s)", have.Number, have.Hash(), want.Number, want.Hash())
+	}
+}
+
+func TestL2HistoryImportAndExport(t *testing.T) {
+	chain, genesis := newHistoryChain(t)
+
+	// Attach L1 origins to all but the first few blocks.
+	origins := make(map[uint64]*era.L1Origin)
+	for n := uint64(8); n <= count; n++ {
+		origins[n] = &era.L1Origin{Number: 1000 + n/4, Hash: common.Hash{0xff, byte(n / 4)}}
+	}
+	dir := t.TempDir()
+	if err := ExportL2History(chain, dir, 0, count, step, origins); err != nil {
+		t.Fatalf("error exporting history: %v", err)
+	}
+	if entries, _ := era.ReadDir(dir, "mainnet"); len(entries) != 0 {
+		t.Fatalf("unexpected era1 files: %v", entries)
+	}
+	entries, err := era.ReadL2Dir(dir, "mainnet")
 	if err != nil {
-		t.Fatalf("unable to initialize chain: %v", err)
+		t.Fatalf("error reading era dir: %v", err)
 	}
+	if want := int((count + step) / step); len(entries) != want {
+		t.Fatalf("unexpected number of era files: have %d, want %d", len(entries), want)
+	}
+	for i, filename := range entries {
+		func() {
+			e, err := era.Open(filepath.Join(dir, filename))
+			if err != nil {
+				t.Fatalf("error opening era: %v", err)
+			}
+			defer e.Close()
+			if !e.IsL2() {
+				t.Fatalf("era %d not in l2 format", i)
+			}
+			it, err := era.NewIterator(e)
+			if err != nil {
+				t.Fatalf("error making era reader: %v", err)
+			}
+			var (
+				hashes []common.Hash
+				have   []*era.L1Origin
+			)
+			for j := 0; it.Next(); j++ {
+				n := uint64(i)*step + uint64(j)
+				block, err := it.Block()
+				if err != nil {
+					t.Fatalf("error reading block entry %d: %v", n, err)
+				}
+				if want := chain.GetBlockByNumber(n); want.Hash() != block.Hash() {
+					t.Fatalf("block hash mismatch %d: want %s, got %s", n, want.Hash().Hex(), block.Hash().Hex())
+				}
+				origin, err := it.L1Origin()
+				if err != nil {
+					t.Fatalf("error reading l1 origin %d: %v", n, err)
+				}
+				if !reflect.DeepEqual(origin, origins[n]) {
+					t.Fatalf("l1 origin mismatch %d: want %v, got %v", n, origins[n], origin)
+				}
+				hashes = append(hashes, block.Hash())
+				have = append(have, origin)
+			}
+			want, _ := era.ComputeL2Accumulator(hashes, have)
+			if root, _ := e.Accumulator(); root != want {
+				t.Fatalf("accumulator mismatch %d: have %s, want %s", i, root, want)
+			}
+		}()
+	}
+
+	// Import the L2 eras into a fresh chain.
+	imported := newImportChain(t, genesis)
 	if err := ImportHistory(imported, dir, "mainnet"); err != nil {
 		t.Fatalf("failed to import chain: %v", err)
 	}
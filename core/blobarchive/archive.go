@@ -0,0 +1,433 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package blobarchive implements a persistent store for the sidecars of included
+// blob transactions, retaining them after the blob pool has dropped them.
+package blobarchive
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// pruneInterval is the time interval between two retention checks when no
+	// new sidecars are being archived.
+	pruneInterval = 10 * time.Minute
+
+	// metaBatch is the number of metadata items to read at once when scanning
+	// the archive on startup.
+	metaBatch = 1024
+)
+
+var (
+	// archivedMeter counts the number of blob transactions archived.
+	archivedMeter = metrics.NewRegisteredMeter("blobarchive/archived", nil)
+
+	// prunedMeter counts the number of blob transactions pruned from the archive.
+	prunedMeter = metrics.NewRegisteredMeter("blobarchive/pruned", nil)
+)
+
+// ErrNotArchived is returned if a requested blob is not present in the archive,
+// either because it was never seen by the node or because it was pruned.
+var ErrNotArchived = errors.New("blob not archived")
+
+// Config are the configuration parameters of the blob archive.
+type Config struct {
+	Enabled    bool          // Whether to archive the sidecars of included blob transactions
+	RetainTime time.Duration // Maximum age of archived sidecars by block time (0 = unlimited)
+	RetainSize uint64        // Maximum total size of archived sidecars in bytes (0 = unlimited)
+}
+
+// DefaultConfig contains the default configurations for the blob archive.
+var DefaultConfig = Config{
+	Enabled: false,
+}
+
+// ChainReader defines the methods needed to access the local blockchain when
+// archiving sidecars.
+type ChainReader interface {
+	GetBlockByNumber(number uint64) *types.Block
+}
+
+// entryMeta is the metadata stored alongside each archived sidecar.
+type entryMeta struct {
+	TxHash      common.Hash   // Hash of the blob transaction owning the sidecar
+	BlockHash   common.Hash   // Hash of the block the transaction was included in
+	BlockNumber uint64        // Number of the block the transaction was included in
+	BlockTime   uint64        // Timestamp of the block, used for time based retention
+	BlobIndex   uint64        // Index of the transaction's first blob within the block
+	BlobHashes  []common.Hash // Versioned hashes of the blobs in the sidecar
+	Size        uint64        // Size of the encoded sidecar, used for size based retention
+}
+
+// Archive is a persistent, append-only store of blob sidecars. Each archived
+// transaction occupies one item in a dedicated freezer, indexed by the versioned
+// hashes of its blobs. Old items are pruned from the tail based on the configured
+// retention limits.
+type Archive struct {
+	config Config
+	db     ethdb.KeyValueStore          // Database holding the versioned hash index
+	store  ethdb.ResettableAncientStore // Freezer holding the archived sidecars
+	chain  ChainReader
+
+	size uint64       // Total size of the retained sidecars
+	lock sync.RWMutex // Protects the store and the size from concurrent access
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New opens the blob archive residing next to the chain freezer of the given
+// database. If the database has no freezer, an in-memory archive is used.
+func New(config Config, db ethdb.Database, chain ChainReader) (*Archive, error) {
+	datadir, err := db.AncientDatadir()
+	if err != nil {
+		datadir = "" // no freezer configured, fall back to an ephemeral archive
+	}
+	store, err := rawdb.NewBlobArchiveFreezer(datadir, false)
+	if err != nil {
+		return nil, err
+	}
+	a := &Archive{
+		config: config,
+		db:     db,
+		store:  store,
+		chain:  chain,
+		closed: make(chan struct{}),
+	}
+	if err := a.index(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	a.prune()
+
+	a.wg.Add(1)
+	go a.loop()
+	return a, nil
+}
+
+// index scans the metadata of all retained items to calculate the archive size.
+func (a *Archive) index() error {
+	tail, err := a.store.Tail()
+	if err != nil {
+		return err
+	}
+	head, err := a.store.Ancients()
+	if err != nil {
+		return err
+	}
+	for start := tail; start < head; start += metaBatch {
+		blobs, err := rawdb.ReadBlobArchiveMetaList(a.store, start, min(metaBatch, head-start))
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			var meta entryMeta
+			if err := rlp.DecodeBytes(blob, &meta); err != nil {
+				return err
+			}
+			a.size += meta.Size
+		}
+	}
+	log.Info("Opened blob archive", "items", head-tail, "size", common.StorageSize(a.size))
+	return nil
+}
+
+// loop periodically enforces the time based retention, even if there are no
+// new sidecars being archived.
+func (a *Archive) loop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.lock.Lock()
+			a.prune()
+			a.lock.Unlock()
+		case <-a.closed:
+			return
+		}
+	}
+}
+
+// Close terminates the background pruning and closes the underlying store.
+func (a *Archive) Close() error {
+	close(a.closed)
+	a.wg.Wait()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.store.Close()
+}
+
+// ArchiveBlobTx persists the sidecar of a blob transaction included in the given
+// block. The block is expected to be finalized, transactions not found in the
+// canonical block at that height are ignored.
+func (a *Archive) ArchiveBlobTx(tx *types.Transaction, number uint64) {
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return
+	}
+	block := a.chain.GetBlockByNumber(number)
+	if block == nil {
+		log.Warn("Blob transaction inclusion block unavailable", "tx", tx.Hash(), "number", number)
+		return
+	}
+	var (
+		txhash = tx.Hash()
+		index  uint64
+		found  bool
+	)
+	for _, btx := range block.Transactions() {
+		if btx.Hash() == txhash {
+			found = true
+			break
+		}
+		index += uint64(len(btx.BlobHashes()))
+	}
+	if !found {
+		log.Warn("Blob transaction not in canonical inclusion block", "tx", txhash, "number", number)
+		return
+	}
+	if err := a.archive(tx, block, index); err != nil {
+		log.Error("Failed to archive blob sidecar", "tx", txhash, "number", number, "err", err)
+	}
+}
+
+// archive appends the sidecar of the given transaction to the store, indexes
+// its blobs and enforces the retention limits.
+func (a *Archive) archive(tx *types.Transaction, block *types.Block, index uint64) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	// Skip the sidecar if it's already archived (e.g. crash before the blob pool
+	// could drop it after a previous archival). The same blob may be reused by
+	// different transactions, so only consider it a duplicate if owned by us.
+	hashes := tx.BlobHashes()
+	if id := a.lookup(hashes[0]); id != nil {
+		var meta entryMeta
+		if err := rlp.DecodeBytes(rawdb.ReadBlobArchiveMeta(a.store, *id), &meta); err == nil && meta.TxHash == tx.Hash() {
+			return nil
+		}
+	}
+	sidecar, err := rlp.EncodeToBytes(tx.BlobTxSidecar())
+	if err != nil {
+		return err
+	}
+	meta, err := rlp.EncodeToBytes(&entryMeta{
+		TxHash:      tx.Hash(),
+		BlockHash:   block.Hash(),
+		BlockNumber: block.NumberU64(),
+		BlockTime:   block.Time(),
+		BlobIndex:   index,
+		BlobHashes:  hashes,
+		Size:        uint64(len(sidecar)),
+	})
+	if err != nil {
+		return err
+	}
+	id, err := a.store.Ancients()
+	if err != nil {
+		return err
+	}
+	if err := rawdb.WriteBlobArchive(a.store, id, meta, sidecar); err != nil {
+		return err
+	}
+	// Blobs reused by an earlier archived transaction are repointed to the new
+	// item, which is the one retained the longest.
+	batch := a.db.NewBatch()
+	for _, vhash := range hashes {
+		rawdb.WriteBlobArchiveIndex(batch, vhash, id)
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	a.size += uint64(len(sidecar))
+	archivedMeter.Mark(1)
+
+	a.prune()
+	return nil
+}
+
+// lookup returns the id of the retained archive item containing the blob with
+// the given versioned hash, or nil if it's not archived (anymore).
+//
+// The caller must hold the archive lock.
+func (a *Archive) lookup(vhash common.Hash) *uint64 {
+	id := rawdb.ReadBlobArchiveIndex(a.db, vhash)
+	if id == nil {
+		return nil
+	}
+	tail, err := a.store.Tail()
+	if err != nil || *id < tail {
+		return nil
+	}
+	return id
+}
+
+// prune drops items from the tail of the archive until all the configured
+// retention limits are satisfied.
+//
+// The caller must hold the archive lock.
+func (a *Archive) prune() {
+	if a.config.RetainTime == 0 && a.config.RetainSize == 0 {
+		return
+	}
+	tail, err := a.store.Tail()
+	if err != nil {
+		log.Error("Failed to retrieve blob archive tail", "err", err)
+		return
+	}
+	head, err := a.store.Ancients()
+	if err != nil {
+		log.Error("Failed to retrieve blob archive head", "err", err)
+		return
+	}
+	var (
+		cutoff  = uint64(time.Now().Add(-a.config.RetainTime).Unix())
+		batch   = a.db.NewBatch()
+		newTail = tail
+	)
+	for ; newTail < head; newTail++ {
+		var (
+			overSize = a.config.RetainSize != 0 && a.size > a.config.RetainSize
+			meta     entryMeta
+		)
+		if err := rlp.DecodeBytes(rawdb.ReadBlobArchiveMeta(a.store, newTail), &meta); err != nil {
+			log.Error("Failed to decode blob archive metadata", "id", newTail, "err", err)
+			break
+		}
+		expired := a.config.RetainTime != 0 && meta.BlockTime < cutoff
+		if !overSize && !expired {
+			break
+		}
+		for _, vhash := range meta.BlobHashes {
+			if id := rawdb.ReadBlobArchiveIndex(a.db, vhash); id != nil && *id == newTail {
+				rawdb.DeleteBlobArchiveIndex(batch, vhash)
+			}
+		}
+		a.size -= meta.Size
+	}
+	if newTail == tail {
+		return
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to delete blob archive indices", "err", err)
+	}
+	if _, err := a.store.TruncateTail(newTail); err != nil {
+		log.Error("Failed to truncate blob archive", "tail", newTail, "err", err)
+		return
+	}
+	prunedMeter.Mark(int64(newTail - tail))
+	log.Debug("Pruned blob archive", "items", newTail-tail, "size", common.StorageSize(a.size))
+}
+
+// Blobs retrieves the archived blob sidecars for the given versioned hashes. The
+// returned slice has the same length as the requested hashes, with nil entries
+// for blobs not present in the archive.
+func (a *Archive) Blobs(vhashes []common.Hash) ([]*types.BlobSidecar, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	var (
+		res   = make([]*types.BlobSidecar, len(vhashes))
+		items = make(map[uint64][]*types.BlobSidecar)
+	)
+	for i, vhash := range vhashes {
+		id := a.lookup(vhash)
+		if id == nil {
+			continue
+		}
+		blobs, ok := items[*id]
+		if !ok {
+			var err error
+			if blobs, err = a.read(*id); err != nil {
+				return nil, err
+			}
+			items[*id] = blobs
+		}
+		for _, blob := range blobs {
+			if blob.VersionedHash == vhash {
+				res[i] = blob
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// read retrieves the archive item with the given id and splits it up into the
+// individual blob sidecars.
+//
+// The caller must hold the archive lock.
+func (a *Archive) read(id uint64) ([]*types.BlobSidecar, error) {
+	var meta entryMeta
+	if err := rlp.DecodeBytes(rawdb.ReadBlobArchiveMeta(a.store, id), &meta); err != nil {
+		return nil, err
+	}
+	var sidecar types.BlobTxSidecar
+	if err := rlp.DecodeBytes(rawdb.ReadBlobArchiveSidecar(a.store, id), &sidecar); err != nil {
+		return nil, err
+	}
+	blobs := make([]*types.BlobSidecar, len(sidecar.Blobs))
+	for i := range sidecar.Blobs {
+		var proofs []kzg4844.Proof
+		switch sidecar.Version {
+		case types.BlobSidecarVersion0:
+			proofs = []kzg4844.Proof{sidecar.Proofs[i]}
+		default:
+			cellProofs, err := sidecar.CellProofsAt(i)
+			if err != nil {
+				return nil, err
+			}
+			proofs = cellProofs
+		}
+		blobs[i] = &types.BlobSidecar{
+			Index:         meta.BlobIndex + uint64(i),
+			Blob:          &sidecar.Blobs[i],
+			Commitment:    sidecar.Commitments[i],
+			Proofs:        proofs,
+			VersionedHash: meta.BlobHashes[i],
+			TxHash:        meta.TxHash,
+			BlockHash:     meta.BlockHash,
+			BlockNumber:   meta.BlockNumber,
+		}
+	}
+	return blobs, nil
+}
+
+// Size returns the total size of the retained sidecars.
+func (a *Archive) Size() uint64 {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.size
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blobarchive
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// testChain is a minimal chain reader serving blocks from memory.
+type testChain map[uint64]*types.Block
+
+func (c testChain) GetBlockByNumber(number uint64) *types.Block {
+	return c[number]
+}
+
+// makeBlobTx creates a blob transaction with the given number of blobs, filled
+// with the seed so that every transaction has distinct versioned hashes. The
+// sidecar contents are not valid KZG data, which the archive doesn't check.
+func makeBlobTx(nonce uint64, blobs int, seed byte) *types.Transaction {
+	sidecar := &types.BlobTxSidecar{}
+	for i := 0; i < blobs; i++ {
+		var (
+			blob   kzg4844.Blob
+			commit kzg4844.Commitment
+			proof  kzg4844.Proof
+		)
+		blob[0], blob[1] = seed, byte(i)
+		commit[0], commit[1] = seed, byte(i)
+		proof[0], proof[1] = seed, byte(i)
+
+		sidecar.Blobs = append(sidecar.Blobs, blob)
+		sidecar.Commitments = append(sidecar.Commitments, commit)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+	return types.NewTx(&types.BlobTx{
+		Nonce:      nonce,
+		Gas:        21000,
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+}
+
+// makeBlock creates a block with the given number, timestamp and transactions.
+func makeBlock(number uint64, time uint64, txs ...*types.Transaction) *types.Block {
+	header := &types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Time:   time,
+	}
+	return types.NewBlock(header, &types.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
+}
+
+// Tests that archived blobs can be retrieved both by versioned hash and with
+// the correct position within their inclusion block.
+func TestArchiveLookup(t *testing.T) {
+	var (
+		tx1   = makeBlobTx(0, 2, 1)
+		tx2   = makeBlobTx(1, 3, 2)
+		tx3   = makeBlobTx(2, 1, 3)
+		block = makeBlock(1, uint64(time.Now().Unix()), tx1, tx2)
+		chain = testChain{1: block}
+	)
+	archive, err := New(Config{Enabled: true}, rawdb.NewMemoryDatabase(), chain)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer archive.Close()
+
+	archive.ArchiveBlobTx(tx1, 1)
+	archive.ArchiveBlobTx(tx2, 1)
+	archive.ArchiveBlobTx(tx2, 1) // duplicate, should be ignored
+	archive.ArchiveBlobTx(tx3, 1) // not in block, should be ignored
+
+	vhashes := append(append(tx1.BlobHashes(), tx2.BlobHashes()...), tx3.BlobHashes()...)
+	blobs, err := archive.Blobs(vhashes)
+	if err != nil {
+		t.Fatalf("failed to retrieve blobs: %v", err)
+	}
+	if len(blobs) != len(vhashes) {
+		t.Fatalf("blob count mismatch: have %d, want %d", len(blobs), len(vhashes))
+	}
+	for i := 0; i < 5; i++ {
+		blob := blobs[i]
+		if blob == nil {
+			t.Fatalf("blob %d: missing from archive", i)
+		}
+		if blob.Index != uint64(i) {
+			t.Errorf("blob %d: index mismatch: have %d, want %d", i, blob.Index, i)
+		}
+		if blob.VersionedHash != vhashes[i] {
+			t.Errorf("blob %d: versioned hash mismatch: have %x, want %x", i, blob.VersionedHash, vhashes[i])
+		}
+		if blob.BlockHash != block.Hash() || blob.BlockNumber != 1 {
+			t.Errorf("blob %d: block mismatch: have %d/%x, want %d/%x", i, blob.BlockNumber, blob.BlockHash, 1, block.Hash())
+		}
+		owner := tx1
+		if i >= 2 {
+			owner = tx2
+		}
+		if blob.TxHash != owner.Hash() {
+			t.Errorf("blob %d: tx hash mismatch: have %x, want %x", i, blob.TxHash, owner.Hash())
+		}
+		if len(blob.Proofs) != 1 {
+			t.Errorf("blob %d: proof count mismatch: have %d, want 1", i, len(blob.Proofs))
+		}
+	}
+	if blobs[5] != nil {
+		t.Errorf("unincluded blob found in archive")
+	}
+	if head, _ := archive.store.Ancients(); head != 2 {
+		t.Errorf("archive item count mismatch: have %d, want 2", head)
+	}
+}
+
+// Tests that a blob reused by a later transaction resolves to the last archived
+// item, which also survives the pruning of the earlier one.
+func TestArchiveBlobReuse(t *testing.T) {
+	var (
+		tx1   = makeBlobTx(0, 1, 1)
+		tx2   = makeBlobTx(1, 1, 1)
+		now   = uint64(time.Now().Unix())
+		chain = testChain{1: makeBlock(1, now, tx1), 2: makeBlock(2, now, tx2)}
+		limit = uint64(len(kzg4844.Blob{})) + 4096
+	)
+	archive, err := New(Config{Enabled: true, RetainSize: limit}, rawdb.NewMemoryDatabase(), chain)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer archive.Close()
+
+	archive.ArchiveBlobTx(tx1, 1)
+	archive.ArchiveBlobTx(tx2, 2)
+
+	if tail, _ := archive.store.Tail(); tail != 1 {
+		t.Fatalf("archive tail mismatch: have %d, want 1", tail)
+	}
+	blobs, err := archive.Blobs(tx1.BlobHashes())
+	if err != nil {
+		t.Fatalf("failed to retrieve blobs: %v", err)
+	}
+	if blobs[0] == nil {
+		t.Fatalf("reused blob missing from archive")
+	}
+	if blobs[0].TxHash != tx2.Hash() || blobs[0].BlockNumber != 2 {
+		t.Errorf("reused blob owner mismatch: have %x/%d, want %x/%d", blobs[0].TxHash, blobs[0].BlockNumber, tx2.Hash(), 2)
+	}
+}
+
+// Tests that the archive drops the oldest blobs when the size limit is exceeded.
+func TestArchiveSizeRetention(t *testing.T) {
+	var (
+		txs   []*types.Transaction
+		chain = make(testChain)
+		now   = uint64(time.Now().Unix())
+	)
+	for i := 0; i < 4; i++ {
+		tx := makeBlobTx(uint64(i), 1, byte(i))
+		txs = append(txs, tx)
+		chain[uint64(i+1)] = makeBlock(uint64(i+1), now, tx)
+	}
+	// Allow a bit more than two blobs to be retained
+	limit := 2*uint64(len(kzg4844.Blob{})) + 4096
+
+	archive, err := New(Config{Enabled: true, RetainSize: limit}, rawdb.NewMemoryDatabase(), chain)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer archive.Close()
+
+	for i, tx := range txs {
+		archive.ArchiveBlobTx(tx, uint64(i+1))
+	}
+	if size := archive.Size(); size > limit {
+		t.Errorf("archive size above limit: have %d, limit %d", size, limit)
+	}
+	for i, tx := range txs {
+		blobs, err := archive.Blobs(tx.BlobHashes())
+		if err != nil {
+			t.Fatalf("tx %d: failed to retrieve blobs: %v", i, err)
+		}
+		if pruned := i < 2; (blobs[0] == nil) != pruned {
+			t.Errorf("tx %d: retention mismatch: have pruned %v, want %v", i, blobs[0] == nil, pruned)
+		}
+	}
+	for i, tx := range txs[:2] {
+		if id := rawdb.ReadBlobArchiveIndex(archive.db, tx.BlobHashes()[0]); id != nil {
+			t.Errorf("tx %d: index of pruned blob not deleted", i)
+		}
+	}
+}
+
+// Tests that the archive drops blobs included in blocks older than the time
+// limit.
+func TestArchiveTimeRetention(t *testing.T) {
+	var (
+		now   = time.Now()
+		old   = makeBlobTx(0, 1, 1)
+		fresh = makeBlobTx(1, 1, 2)
+		chain = testChain{
+			1: makeBlock(1, uint64(now.Add(-2*time.Hour).Unix()), old),
+			2: makeBlock(2, uint64(now.Unix()), fresh),
+		}
+	)
+	archive, err := New(Config{Enabled: true, RetainTime: time.Hour}, rawdb.NewMemoryDatabase(), chain)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer archive.Close()
+
+	archive.ArchiveBlobTx(old, 1)
+	archive.ArchiveBlobTx(fresh, 2)
+
+	blobs, err := archive.Blobs([]common.Hash{old.BlobHashes()[0], fresh.BlobHashes()[0]})
+	if err != nil {
+		t.Fatalf("failed to retrieve blobs: %v", err)
+	}
+	if blobs[0] != nil {
+		t.Errorf("expired blob retained")
+	}
+	if blobs[1] == nil {
+		t.Errorf("fresh blob pruned")
+	}
+}
@@ -152,6 +152,7 @@ type CacheConfig struct {
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 	StateHistory        uint64        // Number of blocks from head whose state histories are reserved.
+	StateIndex          bool          // Whether to index the state histories for serving historic states
 	StateScheme         string        // Scheme used to store ethereum states and merkle tree nodes on top
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
@@ -160,6 +161,14 @@ type CacheConfig struct {
 	// This defines the cutoff block for history expiry.
 	// Blocks before this number may be unavailable in the chain database.
 	ChainHistoryMode history.HistoryMode
+
+	// ChainHistoryWindow is the rolling window of chain history to keep in
+	// the history.KeepRecent mode.
+	ChainHistoryWindow history.RetentionWindow
+
+	// ChainHistoryKeepTxIndex retains the transaction indexes of the pruned
+	// chain history, whose blocks are served from an external history store.
+	ChainHistoryKeepTxIndex bool
 }
 
 // triedbConfig derives the configures for trie database.
@@ -176,6 +185,7 @@ func (c *CacheConfig) triedbConfig(isVerkle bool) *triedb.Config {
 	if c.StateScheme == rawdb.PathScheme {
 		config.PathDB = &pathdb.Config{
 			StateHistory:    c.StateHistory,
+			StateIndex:      c.StateIndex,
 			CleanCacheSize:  c.TrieCleanLimit * 1024 * 1024,
 			WriteBufferSize: c.TrieDirtyLimit * 1024 * 1024,
 		}
@@ -235,7 +245,9 @@ type BlockChain struct {
 	flushInterval atomic.Int64                     // Time interval (processing time) after which to flush a state
 	triedb        *triedb.Database                 // The database handler for maintaining trie nodes.
 	statedb       *state.CachingDB                 // State database to reuse between imports (contains state cache)
+	historicdb    *state.HistoricDB                // State database for historic states, nil if not supported by the scheme
 	txIndexer     *txIndexer                       // Transaction indexer, might be nil if not enabled
+	historyPruner *historyPruner                   // Rolling history pruner, might be nil if not enabled
 
 	hc               *HeaderChain
 	rmLogsFeed       event.Feed
@@ -330,6 +342,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	}
 	bc.flushInterval.Store(int64(cacheConfig.TrieTimeLimit))
 	bc.statedb = state.NewDatabase(bc.triedb, nil)
+	if bc.triedb.Scheme() == rawdb.PathScheme {
+		bc.historicdb = state.NewHistoricDatabase(db, bc.triedb)
+	}
 	bc.validator = NewBlockValidator(chainConfig, bc)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc.hc)
 	bc.processor = NewStateProcessor(chainConfig, bc.hc)
@@ -487,6 +502,10 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	if txLookupLimit != nil {
 		bc.txIndexer = newTxIndexer(*txLookupLimit, bc)
 	}
+	// Start the rolling history pruner if it's enabled.
+	if bc.cacheConfig.ChainHistoryMode == history.KeepRecent {
+		bc.historyPruner = newHistoryPruner(bc.cacheConfig.ChainHistoryWindow, bc)
+	}
 	return bc, nil
 }
 
@@ -587,6 +606,61 @@ func (bc *BlockChain) loadLastState() error {
 	return nil
 }
 
+// FollowHead updates the chain head markers to the ones persisted in the database
+// by another node sharing it, e.g. the primary of a replica node. No data is
+// written, the new head must already be complete in the database. It returns
+// whether the head block has changed.
+func (bc *BlockChain) FollowHead() (bool, error) {
+	if !bc.chainmu.TryLock() {
+		return false, errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	current := bc.CurrentBlock()
+	head := rawdb.ReadHeadBlockHash(bc.db)
+	if head == (common.Hash{}) || head == current.Hash() {
+		return false, nil
+	}
+	headBlock := bc.GetBlockByHash(head)
+	if headBlock == nil {
+		return false, fmt.Errorf("head block %x missing", head)
+	}
+	// Drop the cached transaction lookups if the old head has been reorged out.
+	if rawdb.ReadCanonicalHash(bc.db, current.Number.Uint64()) != current.Hash() {
+		bc.txLookupLock.Lock()
+		bc.txLookupCache.Purge()
+		bc.txLookupLock.Unlock()
+	}
+	bc.currentBlock.Store(headBlock.Header())
+	headBlockGauge.Update(int64(headBlock.NumberU64()))
+
+	headHeader := headBlock.Header()
+	if head := rawdb.ReadHeadHeaderHash(bc.db); head != (common.Hash{}) {
+		if header := bc.GetHeaderByHash(head); header != nil {
+			headHeader = header
+		}
+	}
+	bc.hc.SetCurrentHeader(headHeader)
+
+	if head := rawdb.ReadHeadFastBlockHash(bc.db); head != (common.Hash{}) {
+		if block := bc.GetBlockByHash(head); block != nil {
+			bc.currentSnapBlock.Store(block.Header())
+			headFastBlockGauge.Update(int64(block.NumberU64()))
+		}
+	}
+	if head := rawdb.ReadFinalizedBlockHash(bc.db); head != (common.Hash{}) {
+		if block := bc.GetBlockByHash(head); block != nil {
+			bc.currentFinalBlock.Store(block.Header())
+			headFinalizedBlockGauge.Update(int64(block.NumberU64()))
+			bc.currentSafeBlock.Store(block.Header())
+			headSafeBlockGauge.Update(int64(block.NumberU64()))
+		}
+	}
+	bc.chainFeed.Send(ChainEvent{Header: headBlock.Header()})
+	bc.chainHeadFeed.Send(ChainHeadEvent{Header: headBlock.Header()})
+	return true, nil
+}
+
 // initializeHistoryPruning sets bc.historyPrunePoint.
 func (bc *BlockChain) initializeHistoryPruning(latest uint64) error {
 	freezerTail, _ := bc.db.Tail()
@@ -627,6 +701,23 @@ func (bc *BlockChain) initializeHistoryPruning(latest uint64) error {
 		bc.historyPrunePoint.Store(predefinedPoint)
 		return nil
 
+	case history.KeepRecent:
+		if !bc.cacheConfig.ChainHistoryWindow.IsValid() {
+			return fmt.Errorf("history mode %q requires a retention window", bc.cacheConfig.ChainHistoryMode.String())
+		}
+		// The database tail is moved continuously by the history pruner,
+		// any of them is acceptable.
+		if freezerTail == 0 {
+			return nil
+		}
+		hash := rawdb.ReadCanonicalHash(bc.db, freezerTail)
+		if hash == (common.Hash{}) {
+			log.Error("Chain history database is pruned to missing block", "tail", freezerTail)
+			return fmt.Errorf("unexpected database tail")
+		}
+		bc.historyPrunePoint.Store(&history.PrunePoint{BlockNumber: freezerTail, BlockHash: hash})
+		return nil
+
 	default:
 		return fmt.Errorf("invalid history mode: %d", bc.cacheConfig.ChainHistoryMode)
 	}
@@ -1151,6 +1242,10 @@ func (bc *BlockChain) stopWithoutSaving() {
 	if !bc.stopping.CompareAndSwap(false, true) {
 		return
 	}
+	// Signal shutdown history pruner, before the tx indexer it relies on.
+	if bc.historyPruner != nil {
+		bc.historyPruner.close()
+	}
 	// Signal shutdown tx indexer.
 	if bc.txIndexer != nil {
 		bc.txIndexer.close()
@@ -0,0 +1,105 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TestHistoricState tests that the states flushed below the disk layer in path
+// scheme are still accessible through the state histories.
+func TestHistoricState(t *testing.T) {
+	testHistoricState(t, false)
+	testHistoricState(t, true)
+}
+
+func testHistoricState(t *testing.T, index bool) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		dest    = common.HexToAddress("0xdeadbeef")
+		slot    = common.HexToHash("0x01")
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				address: {Balance: big.NewInt(params.Ether)},
+				// Store the caller's value into slot 1
+				dest: {Code: []byte{byte(vm.CALLVALUE), byte(vm.PUSH1), 0x1, byte(vm.SSTORE)}},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 16, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), dest, big.NewInt(int64(i+1)), 50000, big.NewInt(10*params.InitialBaseFee), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	config := DefaultCacheConfigWithScheme(rawdb.PathScheme)
+	config.StateIndex = index
+	chain, err := NewBlockChain(db, config, gspec, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Failed to insert block %d: %v", n, err)
+	}
+	// Flush all the states into the disk layer, leaving only the head state
+	// directly accessible
+	head := blocks[len(blocks)-1]
+	if err := chain.triedb.Commit(head.Root(), false); err != nil {
+		t.Fatalf("Failed to flush states: %v", err)
+	}
+	if have := chain.HistoricStateIndexed(); have != index {
+		t.Fatalf("Unexpected index status: have %v, want %v", have, index)
+	}
+	for i, block := range blocks[:len(blocks)-1] {
+		if _, err := chain.StateAt(block.Root()); err == nil {
+			t.Fatalf("Block %d: state is unexpectedly available", i+1)
+		}
+		statedb, err := chain.HistoricState(block.Root())
+		if err != nil {
+			t.Fatalf("Block %d: failed to open historic state: %v", i+1, err)
+		}
+		if have, want := statedb.GetBalance(dest).Uint64(), uint64((i+1)*(i+2)/2); have != want {
+			t.Fatalf("Block %d: balance mismatch, have %d, want %d", i+1, have, want)
+		}
+		if have, want := statedb.GetState(dest, slot), common.BigToHash(big.NewInt(int64(i+1))); have != want {
+			t.Fatalf("Block %d: slot mismatch, have %x, want %x", i+1, have, want)
+		}
+		if have, want := statedb.GetNonce(address), uint64(i+1); have != want {
+			t.Fatalf("Block %d: nonce mismatch, have %d, want %d", i+1, have, want)
+		}
+	}
+}
@@ -358,6 +358,24 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.statedb)
 }
 
+// HistoricState returns a new mutable state based on a particular point in time,
+// reconstructed from the state histories. It's only supported in path scheme,
+// for the states no longer available through StateAt whose state histories
+// are still retained.
+func (bc *BlockChain) HistoricState(root common.Hash) (*state.StateDB, error) {
+	if bc.historicdb == nil {
+		return nil, errors.New("historic states are not supported")
+	}
+	return state.New(root, bc.historicdb)
+}
+
+// HistoricStateIndexed reports whether the state histories are fully indexed,
+// making HistoricState cheap for any state with retained histories. Without
+// the index, only the states shortly below the persisted one can be served.
+func (bc *BlockChain) HistoricStateIndexed() bool {
+	return bc.historicdb != nil && bc.triedb.HistoricIndexed()
+}
+
 // Config retrieves the chain's fork configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 
@@ -4390,3 +4390,58 @@ func testInsertChainWithCutoff(t *testing.T, cutoff uint64, ancientLimit uint64,
 		}
 	}
 }
+
+// Tests that a chain sharing its database with another one follows the head
+// written by the other chain.
+func TestFollowHead(t *testing.T) {
+	var (
+		gspec = &Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		db = rawdb.NewMemoryDatabase()
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, nil)
+
+	primary, err := NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create primary chain: %v", err)
+	}
+	defer primary.Stop()
+	if _, err := primary.InsertChain(blocks[:5]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	replica, err := NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create replica chain: %v", err)
+	}
+	defer replica.Stop()
+
+	heads := make(chan ChainHeadEvent, 1)
+	sub := replica.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	if updated, err := replica.FollowHead(); err != nil || updated {
+		t.Fatalf("unexpected head update: %v, %v", updated, err)
+	}
+	if _, err := primary.InsertChain(blocks[5:]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	if updated, err := replica.FollowHead(); err != nil || !updated {
+		t.Fatalf("head not updated: %v, %v", updated, err)
+	}
+	if head := replica.CurrentBlock(); head.Hash() != blocks[9].Hash() {
+		t.Fatalf("head mismatch: have %d, want %d", head.Number, blocks[9].Number())
+	}
+	if head := replica.CurrentHeader(); head.Hash() != blocks[9].Hash() {
+		t.Fatalf("head header mismatch: have %d, want %d", head.Number, blocks[9].Number())
+	}
+	select {
+	case ev := <-heads:
+		if ev.Header.Hash() != blocks[9].Hash() {
+			t.Fatalf("head event mismatch: have %d, want %d", ev.Header.Number, blocks[9].Number())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no head event")
+	}
+}
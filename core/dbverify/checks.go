@@ -0,0 +1,296 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package dbverify
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// checkFreezer checks that all the items of the chain freezer are readable, and
+// that the canonical hashes match the hashes of the frozen headers.
+func (v *Verifier) checkFreezer(r *checkRun) error {
+	frozen, err := v.db.Ancients()
+	if err != nil {
+		r.skip("ancient store not available")
+		return nil
+	}
+	if frozen == 0 {
+		r.skip("ancient store is empty")
+		return nil
+	}
+	tail, err := v.db.Tail()
+	if err != nil {
+		return err
+	}
+	return r.forEachBlock(0, frozen-1, func(number uint64) error {
+		hash, err := v.db.Ancient(rawdb.ChainFreezerHashTable, number)
+		if err != nil {
+			r.blockIssue(number, common.Hash{}, false, "unreadable canonical hash: %v", err)
+			return nil
+		}
+		blob, err := v.db.Ancient(rawdb.ChainFreezerHeaderTable, number)
+		if err != nil {
+			r.blockIssue(number, common.BytesToHash(hash), false, "unreadable header: %v", err)
+			return nil
+		}
+		if have := crypto.Keccak256Hash(blob); have != common.BytesToHash(hash) {
+			r.blockIssue(number, common.BytesToHash(hash), false, "header hash mismatch: have %x", have)
+			return nil
+		}
+		var header types.Header
+		if err := rlp.DecodeBytes(blob, &header); err != nil {
+			r.blockIssue(number, common.BytesToHash(hash), false, "undecodable header: %v", err)
+			return nil
+		}
+		if header.Number.Uint64() != number {
+			r.blockIssue(number, common.BytesToHash(hash), false, "header number mismatch: have %d", header.Number)
+		}
+		// Bodies and receipts are only available above the pruned history.
+		if number < tail {
+			return nil
+		}
+		if _, err := v.db.Ancient(rawdb.ChainFreezerBodiesTable, number); err != nil {
+			r.blockIssue(number, common.BytesToHash(hash), false, "unreadable body: %v", err)
+		}
+		if _, err := v.db.Ancient(rawdb.ChainFreezerReceiptTable, number); err != nil {
+			r.blockIssue(number, common.BytesToHash(hash), false, "unreadable receipts: %v", err)
+		}
+		return nil
+	})
+}
+
+// checkCanonical checks that the canonical hashes form a chain of headers up to
+// the head header, repairing the missing entries of the header number index.
+func (v *Verifier) checkCanonical(r *checkRun) error {
+	head := rawdb.ReadHeadHeader(v.db)
+	if head == nil {
+		return errors.New("head header not found")
+	}
+	return r.forEachBlock(0, head.Number.Uint64(), func(number uint64) error {
+		hash := rawdb.ReadCanonicalHash(v.db, number)
+		if hash == (common.Hash{}) {
+			r.blockIssue(number, common.Hash{}, false, "missing canonical hash")
+			return nil
+		}
+		if number == head.Number.Uint64() && hash != head.Hash() {
+			r.blockIssue(number, hash, false, "canonical hash mismatch with head header %x", head.Hash())
+		}
+		header := rawdb.ReadHeader(v.db, hash, number)
+		if header == nil {
+			r.blockIssue(number, hash, false, "missing canonical header")
+			return nil
+		}
+		if number > 0 {
+			if parent := rawdb.ReadCanonicalHash(v.db, number-1); parent != (common.Hash{}) && parent != header.ParentHash {
+				r.blockIssue(number, hash, false, "parent hash mismatch: have %x, canonical %x", header.ParentHash, parent)
+			}
+		}
+		if n := rawdb.ReadHeaderNumber(v.db, hash); n == nil || *n != number {
+			if v.config.Repair {
+				rawdb.WriteHeaderNumber(v.db, hash, number)
+			}
+			r.blockIssue(number, hash, v.config.Repair, "header number index missing or mismatched")
+		}
+		return nil
+	})
+}
+
+// historyTail returns the first block with available body and receipts.
+func (v *Verifier) historyTail() uint64 {
+	if frozen, err := v.db.Ancients(); err != nil || frozen == 0 {
+		return 0
+	}
+	tail, _ := v.db.Tail()
+	return tail
+}
+
+// checkBlocks checks that the bodies and receipts of the canonical blocks above
+// the pruned history match the roots of their headers.
+func (v *Verifier) checkBlocks(r *checkRun) error {
+	head := rawdb.ReadHeadBlock(v.db)
+	if head == nil {
+		return errors.New("head block not found")
+	}
+	return r.forEachBlock(v.historyTail(), head.NumberU64(), func(number uint64) error {
+		hash := rawdb.ReadCanonicalHash(v.db, number)
+		header := rawdb.ReadHeader(v.db, hash, number)
+		if header == nil {
+			return nil // Reported by the canonical check
+		}
+		body := rawdb.ReadBody(v.db, hash, number)
+		if body == nil {
+			r.blockIssue(number, hash, false, "missing body")
+			return nil
+		}
+		if root := types.DeriveSha(types.Transactions(body.Transactions), trie.NewStackTrie(nil)); root != header.TxHash {
+			r.blockIssue(number, hash, false, "tx root mismatch: have %x, want %x", root, header.TxHash)
+		}
+		if uncles := types.CalcUncleHash(body.Uncles); uncles != header.UncleHash {
+			r.blockIssue(number, hash, false, "uncle hash mismatch: have %x, want %x", uncles, header.UncleHash)
+		}
+		if header.WithdrawalsHash != nil {
+			if body.Withdrawals == nil {
+				r.blockIssue(number, hash, false, "missing withdrawals")
+			} else if root := types.DeriveSha(types.Withdrawals(body.Withdrawals), trie.NewStackTrie(nil)); root != *header.WithdrawalsHash {
+				r.blockIssue(number, hash, false, "withdrawals root mismatch: have %x, want %x", root, *header.WithdrawalsHash)
+			}
+		}
+		if len(rawdb.ReadReceiptsRLP(v.db, hash, number)) == 0 {
+			r.blockIssue(number, hash, false, "missing receipts")
+			return nil
+		}
+		receipts := rawdb.ReadReceipts(v.db, hash, number, header.Time, v.chain)
+		if receipts == nil {
+			r.blockIssue(number, hash, false, "undecodable receipts")
+			return nil
+		}
+		if root := types.DeriveSha(receipts, trie.NewStackTrie(nil)); root != header.ReceiptHash {
+			r.blockIssue(number, hash, false, "receipt root mismatch: have %x, want %x", root, header.ReceiptHash)
+		}
+		return nil
+	})
+}
+
+// checkTxLookup checks that the transactions of the blocks in the indexed range
+// are mapped to their blocks, repairing the missing and mismatched entries.
+func (v *Verifier) checkTxLookup(r *checkRun) error {
+	tail := rawdb.ReadTxIndexTail(v.db)
+	if tail == nil {
+		r.skip("transaction index not initialized")
+		return nil
+	}
+	head := rawdb.ReadHeadBlock(v.db)
+	if head == nil {
+		return errors.New("head block not found")
+	}
+	return r.forEachBlock(*tail, head.NumberU64(), func(number uint64) error {
+		hash := rawdb.ReadCanonicalHash(v.db, number)
+		body := rawdb.ReadBody(v.db, hash, number)
+		if body == nil {
+			return nil // Pruned history, or reported by the blocks check
+		}
+		var broken []common.Hash
+		for _, tx := range body.Transactions {
+			if n := rawdb.ReadTxLookupEntry(v.db, tx.Hash()); n == nil || *n != number {
+				broken = append(broken, tx.Hash())
+			}
+		}
+		if len(broken) == 0 {
+			return nil
+		}
+		if v.config.Repair {
+			rawdb.WriteTxLookupEntries(v.db, number, broken)
+		}
+		for _, txhash := range broken {
+			r.blockIssue(number, txhash, v.config.Repair, "transaction lookup entry missing or mismatched")
+		}
+		return nil
+	})
+}
+
+// checkLogIndex checks that the log index matches the logs of all the indexed
+// blocks. As the log index can't be repaired partially, it is dropped entirely
+// in repair mode for being rebuilt on the next start.
+func (v *Verifier) checkLogIndex(r *checkRun) error {
+	verifier, err := filtermaps.NewVerifier(v.db, v.config.LogIndexParams)
+	if err != nil {
+		return err
+	}
+	blocks := verifier.IndexedBlocks()
+	if blocks.IsEmpty() {
+		r.skip("log index not initialized")
+		return nil
+	}
+	err = r.forEachBlock(blocks.First(), blocks.Last(), func(number uint64) error {
+		hash := rawdb.ReadCanonicalHash(v.db, number)
+		header := rawdb.ReadHeader(v.db, hash, number)
+		if header == nil {
+			return nil // Reported by the canonical check
+		}
+		receipts := rawdb.ReadReceipts(v.db, hash, number, header.Time, v.chain)
+		if receipts == nil && header.ReceiptHash != types.EmptyReceiptsHash {
+			return nil // Pruned history, or reported by the blocks check
+		}
+		if err := verifier.VerifyBlock(number, receipts); err != nil {
+			r.blockIssue(number, hash, v.config.Repair, "log index mismatch: %v", err)
+		}
+		return nil
+	})
+	if err != nil || r.issues.Load() == 0 || !v.config.Repair {
+		return err
+	}
+	hashScheme := rawdb.ReadStateScheme(v.db) == rawdb.HashScheme
+	if err := rawdb.DeleteFilterMapsDb(v.db, hashScheme, func(bool) bool { return false }); err != nil {
+		return err
+	}
+	rawdb.DeleteFilterMapsRange(v.db)
+	log.Warn("Dropped the damaged log index, it is rebuilt on the next start")
+	return nil
+}
+
+// checkState checks that the snapshot of the head state matches the state
+// trie. The damaged snapshot is dropped in repair mode for being regenerated
+// on the next start.
+func (v *Verifier) checkState(r *checkRun) error {
+	if v.triedb == nil {
+		r.skip("trie database not available")
+		return nil
+	}
+	if rawdb.ReadSnapshotRoot(v.db) == (common.Hash{}) {
+		r.skip("snapshot not available")
+		return nil
+	}
+	head := rawdb.ReadHeadBlock(v.db)
+	if head == nil {
+		return errors.New("head block not found")
+	}
+	root := head.Root()
+	r.items.Add(1)
+
+	var issue string
+	snaptree, err := snapshot.New(snapshot.Config{CacheSize: 256, NoBuild: true}, v.db, v.triedb, root)
+	if err != nil {
+		issue = "failed to load snapshot: " + err.Error()
+	} else {
+		defer snaptree.Release()
+		if err := snaptree.Verify(root); err != nil {
+			issue = "snapshot mismatch with state trie: " + err.Error()
+		} else if err := snapshot.CheckDanglingStorage(v.db); err != nil {
+			issue = "dangling snapshot storage: " + err.Error()
+		}
+	}
+	if issue == "" {
+		return nil
+	}
+	if v.config.Repair {
+		rawdb.DeleteSnapshotRoot(v.db)
+		log.Warn("Dropped the damaged snapshot, it is regenerated on the next start")
+	}
+	number := head.NumberU64()
+	r.issue(&number, root, v.config.Repair, "%s", issue)
+	return nil
+}
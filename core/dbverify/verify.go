@@ -0,0 +1,283 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package dbverify implements an offline integrity checker of the chain
+// database, cross-checking the chain data, the derived indexes and the state.
+package dbverify
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// The names of the available checks.
+const (
+	CheckFreezer   = "freezer"   // Freezer tables are readable and hashes match the headers
+	CheckCanonical = "canonical" // Canonical hash chain is linked and number index is complete
+	CheckBlocks    = "blocks"    // Bodies and receipts match the roots of their headers
+	CheckTxLookup  = "txlookup"  // Transaction lookup entries match the bodies
+	CheckLogIndex  = "logindex"  // Log index matches the logs of the receipts
+	CheckState     = "state"     // Snapshot matches the state trie
+)
+
+// AllChecks is the list of all available checks, in the order they're reported.
+var AllChecks = []string{CheckFreezer, CheckCanonical, CheckBlocks, CheckTxLookup, CheckLogIndex, CheckState}
+
+// blockChunkSize is the number of blocks checked in a single task on the
+// worker pool.
+const blockChunkSize = 1024
+
+// Config contains the options of the verifier.
+type Config struct {
+	Checks  []string // Checks to run, all of them if empty
+	Workers int      // Number of parallel workers, the number of CPUs if zero
+	Repair  bool     // Whether to repair the damage of derived indexes
+
+	LogIndexParams filtermaps.Params // Parameters of the log index
+}
+
+// Issue is an inconsistency found in the database.
+type Issue struct {
+	Check    string       `json:"check"`
+	Number   *uint64      `json:"number,omitempty"`
+	Hash     *common.Hash `json:"hash,omitempty"`
+	Message  string       `json:"message"`
+	Repaired bool         `json:"repaired"`
+}
+
+// Result is the summary of a check.
+type Result struct {
+	Check    string  `json:"check"`
+	Items    uint64  `json:"items"`             // Number of checked items
+	Issues   uint64  `json:"issues"`            // Number of found issues
+	Repaired uint64  `json:"repaired"`          // Number of repaired issues
+	Skipped  string  `json:"skipped,omitempty"` // Reason if the check was not run
+	Error    string  `json:"error,omitempty"`   // Failure aborting the check
+	Seconds  float64 `json:"seconds"`           // Time spent on the check
+}
+
+// Verifier cross-checks the data in a chain database. Checks are run in
+// parallel, with the block based checks split over a shared pool of workers.
+type Verifier struct {
+	db     ethdb.Database
+	triedb *triedb.Database
+	chain  *params.ChainConfig
+	config Config
+	sem    chan struct{} // Semaphore limiting the number of parallel tasks
+
+	lock   sync.Mutex   // Lock serializing the issue reports
+	report func(*Issue) // Callback receiving the found issues
+}
+
+// New creates a verifier of the given database. The trie database is only used
+// by the state check.
+func New(db ethdb.Database, triedb *triedb.Database, chain *params.ChainConfig, config Config) (*Verifier, error) {
+	if len(config.Checks) == 0 {
+		config.Checks = AllChecks
+	}
+	for _, check := range config.Checks {
+		if !slices.Contains(AllChecks, check) {
+			return nil, fmt.Errorf("unknown check %q", check)
+		}
+	}
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	return &Verifier{
+		db:     db,
+		triedb: triedb,
+		chain:  chain,
+		config: config,
+		sem:    make(chan struct{}, config.Workers),
+	}, nil
+}
+
+// Run runs all the configured checks in parallel, passing the found issues to
+// the report callback, and returns the summary of each check.
+func (v *Verifier) Run(report func(*Issue)) []*Result {
+	v.report = report
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]*Result, len(v.config.Checks))
+	)
+	for i, check := range v.config.Checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = v.run(check)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// run runs a single check.
+func (v *Verifier) run(check string) *Result {
+	var (
+		start = time.Now()
+		r     = &checkRun{v: v, check: check}
+		err   error
+	)
+	log.Info("Starting database check", "check", check)
+	switch check {
+	case CheckFreezer:
+		err = v.checkFreezer(r)
+	case CheckCanonical:
+		err = v.checkCanonical(r)
+	case CheckBlocks:
+		err = v.checkBlocks(r)
+	case CheckTxLookup:
+		err = v.checkTxLookup(r)
+	case CheckLogIndex:
+		err = v.checkLogIndex(r)
+	case CheckState:
+		err = v.checkState(r)
+	}
+	result := &Result{
+		Check:    check,
+		Items:    r.items.Load(),
+		Issues:   r.issues.Load(),
+		Repaired: r.repaired.Load(),
+		Skipped:  r.skipped,
+		Seconds:  time.Since(start).Seconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	log.Info("Finished database check", "check", check, "items", result.Items, "issues", result.Issues, "repaired", result.Repaired, "err", err, "elapsed", common.PrettyDuration(time.Since(start)))
+	return result
+}
+
+// checkRun tracks the progress of a single check.
+type checkRun struct {
+	v       *Verifier
+	check   string
+	skipped string
+
+	items    atomic.Uint64
+	issues   atomic.Uint64
+	repaired atomic.Uint64
+}
+
+// skip marks the check as not applicable to the database.
+func (r *checkRun) skip(reason string) {
+	r.skipped = reason
+	log.Info("Skipping database check", "check", r.check, "reason", reason)
+}
+
+// issue reports an inconsistency of the block with the given number, and of the
+// entry with the given hash if non-zero.
+func (r *checkRun) issue(number *uint64, hash common.Hash, repaired bool, format string, args ...any) {
+	issue := &Issue{
+		Check:    r.check,
+		Number:   number,
+		Message:  fmt.Sprintf(format, args...),
+		Repaired: repaired,
+	}
+	if hash != (common.Hash{}) {
+		issue.Hash = &hash
+	}
+	r.issues.Add(1)
+	if repaired {
+		r.repaired.Add(1)
+	}
+	r.v.lock.Lock()
+	defer r.v.lock.Unlock()
+
+	if r.v.report != nil {
+		r.v.report(issue)
+	}
+}
+
+// blockIssue reports an inconsistency of the block with the given number.
+func (r *checkRun) blockIssue(number uint64, hash common.Hash, repaired bool, format string, args ...any) {
+	r.issue(&number, hash, repaired, format, args...)
+}
+
+// forEachBlock runs fn for all the blocks in range [first, last], in chunks
+// dispatched to the shared worker pool. The first error returned by fn aborts
+// the iteration.
+func (r *checkRun) forEachBlock(first, last uint64, fn func(number uint64) error) error {
+	if first > last {
+		return nil
+	}
+	var (
+		start  = time.Now()
+		next   atomic.Uint64
+		failed atomic.Pointer[error]
+		wg     sync.WaitGroup
+		done   = make(chan struct{})
+	)
+	next.Store(first)
+
+	// Report the progress periodically until all the workers are finished.
+	go func() {
+		ticker := time.NewTicker(8 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				at := min(next.Load(), last+1)
+				log.Info("Checking database", "check", r.check, "at", at, "last", last, "issues", r.issues.Load(),
+					"elapsed", common.PrettyDuration(time.Since(start)))
+			case <-done:
+				return
+			}
+		}
+	}()
+	for i := 0; i < r.v.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for failed.Load() == nil {
+				from := next.Add(blockChunkSize) - blockChunkSize
+				if from > last || from < first {
+					return // Exhausted (or wrapped around at the end of the number space)
+				}
+				to := min(from+blockChunkSize-1, last)
+
+				r.v.sem <- struct{}{}
+				for number := from; number <= to; number++ {
+					if err := fn(number); err != nil {
+						failed.CompareAndSwap(nil, &err)
+						break
+					}
+					r.items.Add(1)
+				}
+				<-r.v.sem
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+
+	if err := failed.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package dbverify
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// newTestDatabase creates a database containing a chain of blocks with one
+// transaction each, together with all the transaction lookup entries.
+func newTestDatabase(t *testing.T, n int) (ethdb.Database, []*types.Block) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.LatestSigner(params.TestChainConfig)
+		genesis = &core.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, blocks, receipts := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), n, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		b.AddTx(tx)
+	})
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+	for i, block := range blocks {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteTxLookupEntriesByBlock(db, block)
+	}
+	rawdb.WriteHeadHeaderHash(db, blocks[n-1].Hash())
+	rawdb.WriteHeadBlockHash(db, blocks[n-1].Hash())
+	rawdb.WriteTxIndexTail(db, 0)
+	return db, blocks
+}
+
+func runVerifier(t *testing.T, db ethdb.Database, repair bool) ([]*Issue, map[string]*Result) {
+	v, err := New(db, nil, params.TestChainConfig, Config{Workers: 4, Repair: repair})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	var issues []*Issue
+	results := make(map[string]*Result)
+	for _, result := range v.Run(func(issue *Issue) { issues = append(issues, issue) }) {
+		if result.Error != "" {
+			t.Fatalf("check %s failed: %s", result.Check, result.Error)
+		}
+		results[result.Check] = result
+	}
+	return issues, results
+}
+
+func TestVerifyHealthy(t *testing.T) {
+	db, _ := newTestDatabase(t, 3000)
+
+	issues, results := runVerifier(t, db, false)
+	if len(issues) != 0 {
+		t.Fatalf("unexpected issues: %v", issues[0].Message)
+	}
+	for _, check := range []string{CheckCanonical, CheckBlocks, CheckTxLookup} {
+		if results[check].Skipped != "" {
+			t.Fatalf("check %s skipped: %s", check, results[check].Skipped)
+		}
+	}
+	if have := results[CheckCanonical].Items; have != 3001 {
+		t.Fatalf("canonical item count mismatch: have %d, want 3001", have)
+	}
+	for _, check := range []string{CheckFreezer, CheckLogIndex, CheckState} {
+		if results[check].Skipped == "" {
+			t.Fatalf("check %s not skipped", check)
+		}
+	}
+}
+
+func TestVerifyRepair(t *testing.T) {
+	db, blocks := newTestDatabase(t, 100)
+
+	// Corrupt the repairable indexes and a block body.
+	rawdb.DeleteHeaderNumber(db, blocks[10].Hash())
+	rawdb.DeleteTxLookupEntry(db, blocks[20].Transactions()[0].Hash())
+	rawdb.WriteTxLookupEntries(db, 5, []common.Hash{blocks[30].Transactions()[0].Hash()})
+	rawdb.WriteReceipts(db, blocks[40].Hash(), blocks[40].NumberU64(), nil)
+
+	issues, results := runVerifier(t, db, true)
+	want := map[string]uint64{CheckCanonical: 1, CheckBlocks: 1, CheckTxLookup: 2}
+	for check, n := range want {
+		if results[check].Issues != n {
+			t.Fatalf("check %s issue count mismatch: have %d, want %d", check, results[check].Issues, n)
+		}
+	}
+	for _, issue := range issues {
+		if repaired := issue.Check != CheckBlocks; issue.Repaired != repaired {
+			t.Fatalf("issue %q repaired mismatch: have %v, want %v", issue.Message, issue.Repaired, repaired)
+		}
+	}
+	// Only the unrepairable issue must be left after the repair.
+	issues, _ = runVerifier(t, db, false)
+	if len(issues) != 1 || issues[0].Check != CheckBlocks || *issues[0].Number != 41 {
+		t.Fatalf("unexpected issues after repair: %v", issues)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filtermaps
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// maxVerifyLayers limits the number of mapping layers searched for a log value
+// by the verifier, which is way above the number of layers used in practice.
+const maxVerifyLayers = 32
+
+// Verifier checks the consistency of the stored log index with the logs of the
+// chain, without running the indexer. It is safe for concurrent use.
+type Verifier struct {
+	f *FilterMaps
+}
+
+// NewVerifier creates a verifier for the log index stored in the database.
+func NewVerifier(db ethdb.KeyValueStore, params Params) (*Verifier, error) {
+	rs, initialized, err := rawdb.ReadFilterMapsRange(db)
+	if err != nil {
+		return nil, err
+	}
+	params.deriveFields()
+	f := &FilterMaps{
+		db:     db,
+		Params: params,
+		indexedRange: filterMapsRange{
+			initialized:      initialized,
+			headIndexed:      rs.HeadIndexed,
+			headDelimiter:    rs.HeadDelimiter,
+			blocks:           common.NewRange(rs.BlocksFirst, rs.BlocksAfterLast-rs.BlocksFirst),
+			maps:             common.NewRange(rs.MapsFirst, rs.MapsAfterLast-rs.MapsFirst),
+			tailPartialEpoch: rs.TailPartialEpoch,
+		},
+		lastBlockCache: lru.NewCache[uint32, lastBlockOfMap](cachedLastBlocks),
+		lvPointerCache: lru.NewCache[uint64, uint64](cachedLvPointers),
+		baseRowsCache:  lru.NewCache[uint64, [][]uint32](cachedBaseRows),
+	}
+	return &Verifier{f: f}, nil
+}
+
+// IndexedBlocks returns the range of blocks fully covered by the log index.
+func (v *Verifier) IndexedBlocks() common.Range[uint64] {
+	if !v.f.indexedRange.hasIndexedBlocks() {
+		return common.Range[uint64]{}
+	}
+	return v.f.indexedRange.blocks
+}
+
+// VerifyBlock checks that the log value pointers of an indexed block enclose
+// exactly the log values generated by the given receipts of the block, and that
+// all these log values are marked on the filter maps.
+func (v *Verifier) VerifyBlock(number uint64, receipts types.Receipts) error {
+	f := v.f
+	if !v.IndexedBlocks().Includes(number) {
+		return errUnindexedRange
+	}
+	lvPointer, err := f.getBlockLvPointer(number)
+	if err != nil {
+		return err
+	}
+	for i, receipt := range receipts {
+		for j, log := range receipt.Logs {
+			l := uint64(len(log.Topics) + 1)
+			r := f.valuesPerMap - lvPointer%f.valuesPerMap
+			if l > r {
+				lvPointer += r // skip to map boundary
+			}
+			values := []common.Hash{addressValue(log.Address)}
+			for _, topic := range log.Topics {
+				values = append(values, topicValue(topic))
+			}
+			for k, value := range values {
+				if err := v.checkValue(lvPointer+uint64(k), value); err != nil {
+					return fmt.Errorf("log %d of tx %d: %v", j, i, err)
+				}
+			}
+			lvPointer += l
+		}
+	}
+	// The block is followed by a block delimiter, unless it's the indexed head.
+	switch {
+	case number+1 < f.indexedRange.blocks.AfterLast():
+		next, err := f.getBlockLvPointer(number + 1)
+		if err != nil {
+			return err
+		}
+		// The pointer of the next block is moved to the map boundary if
+		// its first log doesn't fit into the map after the delimiter.
+		want := lvPointer + 1
+		if next != want && (next < want || next%f.valuesPerMap != 0 || next-want >= f.valuesPerMap) {
+			return fmt.Errorf("log value pointer mismatch of next block: have %d, want %d", next, want)
+		}
+	case f.indexedRange.headIndexed:
+		if f.indexedRange.headDelimiter != lvPointer {
+			return fmt.Errorf("head delimiter mismatch: have %d, want %d", f.indexedRange.headDelimiter, lvPointer)
+		}
+	}
+	return nil
+}
+
+// checkValue checks that the log value at the given index is marked on the
+// filter map, on the lowest mapping layer whose row was not yet full.
+func (v *Verifier) checkValue(lvIndex uint64, value common.Hash) error {
+	f := v.f
+	var (
+		mapIndex = uint32(lvIndex >> f.logValuesPerMap)
+		column   = f.columnIndex(lvIndex, &value)
+	)
+	for layer := uint32(0); layer < maxVerifyLayers; layer++ {
+		row, err := f.getFilterMapRow(mapIndex, f.rowIndex(mapIndex, layer, value), false)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(row, column) {
+			return nil
+		}
+		if uint32(len(row)) < f.maxRowLength(layer) {
+			break
+		}
+	}
+	return fmt.Errorf("log value %d missing from filter map %d", lvIndex, mapIndex)
+}
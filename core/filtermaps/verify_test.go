@@ -0,0 +1,74 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filtermaps
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestVerifier(t *testing.T) {
+	ts := newTestSetup(t)
+	defer ts.close()
+
+	ts.chain.addBlocks(200, 5, 2, 4, true)
+	ts.setHistory(0, false)
+	ts.fm.WaitIdle()
+	ts.fm.Stop()
+	ts.fm = nil
+
+	v, err := NewVerifier(ts.db, ts.params)
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	blocks := v.IndexedBlocks()
+	if blocks.Count() != 201 {
+		t.Fatalf("indexed block count mismatch: have %d, want 201", blocks.Count())
+	}
+	var tampered uint64
+	for number := blocks.First(); number < blocks.AfterLast(); number++ {
+		receipts := ts.chain.GetReceiptsByHash(ts.chain.GetCanonicalHash(number))
+		if err := v.VerifyBlock(number, receipts); err != nil {
+			t.Fatalf("failed to verify block %d: %v", number, err)
+		}
+		if tampered == 0 && len(receipts) > 0 && len(receipts[0].Logs) > 0 {
+			tampered = number
+		}
+	}
+	if tampered == 0 {
+		t.Fatal("no block with logs")
+	}
+	// Logs not matching the index must be detected.
+	receipts := ts.chain.GetReceiptsByHash(ts.chain.GetCanonicalHash(tampered))
+	changed := make(types.Receipts, len(receipts))
+	copy(changed, receipts)
+	receipt := *changed[0]
+	receipt.Logs = []*types.Log{{Address: common.Address{0xff}, Topics: receipt.Logs[0].Topics}}
+	changed[0] = &receipt
+	if err := v.VerifyBlock(tampered, changed); err == nil {
+		t.Fatal("expected error verifying tampered logs")
+	}
+	// Missing log value pointers must be detected.
+	rawdb.DeleteBlockLvPointer(ts.db, tampered+1)
+	v, _ = NewVerifier(ts.db, ts.params)
+	if err := v.VerifyBlock(tampered, receipts); err == nil {
+		t.Fatal("expected error verifying block with missing pointer")
+	}
+}
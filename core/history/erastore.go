@@ -0,0 +1,420 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"golang.org/x/sync/singleflight"
+)
+
+// EraChecksumsFile is the name of the file listing the era files served by an
+// HTTP era store, in the format of the sha256sum tool:
+//
+//	<hex sha256>  <era file name>
+const EraChecksumsFile = "checksums_sha256.txt"
+
+// eraCacheItems is the number of blocks and receipts kept in memory by the
+// era store.
+const eraCacheItems = 256
+
+// eraCacheFiles is the default number of downloaded era files kept in the cache
+// directory of an HTTP era store.
+const eraCacheFiles = 16
+
+// eraRefreshInterval is the minimum time between two listings of the available
+// era files, limiting the relisting on lookups of unpublished epochs.
+const eraRefreshInterval = time.Minute
+
+// EraStoreConfig configures the source of an EraStore.
+type EraStoreConfig struct {
+	Source     string // Directory or HTTP base URL of the era files
+	Network    string // Network name in the era file names
+	CacheDir   string // Directory for the era files downloaded from HTTP sources
+	CacheFiles int    // Number of era files kept in the cache directory, defaults to eraCacheFiles
+	EraSize    uint64 // Number of blocks per era file, defaults to era.MaxEra1Size
+}
+
+// EraStore serves the chain history pruned from the local database from a
+// directory or an HTTP server of Era1 or L2 era files. The retrieved data is
+// verified against the locally retained headers, the era files downloaded
+// over HTTP are additionally checked against the published checksums.
+type EraStore struct {
+	config EraStoreConfig
+	chain  *params.ChainConfig
+	remote *url.URL     // Base URL of the era files, nil for local directories
+	client *http.Client // HTTP client for the remote era files
+
+	lock      sync.Mutex         // Lock protecting the file set
+	files     map[uint64]string  // Era file names by epoch, loaded lazily
+	listed    time.Time          // Time of the last era file listing
+	checksums map[string]string  // Published checksums of the remote era files
+	fetches   singleflight.Group // Pending downloads by era file name
+
+	blocks   *lru.Cache[common.Hash, *types.Block]
+	receipts *lru.Cache[common.Hash, types.Receipts]
+}
+
+// NewEraStore creates an era store serving the history of the given chain.
+func NewEraStore(config EraStoreConfig, chain *params.ChainConfig) (*EraStore, error) {
+	if config.EraSize == 0 {
+		config.EraSize = uint64(era.MaxEra1Size)
+	}
+	if config.CacheFiles <= 0 {
+		config.CacheFiles = eraCacheFiles
+	}
+	store := &EraStore{
+		config:   config,
+		chain:    chain,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+		blocks:   lru.NewCache[common.Hash, *types.Block](eraCacheItems),
+		receipts: lru.NewCache[common.Hash, types.Receipts](eraCacheItems),
+	}
+	if strings.HasPrefix(config.Source, "http://") || strings.HasPrefix(config.Source, "https://") {
+		u, err := url.Parse(config.Source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid era store url: %w", err)
+		}
+		if config.CacheDir == "" {
+			return nil, errors.New("era store cache directory is required for http sources")
+		}
+		if err := os.MkdirAll(config.CacheDir, 0755); err != nil {
+			return nil, err
+		}
+		store.remote = u
+	} else if info, err := os.Stat(config.Source); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("era store source %s is not a directory", config.Source)
+	}
+	return store, nil
+}
+
+// Block retrieves the block of the given canonical header from the era files.
+func (s *EraStore) Block(header *types.Header) (*types.Block, error) {
+	if block, ok := s.blocks.Get(header.Hash()); ok {
+		return block, nil
+	}
+	e, err := s.open(header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	defer e.Close()
+
+	return s.readBlock(e, header)
+}
+
+// readBlock retrieves the block of the given canonical header from the opened
+// era file containing it.
+func (s *EraStore) readBlock(e *era.Era, header *types.Header) (*types.Block, error) {
+	hash := header.Hash()
+	if block, ok := s.blocks.Get(hash); ok {
+		return block, nil
+	}
+	block, err := e.GetBlockByNumber(header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyBlock(header, block); err != nil {
+		return nil, err
+	}
+	s.blocks.Add(hash, block)
+	return block, nil
+}
+
+// Receipts retrieves the receipts of the given canonical header from the era
+// files, along with their derived metadata fields.
+func (s *EraStore) Receipts(header *types.Header) (types.Receipts, error) {
+	hash := header.Hash()
+	if receipts, ok := s.receipts.Get(hash); ok {
+		return receipts, nil
+	}
+	e, err := s.open(header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	defer e.Close()
+
+	block, err := s.readBlock(e, header)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := e.GetReceiptsByNumber(header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	if root := types.DeriveSha(receipts, trie.NewStackTrie(nil)); root != header.ReceiptHash {
+		return nil, fmt.Errorf("receipt root mismatch in block %d: have %x, want %x", header.Number, root, header.ReceiptHash)
+	}
+	var blobGasPrice *big.Int
+	if header.ExcessBlobGas != nil {
+		blobGasPrice = eip4844.CalcBlobFee(s.chain, header)
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	if err := receipts.DeriveFields(s.chain, hash, header.Number.Uint64(), header.Time, baseFee, blobGasPrice, block.Transactions()); err != nil {
+		return nil, err
+	}
+	s.receipts.Add(hash, receipts)
+	return receipts, nil
+}
+
+// verifyBlock checks that the block retrieved from an era file matches the
+// canonical header.
+func verifyBlock(header *types.Header, block *types.Block) error {
+	if block.Hash() != header.Hash() {
+		return fmt.Errorf("block hash mismatch in block %d: have %x, want %x", header.Number, block.Hash(), header.Hash())
+	}
+	if root := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); root != header.TxHash {
+		return fmt.Errorf("tx root mismatch in block %d: have %x, want %x", header.Number, root, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("uncle hash mismatch in block %d: have %x, want %x", header.Number, hash, header.UncleHash)
+	}
+	if header.WithdrawalsHash != nil {
+		if block.Withdrawals() == nil {
+			return fmt.Errorf("missing withdrawals in block %d", header.Number)
+		}
+		if root := types.DeriveSha(block.Withdrawals(), trie.NewStackTrie(nil)); root != *header.WithdrawalsHash {
+			return fmt.Errorf("withdrawals root mismatch in block %d: have %x, want %x", header.Number, root, *header.WithdrawalsHash)
+		}
+	}
+	return nil
+}
+
+// open opens the era file containing the given block, downloading it first if
+// the store is backed by an HTTP server and the file is not cached yet.
+func (s *EraStore) open(number uint64) (*era.Era, error) {
+	name, err := s.fileName(number / s.config.EraSize)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.config.Source, name)
+	if s.remote != nil {
+		path = filepath.Join(s.config.CacheDir, name)
+	}
+	e, err := era.Open(path)
+	if s.remote != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Downloads of the same file are deduplicated, unrelated requests
+			// are served in the meantime.
+			if _, err, _ = s.fetches.Do(name, func() (interface{}, error) {
+				if _, err := os.Stat(path); err == nil {
+					return nil, nil // Downloaded by a request finishing just before
+				}
+				return nil, s.download(name, path)
+			}); err != nil {
+				return nil, err
+			}
+			e, err = era.Open(path)
+		} else if err == nil {
+			// Mark the file as recently used, sparing it from cache eviction.
+			now := time.Now()
+			os.Chtimes(path, now, now)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if number < e.Start() || number >= e.Start()+e.Count() {
+		e.Close()
+		return nil, fmt.Errorf("block %d not in era file %s", number, name)
+	}
+	return e, nil
+}
+
+// fileName returns the name of the era file of the given epoch, listing the
+// available era files first if needed.
+func (s *EraStore) fileName(epoch uint64) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if name, ok := s.files[epoch]; ok {
+		return name, nil
+	}
+	// The era file might have been published since the last listing, refresh
+	// it unless that was done just recently.
+	if s.files == nil || time.Since(s.listed) >= eraRefreshInterval {
+		s.listed = time.Now()
+		if err := s.loadFiles(); err != nil {
+			return "", err
+		}
+		if name, ok := s.files[epoch]; ok {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no era file for epoch %d", epoch)
+}
+
+// loadFiles lists the era files available in the store.
+func (s *EraStore) loadFiles() error {
+	files := make(map[uint64]string)
+	if s.remote == nil {
+		entries, err := era.ReadDir(s.config.Source, s.config.Network)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			if entries, err = era.ReadL2Dir(s.config.Source, s.config.Network); err != nil {
+				return err
+			}
+		}
+		for epoch, name := range entries {
+			files[uint64(epoch)] = name
+		}
+		s.files = files
+		return nil
+	}
+	res, err := s.client.Get(s.remote.JoinPath(EraChecksumsFile).String())
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch era checksums: %s", res.Status)
+	}
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sum, name := fields[0], fields[1]
+		parts := strings.Split(name, "-")
+		if len(parts) != 3 || parts[0] != s.config.Network {
+			continue
+		}
+		if ext := filepath.Ext(name); ext != ".era1" && ext != ".eral2" {
+			continue
+		}
+		epoch, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("malformed era filename: %s", name)
+		}
+		files[epoch] = name
+		checksums[name] = strings.ToLower(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	s.files, s.checksums = files, checksums
+	return nil
+}
+
+// download fetches a remote era file into the cache directory, verifying it
+// against the published checksum.
+func (s *EraStore) download(name string, path string) error {
+	start := time.Now()
+	res, err := s.client.Get(s.remote.JoinPath(name).String())
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch era file %s: %s", name, res.Status)
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), res.Body); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to download era file %s: %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.lock.Lock()
+	want := s.checksums[name]
+	s.lock.Unlock()
+	if have := hex.EncodeToString(h.Sum(nil)); have != want {
+		os.Remove(tmp)
+		return fmt.Errorf("checksum mismatch for era file %s: have %s, want %s", name, have, want)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	log.Info("Downloaded era file", "name", name, "elapsed", common.PrettyDuration(time.Since(start)))
+	s.pruneCache()
+	return nil
+}
+
+// pruneCache deletes the least recently used era files from the cache directory
+// until at most config.CacheFiles remain.
+func (s *EraStore) pruneCache() {
+	entries, err := os.ReadDir(s.config.CacheDir)
+	if err != nil {
+		log.Warn("Failed to list era cache", "err", err)
+		return
+	}
+	type cached struct {
+		name string
+		used time.Time
+	}
+	var files []cached
+	for _, entry := range entries {
+		if ext := filepath.Ext(entry.Name()); entry.IsDir() || (ext != ".era1" && ext != ".eral2") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Deleted concurrently
+		}
+		files = append(files, cached{entry.Name(), info.ModTime()})
+	}
+	if len(files) <= s.config.CacheFiles {
+		return
+	}
+	slices.SortFunc(files, func(a, b cached) int { return a.used.Compare(b.used) })
+	for _, file := range files[:len(files)-s.config.CacheFiles] {
+		if err := os.Remove(filepath.Join(s.config.CacheDir, file.name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn("Failed to delete cached era file", "name", file.name, "err", err)
+			continue
+		}
+		log.Debug("Evicted cached era file", "name", file.name)
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// makeEraDir writes an era file with a few blocks into a new directory, along
+// with the checksums file served by HTTP era stores.
+func makeEraDir(t *testing.T) (string, []*types.Block) {
+	var (
+		dir    = t.TempDir()
+		blocks []*types.Block
+		parent common.Hash
+	)
+	f, err := os.Create(filepath.Join(dir, "tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := era.NewL2Builder(f)
+	for i := 0; i < 16; i++ {
+		var (
+			txs      []*types.Transaction
+			receipts types.Receipts
+		)
+		for j := 0; j < i%3; j++ {
+			txs = append(txs, types.NewTransaction(uint64(j), common.Address{byte(i)}, big.NewInt(1), 21000, big.NewInt(1), nil))
+			receipts = append(receipts, &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: uint64(j+1) * 21000, Logs: []*types.Log{}})
+		}
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     big.NewInt(int64(i)),
+			GasLimit:   30_000_000,
+			Difficulty: new(big.Int),
+			BaseFee:    big.NewInt(1),
+		}
+		block := types.NewBlock(header, &types.Body{Transactions: txs}, receipts, trie.NewStackTrie(nil))
+		if err := builder.Add(block, receipts, nil); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	name := era.L2Filename("test", 0, root)
+	if err := os.Rename(filepath.Join(dir, "tmp"), filepath.Join(dir, name)); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(blob)
+	checksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+	if err := os.WriteFile(filepath.Join(dir, EraChecksumsFile), []byte(checksums), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, blocks
+}
+
+func checkEraStore(t *testing.T, store *EraStore, blocks []*types.Block) {
+	t.Helper()
+
+	for _, want := range blocks {
+		block, err := store.Block(want.Header())
+		if err != nil {
+			t.Fatalf("failed to retrieve block %d: %v", want.NumberU64(), err)
+		}
+		if block.Hash() != want.Hash() || len(block.Transactions()) != len(want.Transactions()) {
+			t.Fatalf("block %d mismatch", want.NumberU64())
+		}
+		receipts, err := store.Receipts(want.Header())
+		if err != nil {
+			t.Fatalf("failed to retrieve receipts %d: %v", want.NumberU64(), err)
+		}
+		if len(receipts) != len(want.Transactions()) {
+			t.Fatalf("receipt count mismatch in block %d: have %d, want %d", want.NumberU64(), len(receipts), len(want.Transactions()))
+		}
+		for i, receipt := range receipts {
+			if receipt.TxHash != want.Transactions()[i].Hash() || receipt.BlockHash != want.Hash() || receipt.BlockNumber.Uint64() != want.NumberU64() {
+				t.Fatalf("receipt %d of block %d has invalid derived fields", i, want.NumberU64())
+			}
+		}
+	}
+	// Data not matching the canonical header must be rejected.
+	header := blocks[1].Header()
+	header.Extra = []byte("forked")
+	if _, err := store.Block(header); err == nil {
+		t.Fatal("expected error retrieving non-canonical block")
+	}
+	header = blocks[len(blocks)-1].Header()
+	header.Number = big.NewInt(int64(len(blocks)))
+	if _, err := store.Block(header); err == nil {
+		t.Fatal("expected error retrieving block beyond era file")
+	}
+}
+
+func TestEraStoreLocal(t *testing.T) {
+	dir, blocks := makeEraDir(t)
+
+	store, err := NewEraStore(EraStoreConfig{Source: dir, Network: "test"}, params.TestChainConfig)
+	if err != nil {
+		t.Fatalf("failed to create era store: %v", err)
+	}
+	checkEraStore(t, store, blocks)
+}
+
+func TestEraStoreRemote(t *testing.T) {
+	dir, blocks := makeEraDir(t)
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	cache := t.TempDir()
+	store, err := NewEraStore(EraStoreConfig{Source: server.URL, Network: "test", CacheDir: cache}, params.TestChainConfig)
+	if err != nil {
+		t.Fatalf("failed to create era store: %v", err)
+	}
+	checkEraStore(t, store, blocks)
+
+	// The era file must be kept in the cache directory.
+	entries, err := era.ReadL2Dir(cache, "test")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("era file not cached: %v %v", entries, err)
+	}
+}
+
+func TestEraStoreRefresh(t *testing.T) {
+	dir, blocks := makeEraDir(t)
+	entries, _ := era.ReadL2Dir(dir, "test")
+
+	source := t.TempDir()
+	store, err := NewEraStore(EraStoreConfig{Source: source, Network: "test"}, params.TestChainConfig)
+	if err != nil {
+		t.Fatalf("failed to create era store: %v", err)
+	}
+	if _, err := store.Block(blocks[1].Header()); err == nil {
+		t.Fatal("expected error retrieving unpublished block")
+	}
+	// Publish the era file, it's only picked up once the listing is stale.
+	if err := os.Rename(filepath.Join(dir, entries[0]), filepath.Join(source, entries[0])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Block(blocks[1].Header()); err == nil {
+		t.Fatal("expected error retrieving block before relisting")
+	}
+	store.listed = time.Now().Add(-eraRefreshInterval)
+	checkEraStore(t, store, blocks)
+}
+
+func TestEraStoreChecksumMismatch(t *testing.T) {
+	dir, blocks := makeEraDir(t)
+	entries, _ := era.ReadL2Dir(dir, "test")
+	checksums := fmt.Sprintf("%x  %s\n", common.Hash{0x1}, entries[0])
+	if err := os.WriteFile(filepath.Join(dir, EraChecksumsFile), []byte(checksums), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	cache := t.TempDir()
+	store, err := NewEraStore(EraStoreConfig{Source: server.URL, Network: "test", CacheDir: cache}, params.TestChainConfig)
+	if err != nil {
+		t.Fatalf("failed to create era store: %v", err)
+	}
+	if _, err := store.Block(blocks[1].Header()); err == nil {
+		t.Fatal("expected checksum error")
+	}
+	if files, _ := os.ReadDir(cache); len(files) != 0 {
+		t.Fatalf("corrupted era file left in cache: %v", files)
+	}
+}
+
+func TestEraStoreDownloadNotBlocking(t *testing.T) {
+	dir, blocks := makeEraDir(t)
+	checksums, err := os.ReadFile(filepath.Join(dir, EraChecksumsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Announce a second era file whose download stalls until released.
+	stalled := era.L2Filename("test", 1, common.Hash{0x1})
+	checksums = append(checksums, fmt.Sprintf("%x  %s\n", common.Hash{0x1}, stalled)...)
+
+	var (
+		files   = http.FileServer(http.Dir(dir))
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + EraChecksumsFile:
+			w.Write(checksums)
+		case "/" + stalled:
+			close(started)
+			<-release
+			http.NotFound(w, r)
+		default:
+			files.ServeHTTP(w, r)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	store, err := NewEraStore(EraStoreConfig{Source: server.URL, Network: "test", CacheDir: t.TempDir(), EraSize: 16}, params.TestChainConfig)
+	if err != nil {
+		t.Fatalf("failed to create era store: %v", err)
+	}
+	if _, err := store.Block(blocks[1].Header()); err != nil {
+		t.Fatalf("failed to retrieve block: %v", err)
+	}
+	header := blocks[0].Header()
+	header.Number = big.NewInt(16)
+	go store.Block(header)
+	<-started
+
+	// Cached era files must be served while the other download is pending.
+	if _, err := store.Receipts(blocks[2].Header()); err != nil {
+		t.Fatalf("failed to retrieve receipts: %v", err)
+	}
+}
+
+func TestEraStoreCacheLimit(t *testing.T) {
+	dir, blocks := makeEraDir(t)
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	// Fill the cache with stale era files of other epochs.
+	cache := t.TempDir()
+	for i := 1; i <= 3; i++ {
+		path := filepath.Join(cache, era.L2Filename("test", i, common.Hash{byte(i)}))
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store, err := NewEraStore(EraStoreConfig{Source: server.URL, Network: "test", CacheDir: cache, CacheFiles: 2}, params.TestChainConfig)
+	if err != nil {
+		t.Fatalf("failed to create era store: %v", err)
+	}
+	checkEraStore(t, store, blocks)
+
+	// The downloaded file and the most recently used stale one must be kept.
+	files, err := os.ReadDir(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	remote, _ := era.ReadL2Dir(dir, "test")
+	want := []string{era.L2Filename("test", 1, common.Hash{0x1}), remote[0]}
+	slices.Sort(want)
+	if !slices.Equal(names, want) {
+		t.Fatalf("cached era files mismatch: have %v, want %v", names, want)
+	}
+}
@@ -18,6 +18,8 @@ package history
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
@@ -32,10 +34,14 @@ const (
 
 	// KeepPostMerge sets the history pruning point to the merge activation block.
 	KeepPostMerge
+
+	// KeepRecent keeps a rolling window of recent chain history, continuously
+	// pruning the blocks falling out of the configured RetentionWindow.
+	KeepRecent
 )
 
 func (m HistoryMode) IsValid() bool {
-	return m <= KeepPostMerge
+	return m <= KeepRecent
 }
 
 func (m HistoryMode) String() string {
@@ -44,6 +50,8 @@ func (m HistoryMode) String() string {
 		return "all"
 	case KeepPostMerge:
 		return "postmerge"
+	case KeepRecent:
+		return "recent"
 	default:
 		return fmt.Sprintf("invalid HistoryMode(%d)", m)
 	}
@@ -64,12 +72,47 @@ func (m *HistoryMode) UnmarshalText(text []byte) error {
 		*m = KeepAll
 	case "postmerge":
 		*m = KeepPostMerge
+	case "recent":
+		*m = KeepRecent
 	default:
-		return fmt.Errorf(`unknown sync mode %q, want "all" or "postmerge"`, text)
+		return fmt.Errorf(`unknown sync mode %q, want "all", "postmerge" or "recent"`, text)
 	}
 	return nil
 }
 
+// RetentionWindow configures the chain history kept by the KeepRecent mode.
+// Blocks falling out of any of the configured limits are pruned. Note only
+// the blocks already moved into the ancient store can be pruned, so the most
+// recent blocks are always kept.
+type RetentionWindow struct {
+	Blocks uint64        `toml:",omitempty"` // Number of recent blocks to keep, 0 for no limit
+	Age    time.Duration `toml:",omitempty"` // Maximum age of the blocks to keep, 0 for no limit
+}
+
+// IsValid reports whether the window has at least one limit.
+func (w RetentionWindow) IsValid() bool {
+	return w.Blocks > 0 || w.Age > 0
+}
+
+// Cutoff returns the first block to keep with the given chain head. The time
+// blockTime function returns the timestamp of a block, it's only called for the
+// blocks above the previous cutoff, which is never moved backwards.
+func (w RetentionWindow) Cutoff(head uint64, prev uint64, now time.Time, blockTime func(uint64) uint64) uint64 {
+	cutoff := prev
+	if w.Blocks > 0 && head+1 > w.Blocks {
+		cutoff = max(cutoff, head+1-w.Blocks)
+	}
+	if w.Age > 0 && cutoff < head {
+		// Search the first block within the age limit, the head is always kept
+		oldest := now.Add(-w.Age).Unix()
+		n := sort.Search(int(head-cutoff), func(i int) bool {
+			return int64(blockTime(cutoff+uint64(i))) >= oldest
+		})
+		cutoff += uint64(n)
+	}
+	return cutoff
+}
+
 type PrunePoint struct {
 	BlockNumber uint64
 	BlockHash   common.Hash
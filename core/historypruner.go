@@ -0,0 +1,174 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/history"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// historyPruneBatch is the minimum number of blocks pruned at once, avoiding
+// to truncate the ancient store on every block.
+var historyPruneBatch = uint64(1024)
+
+// historyPruner is the module responsible for continuously pruning the chain
+// history falling out of the configured retention window.
+//
+// The pruning point is moved forward first, so that the history below it is
+// no longer served or indexed, then the transaction indexes are realigned and
+// finally the bodies and receipts are truncated from the ancient store.
+type historyPruner struct {
+	window history.RetentionWindow
+	chain  *BlockChain
+	term   chan chan struct{}
+	closed chan struct{}
+}
+
+// newHistoryPruner initializes the history pruner.
+func newHistoryPruner(window history.RetentionWindow, chain *BlockChain) *historyPruner {
+	pruner := &historyPruner{
+		window: window,
+		chain:  chain,
+		term:   make(chan chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go pruner.loop()
+
+	log.Info("Initialized history pruner", "blocks", window.Blocks, "age", common.PrettyDuration(window.Age))
+	return pruner
+}
+
+// target returns the new pruning point for the given chain head, or false if
+// it's not worth moving it yet.
+func (pruner *historyPruner) target(head uint64) (uint64, bool) {
+	cutoff, _ := pruner.chain.HistoryPruningCutoff()
+	target := pruner.window.Cutoff(head, cutoff, time.Now(), func(number uint64) uint64 {
+		if header := pruner.chain.GetHeaderByNumber(number); header != nil {
+			return header.Time
+		}
+		return 0
+	})
+	// Only the chain segment in the ancient store can be truncated, which is
+	// the same safety condition prune-history enforces.
+	frozen, err := pruner.chain.db.Ancients()
+	if err != nil {
+		return 0, false
+	}
+	target = min(target, frozen)
+	if target < cutoff+historyPruneBatch {
+		return 0, false
+	}
+	return target, true
+}
+
+// prune moves the pruning point of the chain to the given block, returning
+// false if the history can't be pruned.
+func (pruner *historyPruner) prune(target uint64) bool {
+	var (
+		db    = pruner.chain.db
+		start = time.Now()
+	)
+	hash := rawdb.ReadCanonicalHash(db, target)
+	if hash == (common.Hash{}) {
+		log.Error("History pruning target is missing", "number", target)
+		return false
+	}
+	pruner.chain.historyPrunePoint.Store(&history.PrunePoint{BlockNumber: target, BlockHash: hash})
+
+	// Unindex the transactions of the expired blocks while they are still
+	// available, refusing to truncate them if the indexes are left behind.
+	// The indexes are deliberately kept if the blocks are served from an
+	// external history store.
+	if indexer := pruner.chain.txIndexer; indexer != nil {
+		indexer.setCutoff(target)
+		if tail := rawdb.ReadTxIndexTail(db); tail != nil && *tail < target && !indexer.keepPruned {
+			log.Warn("Transaction indexes not pruned", "tail", *tail, "target", target)
+			return false
+		}
+	}
+	if _, err := db.TruncateTail(target); err != nil {
+		log.Error("Failed to prune chain history", "target", target, "err", err)
+		return false
+	}
+	// Drop the cached history, it might cover the pruned blocks
+	pruner.chain.bodyCache.Purge()
+	pruner.chain.bodyRLPCache.Purge()
+	pruner.chain.receiptsCache.Purge()
+	pruner.chain.blockCache.Purge()
+	pruner.chain.txLookupCache.Purge()
+
+	log.Info("Pruned chain history", "tail", target, "hash", hash, "elapsed", common.PrettyDuration(time.Since(start)))
+	return true
+}
+
+// loop is the scheduler of the pruner, moving the pruning point along with the
+// chain head.
+func (pruner *historyPruner) loop() {
+	defer close(pruner.closed)
+
+	var (
+		done   chan bool // Non-nil if background routine is active, reporting its success
+		failed bool      // Whether pruning failed, it's then disabled until restart
+		headCh = make(chan ChainHeadEvent)
+		sub    = pruner.chain.SubscribeChainHeadEvent(headCh)
+	)
+	defer sub.Unsubscribe()
+
+	schedule := func(head uint64) {
+		if done != nil || failed {
+			return
+		}
+		target, ok := pruner.target(head)
+		if !ok {
+			return
+		}
+		ch := make(chan bool, 1)
+		go func() { ch <- pruner.prune(target) }()
+		done = ch
+	}
+	schedule(pruner.chain.CurrentBlock().Number.Uint64())
+
+	for {
+		select {
+		case h := <-headCh:
+			schedule(h.Header.Number.Uint64())
+		case ok := <-done:
+			done, failed = nil, !ok
+		case ch := <-pruner.term:
+			if done != nil {
+				log.Info("Waiting background history pruner to exit")
+				<-done
+			}
+			close(ch)
+			return
+		}
+	}
+}
+
+// close shutdown the pruner. Safe to be called for multiple times.
+func (pruner *historyPruner) close() {
+	ch := make(chan struct{})
+	select {
+	case pruner.term <- ch:
+		<-ch
+	case <-pruner.closed:
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/history"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TestHistoryPruner tests the rolling expiry of the chain history.
+func TestHistoryPruner(t *testing.T) { testHistoryPruner(t, false) }
+
+// TestHistoryPrunerKeepTxIndex tests the rolling expiry of the chain history
+// served from an external store, retaining the transaction indexes.
+func TestHistoryPrunerKeepTxIndex(t *testing.T) { testHistoryPruner(t, true) }
+
+func testHistoryPruner(t *testing.T, keepTxIndex bool) {
+	historyPruneBatch = 16
+	defer func() {
+		historyPruneBatch = 1024
+	}()
+
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(gspec.Config)
+		limit  = uint64(0)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, engine, 200, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.HexToAddress("0xdeadbeef"), big.NewInt(1000), params.TxGas, big.NewInt(10*params.InitialBaseFee), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	config := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	config.ChainHistoryMode = history.KeepRecent
+	config.ChainHistoryWindow = history.RetentionWindow{Blocks: 64}
+	config.ChainHistoryKeepTxIndex = keepTxIndex
+	chain, err := NewBlockChain(db, config, gspec, nil, engine, vm.Config{}, &limit)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	// Only the blocks below 150 are frozen, the window keeps 137 and above
+	if n, err := chain.InsertReceiptChain(blocks, receipts, 150); err != nil {
+		t.Fatalf("Failed to insert block %d: %v", n, err)
+	}
+	// Announce the head until the pruner picks it up, it subscribes asynchronously
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if tail, _ := db.Tail(); tail == 137 {
+			break
+		}
+		chain.chainHeadFeed.Send(ChainHeadEvent{Header: blocks[len(blocks)-1].Header()})
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("History not pruned")
+		}
+	}
+	if cutoff, hash := chain.HistoryPruningCutoff(); cutoff != 137 || hash != blocks[136].Hash() {
+		t.Fatalf("Unexpected pruning point: #%d [%x]", cutoff, hash)
+	}
+	for _, block := range blocks {
+		expired := block.NumberU64() < 137
+		if have := chain.GetBlockByNumber(block.NumberU64()); (have == nil) != expired {
+			t.Fatalf("Block #%d availability mismatch: expired %v", block.NumberU64(), expired)
+		}
+		verifyIndexes(t, db, block, !expired || keepTxIndex)
+	}
+	// The pruned database is only accepted in the same history mode on restart
+	chain.historyPrunePoint.Store(nil)
+	if err := chain.initializeHistoryPruning(0); err != nil {
+		t.Fatalf("Failed to initialize pruned history: %v", err)
+	}
+	if cutoff, _ := chain.HistoryPruningCutoff(); cutoff != 137 {
+		t.Fatalf("Unexpected pruning point after restart: %d", cutoff)
+	}
+	chain.cacheConfig.ChainHistoryMode = history.KeepAll
+	if err := chain.initializeHistoryPruning(0); err == nil {
+		t.Fatal("Pruned database accepted without history pruning")
+	}
+	chain.cacheConfig.ChainHistoryMode = history.KeepRecent
+	chain.Stop()
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadBlobArchiveIndex retrieves the id of the blob archive item containing the
+// blob with the given versioned hash.
+func ReadBlobArchiveIndex(db ethdb.KeyValueReader, vhash common.Hash) *uint64 {
+	data, _ := db.Get(blobArchiveIndexKey(vhash))
+	if len(data) != 8 {
+		return nil
+	}
+	id := binary.BigEndian.Uint64(data)
+	return &id
+}
+
+// WriteBlobArchiveIndex stores the id of the blob archive item containing the
+// blob with the given versioned hash. A blob is indexed by its versioned hash
+// only, so if it's archived by multiple items, the last written one wins.
+func WriteBlobArchiveIndex(db ethdb.KeyValueWriter, vhash common.Hash, id uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], id)
+	if err := db.Put(blobArchiveIndexKey(vhash), buf[:]); err != nil {
+		log.Crit("Failed to store blob archive index", "err", err)
+	}
+}
+
+// DeleteBlobArchiveIndex removes the blob archive index of the given versioned hash.
+func DeleteBlobArchiveIndex(db ethdb.KeyValueWriter, vhash common.Hash) {
+	if err := db.Delete(blobArchiveIndexKey(vhash)); err != nil {
+		log.Crit("Failed to delete blob archive index", "err", err)
+	}
+}
+
+// ReadBlobArchiveMeta retrieves the metadata of the blob archive item with the
+// given id.
+func ReadBlobArchiveMeta(db ethdb.AncientReaderOp, id uint64) []byte {
+	blob, err := db.Ancient(blobArchiveMeta, id)
+	if err != nil {
+		return nil
+	}
+	return blob
+}
+
+// ReadBlobArchiveMetaList retrieves a batch of blob archive metadata with the
+// specified start position and count.
+func ReadBlobArchiveMetaList(db ethdb.AncientReaderOp, start uint64, count uint64) ([][]byte, error) {
+	return db.AncientRange(blobArchiveMeta, start, count, 0)
+}
+
+// ReadBlobArchiveSidecar retrieves the encoded blob sidecar of the blob archive
+// item with the given id.
+func ReadBlobArchiveSidecar(db ethdb.AncientReaderOp, id uint64) []byte {
+	blob, err := db.Ancient(blobArchiveSidecars, id)
+	if err != nil {
+		return nil
+	}
+	return blob
+}
+
+// WriteBlobArchive appends the provided blob archive item to the database.
+func WriteBlobArchive(db ethdb.AncientWriter, id uint64, meta []byte, sidecar []byte) error {
+	_, err := db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		if err := op.AppendRaw(blobArchiveMeta, id, meta); err != nil {
+			return err
+		}
+		return op.AppendRaw(blobArchiveSidecars, id, sidecar)
+	})
+	return err
+}
@@ -164,6 +164,29 @@ func DeleteTrieJournal(db ethdb.KeyValueWriter) {
 	}
 }
 
+// ReadPathPruneProgress retrieves the serialized progress of the offline
+// path-based state pruning.
+func ReadPathPruneProgress(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(pathPruneProgressKey)
+	return data
+}
+
+// WritePathPruneProgress stores the serialized progress of the offline
+// path-based state pruning.
+func WritePathPruneProgress(db ethdb.KeyValueWriter, progress []byte) {
+	if err := db.Put(pathPruneProgressKey, progress); err != nil {
+		log.Crit("Failed to store path prune progress", "err", err)
+	}
+}
+
+// DeletePathPruneProgress deletes the progress of the offline path-based
+// state pruning.
+func DeletePathPruneProgress(db ethdb.KeyValueWriter) {
+	if err := db.Delete(pathPruneProgressKey); err != nil {
+		log.Crit("Failed to remove path prune progress", "err", err)
+	}
+}
+
 // ReadStateHistoryMeta retrieves the metadata corresponding to the specified
 // state history. Compute the position of state history in freezer by minus
 // one since the id of first state history starts from one(zero for initial
@@ -0,0 +1,113 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadStateIndexHead retrieves the id of the newest state history included in
+// the state history index.
+func ReadStateIndexHead(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(stateIndexHeadKey)
+	if len(data) != 8 {
+		return nil
+	}
+	id := binary.BigEndian.Uint64(data)
+	return &id
+}
+
+// WriteStateIndexHead stores the id of the newest state history included in
+// the state history index.
+func WriteStateIndexHead(db ethdb.KeyValueWriter, id uint64) {
+	if err := db.Put(stateIndexHeadKey, encodeBlockNumber(id)); err != nil {
+		log.Crit("Failed to store state index head", "err", err)
+	}
+}
+
+// ReadStateIndexAccount retrieves the original value of the account recorded
+// by the first indexed state history after the given id, which is therefore
+// also the value of the account in the state with the given id. False is
+// returned if the account is not mutated by any of the newer histories.
+func ReadStateIndexAccount(db ethdb.Iteratee, accountHash common.Hash, id uint64) ([]byte, bool) {
+	return readStateIndexEntry(db, append(append([]byte{}, stateIndexAccountPrefix...), accountHash.Bytes()...), id)
+}
+
+// WriteStateIndexAccount stores the original value of the account mutated by
+// the state history with the given id.
+func WriteStateIndexAccount(db ethdb.KeyValueWriter, accountHash common.Hash, id uint64, blob []byte) {
+	if err := db.Put(stateIndexAccountKey(accountHash, id), blob); err != nil {
+		log.Crit("Failed to store state index account", "err", err)
+	}
+}
+
+// DeleteStateIndexAccount removes the original value of the account mutated by
+// the state history with the given id.
+func DeleteStateIndexAccount(db ethdb.KeyValueWriter, accountHash common.Hash, id uint64) {
+	if err := db.Delete(stateIndexAccountKey(accountHash, id)); err != nil {
+		log.Crit("Failed to delete state index account", "err", err)
+	}
+}
+
+// ReadStateIndexStorage retrieves the original value of the storage slot
+// recorded by the first indexed state history after the given id, which is
+// therefore also the value of the slot in the state with the given id. False
+// is returned if the slot is not mutated by any of the newer histories.
+func ReadStateIndexStorage(db ethdb.Iteratee, accountHash common.Hash, storageHash common.Hash, id uint64) ([]byte, bool) {
+	return readStateIndexEntry(db, append(append(append([]byte{}, stateIndexStoragePrefix...), accountHash.Bytes()...), storageHash.Bytes()...), id)
+}
+
+// WriteStateIndexStorage stores the original value of the storage slot mutated
+// by the state history with the given id.
+func WriteStateIndexStorage(db ethdb.KeyValueWriter, accountHash common.Hash, storageHash common.Hash, id uint64, blob []byte) {
+	if err := db.Put(stateIndexStorageKey(accountHash, storageHash, id), blob); err != nil {
+		log.Crit("Failed to store state index storage", "err", err)
+	}
+}
+
+// DeleteStateIndexStorage removes the original value of the storage slot
+// mutated by the state history with the given id.
+func DeleteStateIndexStorage(db ethdb.KeyValueWriter, accountHash common.Hash, storageHash common.Hash, id uint64) {
+	if err := db.Delete(stateIndexStorageKey(accountHash, storageHash, id)); err != nil {
+		log.Crit("Failed to delete state index storage", "err", err)
+	}
+}
+
+// DeleteStateIndex removes the entire state history index. The hashScheme flag
+// protects legacy hash-scheme trie nodes sharing the key prefix.
+func DeleteStateIndex(db ethdb.KeyValueStore, hashScheme bool) error {
+	return deletePrefixRange(db, []byte(stateIndexPrefix), hashScheme, func(bool) bool { return false })
+}
+
+// readStateIndexEntry returns the value of the first index entry under the
+// given prefix with an id above the given one.
+func readStateIndexEntry(db ethdb.Iteratee, prefix []byte, id uint64) ([]byte, bool) {
+	it := db.NewIterator(prefix, encodeBlockNumber(id+1))
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+8 {
+			continue
+		}
+		return common.CopyBytes(it.Value()), true
+	}
+	return nil, false
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadTraceIndexTail retrieves the number of the oldest block retained in the
+// call trace index.
+func ReadTraceIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(traceIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteTraceIndexTail stores the number of the oldest block retained in the
+// call trace index.
+func WriteTraceIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(traceIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store trace index tail", "err", err)
+	}
+}
+
+// HasTraceIndexBlock verifies the existence of the indexed call traces of a block.
+func HasTraceIndexBlock(db ethdb.KeyValueReader, number uint64, hash common.Hash) bool {
+	has, _ := db.Has(traceIndexBlockKey(number, hash))
+	return has
+}
+
+// ReadTraceIndexBlock retrieves the json encoded flat call traces of a block.
+func ReadTraceIndexBlock(db ethdb.KeyValueReader, number uint64, hash common.Hash) []byte {
+	data, _ := db.Get(traceIndexBlockKey(number, hash))
+	return data
+}
+
+// WriteTraceIndexBlock stores the json encoded flat call traces of a block.
+func WriteTraceIndexBlock(db ethdb.KeyValueWriter, number uint64, hash common.Hash, traces []byte) {
+	if err := db.Put(traceIndexBlockKey(number, hash), traces); err != nil {
+		log.Crit("Failed to store trace index block", "err", err)
+	}
+}
+
+// DeleteTraceIndexBlock removes the indexed call traces of a block.
+func DeleteTraceIndexBlock(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	if err := db.Delete(traceIndexBlockKey(number, hash)); err != nil {
+		log.Crit("Failed to delete trace index block", "err", err)
+	}
+}
+
+// ReadTraceIndexBlocksInRange retrieves the numbers and hashes of all the blocks
+// with indexed call traces within the given range, both limits inclusive.
+func ReadTraceIndexBlocksInRange(db ethdb.Iteratee, first, last uint64) []*NumberHash {
+	return readBlockIndexRange(db, traceIndexBlockPrefix, first, last)
+}
+
+// WriteTraceIndexAddress marks that a block contains a call trace sent from,
+// or addressed to the given account.
+func WriteTraceIndexAddress(db ethdb.KeyValueWriter, addr common.Address, sender bool, number uint64, hash common.Hash) {
+	if err := db.Put(traceIndexAddressKey(addr, sender, number, hash), nil); err != nil {
+		log.Crit("Failed to store trace index address", "err", err)
+	}
+}
+
+// DeleteTraceIndexAddress removes the mark of a block containing a call trace
+// sent from, or addressed to the given account.
+func DeleteTraceIndexAddress(db ethdb.KeyValueWriter, addr common.Address, sender bool, number uint64, hash common.Hash) {
+	if err := db.Delete(traceIndexAddressKey(addr, sender, number, hash)); err != nil {
+		log.Crit("Failed to delete trace index address", "err", err)
+	}
+}
+
+// ReadTraceIndexAddressBlocks retrieves the numbers and hashes of all the blocks
+// within the given range containing a call trace sent from, or addressed to the
+// given account. Both limits are inclusive.
+func ReadTraceIndexAddressBlocks(db ethdb.Iteratee, addr common.Address, sender bool, first, last uint64) []*NumberHash {
+	return readBlockIndexRange(db, traceIndexAddressPrefix(addr, sender), first, last)
+}
+
+// readBlockIndexRange iterates over the index entries with the given prefix
+// followed by a block number and hash, within the given block range.
+func readBlockIndexRange(db ethdb.Iteratee, prefix []byte, first, last uint64) []*NumberHash {
+	var (
+		keyLength = len(prefix) + 8 + common.HashLength
+		blocks    []*NumberHash
+		it        = db.NewIterator(prefix, encodeBlockNumber(first))
+	)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != keyLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix) : len(prefix)+8])
+		if number > last {
+			break
+		}
+		blocks = append(blocks, &NumberHash{Number: number, Hash: common.BytesToHash(key[len(prefix)+8:])})
+	}
+	return blocks
+}
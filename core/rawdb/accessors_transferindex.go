@@ -0,0 +1,94 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadTransferIndexTail retrieves the number of the oldest block retained in
+// the token transfer index.
+func ReadTransferIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(transferIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteTransferIndexTail stores the number of the oldest block retained in the
+// token transfer index.
+func WriteTransferIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(transferIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store transfer index tail", "err", err)
+	}
+}
+
+// ReadTransferIndexBlock retrieves the json encoded token transfers of a block.
+func ReadTransferIndexBlock(db ethdb.KeyValueReader, number uint64, hash common.Hash) []byte {
+	data, _ := db.Get(transferIndexBlockKey(number, hash))
+	return data
+}
+
+// WriteTransferIndexBlock stores the json encoded token transfers of a block.
+func WriteTransferIndexBlock(db ethdb.KeyValueWriter, number uint64, hash common.Hash, transfers []byte) {
+	if err := db.Put(transferIndexBlockKey(number, hash), transfers); err != nil {
+		log.Crit("Failed to store transfer index block", "err", err)
+	}
+}
+
+// DeleteTransferIndexBlock removes the indexed token transfers of a block.
+func DeleteTransferIndexBlock(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	if err := db.Delete(transferIndexBlockKey(number, hash)); err != nil {
+		log.Crit("Failed to delete transfer index block", "err", err)
+	}
+}
+
+// ReadTransferIndexBlocksInRange retrieves the numbers and hashes of all the
+// blocks with indexed token transfers within the given range, both limits
+// inclusive.
+func ReadTransferIndexBlocksInRange(db ethdb.Iteratee, first, last uint64) []*NumberHash {
+	return readBlockIndexRange(db, transferIndexBlockPrefix, first, last)
+}
+
+// WriteTransferIndexAddress marks that a block contains a token transfer from
+// or to the given account.
+func WriteTransferIndexAddress(db ethdb.KeyValueWriter, addr common.Address, number uint64, hash common.Hash) {
+	if err := db.Put(transferIndexAddressKey(addr, number, hash), nil); err != nil {
+		log.Crit("Failed to store transfer index address", "err", err)
+	}
+}
+
+// DeleteTransferIndexAddress removes the mark of a block containing a token
+// transfer from or to the given account.
+func DeleteTransferIndexAddress(db ethdb.KeyValueWriter, addr common.Address, number uint64, hash common.Hash) {
+	if err := db.Delete(transferIndexAddressKey(addr, number, hash)); err != nil {
+		log.Crit("Failed to delete transfer index address", "err", err)
+	}
+}
+
+// ReadTransferIndexAddressBlocks retrieves the numbers and hashes of all the
+// blocks within the given range containing a token transfer from or to the
+// given account. Both limits are inclusive.
+func ReadTransferIndexAddressBlocks(db ethdb.Iteratee, addr common.Address, first, last uint64) []*NumberHash {
+	return readBlockIndexRange(db, append(append([]byte{}, transferIndexAddressPrefix...), addr.Bytes()...), first, last)
+}
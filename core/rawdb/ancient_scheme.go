@@ -52,6 +52,11 @@ var chainFreezerTableConfigs = map[string]freezerTableConfig{
 type freezerTableConfig struct {
 	noSnappy bool // disables item compression
 	prunable bool // true for tables that can be pruned by TruncateTail
+
+	// Placement of the table files, set from the freezer layout.
+	dir      string // directory of the table, the freezer directory if empty
+	coldDir  string // directory of the old data files, tiering is disabled if empty
+	hotFiles uint32 // number of the most recent data files kept out of the cold directory
 }
 
 const (
@@ -75,15 +80,33 @@ var stateFreezerTableConfigs = map[string]freezerTableConfig{
 	stateHistoryStorageData:  {noSnappy: false, prunable: true},
 }
 
+const (
+	// blobArchiveTableSize defines the maximum size of freezer data files.
+	blobArchiveTableSize = 2 * 1000 * 1000 * 1000
+
+	// blobArchiveMeta indicates the name of the freezer blob archive metadata table.
+	blobArchiveMeta     = "blobs.meta"
+	blobArchiveSidecars = "blobs.sidecars"
+)
+
+// blobArchiveFreezerTableConfigs configures the settings for tables in the blob
+// archive freezer. Compression is disabled for the sidecars as blobs are mostly
+// compressed rollup data already.
+var blobArchiveFreezerTableConfigs = map[string]freezerTableConfig{
+	blobArchiveMeta:     {noSnappy: false, prunable: true},
+	blobArchiveSidecars: {noSnappy: true, prunable: true},
+}
+
 // The list of identifiers of ancient stores.
 var (
 	ChainFreezerName       = "chain"        // the folder name of chain segment ancient store.
 	MerkleStateFreezerName = "state"        // the folder name of state history ancient store.
 	VerkleStateFreezerName = "state_verkle" // the folder name of state history ancient store.
+	BlobArchiveFreezerName = "blobs"        // the folder name of blob sidecar archive ancient store.
 )
 
 // freezers the collections of all builtin freezers.
-var freezers = []string{ChainFreezerName, MerkleStateFreezerName, VerkleStateFreezerName}
+var freezers = []string{ChainFreezerName, MerkleStateFreezerName, VerkleStateFreezerName, BlobArchiveFreezerName}
 
 // NewStateFreezer initializes the ancient store for state history.
 //
@@ -103,3 +126,16 @@ func NewStateFreezer(ancientDir string, verkle bool, readOnly bool) (ethdb.Reset
 	}
 	return newResettableFreezer(name, "eth/db/state", readOnly, stateHistoryTableSize, stateFreezerTableConfigs)
 }
+
+// NewBlobArchiveFreezer initializes the ancient store for archived blob sidecars.
+//
+//   - if the empty directory is given, initializes the pure in-memory
+//     blob archive freezer (e.g. dev mode).
+//   - if non-empty directory is given, initializes the regular file-based
+//     blob archive freezer.
+func NewBlobArchiveFreezer(ancientDir string, readOnly bool) (ethdb.ResettableAncientStore, error) {
+	if ancientDir == "" {
+		return NewMemoryFreezer(readOnly, blobArchiveFreezerTableConfigs), nil
+	}
+	return newResettableFreezer(filepath.Join(ancientDir, BlobArchiveFreezerName), "eth/db/blobs", readOnly, blobArchiveTableSize, blobArchiveFreezerTableConfigs)
+}
@@ -18,10 +18,12 @@ package rawdb
 
 import (
 	"fmt"
-	"path/filepath"
+	"os"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/olekukonko/tablewriter"
 )
 
 type tableSize struct {
@@ -105,6 +107,23 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 			}
 			infos = append(infos, info)
 
+		case BlobArchiveFreezerName:
+			datadir, err := db.AncientDatadir()
+			if err != nil {
+				return nil, err
+			}
+			f, err := NewBlobArchiveFreezer(datadir, true)
+			if err != nil {
+				continue // might be possible the blob archive is not existent
+			}
+			defer f.Close()
+
+			info, err := inspect(freezer, blobArchiveFreezerTableConfigs, f)
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+
 		default:
 			return nil, fmt.Errorf("unknown freezer, supported ones: %v", freezers)
 		}
@@ -112,32 +131,56 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 	return infos, nil
 }
 
+// printFreezerTiers prints the disk usage of the storage tiers of the chain
+// freezer, if it has a custom layout.
+func printFreezerTiers(ancient string) error {
+	datadir, tables, err := resolveFreezer(ancient, ChainFreezerName)
+	if err != nil {
+		return err
+	}
+	layout, err := loadFreezerLayout(datadir)
+	if err != nil {
+		return err
+	}
+	if len(layout.Tables) == 0 && layout.ColdDir == "" {
+		return nil
+	}
+	usages, err := inspectFreezerTiers(datadir, tables)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, usage := range usages {
+		cold := "-"
+		if usage.ColdDir != "" {
+			cold = common.StorageSize(usage.Cold).String()
+		}
+		rows = append(rows, []string{
+			"Ancient store (Chain)",
+			strings.Title(usage.Table),
+			usage.Dir,
+			common.StorageSize(usage.Hot).String(),
+			usage.ColdDir,
+			cold,
+		})
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Database", "Category", "Hot directory", "Hot size", "Cold directory", "Cold size"})
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
+
 // InspectFreezerTable dumps out the index of a specific freezer table. The passed
 // ancient indicates the path of root ancient directory where the chain freezer can
 // be opened. Start and end specify the range for dumping out indexes.
 // Note this function can only be used for debugging purposes.
 func InspectFreezerTable(ancient string, freezerName string, tableName string, start, end int64) error {
-	var (
-		path   string
-		tables map[string]freezerTableConfig
-	)
-	switch freezerName {
-	case ChainFreezerName:
-		path, tables = resolveChainFreezerDir(ancient), chainFreezerTableConfigs
-	case MerkleStateFreezerName, VerkleStateFreezerName:
-		path, tables = filepath.Join(ancient, freezerName), stateFreezerTableConfigs
-	default:
-		return fmt.Errorf("unknown freezer, supported ones: %v", freezers)
-	}
-	noSnappy, exist := tables[tableName]
-	if !exist {
-		var names []string
-		for name := range tables {
-			names = append(names, name)
-		}
-		return fmt.Errorf("unknown table, supported ones: %v", names)
+	path, config, err := resolveFreezerTable(ancient, freezerName, tableName)
+	if err != nil {
+		return err
 	}
-	table, err := newFreezerTable(path, tableName, noSnappy, true)
+	table, err := newFreezerTable(path, tableName, config, true)
 	if err != nil {
 		return err
 	}
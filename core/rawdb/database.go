@@ -386,6 +386,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		filterMapRows      stat
 		filterMapLastBlock stat
 		filterMapBlockLV   stat
+		blobArchiveIndex   stat
 
 		// Verkle statistics
 		verkleTries        stat
@@ -458,6 +459,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		case bytes.HasPrefix(key, filterMapBlockLVPrefix) && len(key) == len(filterMapBlockLVPrefix)+8:
 			filterMapBlockLV.Add(size)
 
+		// blob archive index
+		case bytes.HasPrefix(key, blobArchiveIndexPrefix) && len(key) == len(blobArchiveIndexPrefix)+common.HashLength:
+			blobArchiveIndex.Add(size)
+
 		// old log index (deprecated)
 		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
 			bloomBits.Add(size)
@@ -514,6 +519,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Log index last-block-of-map", filterMapLastBlock.Size(), filterMapLastBlock.Count()},
 		{"Key-Value store", "Log index block-lv", filterMapBlockLV.Size(), filterMapBlockLV.Count()},
 		{"Key-Value store", "Log bloombits (deprecated)", bloomBits.Size(), bloomBits.Count()},
+		{"Key-Value store", "Blob archive index", blobArchiveIndex.Size(), blobArchiveIndex.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
 		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
@@ -550,6 +556,13 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 	table.AppendBulk(stats)
 	table.Render()
 
+	// Show the placement of the freezers spread over multiple directories.
+	if ancient, err := db.AncientDatadir(); err == nil && ancient != "" {
+		if err := printFreezerTiers(ancient); err != nil {
+			log.Warn("Failed to inspect freezer tiers", "err", err)
+		}
+	}
+
 	if unaccounted.size > 0 {
 		log.Error("Database contains unaccounted data", "size", unaccounted.size, "count", unaccounted.count)
 		for _, e := range slices.SortedFunc(maps.Values(unaccountedKeys), bytes.Compare) {
@@ -566,7 +579,7 @@ var knownMetadataKeys = [][]byte{
 	snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 	uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
 	persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
-	filterMapsRangeKey,
+	filterMapsRangeKey, pathPruneProgressKey,
 }
 
 // printChainMetadata prints out chain metadata to stderr.
@@ -120,8 +120,20 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		instanceLock: lock,
 	}
 
-	// Create the tables.
-	for name, config := range tables {
+	// Create the tables at the locations configured by the layout, finishing
+	// any interrupted table migration first.
+	layout, err := loadFreezerLayout(datadir)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+	for name, config := range layout.apply(datadir, tables) {
+		if !readonly {
+			if err := recoverTableMigration(config.dir, name, config.coldDir); err != nil {
+				lock.Unlock()
+				return nil, err
+			}
+		}
 		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, config, readonly)
 		if err != nil {
 			for _, table := range freezer.tables {
@@ -132,7 +144,6 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		}
 		freezer.tables[name] = table
 	}
-	var err error
 	if freezer.readonly {
 		// In readonly mode only validate, don't truncate.
 		// validate also sets `freezer.frozen`.
@@ -0,0 +1,186 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+)
+
+// freezerCodec identifies the compression algorithm of the items in a freezer
+// table. The codec is persisted in the table metadata, tables with legacy
+// metadata use the default codec.
+type freezerCodec uint8
+
+const (
+	// codecDefault compresses the items with snappy, unless the compression
+	// is disabled for the table (noSnappy).
+	codecDefault freezerCodec = iota
+
+	// codecZstd compresses the items with zstd, using the dictionary stored
+	// in the metadata if present.
+	codecZstd
+)
+
+// String implements fmt.Stringer.
+func (c freezerCodec) String() string {
+	switch c {
+	case codecDefault:
+		return "snappy"
+	case codecZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// parseFreezerCodec parses the name of a compression codec of freezer tables.
+func parseFreezerCodec(name string) (freezerCodec, error) {
+	switch name {
+	case "snappy":
+		return codecDefault, nil
+	case "zstd":
+		return codecZstd, nil
+	default:
+		return 0, fmt.Errorf("unknown freezer codec %q, supported ones: snappy, zstd", name)
+	}
+}
+
+// itemCodec compresses and decompresses the items of a freezer table. The
+// implementations must be safe for concurrent use.
+type itemCodec interface {
+	// encode compresses the item, reusing the dst buffer if large enough.
+	encode(dst, src []byte) []byte
+
+	// decode decompresses the item.
+	decode(src []byte) ([]byte, error)
+
+	// decodedLen returns the size of the decompressed item, without performing
+	// the decompression.
+	decodedLen(src []byte) (int, error)
+
+	// close releases the resources held by the codec.
+	close()
+}
+
+// newItemCodec creates the codec of a freezer table. Nil is returned if the
+// items of the table are stored uncompressed.
+func newItemCodec(config freezerTableConfig, codec freezerCodec, dict []byte) (itemCodec, error) {
+	if config.noSnappy {
+		if codec != codecDefault {
+			return nil, fmt.Errorf("%v compression of raw table", codec)
+		}
+		return nil, nil
+	}
+	switch codec {
+	case codecDefault:
+		return snappyCodec{}, nil
+	case codecZstd:
+		return newZstdCodec(dict)
+	default:
+		return nil, fmt.Errorf("unknown freezer codec %d", codec)
+	}
+}
+
+// snappyCodec compresses the items with snappy in block format.
+type snappyCodec struct{}
+
+func (snappyCodec) encode(dst, src []byte) []byte {
+	// The snappy library does not care what the capacity of the buffer is,
+	// but only checks the length. If the length is too small, it will
+	// allocate a brand new buffer.
+	// To avoid that, we check the required size here, and grow the size of the
+	// buffer to utilize the full capacity.
+	if n := snappy.MaxEncodedLen(len(src)); len(dst) < n {
+		if cap(dst) < n {
+			dst = make([]byte, n)
+		}
+		dst = dst[:n]
+	}
+	return snappy.Encode(dst, src)
+}
+
+func (snappyCodec) decode(src []byte) ([]byte, error) {
+	return snappy.Decode(nil, src)
+}
+
+func (snappyCodec) decodedLen(src []byte) (int, error) {
+	return snappy.DecodedLen(src)
+}
+
+func (snappyCodec) close() {}
+
+// zstdCodec compresses the items with zstd, each item in a separate frame.
+type zstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// newZstdCodec creates a zstd codec, using the given dictionary if non-empty.
+func newZstdCodec(dict []byte) (*zstdCodec, error) {
+	var (
+		eopts = []zstd.EOption{zstd.WithEncoderConcurrency(1), zstd.WithEncoderCRC(false)}
+		dopts = []zstd.DOption{zstd.WithDecoderConcurrency(0)}
+	)
+	if len(dict) > 0 {
+		eopts = append(eopts, zstd.WithEncoderDict(dict))
+		dopts = append(dopts, zstd.WithDecoderDicts(dict))
+	}
+	encoder, err := zstd.NewWriter(nil, eopts...)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil, dopts...)
+	if err != nil {
+		encoder.Close()
+		return nil, err
+	}
+	return &zstdCodec{encoder: encoder, decoder: decoder}, nil
+}
+
+func (c *zstdCodec) encode(dst, src []byte) []byte {
+	return c.encoder.EncodeAll(src, dst[:0])
+}
+
+func (c *zstdCodec) decode(src []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(src, nil)
+}
+
+func (c *zstdCodec) decodedLen(src []byte) (int, error) {
+	var header zstd.Header
+	if err := header.Decode(src); err != nil {
+		return 0, err
+	}
+	if !header.HasFCS {
+		return len(src), nil // Not known upfront, approximate with the frame size
+	}
+	return int(header.FrameContentSize), nil
+}
+
+func (c *zstdCodec) close() {
+	c.encoder.Close()
+	c.decoder.Close()
+}
+
+// trainZstdDict builds a zstd dictionary of at most the given size from the
+// sample items.
+func trainZstdDict(samples [][]byte, size int) ([]byte, error) {
+	return dict.BuildZstdDict(samples, dict.Options{MaxDictSize: size, HashBytes: 6})
+}
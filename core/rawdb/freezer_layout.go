@@ -0,0 +1,447 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gofrs/flock"
+)
+
+// freezerLayoutFile is the name of the file describing the placement of the
+// freezer tables, stored in the freezer directory.
+const freezerLayoutFile = "LAYOUT.json"
+
+// errFreezerCopyAborted is returned if copying a freezer file is interrupted.
+var errFreezerCopyAborted = errors.New("freezer file copy aborted")
+
+// FreezerLayout describes the placement of the files of the freezer tables on
+// the disk, allowing to spread the tables over separate volumes.
+//
+// Each table may be placed in a separate directory, holding its index, metadata
+// and data files. Additionally, the old data files of all the tables may be
+// offloaded to a cold directory, keeping only the most recent data files in the
+// table directory. New data files are always created in the table directory,
+// and moved to the cold directory in the background once they get old.
+type FreezerLayout struct {
+	Tables   map[string]string `json:"tables,omitempty"`   // Directories of the tables placed outside of the freezer directory
+	ColdDir  string            `json:"coldDir,omitempty"`  // Directory of the old data files, tiering is disabled if empty
+	HotFiles uint32            `json:"hotFiles,omitempty"` // Number of the most recent data files not offloaded, at least one
+}
+
+// loadFreezerLayout reads the layout of the freezer in the given directory.
+// An empty layout is returned if the freezer doesn't have one.
+func loadFreezerLayout(datadir string) (*FreezerLayout, error) {
+	blob, err := os.ReadFile(filepath.Join(datadir, freezerLayoutFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return new(FreezerLayout), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var layout FreezerLayout
+	if err := json.Unmarshal(blob, &layout); err != nil {
+		return nil, fmt.Errorf("invalid freezer layout: %v", err)
+	}
+	return &layout, nil
+}
+
+// store atomically writes the layout into the given freezer directory.
+func (l *FreezerLayout) store(datadir string) error {
+	blob, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(datadir, freezerLayoutFile+".tmp")
+	if err := writeFileSync(tmp, blob); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(datadir, freezerLayoutFile)); err != nil {
+		return err
+	}
+	return syncDir(datadir)
+}
+
+// validate checks the layout against the tables of the freezer, and turns all
+// the directories into absolute paths.
+func (l *FreezerLayout) validate(tables map[string]freezerTableConfig) error {
+	for name, dir := range l.Tables {
+		if _, ok := tables[name]; !ok {
+			return fmt.Errorf("unknown table %q, supported ones: %v", name, slices.Sorted(maps.Keys(tables)))
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		l.Tables[name] = abs
+	}
+	if l.ColdDir != "" {
+		abs, err := filepath.Abs(l.ColdDir)
+		if err != nil {
+			return err
+		}
+		l.ColdDir = abs
+	}
+	return nil
+}
+
+// tableDir returns the directory of the table in the given freezer directory.
+func (l *FreezerLayout) tableDir(datadir string, name string) string {
+	if dir := l.Tables[name]; dir != "" {
+		return dir
+	}
+	return datadir
+}
+
+// apply returns the table configs with the placement of the layout.
+func (l *FreezerLayout) apply(datadir string, tables map[string]freezerTableConfig) map[string]freezerTableConfig {
+	configs := make(map[string]freezerTableConfig, len(tables))
+	for name, config := range tables {
+		config.dir = l.tableDir(datadir, name)
+		config.coldDir = l.ColdDir
+		config.hotFiles = max(l.HotFiles, 1)
+		configs[name] = config
+	}
+	return configs
+}
+
+// dataFileNumber returns the number of the data file with the given path.
+func dataFileNumber(path string) (uint32, bool) {
+	parts := strings.Split(filepath.Base(path), ".")
+	if len(parts) < 3 {
+		return 0, false
+	}
+	num, err := strconv.ParseUint(parts[len(parts)-2], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(num), true
+}
+
+// dataFiles returns the paths of the data files of a table in the directory.
+func dataFiles(dir string, name string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{".*.rdat", ".*.cdat"} {
+		matches, err := filepath.Glob(filepath.Join(dir, name+pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if _, ok := dataFileNumber(match); ok {
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// filesSize returns the total size of the given files.
+func filesSize(files []string) (uint64, error) {
+	var size uint64
+	for _, file := range files {
+		stat, err := os.Stat(file)
+		if err != nil {
+			return 0, err
+		}
+		size += uint64(stat.Size())
+	}
+	return size, nil
+}
+
+// FreezerTierUsage is the disk usage of a freezer table in each storage tier.
+type FreezerTierUsage struct {
+	Table   string
+	Dir     string // Directory of the table
+	Hot     uint64 // Size of the index, metadata and data files in the table directory
+	ColdDir string // Directory of the old data files, empty if tiering is disabled
+	Cold    uint64 // Size of the data files in the cold directory
+}
+
+// inspectFreezerTiers returns the disk usage of the tables of a freezer in each
+// storage tier.
+func inspectFreezerTiers(datadir string, tables map[string]freezerTableConfig) ([]FreezerTierUsage, error) {
+	layout, err := loadFreezerLayout(datadir)
+	if err != nil {
+		return nil, err
+	}
+	var usages []FreezerTierUsage
+	for _, name := range slices.Sorted(maps.Keys(tables)) {
+		usage := FreezerTierUsage{Table: name, Dir: layout.tableDir(datadir, name), ColdDir: layout.ColdDir}
+		files, err := tableFiles(usage.Dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if usage.Hot, err = filesSize(files); err != nil {
+			return nil, err
+		}
+		if usage.ColdDir != "" {
+			if files, err = dataFiles(usage.ColdDir, name); err != nil {
+				return nil, err
+			}
+			if usage.Cold, err = filesSize(files); err != nil {
+				return nil, err
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// ReadFreezerLayout returns the layout of a freezer. The passed ancient indicates
+// the path of root ancient directory.
+func ReadFreezerLayout(ancient string, freezerName string) (*FreezerLayout, error) {
+	datadir, _, err := resolveFreezer(ancient, freezerName)
+	if err != nil {
+		return nil, err
+	}
+	return loadFreezerLayout(datadir)
+}
+
+// InspectFreezerTiers returns the disk usage of the tables of a freezer in each
+// storage tier. The passed ancient indicates the path of root ancient directory.
+func InspectFreezerTiers(ancient string, freezerName string) ([]FreezerTierUsage, error) {
+	datadir, tables, err := resolveFreezer(ancient, freezerName)
+	if err != nil {
+		return nil, err
+	}
+	return inspectFreezerTiers(datadir, tables)
+}
+
+// RebalanceFreezer moves the files of the freezer tables according to the given
+// layout and persists the layout for the subsequent runs. The freezer must not
+// be in use, it is locked for the duration of the rebalance.
+//
+// The tables moved to a different directory are copied first, and only deleted
+// from the original directory once the new layout is persisted, so the freezer
+// stays consistent if interrupted at any point.
+//
+// Only the chain freezer supports custom layouts, as the other freezers can be
+// reset by deleting their directory.
+func RebalanceFreezer(ancient string, freezerName string, layout *FreezerLayout) error {
+	if freezerName != ChainFreezerName {
+		return fmt.Errorf("custom layout is only supported by the %s freezer", ChainFreezerName)
+	}
+	datadir, tables, err := resolveFreezer(ancient, freezerName)
+	if err != nil {
+		return err
+	}
+	if err := layout.validate(tables); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(datadir, "FLOCK"))
+	if locked, err := lock.TryLock(); err != nil {
+		return err
+	} else if !locked {
+		return errors.New("freezer is in use")
+	}
+	defer lock.Unlock()
+
+	old, err := loadFreezerLayout(datadir)
+	if err != nil {
+		return err
+	}
+	// Move the tables to their new directories, leaving the originals in place.
+	var moved []string
+	for _, name := range slices.Sorted(maps.Keys(tables)) {
+		from, to := old.tableDir(datadir, name), layout.tableDir(datadir, name)
+		if from == to {
+			continue
+		}
+		if err := os.MkdirAll(to, 0755); err != nil {
+			return err
+		}
+		if err := recoverTableMigration(from, name, old.ColdDir); err != nil {
+			return err
+		}
+		files, err := tableFiles(from, name)
+		if err != nil {
+			return err
+		}
+		log.Info("Copying freezer table", "table", name, "from", from, "to", to, "files", len(files))
+		for _, file := range files {
+			if err := copyFreezerFile(file, filepath.Join(to, filepath.Base(file)), nil); err != nil {
+				return err
+			}
+		}
+		moved = append(moved, name)
+	}
+	if err := layout.store(datadir); err != nil {
+		return err
+	}
+	for _, name := range moved {
+		files, err := tableFiles(old.tableDir(datadir, name), name)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	// Move the data files between the storage tiers, as the cold directory or
+	// the number of hot files might have been changed.
+	configs := layout.apply(datadir, tables)
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		if err := rebalanceTable(name, configs[name], old.ColdDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebalanceTable moves the data files of a table between its directory and the
+// cold directory, according to their age.
+func rebalanceTable(name string, config freezerTableConfig, oldCold string) error {
+	// Collect the data files from all the possible locations, the head file
+	// being the newest one.
+	var dirs, files []string
+	for _, dir := range []string{config.dir, oldCold, config.coldDir} {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		matches, err := dataFiles(dir, name)
+		if err != nil {
+			return err
+		}
+		files = append(files, matches...)
+	}
+	var head uint32
+	for _, file := range files {
+		num, _ := dataFileNumber(file)
+		head = max(head, num)
+	}
+	for _, file := range files {
+		num, _ := dataFileNumber(file)
+
+		target := config.dir
+		if config.coldDir != "" && num+config.hotFiles <= head {
+			target = config.coldDir
+		}
+		if filepath.Dir(file) == target {
+			continue
+		}
+		dst := filepath.Join(target, filepath.Base(file))
+		if common.FileExist(dst) {
+			// Leftover of an interrupted move, the file is complete in both places
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		log.Info("Moving freezer file", "file", filepath.Base(file), "to", target)
+		if err := moveFreezerFile(file, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveFreezerFile moves a file to the destination, which may reside on another
+// volume.
+func moveFreezerFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return syncDir(filepath.Dir(dst))
+	}
+	if err := copyFreezerFile(src, dst, nil); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// copyFreezerFile durably copies a file to the destination, which may reside
+// on another volume. The destination is created under a temporary name, and
+// only renamed once complete. The copy is aborted if the quit channel is
+// closed.
+func copyFreezerFile(src, dst string, quit <-chan struct{}) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 1024*1024)
+	for {
+		select {
+		case <-quit:
+			err = errFreezerCopyAborted
+		default:
+			var n int
+			n, err = in.Read(buf)
+			if n > 0 {
+				if _, werr := out.Write(buf[:n]); werr != nil {
+					err = werr
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(dst))
+}
+
+// writeFileSync writes the data into the file and fsyncs it.
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// tierUsage returns the tier usage of a chain freezer table.
+func tierUsage(t *testing.T, ancient string, table string) FreezerTierUsage {
+	t.Helper()
+
+	usages, err := InspectFreezerTiers(ancient, ChainFreezerName)
+	if err != nil {
+		t.Fatalf("Failed to inspect tiers: %v", err)
+	}
+	for _, usage := range usages {
+		if usage.Table == table {
+			return usage
+		}
+	}
+	t.Fatalf("Table %s not found", table)
+	return FreezerTierUsage{}
+}
+
+// countDataFiles returns the number of data files of a table in a directory.
+func countDataFiles(t *testing.T, dir string, table string) int {
+	t.Helper()
+
+	files, err := dataFiles(dir, table)
+	if err != nil {
+		t.Fatalf("Failed to list data files: %v", err)
+	}
+	return len(files)
+}
+
+func TestRebalanceFreezer(t *testing.T) {
+	var (
+		ancient = t.TempDir()
+		bodies  = t.TempDir()
+		cold    = t.TempDir()
+		path    = filepath.Join(ancient, ChainFreezerName)
+	)
+	newMigrationTestFreezer(t, ancient, 500, 100)
+	total := countDataFiles(t, path, ChainFreezerBodiesTable)
+	if total < 4 {
+		t.Fatalf("Too few data files: %d", total)
+	}
+	// Move the bodies to a separate directory and offload all but two data files.
+	layout := &FreezerLayout{
+		Tables:   map[string]string{ChainFreezerBodiesTable: bodies},
+		ColdDir:  cold,
+		HotFiles: 2,
+	}
+	if err := RebalanceFreezer(ancient, ChainFreezerName, layout); err != nil {
+		t.Fatalf("Failed to rebalance freezer: %v", err)
+	}
+	if n := countDataFiles(t, path, ChainFreezerBodiesTable); n != 0 {
+		t.Fatalf("Data files left in the freezer directory: %d", n)
+	}
+	if n := countDataFiles(t, bodies, ChainFreezerBodiesTable); n != 2 {
+		t.Fatalf("Hot data file count mismatch: have %d, want 2", n)
+	}
+	if n := countDataFiles(t, cold, ChainFreezerBodiesTable); n != total-2 {
+		t.Fatalf("Cold data file count mismatch: have %d, want %d", n, total-2)
+	}
+	usage := tierUsage(t, ancient, ChainFreezerBodiesTable)
+	if usage.Dir != bodies || usage.ColdDir != cold || usage.Hot == 0 || usage.Cold == 0 {
+		t.Fatalf("Unexpected tier usage: %+v", usage)
+	}
+	checkMigrationTestFreezer(t, ancient, 500, 100)
+
+	// Recompression must keep the table in its directory.
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerBodiesTable, "zstd", 0); err != nil {
+		t.Fatalf("Failed to recompress table: %v", err)
+	}
+	checkMigrationTestFreezer(t, ancient, 500, 100)
+
+	// Revert to the default layout, moving everything back.
+	if err := RebalanceFreezer(ancient, ChainFreezerName, &FreezerLayout{}); err != nil {
+		t.Fatalf("Failed to rebalance freezer: %v", err)
+	}
+	if n := countDataFiles(t, bodies, ChainFreezerBodiesTable); n != 0 {
+		t.Fatalf("Data files left in the table directory: %d", n)
+	}
+	if n := countDataFiles(t, cold, ChainFreezerBodiesTable); n != 0 {
+		t.Fatalf("Data files left in the cold directory: %d", n)
+	}
+	checkMigrationTestFreezer(t, ancient, 500, 100)
+}
+
+func TestRebalanceFreezerRejected(t *testing.T) {
+	ancient := t.TempDir()
+	newMigrationTestFreezer(t, ancient, 10, 0)
+
+	if err := RebalanceFreezer(ancient, MerkleStateFreezerName, &FreezerLayout{ColdDir: t.TempDir()}); err == nil {
+		t.Fatal("Rebalanced state freezer")
+	}
+	layout := &FreezerLayout{Tables: map[string]string{"unknown": t.TempDir()}}
+	if err := RebalanceFreezer(ancient, ChainFreezerName, layout); err == nil {
+		t.Fatal("Rebalanced unknown table")
+	}
+}
+
+func TestFreezerOffload(t *testing.T) {
+	var (
+		ancient = t.TempDir()
+		cold    = t.TempDir()
+		path    = filepath.Join(ancient, ChainFreezerName)
+	)
+	newMigrationTestFreezer(t, ancient, 10, 0)
+	if err := RebalanceFreezer(ancient, ChainFreezerName, &FreezerLayout{ColdDir: cold, HotFiles: 1}); err != nil {
+		t.Fatalf("Failed to rebalance freezer: %v", err)
+	}
+	// Append enough items to span multiple data files, which must be offloaded
+	// in the background while the freezer is in use.
+	f, err := NewFreezer(path, "", false, 2049, chainFreezerTableConfigs)
+	if err != nil {
+		t.Fatalf("Failed to open freezer: %v", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(10); i < 500; i++ {
+			for kind := range chainFreezerTableConfigs {
+				if err := op.AppendRaw(kind, i, testMigrationItem(kind, i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to write items: %v", err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		if countDataFiles(t, path, ChainFreezerBodiesTable) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Data files not offloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// The offloaded items must stay readable.
+	for i := uint64(0); i < 500; i++ {
+		if _, err := f.Ancient(ChainFreezerBodiesTable, i); err != nil {
+			t.Fatalf("Failed to read item %d: %v", i, err)
+		}
+	}
+	f.Close()
+
+	if countDataFiles(t, cold, ChainFreezerBodiesTable) == 0 {
+		t.Fatal("No data files in the cold directory")
+	}
+	if countDataFiles(t, cold, ChainFreezerHashTable) == 0 {
+		t.Fatal("No raw data files in the cold directory")
+	}
+	checkMigrationTestFreezer(t, ancient, 500, 0)
+}
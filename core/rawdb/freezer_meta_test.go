@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -100,6 +101,48 @@ func TestUpgradeMetadata(t *testing.T) {
 	}
 }
 
+func TestCodecMetadata(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "*")
+	if err != nil {
+		t.Fatalf("Failed to create file %v", err)
+	}
+	defer f.Close()
+
+	meta, err := newMetadata(f)
+	if err != nil {
+		t.Fatalf("Failed to new metadata %v", err)
+	}
+	meta.setVirtualTail(100, false)
+	meta.setCodec(codecZstd, []byte{1, 2, 3}, false)
+
+	meta, err = newMetadata(f)
+	if err != nil {
+		t.Fatalf("Failed to reload metadata %v", err)
+	}
+	if meta.version != freezerTableV3 {
+		t.Fatalf("Unexpected version field")
+	}
+	if meta.virtualTail != uint64(100) {
+		t.Fatalf("Unexpected virtual tail field")
+	}
+	if meta.codec != codecZstd || !bytes.Equal(meta.dict, []byte{1, 2, 3}) {
+		t.Fatalf("Unexpected codec fields")
+	}
+	// Switching back to the default codec must fall back to the v2 format.
+	meta.setCodec(codecDefault, nil, false)
+
+	meta, err = newMetadata(f)
+	if err != nil {
+		t.Fatalf("Failed to reload metadata %v", err)
+	}
+	if meta.version != freezerTableV2 {
+		t.Fatalf("Unexpected version field")
+	}
+	if meta.virtualTail != uint64(100) || meta.codec != codecDefault {
+		t.Fatalf("Unexpected metadata fields")
+	}
+}
+
 func TestInvalidMetadata(t *testing.T) {
 	f, err := os.CreateTemp(t.TempDir(), "*")
 	if err != nil {
@@ -0,0 +1,408 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/gofrs/flock"
+)
+
+const (
+	// migrateBatchBytes is the maximum amount of data copied at once when
+	// rewriting a freezer table.
+	migrateBatchBytes = 16 * 1024 * 1024
+
+	// dictMaxSamples is the maximum number of items sampled for training the
+	// compression dictionary of a freezer table.
+	dictMaxSamples = 32768
+
+	// dictSampleBytes is the maximum amount of sampled data, relative to the
+	// size of the trained dictionary.
+	dictSampleBytes = 100
+)
+
+// The suffixes of the directories used while rewriting a freezer table. The
+// rewritten table is built in the staging directory, the original files are
+// moved to the backup directory, which is renamed to mark the completion of
+// the backup, before moving the rewritten files in place.
+const (
+	migrateStagingSuffix = ".migrating"
+	migrateBackupSuffix  = ".old"
+	migrateDoneSuffix    = ".bak"
+)
+
+// resolveFreezer returns the directory and table configs of a freezer, with
+// the placement of the tables set from the freezer layout.
+func resolveFreezer(ancient string, freezerName string) (string, map[string]freezerTableConfig, error) {
+	var (
+		path   string
+		tables map[string]freezerTableConfig
+	)
+	switch freezerName {
+	case ChainFreezerName:
+		path, tables = resolveChainFreezerDir(ancient), chainFreezerTableConfigs
+	case MerkleStateFreezerName, VerkleStateFreezerName:
+		path, tables = filepath.Join(ancient, freezerName), stateFreezerTableConfigs
+	case BlobArchiveFreezerName:
+		path, tables = filepath.Join(ancient, freezerName), blobArchiveFreezerTableConfigs
+	default:
+		return "", nil, fmt.Errorf("unknown freezer, supported ones: %v", freezers)
+	}
+	layout, err := loadFreezerLayout(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, layout.apply(path, tables), nil
+}
+
+// resolveFreezerTable returns the freezer directory and the config of a table.
+func resolveFreezerTable(ancient string, freezerName string, tableName string) (string, freezerTableConfig, error) {
+	path, tables, err := resolveFreezer(ancient, freezerName)
+	if err != nil {
+		return "", freezerTableConfig{}, err
+	}
+	config, exist := tables[tableName]
+	if !exist {
+		return "", freezerTableConfig{}, fmt.Errorf("unknown table, supported ones: %v", slices.Sorted(maps.Keys(tables)))
+	}
+	return path, config, nil
+}
+
+// RecompressFreezerTable rewrites all the items of a freezer table with the
+// given compression codec ("snappy" or "zstd"). For zstd, a dictionary of the
+// given size is trained on the items of the table if dictSize is non-zero. The
+// freezer must not be in use, it is locked for the duration of the rewrite.
+//
+// The rewritten table is swapped in place only once complete. If interrupted
+// during the swap, the migration is completed when the freezer is opened next.
+func RecompressFreezerTable(ancient string, freezerName string, tableName string, codecName string, dictSize int) error {
+	datadir, config, err := resolveFreezerTable(ancient, freezerName, tableName)
+	if err != nil {
+		return err
+	}
+	if config.noSnappy {
+		return fmt.Errorf("compression is disabled for table %s", tableName)
+	}
+	codec, err := parseFreezerCodec(codecName)
+	if err != nil {
+		return err
+	}
+	if codec != codecZstd && dictSize != 0 {
+		return errors.New("dictionary is only supported by zstd")
+	}
+	lock := flock.New(filepath.Join(datadir, "FLOCK"))
+	if locked, err := lock.TryLock(); err != nil {
+		return err
+	} else if !locked {
+		return errors.New("freezer is in use")
+	}
+	defer lock.Unlock()
+
+	path := config.dir
+	if err := recoverTableMigration(path, tableName, config.coldDir); err != nil {
+		return err
+	}
+	oldSize, dict, skipped, err := rewriteTableFiles(path, tableName, config, codec, dictSize)
+	if err != nil || skipped {
+		return err
+	}
+	if err := swapTable(path, tableName, config.coldDir); err != nil {
+		return err
+	}
+	// Reopen the table to ensure the swapped files are complete
+	table, err := newFreezerTable(path, tableName, config, true)
+	if err != nil {
+		return err
+	}
+	defer table.Close()
+
+	newSize, err := table.size()
+	if err != nil {
+		return err
+	}
+	log.Info("Recompressed freezer table", "table", tableName, "codec", codec, "dict", common.StorageSize(len(dict)),
+		"old", common.StorageSize(oldSize), "new", common.StorageSize(newSize))
+	return nil
+}
+
+// rewriteTableFiles rewrites the table into the staging directory, returning
+// the size of the original table and the trained dictionary. The rewrite is
+// skipped if the table already uses the default codec.
+func rewriteTableFiles(path string, name string, config freezerTableConfig, codec freezerCodec, dictSize int) (uint64, []byte, bool, error) {
+	table, err := newFreezerTable(path, name, config, false)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	defer table.Close()
+
+	if table.metadata.codec == codec && codec == codecDefault {
+		log.Info("Freezer table already uses the requested codec", "table", name, "codec", codec)
+		return 0, nil, true, nil
+	}
+	var dict []byte
+	if dictSize > 0 {
+		if dict, err = trainTableDict(table, dictSize); err != nil {
+			return 0, nil, false, err
+		}
+	}
+	staging := filepath.Join(path, name+migrateStagingSuffix)
+	if err := rewriteTable(table, staging, codec, dict); err != nil {
+		os.RemoveAll(staging)
+		return 0, nil, false, err
+	}
+	size, err := table.size()
+	if err != nil {
+		return 0, nil, false, err
+	}
+	return size, dict, false, nil
+}
+
+// trainTableDict trains a zstd dictionary on items sampled evenly across the
+// given table.
+func trainTableDict(table *freezerTable, dictSize int) ([]byte, error) {
+	var (
+		first   = table.itemHidden.Load()
+		count   = table.items.Load() - first
+		step    = max(count/dictMaxSamples, 1)
+		samples [][]byte
+		total   int
+	)
+	for i := first; i < first+count && total < dictSize*dictSampleBytes; i += step {
+		item, err := table.Retrieve(i)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, item)
+		total += len(item)
+	}
+	if len(samples) == 0 {
+		return nil, errors.New("no items to train the dictionary on")
+	}
+	log.Info("Training compression dictionary", "table", table.name, "samples", len(samples), "size", common.StorageSize(total))
+	return trainZstdDict(samples, dictSize)
+}
+
+// rewriteTable copies the visible items of the table into a new table in the
+// staging directory, compressed with the given codec.
+func rewriteTable(table *freezerTable, staging string, codec freezerCodec, dict []byte) error {
+	var (
+		first = table.itemHidden.Load()
+		items = table.items.Load()
+	)
+	if first > math.MaxUint32 {
+		return fmt.Errorf("table tail %d out of range", first)
+	}
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return err
+	}
+	// Initialize the index and the metadata of the new table, starting at the
+	// tail of the original one.
+	index, err := openFreezerFileForAppend(filepath.Join(staging, table.name+".cidx"))
+	if err != nil {
+		return err
+	}
+	tail := indexEntry{filenum: 0, offset: uint32(first)}
+	_, err = index.Write(tail.append(nil))
+	if cerr := index.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	file, err := openFreezerFileForAppend(filepath.Join(staging, table.name+".meta"))
+	if err != nil {
+		return err
+	}
+	meta := &freezerTableMeta{file: file, version: freezerVersion, virtualTail: first, codec: codec, dict: dict}
+	err = meta.write(true)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	// Copy all the items, recompressing them on the way. All the files of the
+	// new table are placed in the staging directory.
+	config := table.config
+	config.dir, config.coldDir = "", ""
+	dest, err := newTable(staging, table.name, metrics.NewInactiveMeter(), metrics.NewInactiveMeter(), metrics.NewGauge(), table.maxFileSize, config, false)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	var (
+		batch  = dest.newBatch()
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := first; number < items; {
+		blobs, err := table.RetrieveItems(number, items-number, migrateBatchBytes)
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			if err := batch.AppendRaw(number, blob); err != nil {
+				return err
+			}
+			number++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Recompressing freezer table", "table", table.name, "at", number, "items", items,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.commit(); err != nil {
+		return err
+	}
+	if have := dest.items.Load(); have != items {
+		return fmt.Errorf("rewritten item count mismatch: have %d, want %d", have, items)
+	}
+	return dest.Sync()
+}
+
+// tableFiles returns the paths of all the files of a table in the directory.
+func tableFiles(dir string, name string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{".meta", ".ridx", ".cidx", ".*.rdat", ".*.cdat"} {
+		matches, err := filepath.Glob(filepath.Join(dir, name+pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// moveTableFiles moves all the files of a table between two directories.
+func moveTableFiles(from, to string, name string) error {
+	files, err := tableFiles(from, name)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Rename(file, filepath.Join(to, filepath.Base(file))); err != nil {
+			return err
+		}
+	}
+	return syncDir(to)
+}
+
+// swapTable replaces the files of a table with the rewritten ones from the
+// staging directory. It's safe to resume by recoverTableMigration if
+// interrupted at any point.
+func swapTable(path string, name string, coldDir string) error {
+	var (
+		backup = filepath.Join(path, name+migrateBackupSuffix)
+		done   = filepath.Join(path, name+migrateDoneSuffix)
+	)
+	if err := os.MkdirAll(backup, 0755); err != nil {
+		return err
+	}
+	if err := moveTableFiles(path, backup, name); err != nil {
+		return err
+	}
+	if err := os.Rename(backup, done); err != nil {
+		return err
+	}
+	if err := syncDir(path); err != nil {
+		return err
+	}
+	return finishTableSwap(path, name, coldDir)
+}
+
+// finishTableSwap moves the rewritten files of a table in place, once the
+// original files are backed up. The original data files offloaded to the cold
+// directory are deleted.
+func finishTableSwap(path string, name string, coldDir string) error {
+	staging := filepath.Join(path, name+migrateStagingSuffix)
+	if err := moveTableFiles(staging, path, name); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if coldDir != "" {
+		files, err := dataFiles(coldDir, name)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return os.RemoveAll(filepath.Join(path, name+migrateDoneSuffix))
+}
+
+// recoverTableMigration cleans up the leftovers of an interrupted rewrite of
+// the table, finishing the swap of files if it was already started.
+func recoverTableMigration(path string, name string, coldDir string) error {
+	var (
+		staging = filepath.Join(path, name+migrateStagingSuffix)
+		backup  = filepath.Join(path, name+migrateBackupSuffix)
+		done    = filepath.Join(path, name+migrateDoneSuffix)
+	)
+	switch {
+	case common.FileExist(done):
+		// The original files are backed up, move the rewritten ones in place.
+		log.Warn("Finishing interrupted freezer table migration", "table", name)
+		return finishTableSwap(path, name, coldDir)
+
+	case common.FileExist(backup):
+		// The backup of the original files was interrupted, the rewritten
+		// table is complete though.
+		log.Warn("Resuming interrupted freezer table migration", "table", name)
+		return swapTable(path, name, coldDir)
+
+	case common.FileExist(staging):
+		// The rewrite was interrupted, the original table is untouched.
+		log.Warn("Discarding incomplete freezer table migration", "table", name)
+		return os.RemoveAll(staging)
+	}
+	return nil
+}
+
+// syncDir fsyncs the directory to persist the renamed entries.
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Syncing directories is unsupported on some platforms (e.g. windows),
+	// the error is ignored there.
+	f.Sync()
+	return nil
+}
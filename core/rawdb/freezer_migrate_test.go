@@ -0,0 +1,214 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// testMigrationItem returns a compressible test item.
+func testMigrationItem(kind string, number uint64) []byte {
+	return bytes.Repeat([]byte(fmt.Sprintf("%s-item-%d;", kind, number)), int(number%7)+1)
+}
+
+// newMigrationTestFreezer creates a chain freezer in the ancient directory,
+// filled with the given number of items, and with the given tail.
+func newMigrationTestFreezer(t *testing.T, ancient string, items, tail uint64) {
+	f, err := NewFreezer(filepath.Join(ancient, ChainFreezerName), "", false, 2049, chainFreezerTableConfigs)
+	if err != nil {
+		t.Fatalf("Failed to open freezer: %v", err)
+	}
+	defer f.Close()
+
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < items; i++ {
+			for kind := range chainFreezerTableConfigs {
+				if err := op.AppendRaw(kind, i, testMigrationItem(kind, i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to write items: %v", err)
+	}
+	if _, err := f.TruncateTail(tail); err != nil {
+		t.Fatalf("Failed to truncate tail: %v", err)
+	}
+}
+
+// checkMigrationTestFreezer checks the content of the chain freezer.
+func checkMigrationTestFreezer(t *testing.T, ancient string, items, tail uint64) {
+	t.Helper()
+
+	f, err := NewFreezer(filepath.Join(ancient, ChainFreezerName), "", false, 2049, chainFreezerTableConfigs)
+	if err != nil {
+		t.Fatalf("Failed to open freezer: %v", err)
+	}
+	defer f.Close()
+
+	if have, _ := f.Ancients(); have != items {
+		t.Fatalf("Item count mismatch: have %d, want %d", have, items)
+	}
+	if have, _ := f.Tail(); have != tail {
+		t.Fatalf("Tail mismatch: have %d, want %d", have, tail)
+	}
+	for i := tail; i < items; i++ {
+		blob, err := f.Ancient(ChainFreezerBodiesTable, i)
+		if err != nil {
+			t.Fatalf("Failed to read item %d: %v", i, err)
+		}
+		if !bytes.Equal(blob, testMigrationItem(ChainFreezerBodiesTable, i)) {
+			t.Fatalf("Item %d mismatch", i)
+		}
+	}
+	if _, err := f.Ancient(ChainFreezerBodiesTable, tail-1); err == nil {
+		t.Fatal("Item below the tail is readable")
+	}
+}
+
+func TestRecompressFreezerTable(t *testing.T) {
+	ancient := t.TempDir()
+	newMigrationTestFreezer(t, ancient, 500, 100)
+
+	// Recompress with zstd and a trained dictionary.
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerBodiesTable, "zstd", 1024); err != nil {
+		t.Fatalf("Failed to recompress table: %v", err)
+	}
+	checkMigrationTestFreezer(t, ancient, 500, 100)
+
+	path := filepath.Join(ancient, ChainFreezerName)
+	table, err := newFreezerTable(path, ChainFreezerBodiesTable, chainFreezerTableConfigs[ChainFreezerBodiesTable], true)
+	if err != nil {
+		t.Fatalf("Failed to open table: %v", err)
+	}
+	if table.metadata.codec != codecZstd || len(table.metadata.dict) == 0 {
+		t.Fatalf("Unexpected table codec: %v, dict size %d", table.metadata.codec, len(table.metadata.dict))
+	}
+	table.Close()
+
+	// Appending to the recompressed table must use the new codec.
+	f, err := NewFreezer(path, "", false, 2049, chainFreezerTableConfigs)
+	if err != nil {
+		t.Fatalf("Failed to open freezer: %v", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(500); i < 600; i++ {
+			for kind := range chainFreezerTableConfigs {
+				if err := op.AppendRaw(kind, i, testMigrationItem(kind, i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to write items: %v", err)
+	}
+	f.Close()
+	checkMigrationTestFreezer(t, ancient, 600, 100)
+
+	// Revert to snappy, which must restore the legacy metadata format.
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerBodiesTable, "snappy", 0); err != nil {
+		t.Fatalf("Failed to recompress table: %v", err)
+	}
+	checkMigrationTestFreezer(t, ancient, 600, 100)
+
+	meta, err := os.Open(filepath.Join(path, ChainFreezerBodiesTable+".meta"))
+	if err != nil {
+		t.Fatalf("Failed to open metadata: %v", err)
+	}
+	defer meta.Close()
+	if decodeV2(meta) == nil {
+		t.Fatal("Metadata of snappy table not in v2 format")
+	}
+}
+
+func TestRecompressFreezerTableRejected(t *testing.T) {
+	ancient := t.TempDir()
+	newMigrationTestFreezer(t, ancient, 10, 0)
+
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerHashTable, "zstd", 0); err == nil {
+		t.Fatal("Recompressed raw table")
+	}
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerBodiesTable, "lz4", 0); err == nil {
+		t.Fatal("Recompressed with unknown codec")
+	}
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerBodiesTable, "snappy", 1024); err == nil {
+		t.Fatal("Recompressed snappy with dictionary")
+	}
+}
+
+func TestRecoverTableMigration(t *testing.T) {
+	for _, moved := range []int{0, 1, 2} {
+		t.Run(fmt.Sprintf("moved-%d", moved), func(t *testing.T) {
+			ancient := t.TempDir()
+			newMigrationTestFreezer(t, ancient, 200, 20)
+
+			// Rewrite the table and interrupt the backup of the original files.
+			path := filepath.Join(ancient, ChainFreezerName)
+			config := chainFreezerTableConfigs[ChainFreezerBodiesTable]
+			if _, _, _, err := rewriteTableFiles(path, ChainFreezerBodiesTable, config, codecZstd, 0); err != nil {
+				t.Fatalf("Failed to rewrite table: %v", err)
+			}
+			backup := filepath.Join(path, ChainFreezerBodiesTable+migrateBackupSuffix)
+			if err := os.MkdirAll(backup, 0755); err != nil {
+				t.Fatal(err)
+			}
+			files, _ := tableFiles(path, ChainFreezerBodiesTable)
+			for _, file := range files[:moved] {
+				if err := os.Rename(file, filepath.Join(backup, filepath.Base(file))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			checkMigrationTestFreezer(t, ancient, 200, 20)
+
+			for _, suffix := range []string{migrateStagingSuffix, migrateBackupSuffix, migrateDoneSuffix} {
+				if _, err := os.Stat(filepath.Join(path, ChainFreezerBodiesTable+suffix)); !os.IsNotExist(err) {
+					t.Fatalf("Leftover migration directory %s", suffix)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscardTableMigration(t *testing.T) {
+	ancient := t.TempDir()
+	newMigrationTestFreezer(t, ancient, 200, 20)
+
+	// Leave an incomplete rewrite behind, which must be discarded.
+	path := filepath.Join(ancient, ChainFreezerName)
+	staging := filepath.Join(path, ChainFreezerBodiesTable+migrateStagingSuffix)
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(staging, ChainFreezerBodiesTable+".cidx"), []byte{1, 2, 3}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	checkMigrationTestFreezer(t, ancient, 200, 20)
+
+	if _, err := os.Stat(staging); !os.IsNotExist(err) {
+		t.Fatal("Incomplete migration not discarded")
+	}
+}
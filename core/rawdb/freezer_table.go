@@ -32,7 +32,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
-	"github.com/golang/snappy"
 )
 
 var (
@@ -101,6 +100,7 @@ type freezerTable struct {
 	itemHidden atomic.Uint64
 
 	config      freezerTableConfig // if true, disables snappy compression. Note: does not work retroactively
+	codec       itemCodec          // item compression codec, nil if items are stored raw
 	readonly    bool
 	maxFileSize uint32 // Max file size for data-files
 	name        string
@@ -122,6 +122,10 @@ type freezerTable struct {
 
 	logger log.Logger   // Logger with database path and table name embedded
 	lock   sync.RWMutex // Mutex protecting the data file descriptors
+
+	offloadCh   chan struct{}  // Notification channel of new head files, nil if tiering is disabled
+	offloadQuit chan struct{}  // Quit channel of the offloader
+	offloadWg   sync.WaitGroup // Tracker of the offloader
 }
 
 // newFreezerTable opens the given path as a freezer table.
@@ -133,6 +137,10 @@ func newFreezerTable(path, name string, config freezerTableConfig, readonly bool
 // non-existent. Both files are truncated to the shortest common length to ensure
 // they don't go out of sync.
 func newTable(path string, name string, readMeter, writeMeter *metrics.Meter, sizeGauge *metrics.Gauge, maxFilesize uint32, config freezerTableConfig, readonly bool) (*freezerTable, error) {
+	// Place the table into the directory configured by the freezer layout
+	if config.dir != "" {
+		path = config.dir
+	}
 	// Ensure the containing directory exists and open the indexEntry file
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
@@ -174,6 +182,10 @@ func newTable(path string, name string, readMeter, writeMeter *metrics.Meter, si
 	if err != nil {
 		return nil, err
 	}
+	codec, err := newItemCodec(config, metadata.codec, metadata.dict)
+	if err != nil {
+		return nil, fmt.Errorf("freezer table %s: %w", name, err)
+	}
 	// Create the table and repair any past inconsistency
 	tab := &freezerTable{
 		index:       index,
@@ -187,6 +199,7 @@ func newTable(path string, name string, readMeter, writeMeter *metrics.Meter, si
 		path:        path,
 		logger:      log.New("database", path, "table", name),
 		config:      config,
+		codec:       codec,
 		readonly:    readonly,
 		maxFileSize: maxFilesize,
 	}
@@ -202,6 +215,13 @@ func newTable(path string, name string, readMeter, writeMeter *metrics.Meter, si
 	}
 	tab.sizeGauge.Inc(int64(size))
 
+	// Start moving the old data files to the cold directory
+	if config.coldDir != "" && !readonly {
+		tab.offloadCh = make(chan struct{}, 1)
+		tab.offloadQuit = make(chan struct{})
+		tab.offloadWg.Add(1)
+		go tab.offloadLoop()
+	}
 	return tab, nil
 }
 
@@ -839,6 +859,12 @@ func (t *freezerTable) truncateTail(items uint64) error {
 // This operation must be completed before shutdown to prevent the loss of
 // recent writes.
 func (t *freezerTable) Close() error {
+	// Stop the offloader before closing the files
+	if t.offloadQuit != nil {
+		close(t.offloadQuit)
+		t.offloadWg.Wait()
+		t.offloadQuit = nil
+	}
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -859,6 +885,9 @@ func (t *freezerTable) Close() error {
 	t.index = nil
 	t.head = nil
 	t.metadata.file = nil
+	if t.codec != nil {
+		t.codec.close()
+	}
 
 	if errs != nil {
 		return fmt.Errorf("%v", errs)
@@ -866,17 +895,33 @@ func (t *freezerTable) Close() error {
 	return nil
 }
 
+// dataFileName returns the name of the data file with the given number.
+func (t *freezerTable) dataFileName(num uint32) string {
+	if t.config.noSnappy {
+		return fmt.Sprintf("%s.%04d.rdat", t.name, num)
+	}
+	return fmt.Sprintf("%s.%04d.cdat", t.name, num)
+}
+
+// dataFilePath returns the path of the data file with the given number. The
+// file resides in the cold directory if it was offloaded, otherwise (or if
+// it's non-existent) in the table directory.
+func (t *freezerTable) dataFilePath(num uint32) string {
+	path := filepath.Join(t.path, t.dataFileName(num))
+	if t.config.coldDir == "" || common.FileExist(path) {
+		return path
+	}
+	if cold := filepath.Join(t.config.coldDir, t.dataFileName(num)); common.FileExist(cold) {
+		return cold
+	}
+	return path
+}
+
 // openFile assumes that the write-lock is held by the caller
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.config.noSnappy {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
-		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(t.dataFilePath(num))
 		if err != nil {
 			return nil, err
 		}
@@ -987,14 +1032,14 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 		item := diskData[offset : offset+diskSize]
 		offset += diskSize
 		decompressedSize := diskSize
-		if !t.config.noSnappy {
-			decompressedSize, _ = snappy.DecodedLen(item)
+		if t.codec != nil {
+			decompressedSize, _ = t.codec.decodedLen(item)
 		}
 		if i > 0 && maxBytes != 0 && uint64(outputSize+decompressedSize) > maxBytes {
 			break
 		}
-		if !t.config.noSnappy {
-			data, err := snappy.Decode(nil, item)
+		if t.codec != nil {
+			data, err := t.codec.decode(item)
 			if err != nil {
 				return nil, err
 			}
@@ -1164,9 +1209,88 @@ func (t *freezerTable) advanceHead() error {
 	t.head = newHead
 	t.headBytes = 0
 	t.headId = nextID
+
+	// Notify the offloader about the aged data files
+	if t.offloadCh != nil {
+		select {
+		case t.offloadCh <- struct{}{}:
+		default:
+		}
+	}
 	return nil
 }
 
+// offloadLoop moves the data files which are out of the hot tier into the cold
+// directory, whenever a new head file is created.
+func (t *freezerTable) offloadLoop() {
+	defer t.offloadWg.Done()
+
+	for {
+		for {
+			moved, err := t.offloadFile()
+			if err != nil {
+				if !errors.Is(err, errFreezerCopyAborted) {
+					t.logger.Error("Failed to offload freezer file", "err", err)
+				}
+				break
+			}
+			if !moved {
+				break
+			}
+		}
+		select {
+		case <-t.offloadCh:
+		case <-t.offloadQuit:
+			return
+		}
+	}
+}
+
+// offloadFile moves the oldest data file out of the hot tier into the cold
+// directory, reporting whether there was any such file.
+func (t *freezerTable) offloadFile() (bool, error) {
+	// Find the oldest data file which is still in the table directory
+	var (
+		num uint32
+		src *os.File
+	)
+	t.lock.RLock()
+	for n := t.tailId; n+t.config.hotFiles <= t.headId; n++ {
+		if f := t.files[n]; f != nil && filepath.Dir(f.Name()) != t.config.coldDir {
+			num, src = n, f
+			break
+		}
+	}
+	t.lock.RUnlock()
+	if src == nil {
+		return false, nil
+	}
+	// Copy the file without holding the lock, the file is immutable unless the
+	// table is truncated meanwhile.
+	dst := filepath.Join(t.config.coldDir, filepath.Base(src.Name()))
+	if err := os.MkdirAll(t.config.coldDir, 0755); err != nil {
+		return false, err
+	}
+	if err := copyFreezerFile(src.Name(), dst, t.offloadQuit); err != nil {
+		return false, err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.files[num] != src {
+		// The file was deleted or rewritten by a truncation, drop the copy
+		return true, os.Remove(dst)
+	}
+	f, err := openFreezerFileForReadOnly(dst)
+	if err != nil {
+		return false, err
+	}
+	t.files[num] = f
+	src.Close()
+	t.logger.Debug("Offloaded freezer file", "file", filepath.Base(dst), "dir", t.config.coldDir)
+	return true, os.Remove(src.Name())
+}
+
 // Sync pushes any pending data from memory out to disk. This is an expensive
 // operation, so use it with care.
 func (t *freezerTable) Sync() error {
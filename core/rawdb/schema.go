@@ -76,6 +76,10 @@ var (
 	// trieJournalKey tracks the in-memory trie node layers across restarts.
 	trieJournalKey = []byte("TrieJournal")
 
+	// pathPruneProgressKey tracks the offline path-based state pruning progress
+	// across restarts.
+	pathPruneProgressKey = []byte("PathPruneProgress")
+
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
@@ -144,6 +148,28 @@ var (
 	// old log index
 	bloomBitsMetaPrefix = []byte("iB")
 
+	// blob archive, avoid a leading `b` as it's used for block bodies
+	blobArchiveIndexPrefix = []byte("xb-") // blobArchiveIndexPrefix + versioned hash -> archive item id (uint64 big endian)
+
+	// call trace index
+	traceIndexPrefix       = "ti-"
+	traceIndexTailKey      = []byte(traceIndexPrefix + "T")
+	traceIndexBlockPrefix  = []byte(traceIndexPrefix + "b") // traceIndexBlockPrefix + num (uint64 big endian) + hash -> json encoded flat call traces
+	traceIndexSenderPrefix = []byte(traceIndexPrefix + "f") // traceIndexSenderPrefix + address + num (uint64 big endian) + hash -> empty
+	traceIndexRecipPrefix  = []byte(traceIndexPrefix + "t") // traceIndexRecipPrefix + address + num (uint64 big endian) + hash -> empty
+
+	// token transfer index
+	transferIndexPrefix        = "tt-"
+	transferIndexTailKey       = []byte(transferIndexPrefix + "T")
+	transferIndexBlockPrefix   = []byte(transferIndexPrefix + "b") // transferIndexBlockPrefix + num (uint64 big endian) + hash -> json encoded transfers
+	transferIndexAddressPrefix = []byte(transferIndexPrefix + "a") // transferIndexAddressPrefix + address + num (uint64 big endian) + hash -> empty
+
+	// state history index
+	stateIndexPrefix        = "sh-"
+	stateIndexHeadKey       = []byte(stateIndexPrefix + "H")
+	stateIndexAccountPrefix = []byte(stateIndexPrefix + "a") // stateIndexAccountPrefix + account hash + id (uint64 big endian) -> original account
+	stateIndexStoragePrefix = []byte(stateIndexPrefix + "s") // stateIndexStoragePrefix + account hash + storage hash + id (uint64 big endian) -> original slot
+
 	preimageCounter     = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitsCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 	preimageMissCounter = metrics.NewRegisteredCounter("db/preimage/miss", nil)
@@ -332,6 +358,50 @@ func IsStorageTrieNode(key []byte) bool {
 	return ok
 }
 
+// blobArchiveIndexKey = blobArchiveIndexPrefix + versioned hash
+func blobArchiveIndexKey(vhash common.Hash) []byte {
+	return append(blobArchiveIndexPrefix, vhash.Bytes()...)
+}
+
+// traceIndexBlockKey = traceIndexBlockPrefix + num (uint64 big endian) + hash
+func traceIndexBlockKey(number uint64, hash common.Hash) []byte {
+	return append(append(append([]byte{}, traceIndexBlockPrefix...), encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// traceIndexAddressKey = traceIndexSenderPrefix/traceIndexRecipPrefix + address + num (uint64 big endian) + hash
+func traceIndexAddressKey(addr common.Address, sender bool, number uint64, hash common.Hash) []byte {
+	return append(append(traceIndexAddressPrefix(addr, sender), encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// traceIndexAddressPrefix = traceIndexSenderPrefix/traceIndexRecipPrefix + address
+func traceIndexAddressPrefix(addr common.Address, sender bool) []byte {
+	prefix := traceIndexRecipPrefix
+	if sender {
+		prefix = traceIndexSenderPrefix
+	}
+	return append(append([]byte{}, prefix...), addr.Bytes()...)
+}
+
+// transferIndexBlockKey = transferIndexBlockPrefix + num (uint64 big endian) + hash
+func transferIndexBlockKey(number uint64, hash common.Hash) []byte {
+	return append(append(append([]byte{}, transferIndexBlockPrefix...), encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// transferIndexAddressKey = transferIndexAddressPrefix + address + num (uint64 big endian) + hash
+func transferIndexAddressKey(addr common.Address, number uint64, hash common.Hash) []byte {
+	return append(append(append(append([]byte{}, transferIndexAddressPrefix...), addr.Bytes()...), encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// stateIndexAccountKey = stateIndexAccountPrefix + account hash + id (uint64 big endian)
+func stateIndexAccountKey(accountHash common.Hash, id uint64) []byte {
+	return append(append(append([]byte{}, stateIndexAccountPrefix...), accountHash.Bytes()...), encodeBlockNumber(id)...)
+}
+
+// stateIndexStorageKey = stateIndexStoragePrefix + account hash + storage hash + id (uint64 big endian)
+func stateIndexStorageKey(accountHash common.Hash, storageHash common.Hash, id uint64) []byte {
+	return append(append(append(append([]byte{}, stateIndexStoragePrefix...), accountHash.Bytes()...), storageHash.Bytes()...), encodeBlockNumber(id)...)
+}
+
 // filterMapRowKey = filterMapRowPrefix + mapRowIndex (uint64 big endian)
 func filterMapRowKey(mapRowIndex uint64, base bool) []byte {
 	extLen := 8
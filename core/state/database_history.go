@@ -0,0 +1,159 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/trie/utils"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// errHistoricTrie is returned when accessing the tries of a historic state.
+var errHistoricTrie = errors.New("tries of historic states are not available")
+
+// HistoricDB is a state database for the historic states of the path-based
+// trie database, reconstructed from the state histories.
+//
+// The tries of historic states are not available: the states can be read and
+// mutated in memory, e.g. to re-execute blocks on top of them, but their roots
+// can neither be computed nor committed.
+type HistoricDB struct {
+	disk          ethdb.KeyValueStore
+	triedb        *triedb.Database
+	codeCache     *lru.SizeConstrainedCache[common.Hash, []byte]
+	codeSizeCache *lru.Cache[common.Hash, int]
+	pointCache    *utils.PointCache
+}
+
+// NewHistoricDatabase creates a state database for historic states.
+func NewHistoricDatabase(disk ethdb.KeyValueStore, triedb *triedb.Database) *HistoricDB {
+	return &HistoricDB{
+		disk:          disk,
+		triedb:        triedb,
+		codeCache:     lru.NewSizeConstrainedCache[common.Hash, []byte](codeCacheSize),
+		codeSizeCache: lru.NewCache[common.Hash, int](codeSizeCacheSize),
+		pointCache:    utils.NewPointCache(pointCacheSize),
+	}
+}
+
+// Reader implements Database, returning a reader of the historic state. The
+// states not mutated since the historic state are read from the tries of the
+// base state of the reconstruction.
+func (db *HistoricDB) Reader(stateRoot common.Hash) (Reader, error) {
+	reader, base, err := db.triedb.HistoricReader(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := newTrieReader(base, db.triedb, db.pointCache)
+	if err != nil {
+		return nil, err
+	}
+	combined, err := newMultiStateReader(newFlatReader(reader), tr)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(newCachingCodeReader(db.disk, db.codeCache, db.codeSizeCache), combined), nil
+}
+
+// OpenTrie implements Database, returning a placeholder for the unavailable
+// account trie.
+func (db *HistoricDB) OpenTrie(root common.Hash) (Trie, error) {
+	return &historicTrie{root: root}, nil
+}
+
+// OpenStorageTrie implements Database, returning a placeholder for the
+// unavailable storage trie.
+func (db *HistoricDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
+	return &historicTrie{root: root}, nil
+}
+
+// PointCache implements Database.
+func (db *HistoricDB) PointCache() *utils.PointCache {
+	return db.pointCache
+}
+
+// TrieDB implements Database.
+func (db *HistoricDB) TrieDB() *triedb.Database {
+	return db.triedb
+}
+
+// Snapshot implements Database, historic states have no snapshot.
+func (db *HistoricDB) Snapshot() *snapshot.Tree {
+	return nil
+}
+
+// historicTrie is a placeholder for the trie of a historic state, failing all
+// the operations but returning its root.
+type historicTrie struct {
+	root common.Hash
+}
+
+func (t *historicTrie) GetKey([]byte) []byte { return nil }
+
+func (t *historicTrie) GetAccount(address common.Address) (*types.StateAccount, error) {
+	return nil, errHistoricTrie
+}
+
+func (t *historicTrie) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	return nil, errHistoricTrie
+}
+
+func (t *historicTrie) UpdateAccount(address common.Address, account *types.StateAccount, codeLen int) error {
+	return errHistoricTrie
+}
+
+func (t *historicTrie) UpdateStorage(addr common.Address, key, value []byte) error {
+	return errHistoricTrie
+}
+
+func (t *historicTrie) DeleteAccount(address common.Address) error {
+	return errHistoricTrie
+}
+
+func (t *historicTrie) DeleteStorage(addr common.Address, key []byte) error {
+	return errHistoricTrie
+}
+
+func (t *historicTrie) UpdateContractCode(address common.Address, codeHash common.Hash, code []byte) error {
+	return errHistoricTrie
+}
+
+func (t *historicTrie) Hash() common.Hash { return t.root }
+
+func (t *historicTrie) Commit(collectLeaf bool) (common.Hash, *trienode.NodeSet) {
+	return t.root, nil
+}
+
+func (t *historicTrie) Witness() map[string]struct{} { return nil }
+
+func (t *historicTrie) NodeIterator(startKey []byte) (trie.NodeIterator, error) {
+	return nil, errHistoricTrie
+}
+
+func (t *historicTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
+	return errHistoricTrie
+}
+
+func (t *historicTrie) IsVerkle() bool { return false }
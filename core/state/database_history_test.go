@@ -0,0 +1,86 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+func TestHistoricDatabase(t *testing.T) {
+	disk, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		tdb   = triedb.NewDatabase(disk, &triedb.Config{PathDB: pathdb.Defaults})
+		db    = NewDatabase(tdb, nil)
+		a1    = common.HexToAddress("0x01")
+		a2    = common.HexToAddress("0x02")
+		slot  = common.HexToHash("0x01")
+		roots []common.Hash
+	)
+	defer tdb.Close()
+
+	// Mutate a subset of the states in every block
+	root := types.EmptyRootHash
+	for i := uint64(1); i <= 4; i++ {
+		state, _ := New(root, db)
+		state.SetBalance(a1, uint256.NewInt(i), tracing.BalanceChangeUnspecified)
+		if i <= 2 {
+			state.SetState(a2, slot, common.BigToHash(uint256.NewInt(i).ToBig()))
+			state.SetBalance(a2, uint256.NewInt(100), tracing.BalanceChangeUnspecified)
+		}
+		root, err = state.Commit(i, true, false)
+		if err != nil {
+			t.Fatalf("Failed to commit block %d: %v", i, err)
+		}
+		roots = append(roots, root)
+	}
+	// Flush all the states to disk, leaving only histories for the old ones
+	if err := tdb.Commit(root, false); err != nil {
+		t.Fatalf("Failed to flush states: %v", err)
+	}
+	if _, err := New(roots[0], db); err == nil {
+		t.Fatal("Historic state unexpectedly available")
+	}
+	hdb := NewHistoricDatabase(disk, tdb)
+	for i, root := range roots {
+		state, err := New(root, hdb)
+		if err != nil {
+			t.Fatalf("Failed to open historic state %d: %v", i+1, err)
+		}
+		if have := state.GetBalance(a1).Uint64(); have != uint64(i+1) {
+			t.Errorf("Unexpected balance in state %d: have %d, want %d", i+1, have, i+1)
+		}
+		// The account last mutated in block 2 is read from the current state
+		if have := state.GetBalance(a2).Uint64(); have != 100 {
+			t.Errorf("Unexpected balance in state %d: have %d, want 100", i+1, have)
+		}
+		want := common.BigToHash(uint256.NewInt(uint64(min(i+1, 2))).ToBig())
+		if have := state.GetState(a2, slot); have != want {
+			t.Errorf("Unexpected slot in state %d: have %x, want %x", i+1, have, want)
+		}
+	}
+}
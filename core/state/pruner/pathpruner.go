@@ -0,0 +1,438 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+)
+
+// ErrPruningInterrupted is returned if the path-based state pruning is stopped
+// before finishing. The progress is persisted and the pruning resumes from it
+// when restarted.
+var ErrPruningInterrupted = errors.New("state pruning interrupted")
+
+const (
+	pathPhaseCode  = iota // Migrating the live contract codes of legacy scheme
+	pathPhaseSweep        // Sweeping the stale entries in the database
+)
+
+// pathProgress is the persisted progress of the path-based state pruning.
+type pathProgress struct {
+	Root   common.Hash // Persistent state root being pruned against
+	Phase  uint8       // Current pruning phase
+	Marker []byte      // Last processed account hash or database key in the phase
+}
+
+// PathConfig includes all the configurations for pruning path-based state.
+type PathConfig struct {
+	// Target is the key-value store to copy the live state and all the other
+	// data of the key-value store into. The source database is pruned in place
+	// if it's not specified.
+	Target ethdb.KeyValueStore
+}
+
+// PathPruner is an offline tool to compact the state of the path-based scheme.
+// Although the path-based scheme overwrites the stale trie nodes in place, the
+// database may still accumulate garbage over time, e.g. the nodes of deleted
+// storage tries orphaned by crashes, the leftovers of schema migrations and a
+// long tail of state histories. The workflow of pruner is:
+//
+//   - flatten the in-memory state layers into the persistent state
+//   - drop all the state histories along with their index
+//   - migrate the live contract codes stored in the legacy scheme
+//   - iterate the database, deleting all trie nodes not reachable from the
+//     persistent state root and all the entries of the legacy hash scheme
+//
+// If a target database is configured, the database is copied into it instead
+// of being pruned in place, skipping all the stale entries.
+//
+// The progress is persisted periodically, the pruning can be interrupted at
+// any time and resumes from the last checkpoint when restarted.
+type PathPruner struct {
+	config PathConfig
+	db     ethdb.Database
+	triedb *triedb.Database
+}
+
+// NewPathPruner creates the path-based state pruner instance.
+func NewPathPruner(db ethdb.Database, config PathConfig) (*PathPruner, error) {
+	if scheme := rawdb.ReadStateScheme(db); scheme != rawdb.PathScheme {
+		return nil, fmt.Errorf("unexpected state scheme: %q", scheme)
+	}
+	if rawdb.ReadSnapSyncStatusFlag(db) == rawdb.StateSyncRunning {
+		return nil, errors.New("state sync is not finished")
+	}
+	return &PathPruner{
+		config: config,
+		db:     db,
+		triedb: triedb.NewDatabase(db, &triedb.Config{PathDB: pathdb.Defaults}),
+	}, nil
+}
+
+// writer returns the key-value store receiving the live entries and the
+// pruning progress.
+func (p *PathPruner) writer() ethdb.KeyValueStore {
+	if p.config.Target != nil {
+		return p.config.Target
+	}
+	return p.db
+}
+
+// diskRoot returns the root of the persistent state.
+func (p *PathPruner) diskRoot() common.Hash {
+	blob := rawdb.ReadAccountTrieNode(p.db, nil)
+	if len(blob) == 0 {
+		return types.EmptyRootHash
+	}
+	return crypto.Keccak256Hash(blob)
+}
+
+// Prune compacts the path-based state, keeping only the persistent state
+// after flattening the state of the head block into it. The pruning stops at
+// the next checkpoint and returns ErrPruningInterrupted once the interrupt
+// channel is closed.
+func (p *PathPruner) Prune(interrupt <-chan struct{}) error {
+	defer p.triedb.Close()
+
+	start := time.Now()
+	root := p.diskRoot()
+	if head := rawdb.ReadHeadBlock(p.db); head != nil && head.Root() != root {
+		if err := p.triedb.Commit(head.Root(), false); err != nil {
+			log.Warn("Head state is not available, pruning the persistent state", "head", head.Root(), "root", root, "err", err)
+		} else {
+			root = head.Root()
+			log.Info("Flattened head state into disk", "number", head.NumberU64(), "root", root)
+		}
+	}
+	pruned, err := p.triedb.DropHistories()
+	if err != nil {
+		return err
+	}
+	if pruned != 0 {
+		log.Info("Dropped state histories", "number", pruned)
+	}
+	// Persist the flattened layer tree, any stale journal is left unmatched
+	// with the persistent state otherwise. The trie database is read only
+	// afterwards.
+	if err := p.triedb.Journal(root); err != nil {
+		return err
+	}
+	// Resume the pruning from the last checkpoint if it was made against the
+	// same persistent state, otherwise start over.
+	progress := &pathProgress{Root: root}
+	if blob := rawdb.ReadPathPruneProgress(p.writer()); len(blob) > 0 {
+		var stored pathProgress
+		if err := rlp.DecodeBytes(blob, &stored); err != nil {
+			log.Warn("Failed to decode pruning progress", "err", err)
+		} else if stored.Root != root {
+			log.Warn("Discarding pruning progress of stale state", "have", stored.Root, "want", root)
+		} else {
+			progress = &stored
+			log.Info("Resuming state pruning", "phase", stored.Phase, "marker", fmt.Sprintf("%x", stored.Marker))
+		}
+	}
+	if progress.Phase == pathPhaseCode {
+		if err := p.migrateCodes(progress, interrupt); err != nil {
+			return err
+		}
+		progress.Phase, progress.Marker = pathPhaseSweep, nil
+		if err := p.saveProgress(p.writer(), progress); err != nil {
+			return err
+		}
+	}
+	count, size, err := p.sweep(progress, interrupt)
+	if err != nil {
+		return err
+	}
+	rawdb.DeletePathPruneProgress(p.writer())
+
+	if p.config.Target != nil {
+		log.Info("State copying successful", "skipped", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+		log.Info("The ancient store is not copied, move it along with the target database")
+		return nil
+	}
+	// Start compactions, will remove the deleted data from the disk immediately.
+	// Note for small pruning, the compaction is skipped.
+	if count >= rangeCompactionThreshold {
+		if err := compactDatabase(p.db); err != nil {
+			return err
+		}
+	}
+	log.Info("State pruning successful", "pruned", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// saveProgress writes the pruning progress into the given batch or database.
+func (p *PathPruner) saveProgress(w ethdb.KeyValueWriter, progress *pathProgress) error {
+	blob, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		return err
+	}
+	rawdb.WritePathPruneProgress(w, blob)
+	return nil
+}
+
+// flush writes out the batch along with the pruning progress.
+func (p *PathPruner) flush(batch ethdb.Batch, progress *pathProgress) error {
+	if err := p.saveProgress(batch, progress); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	batch.Reset()
+	return nil
+}
+
+// interrupted reports whether the pruning is requested to stop.
+func interrupted(interrupt <-chan struct{}) bool {
+	select {
+	case <-interrupt:
+		return true
+	default:
+		return false
+	}
+}
+
+// migrateCodes stores the live contract codes of the legacy scheme with the
+// prefixed scheme, as all the legacy entries are deleted by the sweeping.
+func (p *PathPruner) migrateCodes(progress *pathProgress, interrupt <-chan struct{}) error {
+	tr, err := trie.NewStateTrie(trie.StateTrieID(progress.Root), p.triedb)
+	if err != nil {
+		return err
+	}
+	nodeIt, err := tr.NodeIterator(progress.Marker)
+	if err != nil {
+		return err
+	}
+	var (
+		accounts, migrated int
+		start              = time.Now()
+		logged             = time.Now()
+		batch              = p.writer().NewBatch()
+		iter               = trie.NewIterator(nodeIt)
+	)
+	for iter.Next() {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(iter.Value, &account); err != nil {
+			return err
+		}
+		accounts++
+
+		hash := common.BytesToHash(account.CodeHash)
+		if hash != types.EmptyCodeHash && !rawdb.HasCodeWithPrefix(p.db, hash) {
+			if code, _ := p.db.Get(hash.Bytes()); len(code) > 0 {
+				rawdb.WriteCode(batch, hash, code)
+				migrated++
+			}
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize || interrupted(interrupt) {
+			progress.Marker = common.CopyBytes(iter.Key)
+			if err := p.flush(batch, progress); err != nil {
+				return err
+			}
+			if interrupted(interrupt) {
+				return ErrPruningInterrupted
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating legacy contract codes", "accounts", accounts, "migrated", migrated, "at", common.BytesToHash(iter.Key), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if iter.Err != nil {
+		return iter.Err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Migrated legacy contract codes", "accounts", accounts, "migrated", migrated, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// sweep iterates the database from the progress marker, deleting the stale
+// entries, or copying the live ones into the target database. It returns the
+// number and size of the stale entries.
+func (p *PathPruner) sweep(progress *pathProgress, interrupt <-chan struct{}) (int, common.StorageSize, error) {
+	accountTrie, err := trie.NewStateTrie(trie.StateTrieID(progress.Root), p.triedb)
+	if err != nil {
+		return 0, 0, err
+	}
+	var (
+		count, keys int
+		size        common.StorageSize
+		start       = time.Now()
+		logged      = time.Now()
+		batch       = p.writer().NewBatch()
+		iter        = p.db.NewIterator(nil, progress.Marker)
+
+		accounts *liveNodes   // Live nodes of the account trie, opened lazily
+		storages *liveNodes   // Live nodes of the storage trie being swept
+		owner    *common.Hash // Owner of the storage trie being swept
+	)
+	defer func() { iter.Release() }()
+
+	for iter.Next() {
+		var (
+			key   = iter.Key()
+			stale bool
+		)
+		keys++
+
+		if ok, path := rawdb.ResolveAccountTrieNodeKey(key); ok {
+			if accounts == nil {
+				if accounts, err = newLiveNodes(accountTrie, path); err != nil {
+					return 0, 0, err
+				}
+			}
+			live, err := accounts.contains(path)
+			if err != nil {
+				return 0, 0, err
+			}
+			stale = !live
+		} else if ok, hash, path := rawdb.ResolveStorageTrieNode(key); ok {
+			if owner == nil || *owner != hash {
+				owner, storages = &hash, nil
+
+				account, err := accountTrie.GetAccountByHash(hash)
+				if err != nil {
+					return 0, 0, err
+				}
+				if account != nil && account.Root != types.EmptyRootHash {
+					tr, err := trie.NewStateTrie(trie.StorageTrieID(progress.Root, hash, account.Root), p.triedb)
+					if err != nil {
+						return 0, 0, err
+					}
+					if storages, err = newLiveNodes(tr, path); err != nil {
+						return 0, 0, err
+					}
+				}
+			}
+			// All the nodes of deleted or emptied storage tries are stale.
+			if storages != nil {
+				live, err := storages.contains(path)
+				if err != nil {
+					return 0, 0, err
+				}
+				stale = !live
+			} else {
+				stale = true
+			}
+		} else if len(key) == common.HashLength {
+			// Trie nodes and contract codes of the legacy hash scheme,
+			// the live codes are already migrated.
+			stale = true
+		}
+		if stale {
+			count++
+			size += common.StorageSize(len(key) + len(iter.Value()))
+			if p.config.Target == nil {
+				batch.Delete(key)
+			}
+		} else if p.config.Target != nil {
+			batch.Put(key, iter.Value())
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize || interrupted(interrupt) {
+			progress.Marker = common.CopyBytes(key)
+			if err := p.flush(batch, progress); err != nil {
+				return 0, 0, err
+			}
+			if interrupted(interrupt) {
+				return 0, 0, ErrPruningInterrupted
+			}
+			// Recreate the iterator after every batch commit in order
+			// to allow the underlying compactor to delete the entries.
+			iter.Release()
+			iter = p.db.NewIterator(nil, progress.Marker)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Sweeping state data", "keys", keys, "stale", count, "size", size, "at", fmt.Sprintf("%x", key[:min(len(key), 8)]), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return 0, 0, err
+	}
+	if err := batch.Write(); err != nil {
+		return 0, 0, err
+	}
+	log.Info("Swept state data", "keys", keys, "stale", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return count, size, nil
+}
+
+// liveNodes walks the nodes reachable from a trie root in path order, which is
+// also the order of the trie node keys in the database.
+type liveNodes struct {
+	iter trie.NodeIterator
+	done bool
+}
+
+// newLiveNodes creates an iterator of the live nodes of a trie, starting from
+// the nodes not preceding the given path.
+func newLiveNodes(tr *trie.StateTrie, path []byte) (*liveNodes, error) {
+	// The iterator is seeked with a key, use the longest key whose nibbles are
+	// a prefix of the path.
+	start := make([]byte, 0, len(path)/2)
+	for i := 0; i+1 < len(path); i += 2 {
+		start = append(start, path[i]<<4|path[i+1])
+	}
+	iter, err := tr.NodeIterator(start)
+	if err != nil {
+		return nil, err
+	}
+	it := &liveNodes{iter: iter}
+	if !iter.Next(true) {
+		it.done = true
+		return it, iter.Error()
+	}
+	return it, nil
+}
+
+// contains reports whether the node with the given path is reachable from the
+// trie root. The paths must be queried in ascending order.
+func (it *liveNodes) contains(path []byte) (bool, error) {
+	for !it.done {
+		// Embedded nodes and values are not stored on their own.
+		if it.iter.Hash() != (common.Hash{}) {
+			switch bytes.Compare(it.iter.Path(), path) {
+			case 0:
+				return true, nil
+			case 1:
+				return false, nil
+			}
+		}
+		if !it.iter.Next(true) {
+			it.done = true
+			return false, it.iter.Error()
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+var (
+	legacyCode      = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	legacyCodeHash  = crypto.Keccak256Hash(legacyCode)
+	orphanPath      = []byte{0xf, 0xf, 0xf, 0xf, 0xf, 0xf, 0xf}
+	orphanOwner     = common.Hash{0xde, 0xad}
+	legacyNode      = []byte{0xc2, 0x80, 0x80}
+	legacyNodeHash  = crypto.Keccak256Hash(legacyNode)
+	orphanNodeBlob  = []byte{0xc3, 0x82, 0x01, 0x02}
+	prunedCodeOwner = common.Address{0xcc}
+)
+
+// makePathState creates a path-based database with a few blocks of state and
+// garbage left by crashes and schema migrations, returning the head root.
+func makePathState(t *testing.T) (ethdb.Database, common.Hash) {
+	db, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tdb := triedb.NewDatabase(db, &triedb.Config{PathDB: pathdb.Defaults})
+	sdb := state.NewDatabase(tdb, nil)
+
+	root := types.EmptyRootHash
+	for block := uint64(1); block <= 4; block++ {
+		statedb, err := state.New(root, sdb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 32; i++ {
+			addr := common.Address{byte(i)}
+			statedb.AddBalance(addr, uint256.NewInt(block), tracing.BalanceChangeUnspecified)
+			if i%4 == 0 {
+				statedb.SetState(addr, common.Hash{byte(block)}, common.Hash{byte(i + 1)})
+				statedb.SetState(addr, common.Hash{byte(block), 0x1}, common.Hash{byte(i + 1)})
+			}
+		}
+		if block == 1 {
+			statedb.SetCode(prunedCodeOwner, legacyCode)
+		}
+		parent := root
+		if root, err = statedb.Commit(block, false, false); err != nil {
+			t.Fatal(err)
+		}
+		if block < 4 {
+			if err := tdb.Commit(root, false); err != nil {
+				t.Fatal(err)
+			}
+		}
+		header := &types.Header{Number: new(big.Int).SetUint64(block), Root: root, ParentHash: parent}
+		blk := types.NewBlockWithHeader(header)
+		rawdb.WriteBlock(db, blk)
+		rawdb.WriteCanonicalHash(db, blk.Hash(), block)
+		rawdb.WriteHeadBlockHash(db, blk.Hash())
+	}
+	if err := tdb.Journal(root); err != nil {
+		t.Fatal(err)
+	}
+	tdb.Close()
+
+	// Move the contract code into the legacy scheme.
+	rawdb.DeleteCode(db, legacyCodeHash)
+	db.Put(legacyCodeHash.Bytes(), legacyCode)
+
+	// Inject the garbage: a legacy trie node, orphaned account trie node and
+	// the storage nodes of a non-existent and a live account.
+	db.Put(legacyNodeHash.Bytes(), legacyNode)
+	rawdb.WriteAccountTrieNode(db, orphanPath, orphanNodeBlob)
+	rawdb.WriteStorageTrieNode(db, orphanOwner, nil, orphanNodeBlob)
+	rawdb.WriteStorageTrieNode(db, crypto.Keccak256Hash(common.Address{0x0}.Bytes()), orphanPath, orphanNodeBlob)
+	return db, root
+}
+
+// checkPrunedState verifies the garbage is removed and the entire state is
+// still available in the given database.
+func checkPrunedState(t *testing.T, db ethdb.Database, root common.Hash) {
+	t.Helper()
+
+	if has, _ := db.Has(legacyNodeHash.Bytes()); has {
+		t.Error("legacy trie node is not pruned")
+	}
+	if has, _ := db.Has(legacyCodeHash.Bytes()); has {
+		t.Error("legacy code is not pruned")
+	}
+	if rawdb.HasAccountTrieNode(db, orphanPath) {
+		t.Error("orphaned account trie node is not pruned")
+	}
+	if len(rawdb.ReadStorageTrieNode(db, orphanOwner, nil)) != 0 {
+		t.Error("storage trie node of missing account is not pruned")
+	}
+	if len(rawdb.ReadStorageTrieNode(db, crypto.Keccak256Hash(common.Address{0x0}.Bytes()), orphanPath)) != 0 {
+		t.Error("orphaned storage trie node is not pruned")
+	}
+	if code := rawdb.ReadCodeWithPrefix(db, legacyCodeHash); len(code) == 0 {
+		t.Error("live legacy code is not migrated")
+	}
+	if len(rawdb.ReadPathPruneProgress(db)) != 0 {
+		t.Error("pruning progress is not deleted")
+	}
+	// Ensure the state is complete by iterating all the tries.
+	tdb := triedb.NewDatabase(db, &triedb.Config{PathDB: pathdb.ReadOnly})
+	defer tdb.Close()
+
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), tdb)
+	if err != nil {
+		t.Fatalf("failed to open state trie: %v", err)
+	}
+	var accounts, slots int
+	iter := trie.NewIterator(tr.MustNodeIterator(nil))
+	for iter.Next() {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(iter.Value, &account); err != nil {
+			t.Fatal(err)
+		}
+		accounts++
+		if account.Root == types.EmptyRootHash {
+			continue
+		}
+		owner := common.BytesToHash(iter.Key)
+		str, err := trie.NewStateTrie(trie.StorageTrieID(root, owner, account.Root), tdb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		siter := trie.NewIterator(str.MustNodeIterator(nil))
+		for siter.Next() {
+			slots++
+		}
+		if siter.Err != nil {
+			t.Fatalf("failed to iterate storage of %x: %v", owner, siter.Err)
+		}
+	}
+	if iter.Err != nil {
+		t.Fatalf("failed to iterate accounts: %v", iter.Err)
+	}
+	if accounts != 33 || slots != 64 {
+		t.Fatalf("state mismatch: have %d accounts and %d slots, want 33 and 64", accounts, slots)
+	}
+}
+
+func TestPathPrunerInPlace(t *testing.T) {
+	db, root := makePathState(t)
+	defer db.Close()
+
+	p, err := NewPathPruner(db, PathConfig{})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := p.Prune(nil); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	checkPrunedState(t, db, root)
+
+	// All the state histories must be dropped.
+	freezer, err := rawdb.NewStateFreezer(mustAncientDir(t, db), false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freezer.Close()
+	tail, _ := freezer.Tail()
+	head, _ := freezer.Ancients()
+	if head != 4 || tail != head {
+		t.Fatalf("state histories are not dropped, tail: %d, head: %d", tail, head)
+	}
+}
+
+func TestPathPrunerResume(t *testing.T) {
+	db, root := makePathState(t)
+	defer db.Close()
+
+	// Interrupt the pruning immediately, the progress must be retained.
+	stop := make(chan struct{})
+	close(stop)
+	for i := 0; i < 2; i++ {
+		p, err := NewPathPruner(db, PathConfig{})
+		if err != nil {
+			t.Fatalf("failed to create pruner: %v", err)
+		}
+		if err := p.Prune(stop); !errors.Is(err, ErrPruningInterrupted) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rawdb.ReadPathPruneProgress(db)) == 0 {
+			t.Fatal("pruning progress is not persisted")
+		}
+	}
+	p, err := NewPathPruner(db, PathConfig{})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := p.Prune(nil); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	checkPrunedState(t, db, root)
+}
+
+func TestPathPrunerCopy(t *testing.T) {
+	db, root := makePathState(t)
+	defer db.Close()
+
+	target := rawdb.NewDatabase(memorydb.New())
+	p, err := NewPathPruner(db, PathConfig{Target: target})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := p.Prune(nil); err != nil {
+		t.Fatalf("failed to copy state: %v", err)
+	}
+	checkPrunedState(t, target, root)
+
+	// The non-state data must be copied as well, while the source is left
+	// with the garbage.
+	if rawdb.ReadHeadBlockHash(target) != rawdb.ReadHeadBlockHash(db) {
+		t.Fatal("head block is not copied")
+	}
+	if has, _ := db.Has(legacyNodeHash.Bytes()); !has {
+		t.Fatal("source database is pruned")
+	}
+}
+
+func mustAncientDir(t *testing.T, db ethdb.Database) string {
+	dir, err := db.AncientDatadir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
@@ -0,0 +1,497 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+// The state export file is a sequence of frames following a short preamble of
+// the magic string and the format version. Every frame is laid out as
+//
+//	kind (1 byte) | length (4 bytes) | crc32c of the payload (4 bytes) | payload
+//
+// with the payload being the snappy compressed RLP encoding of the frame
+// content. The first frame is the header, identifying the exported state, and
+// the last one is the footer, holding the totals to detect truncated files.
+// The frames in between hold the accounts in hash order along with their
+// storage slots and contract codes.
+const (
+	exportMagic   = "gethsnap"
+	exportVersion = 1
+
+	exportFrameHeader = 0
+	exportFrameData   = 1
+	exportFrameFooter = 2
+
+	// exportFrameLimit is the maximum length of a frame payload accepted by
+	// the importer, protecting against corrupted lengths.
+	exportFrameLimit = 64 * 1024 * 1024
+)
+
+var (
+	// exportChunkSize is the approximate amount of uncompressed state data
+	// packed into a single data frame.
+	exportChunkSize = 4 * 1024 * 1024
+
+	exportCRCTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// ExportHeader identifies the state contained in an export file.
+type ExportHeader struct {
+	Number uint64      // Number of the block the state belongs to
+	Hash   common.Hash // Hash of the block the state belongs to
+	Root   common.Hash // Root of the state
+}
+
+// exportFooter holds the totals of the exported state.
+type exportFooter struct {
+	Accounts uint64
+	Slots    uint64
+	Codes    uint64
+}
+
+// exportEntry is an account along with its storage slots in a data frame. The
+// storage of an account might span several frames, the entries continuing the
+// storage of the previous account have no account data.
+type exportEntry struct {
+	Hash    common.Hash
+	Account []byte // Account in slim RLP format, empty for continuations
+	Code    []byte // Contract code, only for the first account referencing it
+	Storage []exportSlot
+}
+
+// exportSlot is a storage slot in a data frame.
+type exportSlot struct {
+	Hash  common.Hash
+	Value []byte // Slot value in RLP format
+}
+
+// exportWriter packs the exported state into frames.
+type exportWriter struct {
+	w       *bufio.Writer
+	entries []*exportEntry
+	size    int
+}
+
+// writeFrame compresses and writes out a frame with the given content.
+func (w *exportWriter) writeFrame(kind byte, content interface{}) error {
+	blob, err := rlp.EncodeToBytes(content)
+	if err != nil {
+		return err
+	}
+	payload := snappy.Encode(nil, blob)
+
+	var prefix [9]byte
+	prefix[0] = kind
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(payload)))
+	binary.BigEndian.PutUint32(prefix[5:], crc32.Checksum(payload, exportCRCTable))
+	if _, err := w.w.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err = w.w.Write(payload)
+	return err
+}
+
+// add appends an entry to the pending data frame, flushing it if it's full.
+func (w *exportWriter) add(entry *exportEntry, size int) error {
+	w.entries = append(w.entries, entry)
+	w.size += size
+	if w.size < exportChunkSize {
+		return nil
+	}
+	return w.flush()
+}
+
+// flush writes out the pending data frame.
+func (w *exportWriter) flush() error {
+	if len(w.entries) == 0 {
+		return nil
+	}
+	if err := w.writeFrame(exportFrameData, w.entries); err != nil {
+		return err
+	}
+	w.entries, w.size = nil, 0
+	return nil
+}
+
+// Export writes the state iterated by the given account iterator into an export
+// file. The storage slots of the accounts are iterated by the iterators returned
+// by the storage callback and the contract codes are retrieved through the code
+// callback.
+func Export(out io.Writer, header *ExportHeader, accounts AccountIterator, storage func(account common.Hash) (StorageIterator, error), code func(hash common.Hash) []byte) error {
+	var (
+		w      = &exportWriter{w: bufio.NewWriter(out)}
+		footer exportFooter
+		codes  = make(map[common.Hash]struct{})
+
+		start  = time.Now()
+		logged = time.Now()
+	)
+	if _, err := w.w.WriteString(exportMagic); err != nil {
+		return err
+	}
+	if err := w.w.WriteByte(exportVersion); err != nil {
+		return err
+	}
+	if err := w.writeFrame(exportFrameHeader, header); err != nil {
+		return err
+	}
+	for accounts.Next() {
+		account, err := types.FullAccount(accounts.Account())
+		if err != nil {
+			return err
+		}
+		entry := &exportEntry{
+			Hash:    accounts.Hash(),
+			Account: common.CopyBytes(accounts.Account()),
+		}
+		size := common.HashLength + len(entry.Account)
+
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			if _, ok := codes[codeHash]; !ok {
+				entry.Code = code(codeHash)
+				if len(entry.Code) == 0 {
+					return fmt.Errorf("missing code %x of account %x", codeHash, entry.Hash)
+				}
+				codes[codeHash] = struct{}{}
+				size += len(entry.Code)
+				footer.Codes++
+			}
+		}
+		if account.Root != types.EmptyRootHash {
+			it, err := storage(entry.Hash)
+			if err != nil {
+				return err
+			}
+			for it.Next() {
+				slot := exportSlot{Hash: it.Hash(), Value: common.CopyBytes(it.Slot())}
+				entry.Storage = append(entry.Storage, slot)
+				size += common.HashLength + len(slot.Value)
+				footer.Slots++
+
+				// Split the storage of large contracts over several frames
+				if size >= exportChunkSize {
+					if err := w.add(entry, size); err != nil {
+						it.Release()
+						return err
+					}
+					entry, size = &exportEntry{Hash: entry.Hash}, common.HashLength
+				}
+			}
+			err = it.Error()
+			it.Release()
+			if err != nil {
+				return err
+			}
+		}
+		if err := w.add(entry, size); err != nil {
+			return err
+		}
+		footer.Accounts++
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting state", "at", entry.Hash, "accounts", footer.Accounts, "slots", footer.Slots, "codes", footer.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accounts.Error(); err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.writeFrame(exportFrameFooter, &footer); err != nil {
+		return err
+	}
+	log.Info("Exported state", "root", header.Root, "accounts", footer.Accounts, "slots", footer.Slots, "codes", footer.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return w.w.Flush()
+}
+
+// readFrame reads the next frame, returning its kind and the verified and
+// decompressed content.
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var prefix [9]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, nil, errors.New("truncated export file, footer missing")
+		}
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(prefix[1:])
+	if length > exportFrameLimit {
+		return 0, nil, fmt.Errorf("oversized frame: %d bytes", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return prefix[0], payload, verifyFrame(payload, binary.BigEndian.Uint32(prefix[5:]))
+}
+
+// verifyFrame checks the integrity of a frame payload.
+func verifyFrame(payload []byte, checksum uint32) error {
+	if crc32.Checksum(payload, exportCRCTable) != checksum {
+		return errors.New("frame checksum mismatch")
+	}
+	return nil
+}
+
+// decodeFrame decompresses and decodes the content of a frame.
+func decodeFrame(payload []byte, content interface{}) error {
+	blob, err := snappy.Decode(nil, payload)
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(blob, content)
+}
+
+// ReadExportHeader reads the header of an export file.
+func ReadExportHeader(r io.Reader) (*ExportHeader, error) {
+	preamble := make([]byte, len(exportMagic)+1)
+	if _, err := io.ReadFull(r, preamble); err != nil {
+		return nil, err
+	}
+	if string(preamble[:len(exportMagic)]) != exportMagic {
+		return nil, errors.New("not a state export file")
+	}
+	if version := preamble[len(exportMagic)]; version != exportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", version)
+	}
+	kind, payload, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	if kind != exportFrameHeader {
+		return nil, fmt.Errorf("unexpected frame %d, want header", kind)
+	}
+	header := new(ExportHeader)
+	if err := decodeFrame(payload, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// Import loads the state from an export file into the database as the snapshot
+// disk layer, then regenerates the tries of the given scheme from it and checks
+// the resulting state root. The frames are decoded and the storage tries are
+// regenerated concurrently.
+//
+// The existing snapshot is wiped, but the existing trie nodes are left intact,
+// so the import is meant to be done on a freshly initialized database.
+func Import(db ethdb.KeyValueStore, scheme string, r io.Reader) (*ExportHeader, error) {
+	br := bufio.NewReader(r)
+	header, err := ReadExportHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Importing state", "number", header.Number, "hash", header.Hash, "root", header.Root)
+
+	if err := wipeSnapshot(db); err != nil {
+		return nil, err
+	}
+	footer, err := importFrames(db, br)
+	if err != nil {
+		return nil, err
+	}
+	// All the flat states are loaded, regenerate the tries
+	start := time.Now()
+	log.Info("Regenerating state tries", "accounts", footer.Accounts, "slots", footer.Slots)
+
+	base := &diskLayer{diskdb: db}
+	accIt := base.AccountIterator(common.Hash{})
+	defer accIt.Release()
+
+	root, err := generateTrieRoot(db, scheme, accIt, common.Hash{}, stackTrieGenerate, func(dst ethdb.KeyValueWriter, accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
+		if codeHash != types.EmptyCodeHash && !rawdb.HasCode(db, codeHash) {
+			return common.Hash{}, fmt.Errorf("missing code %x of account %x", codeHash, accountHash)
+		}
+		storageIt := base.StorageIterator(accountHash, common.Hash{})
+		defer storageIt.Release()
+
+		return generateTrieRoot(dst, scheme, storageIt, accountHash, stackTrieGenerate, nil, stat, false)
+	}, newGenerateStats(), true)
+	if err != nil {
+		return nil, err
+	}
+	if root != header.Root {
+		return nil, fmt.Errorf("state root mismatch: have %x, want %x", root, header.Root)
+	}
+	// Mark the loaded flat states as a complete snapshot
+	batch := db.NewBatch()
+	rawdb.WriteSnapshotRoot(batch, root)
+	journalProgress(batch, nil, nil)
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	log.Info("Imported state", "root", root, "elapsed", common.PrettyDuration(time.Since(start)))
+	return header, nil
+}
+
+// importFrames loads the data frames into the database until the footer and
+// checks the totals against it.
+func importFrames(db ethdb.KeyValueStore, r io.Reader) (*exportFooter, error) {
+	var (
+		threads = runtime.NumCPU()
+		tasks   = make(chan []byte, threads)
+		errc    = make(chan error, threads)
+		wg      sync.WaitGroup
+
+		accounts, slots, codes atomic.Uint64
+
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for payload := range tasks {
+				var entries []*exportEntry
+				if err := decodeFrame(payload, &entries); err != nil {
+					errc <- err
+					return
+				}
+				batch := db.NewBatch()
+				for _, entry := range entries {
+					if len(entry.Account) > 0 {
+						rawdb.WriteAccountSnapshot(batch, entry.Hash, entry.Account)
+						accounts.Add(1)
+					}
+					if len(entry.Code) > 0 {
+						rawdb.WriteCode(batch, crypto.Keccak256Hash(entry.Code), entry.Code)
+						codes.Add(1)
+					}
+					for _, slot := range entry.Storage {
+						rawdb.WriteStorageSnapshot(batch, entry.Hash, slot.Hash, slot.Value)
+					}
+					slots.Add(uint64(len(entry.Storage)))
+
+					if batch.ValueSize() > ethdb.IdealBatchSize {
+						if err := batch.Write(); err != nil {
+							errc <- err
+							return
+						}
+						batch.Reset()
+					}
+				}
+				if err := batch.Write(); err != nil {
+					errc <- err
+					return
+				}
+			}
+		}()
+	}
+	// Feed the data frames to the workers until the footer is reached
+	var (
+		footer *exportFooter
+		fail   error
+	)
+	for footer == nil && fail == nil {
+		kind, payload, err := readFrame(r)
+		if err != nil {
+			fail = err
+			break
+		}
+		switch kind {
+		case exportFrameData:
+			select {
+			case tasks <- payload:
+			case fail = <-errc:
+			}
+		case exportFrameFooter:
+			footer = new(exportFooter)
+			fail = decodeFrame(payload, footer)
+		default:
+			fail = fmt.Errorf("unexpected frame %d", kind)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Loading state", "accounts", accounts.Load(), "slots", slots.Load(), "codes", codes.Load(), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	close(tasks)
+	wg.Wait()
+
+	if fail == nil {
+		select {
+		case fail = <-errc:
+		default:
+		}
+	}
+	if fail != nil {
+		return nil, fail
+	}
+	if accounts.Load() != footer.Accounts || slots.Load() != footer.Slots || codes.Load() != footer.Codes {
+		return nil, fmt.Errorf("state totals mismatch: have %d accounts, %d slots, %d codes, want %d, %d, %d",
+			accounts.Load(), slots.Load(), codes.Load(), footer.Accounts, footer.Slots, footer.Codes)
+	}
+	log.Info("Loaded state", "accounts", footer.Accounts, "slots", footer.Slots, "codes", footer.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return footer, nil
+}
+
+// wipeSnapshot deletes the entire snapshot from the database.
+func wipeSnapshot(db ethdb.KeyValueStore) error {
+	batch := db.NewBatch()
+	rawdb.DeleteSnapshotRoot(batch)
+	rawdb.DeleteSnapshotJournal(batch)
+	rawdb.DeleteSnapshotGenerator(batch)
+
+	for _, kind := range []struct {
+		prefix []byte
+		length int
+	}{
+		{rawdb.SnapshotAccountPrefix, len(rawdb.SnapshotAccountPrefix) + common.HashLength},
+		{rawdb.SnapshotStoragePrefix, len(rawdb.SnapshotStoragePrefix) + 2*common.HashLength},
+	} {
+		it := db.NewIterator(kind.prefix, nil)
+		for it.Next() {
+			if len(it.Key()) != kind.length {
+				continue
+			}
+			batch.Delete(it.Key())
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+	}
+	return batch.Write()
+}
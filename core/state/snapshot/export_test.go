@@ -0,0 +1,112 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// Tests that an exported state can be imported into an empty database, and
+// that corrupted or truncated export files are rejected.
+func TestExportImport(t *testing.T) {
+	testExportImport(t, rawdb.HashScheme)
+	testExportImport(t, rawdb.PathScheme)
+}
+
+func testExportImport(t *testing.T, scheme string) {
+	// Split the storage over several frames
+	exportChunkSize = 64
+	defer func() {
+		exportChunkSize = 4 * 1024 * 1024
+	}()
+
+	var (
+		helper   = newHelper(scheme)
+		code     = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+		codeHash = crypto.Keccak256Hash(code)
+	)
+	rawdb.WriteCode(helper.diskdb, codeHash, code)
+
+	stRoot := helper.makeStorageTrie("", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, false)
+	helper.addTrieAccount("acc-1", &types.StateAccount{Balance: uint256.NewInt(1), Root: stRoot, CodeHash: codeHash.Bytes()})
+	helper.addTrieAccount("acc-2", &types.StateAccount{Balance: uint256.NewInt(2), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.addTrieAccount("acc-3", &types.StateAccount{Balance: uint256.NewInt(3), Root: stRoot, CodeHash: codeHash.Bytes()})
+	helper.makeStorageTrie("acc-1", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, true)
+	helper.makeStorageTrie("acc-3", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, true)
+
+	root, snap := helper.CommitAndGenerate()
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Snapshot generation failed")
+	}
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+
+	// Export the state
+	var (
+		buf    bytes.Buffer
+		header = &ExportHeader{Number: 1, Hash: common.Hash{0x1}, Root: root}
+	)
+	accIt := snap.AccountIterator(common.Hash{})
+	defer accIt.Release()
+
+	err := Export(&buf, header, accIt, func(account common.Hash) (StorageIterator, error) {
+		return snap.StorageIterator(account, common.Hash{}), nil
+	}, func(hash common.Hash) []byte {
+		return rawdb.ReadCode(helper.diskdb, hash)
+	})
+	if err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+	// Import it into an empty database
+	db := rawdb.NewMemoryDatabase()
+	have, err := Import(db, scheme, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to import state: %v", err)
+	}
+	if *have != *header {
+		t.Fatalf("Header mismatch: have %v, want %v", have, header)
+	}
+	if stored := rawdb.ReadSnapshotRoot(db); stored != root {
+		t.Fatalf("Snapshot root mismatch: have %x, want %x", stored, root)
+	}
+	if !bytes.Equal(rawdb.ReadCode(db, codeHash), code) {
+		t.Fatal("Contract code is not imported")
+	}
+	if rawdb.ReadAccountTrieNode(db, nil) == nil && scheme == rawdb.PathScheme {
+		t.Fatal("Account trie is not imported")
+	}
+	// Corrupted and truncated files must be rejected
+	corrupt := bytes.Clone(buf.Bytes())
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := Import(rawdb.NewMemoryDatabase(), scheme, bytes.NewReader(corrupt)); err == nil {
+		t.Fatal("Corrupted file imported")
+	}
+	if _, err := Import(rawdb.NewMemoryDatabase(), scheme, bytes.NewReader(buf.Bytes()[:buf.Len()-4])); err == nil {
+		t.Fatal("Truncated file imported")
+	}
+}
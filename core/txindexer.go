@@ -49,23 +49,38 @@ type txIndexer struct {
 
 	// cutoff denotes the block number before which the chain segment should
 	// be pruned and not available locally.
-	cutoff   uint64
+	cutoff uint64
+
+	// keepPruned retains the existing indexes of the blocks below the cutoff,
+	// which are served from an external history store. Only applicable if the
+	// entire chain is requested for indexing.
+	keepPruned bool
+
 	db       ethdb.Database
 	progress chan chan TxIndexProgress
+	update   chan cutoffUpdate
 	term     chan chan struct{}
 	closed   chan struct{}
 }
 
+// cutoffUpdate is a request to move the cutoff point of the indexer forward.
+type cutoffUpdate struct {
+	cutoff uint64
+	done   chan struct{} // Closed once the indexes are realigned with the cutoff
+}
+
 // newTxIndexer initializes the transaction indexer.
 func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	cutoff, _ := chain.HistoryPruningCutoff()
 	indexer := &txIndexer{
-		limit:    limit,
-		cutoff:   cutoff,
-		db:       chain.db,
-		progress: make(chan chan TxIndexProgress),
-		term:     make(chan chan struct{}),
-		closed:   make(chan struct{}),
+		limit:      limit,
+		cutoff:     cutoff,
+		keepPruned: limit == 0 && chain.cacheConfig.ChainHistoryKeepTxIndex,
+		db:         chain.db,
+		progress:   make(chan chan TxIndexProgress),
+		update:     make(chan cutoffUpdate),
+		term:       make(chan chan struct{}),
+		closed:     make(chan struct{}),
 	}
 	go indexer.loop(chain)
 
@@ -116,7 +131,13 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 	// The tail flag is existent (which means indexes in [tail, head] should be
 	// present), while the whole chain are requested for indexing.
 	if indexer.limit == 0 || head < indexer.limit {
-		if *tail > 0 {
+		if *tail < indexer.cutoff {
+			// The chain segment below the cutoff is being pruned, unindex it
+			// while the blocks are still available.
+			if !indexer.keepPruned {
+				rawdb.UnindexTransactions(indexer.db, *tail, indexer.cutoff, stop, false)
+			}
+		} else if *tail > 0 {
 			from := max(uint64(0), indexer.cutoff)
 			rawdb.IndexTransactions(indexer.db, from, *tail, stop, true)
 		}
@@ -182,8 +203,8 @@ func (indexer *txIndexer) repair(head uint64) {
 
 	// The chain head is above the cutoff while the tail is below the
 	// cutoff. Shift the tail to the cutoff point and remove the indexes
-	// below.
-	if *tail < indexer.cutoff {
+	// below, unless they are retained for the external history store.
+	if *tail < indexer.cutoff && !indexer.keepPruned {
 		// A crash may occur between the two delete operations,
 		// potentially leaving dangling indexes in the database.
 		// However, this is considered acceptable.
@@ -207,6 +228,9 @@ func (indexer *txIndexer) loop(chain *BlockChain) {
 		done chan struct{}                                 // Non-nil if background routine is active
 		head = rawdb.ReadHeadBlock(indexer.db).NumberU64() // The latest announced chain head
 
+		pending    *cutoffUpdate // Cutoff update waiting for the active routine to finish
+		realigning *cutoffUpdate // Cutoff update applied by the active routine
+
 		headCh = make(chan ChainHeadEvent)
 		sub    = chain.SubscribeChainHeadEvent(headCh)
 	)
@@ -234,6 +258,34 @@ func (indexer *txIndexer) loop(chain *BlockChain) {
 		case <-done:
 			stop = nil
 			done = nil
+
+			// Acknowledge the cutoff update applied by the finished routine,
+			// and apply the pending one if any.
+			if realigning != nil {
+				close(realigning.done)
+				realigning = nil
+			}
+			if pending != nil {
+				indexer.cutoff = pending.cutoff
+				realigning, pending = pending, nil
+
+				stop = make(chan struct{})
+				done = make(chan struct{})
+				go indexer.run(head, stop, done)
+			}
+		case req := <-indexer.update:
+			// The cutoff can't be changed while a routine is using it, defer
+			// the update until it finishes.
+			if done != nil {
+				pending = &req
+				continue
+			}
+			indexer.cutoff = req.cutoff
+			realigning = &req
+
+			stop = make(chan struct{})
+			done = make(chan struct{})
+			go indexer.run(head, stop, done)
 		case ch := <-indexer.progress:
 			ch <- indexer.report(head)
 		case ch := <-indexer.term:
@@ -299,6 +351,22 @@ func (indexer *txIndexer) txIndexProgress() (TxIndexProgress, error) {
 	}
 }
 
+// setCutoff moves the cutoff point of the indexer forward, unindexing the
+// transactions of the blocks below it, which must still be available. It
+// blocks until the indexes are realigned, or the indexer is closed.
+func (indexer *txIndexer) setCutoff(cutoff uint64) {
+	req := cutoffUpdate{cutoff: cutoff, done: make(chan struct{})}
+	select {
+	case indexer.update <- req:
+	case <-indexer.closed:
+		return
+	}
+	select {
+	case <-req.done:
+	case <-indexer.closed:
+	}
+}
+
 // close shutdown the indexer. Safe to be called for multiple times.
 func (indexer *txIndexer) close() {
 	ch := make(chan struct{})
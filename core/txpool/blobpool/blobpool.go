@@ -90,6 +90,7 @@ type blobTxMeta struct {
 	id          uint64 // Storage ID in the pool's persistent store
 	storageSize uint32 // Byte size in the pool's persistent store
 	size        uint64 // RLP-encoded size of transaction including the attached blob
+	version     byte   // Sidecar version the transaction is stored with
 
 	nonce      uint64       // Needed to prioritize inclusion order within an account
 	costCap    *uint256.Int // Needed to validate cumulative balance sufficiency
@@ -116,6 +117,7 @@ func newBlobTxMeta(id uint64, size uint64, storageSize uint32, tx *types.Transac
 		id:          id,
 		storageSize: storageSize,
 		size:        size,
+		version:     tx.BlobTxSidecar().Version,
 		nonce:       tx.Nonce(),
 		costCap:     uint256.MustFromBig(tx.Cost()),
 		execTipCap:  uint256.MustFromBig(tx.GasTipCap()),
@@ -306,6 +308,8 @@ type BlobPool struct {
 	stored uint64         // Useful data size of all transactions on disk
 	limbo  *limbo         // Persistent data store for the non-finalized blobs
 
+	archiver Archiver // Optional persistent store for the blobs of finalized txs
+
 	signer types.Signer // Transaction signer to use for sender recovery
 	chain  BlockChain   // Chain object to access the state through
 
@@ -325,6 +329,10 @@ type BlobPool struct {
 	// overridden for testing purposes.
 	txValidationFn txpool.ValidationFunction
 
+	convertCh chan struct{}  // Notification channel to convert sidecars to the current fork's format
+	quit      chan struct{}  // Termination channel to stop the background sidecar converter
+	wg        sync.WaitGroup // Tracks the background sidecar converter
+
 	lock sync.RWMutex // Mutex protecting the pool during reorg handling
 }
 
@@ -344,9 +352,17 @@ func New(config Config, chain BlockChain, hasPendingAuth func(common.Address) bo
 		index:          make(map[common.Address][]*blobTxMeta),
 		spent:          make(map[common.Address]*uint256.Int),
 		txValidationFn: txpool.ValidateTransaction,
+		convertCh:      make(chan struct{}, 1),
+		quit:           make(chan struct{}),
 	}
 }
 
+// SetArchiver sets an archiver to hand the blobs of finalized transactions to
+// before dropping them from the pool. It must be called before Init.
+func (p *BlobPool) SetArchiver(archiver Archiver) {
+	p.archiver = archiver
+}
+
 // Filter returns whether the given transaction can be consumed by the blob pool.
 func (p *BlobPool) Filter(tx *types.Transaction) bool {
 	return tx.Type() == types.BlobTxType
@@ -444,11 +460,20 @@ func (p *BlobPool) Init(gasTip uint64, head *types.Header, reserver txpool.Reser
 	// Update the metrics and return the constructed pool
 	datacapGauge.Update(int64(p.config.Datacap))
 	p.updateStorageMetrics()
+
+	// If the node was restarted across the cell proof fork, bring any sidecar
+	// stored in the old format up to date in the background
+	p.wg.Add(1)
+	go p.convertLoop()
+	p.scheduleConversion()
 	return nil
 }
 
 // Close closes down the underlying persistent store.
 func (p *BlobPool) Close() error {
+	close(p.quit)
+	p.wg.Wait()
+
 	var errs []error
 	if p.limbo != nil { // Close might be invoked due to error in constructor, before p,limbo is set
 		if err := p.limbo.Close(); err != nil {
@@ -807,6 +832,7 @@ func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset blobpool state", "err", err)
 		return
 	}
+	forked := p.sidecarVersion(newHead) != p.sidecarVersion(p.head)
 	p.head = newHead
 	p.state = statedb
 
@@ -829,9 +855,18 @@ func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
 			p.insertFeed.Send(core.NewTxsEvent{Txs: adds})
 		}
 	}
+	// If the head crossed the cell proof fork, convert all pooled sidecars into
+	// the new format once, so retrievals never have to recompute proofs
+	if forked {
+		p.scheduleConversion()
+	}
 	// Flush out any blobs from limbo that are older than the latest finality
 	if p.chain.Config().IsCancun(p.head.Number, p.head.Time) {
-		p.limbo.finalize(p.chain.CurrentFinalBlock())
+		var archive func(tx *types.Transaction, block uint64)
+		if p.archiver != nil {
+			archive = p.archiver.ArchiveBlobTx
+		}
+		p.limbo.finalize(p.chain.CurrentFinalBlock(), archive)
 	}
 	// Reset the price heap for the new set of basefee/blobfee pairs
 	var (
@@ -1021,6 +1056,12 @@ func (p *BlobPool) reinject(addr common.Address, txhash common.Hash) error {
 	}
 	p.lookup.track(meta)
 	p.stored += uint64(meta.storageSize)
+
+	// If the transaction was included before the cell proof fork but reorged out
+	// after it (or vice versa), bring its sidecar to the current format.
+	if meta.version != p.sidecarVersion(p.head) {
+		p.scheduleConversion()
+	}
 	return nil
 }
 
@@ -1295,14 +1336,17 @@ func (p *BlobPool) GetMetadata(hash common.Hash) *txpool.TxMetadata {
 	}
 }
 
-// GetBlobs returns a number of blobs are proofs for the given versioned hashes.
-// This is a utility method for the engine API, enabling consensus clients to
-// retrieve blobs from the pools directly instead of the network.
-func (p *BlobPool) GetBlobs(vhashes []common.Hash) ([]*kzg4844.Blob, []*kzg4844.Proof) {
+// GetBlobs returns a number of blobs and proofs for the given versioned hashes.
+// The pool stores all sidecars in the format of the current fork, so blobs are
+// only returned if the requested sidecar version matches it; proofs are never
+// recomputed on retrieval. This is a utility method for the engine API, enabling
+// consensus clients to retrieve blobs from the pools directly instead of the
+// network.
+func (p *BlobPool) GetBlobs(vhashes []common.Hash, version byte) ([]*kzg4844.Blob, [][]kzg4844.Proof) {
 	// Create a map of the blob hash to indices for faster fills
 	var (
 		blobs  = make([]*kzg4844.Blob, len(vhashes))
-		proofs = make([]*kzg4844.Proof, len(vhashes))
+		proofs = make([][]kzg4844.Proof, len(vhashes))
 	)
 	index := make(map[common.Hash]int)
 	for i, vhash := range vhashes {
@@ -1335,18 +1379,184 @@ func (p *BlobPool) GetBlobs(vhashes []common.Hash) ([]*kzg4844.Blob, []*kzg4844.
 			log.Error("Blobs corrupted for traced transaction", "id", id, "err", err)
 			continue
 		}
-		// Fill anything requested, not just the current versioned hash
+		// Skip sidecars stored in a different format than requested, they
+		// are only available in the format of the current fork
 		sidecar := item.BlobTxSidecar()
+		if sidecar.Version != version {
+			continue
+		}
+		// Fill anything requested, not just the current versioned hash
 		for j, blobhash := range item.BlobHashes() {
 			if idx, ok := index[blobhash]; ok {
 				blobs[idx] = &sidecar.Blobs[j]
-				proofs[idx] = &sidecar.Proofs[j]
+				if version == types.BlobSidecarVersion1 {
+					cellProofs, err := sidecar.CellProofsAt(j)
+					if err != nil {
+						log.Error("Blob cell proofs corrupted for pooled transaction", "id", id, "err", err)
+						blobs[idx] = nil
+						continue
+					}
+					proofs[idx] = cellProofs
+				} else {
+					proofs[idx] = []kzg4844.Proof{sidecar.Proofs[j]}
+				}
 			}
 		}
 	}
 	return blobs, proofs
 }
 
+// sidecarVersion returns the blob sidecar version the pool stores transactions
+// with at the given head.
+func (p *BlobPool) sidecarVersion(head *types.Header) byte {
+	if p.chain.Config().IsOsaka(head.Number, head.Time) {
+		return types.BlobSidecarVersion1
+	}
+	return types.BlobSidecarVersion0
+}
+
+// scheduleConversion requests the background converter to bring the pooled
+// sidecars to the format of the current fork.
+func (p *BlobPool) scheduleConversion() {
+	select {
+	case p.convertCh <- struct{}{}:
+	default:
+	}
+}
+
+// convertLoop converts the pooled sidecars not in the format of the current fork
+// whenever requested, until the pool is closed.
+func (p *BlobPool) convertLoop() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.convertCh:
+			p.convertSidecars()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// convertSidecars rewrites every pooled transaction whose sidecar is not in the
+// format of the current fork, recomputing its proofs and persisting the result.
+// It runs once when the chain crosses the cell proof fork, and whenever a
+// transaction in the other format is added.
+//
+// The proofs are computed without holding the pool lock, each transaction is
+// only swapped in afterwards if it's still pooled and the fork didn't change.
+func (p *BlobPool) convertSidecars() {
+	type pending struct {
+		meta *blobTxMeta
+		id   uint64
+	}
+	p.lock.RLock()
+	var (
+		version = p.sidecarVersion(p.head)
+		queue   []pending
+	)
+	for _, txs := range p.index {
+		for _, meta := range txs {
+			if meta.version != version {
+				queue = append(queue, pending{meta, meta.id})
+			}
+		}
+	}
+	p.lock.RUnlock()
+
+	var (
+		start     = time.Now()
+		converted int
+	)
+	for _, item := range queue {
+		select {
+		case <-p.quit:
+			return
+		default:
+		}
+		p.lock.RLock()
+		data, err := p.store.Get(item.id)
+		p.lock.RUnlock()
+		if err != nil {
+			continue // Dropped from the pool meanwhile
+		}
+		tx := new(types.Transaction)
+		if err = rlp.DecodeBytes(data, tx); err != nil {
+			log.Error("Blobs corrupted for pooled transaction", "id", item.id, "err", err)
+			continue
+		}
+		sidecar := tx.BlobTxSidecar().Copy()
+		if err := convertSidecar(sidecar, version); err != nil {
+			log.Error("Failed to convert blob sidecar", "hash", item.meta.hash, "err", err)
+			continue
+		}
+		tx = tx.WithBlobTxSidecar(sidecar)
+
+		blob, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			log.Error("Failed to encode transaction for storage", "hash", item.meta.hash, "err", err)
+			continue
+		}
+		if p.swapSidecar(item.meta, item.id, version, tx.Size(), blob) {
+			converted++
+		}
+	}
+	if converted > 0 {
+		convertedMeter.Mark(int64(converted))
+		log.Info("Converted pooled blob sidecars", "version", version, "count", converted, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// swapSidecar replaces the stored transaction of a pooled metadata with its
+// converted encoding, unless it was dropped or replaced since the conversion
+// started, or the chain moved to another fork. The pool is shrunk back below
+// its data cap if the converted transaction is larger.
+func (p *BlobPool) swapSidecar(meta *blobTxMeta, id uint64, version byte, size uint64, blob []byte) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if current, ok := p.lookup.storeidOfTx(meta.hash); !ok || current != id || p.sidecarVersion(p.head) != version {
+		return false
+	}
+	newid, err := p.store.Put(blob)
+	if err != nil {
+		log.Error("Failed to write transaction into storage", "hash", meta.hash, "err", err)
+		return false
+	}
+	if err := p.store.Delete(id); err != nil {
+		log.Error("Failed to delete converted transaction", "id", id, "err", err)
+	}
+	// Swap the storage references in place, everything else in the metadata
+	// is derived from the transaction fields and unchanged
+	p.lookup.untrack(meta)
+	p.stored -= uint64(meta.storageSize)
+
+	meta.id, meta.size, meta.storageSize, meta.version = newid, size, p.store.Size(newid), version
+
+	p.lookup.track(meta)
+	p.stored += uint64(meta.storageSize)
+
+	for p.stored > p.config.Datacap {
+		p.drop()
+	}
+	p.updateStorageMetrics()
+	return true
+}
+
+// convertSidecar converts a blob sidecar in place into the requested version,
+// recomputing the proofs from the blobs.
+func convertSidecar(sidecar *types.BlobTxSidecar, version byte) error {
+	switch version {
+	case types.BlobSidecarVersion0:
+		return sidecar.ToV0()
+	case types.BlobSidecarVersion1:
+		return sidecar.ToV1()
+	default:
+		return fmt.Errorf("unsupported blob sidecar version %d", version)
+	}
+}
+
 // Add inserts a set of blob transactions into the pool if they pass validation (both
 // consensus validity and pool restrictions).
 //
@@ -1531,6 +1741,12 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 	}
 	p.updateStorageMetrics()
 
+	// Sidecars not in the format of the current fork are converted in the
+	// background, not to hold the pool lock while recomputing the proofs.
+	if meta.version != p.sidecarVersion(p.head) {
+		addConvertedMeter.Mark(1)
+		p.scheduleConversion()
+	}
 	addValidMeter.Mark(1)
 	return nil
 }
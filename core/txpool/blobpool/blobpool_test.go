@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
@@ -417,7 +418,8 @@ func verifyBlobRetrievals(t *testing.T, pool *BlobPool) {
 	for i := range testBlobVHashes {
 		copy(hashes[i][:], testBlobVHashes[i][:])
 	}
-	blobs, proofs := pool.GetBlobs(hashes)
+	version := pool.sidecarVersion(pool.head)
+	blobs, proofs := pool.GetBlobs(hashes, version)
 
 	// Cross validate what we received vs what we wanted
 	if len(blobs) != len(hashes) || len(proofs) != len(hashes) {
@@ -433,8 +435,16 @@ func verifyBlobRetrievals(t *testing.T, pool *BlobPool) {
 			continue
 		}
 		// Item retrieved, make sure it matches the expectation
-		if *blobs[i] != *testBlobs[i] || *proofs[i] != testBlobProofs[i] {
-			t.Errorf("retrieved blob or proof mismatch: item %d, hash %x", i, hash)
+		if *blobs[i] != *testBlobs[i] {
+			t.Errorf("retrieved blob mismatch: item %d, hash %x", i, hash)
+			continue
+		}
+		if version == types.BlobSidecarVersion0 && (len(proofs[i]) != 1 || proofs[i][0] != testBlobProofs[i]) {
+			t.Errorf("retrieved proof mismatch: item %d, hash %x", i, hash)
+			continue
+		}
+		if version == types.BlobSidecarVersion1 && len(proofs[i]) != kzg4844.CellProofsPerBlob {
+			t.Errorf("retrieved cell proof count mismatch: item %d, hash %x", i, hash)
 			continue
 		}
 		delete(known, hash)
@@ -1609,6 +1619,274 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+// Tests that blob transactions are accepted with both sidecar versions, that
+// they are stored in the sidecar format of the current fork, and that blobs are
+// only served in the stored format without recomputing proofs.
+func TestSidecarConversion(t *testing.T) {
+	osaka := *params.MainnetChainConfig
+	osaka.OsakaTime = new(uint64)
+	osaka.BlobScheduleConfig = &params.BlobScheduleConfig{
+		Cancun: params.DefaultCancunBlobConfig,
+		Prague: params.DefaultPragueBlobConfig,
+		Osaka:  params.DefaultPragueBlobConfig,
+	}
+
+	for _, config := range []*params.ChainConfig{params.MainnetChainConfig, &osaka} {
+		var (
+			isOsaka    = config.OsakaTime != nil
+			key1, _    = crypto.GenerateKey()
+			key2, _    = crypto.GenerateKey()
+			addr1      = crypto.PubkeyToAddress(key1.PublicKey)
+			addr2      = crypto.PubkeyToAddress(key2.PublicKey)
+			statedb, _ = state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		)
+		statedb.AddBalance(addr1, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+		statedb.AddBalance(addr2, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+		statedb.Commit(0, true, false)
+
+		chain := &testBlockChain{
+			config:  config,
+			basefee: uint256.NewInt(1050),
+			blobfee: uint256.NewInt(105),
+			statedb: statedb,
+		}
+		pool := New(Config{Datadir: t.TempDir()}, chain, nil)
+		if err := pool.Init(1, chain.CurrentBlock(), newReserver()); err != nil {
+			t.Fatalf("failed to create blob pool: %v", err)
+		}
+		// Add a transaction with a legacy sidecar and one with cell proofs
+		tx1 := types.MustSignNewTx(key1, types.LatestSigner(config), makeUnsignedTxWithTestBlob(0, 10, 2000, 200, 0))
+
+		inner := makeUnsignedTxWithTestBlob(0, 10, 2000, 200, 1)
+		if err := inner.Sidecar.ToV1(); err != nil {
+			t.Fatalf("failed to convert sidecar: %v", err)
+		}
+		tx2 := types.MustSignNewTx(key2, types.LatestSigner(config), inner)
+
+		for i, err := range pool.Add([]*types.Transaction{tx1, tx2}, true) {
+			if err != nil {
+				t.Fatalf("osaka %v: failed to add transaction %d: %v", isOsaka, i, err)
+			}
+		}
+		waitSidecarConversion(t, pool)
+
+		// Both sidecars must be stored in the format of the current fork
+		want, other := types.BlobSidecarVersion0, types.BlobSidecarVersion1
+		if isOsaka {
+			want, other = types.BlobSidecarVersion1, types.BlobSidecarVersion0
+		}
+		for i, tx := range []*types.Transaction{tx1, tx2} {
+			if have := pool.Get(tx.Hash()).BlobTxSidecar().Version; have != want {
+				t.Errorf("osaka %v: tx %d stored sidecar version mismatch: have %d, want %d", isOsaka, i, have, want)
+			}
+		}
+		// Retrieve the blobs in the stored format and validate the proofs
+		hashes := []common.Hash{testBlobVHashes[0], testBlobVHashes[1]}
+		verifyPooledBlobs(t, pool, hashes, want)
+
+		// Retrievals in the other format must miss instead of converting
+		blobs, _ := pool.GetBlobs(hashes, other)
+		for i := range hashes {
+			if blobs[i] != nil {
+				t.Errorf("osaka %v: blob %d served in unstored version %d", isOsaka, i, other)
+			}
+		}
+		verifyPoolInternals(t, pool)
+		pool.Close()
+	}
+}
+
+// Tests that pooled legacy sidecars are converted to cell proofs and persisted
+// once the chain head crosses the fork, and that the conversion survives restarts.
+func TestSidecarConversionOnFork(t *testing.T) {
+	config := *params.MainnetChainConfig
+	config.OsakaTime = new(uint64)
+	*config.OsakaTime = *config.CancunTime + 2
+	config.BlobScheduleConfig = &params.BlobScheduleConfig{
+		Cancun: params.DefaultCancunBlobConfig,
+		Prague: params.DefaultPragueBlobConfig,
+		Osaka:  params.DefaultPragueBlobConfig,
+	}
+	var (
+		key, _     = crypto.GenerateKey()
+		addr       = crypto.PubkeyToAddress(key.PublicKey)
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		storage    = t.TempDir()
+	)
+	statedb.AddBalance(addr, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.Commit(0, true, false)
+
+	chain := &testBlockChain{
+		config:  &config,
+		basefee: uint256.NewInt(1050),
+		blobfee: uint256.NewInt(105),
+		statedb: statedb,
+	}
+	pool := New(Config{Datadir: storage}, chain, nil)
+	if err := pool.Init(1, chain.CurrentBlock(), newReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	tx := types.MustSignNewTx(key, types.LatestSigner(&config), makeUnsignedTxWithTestBlob(0, 10, 2000, 200, 0))
+	if err := pool.Add([]*types.Transaction{tx}, true)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if have := pool.Get(tx.Hash()).BlobTxSidecar().Version; have != types.BlobSidecarVersion0 {
+		t.Fatalf("pre-fork sidecar version mismatch: have %d, want %d", have, types.BlobSidecarVersion0)
+	}
+	// Move the head past the fork and ensure the sidecar got rewritten
+	parent := chain.CurrentBlock()
+	header := &types.Header{
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       *config.OsakaTime,
+		Difficulty: common.Big0,
+		BaseFee:    parent.BaseFee,
+	}
+	chain.blocks = map[uint64]*types.Block{header.Number.Uint64(): types.NewBlockWithHeader(header)}
+	pool.Reset(parent, header)
+	waitSidecarConversion(t, pool)
+
+	if have := pool.Get(tx.Hash()).BlobTxSidecar().Version; have != types.BlobSidecarVersion1 {
+		t.Fatalf("post-fork sidecar version mismatch: have %d, want %d", have, types.BlobSidecarVersion1)
+	}
+	verifyPooledBlobs(t, pool, []common.Hash{testBlobVHashes[0]}, types.BlobSidecarVersion1)
+	verifyPoolInternals(t, pool)
+	pool.Close()
+
+	// Reopen the pool and ensure the converted sidecar was persisted
+	pool = New(Config{Datadir: storage}, chain, nil)
+	if err := pool.Init(1, header, newReserver()); err != nil {
+		t.Fatalf("failed to reopen blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	if have := pool.Get(tx.Hash()).BlobTxSidecar().Version; have != types.BlobSidecarVersion1 {
+		t.Fatalf("reopened sidecar version mismatch: have %d, want %d", have, types.BlobSidecarVersion1)
+	}
+	verifyPoolInternals(t, pool)
+}
+
+// Tests that the pool is shrunk back below its data cap if the sidecars grow
+// when converted to cell proofs at the fork.
+func TestSidecarConversionDatacap(t *testing.T) {
+	config := *params.MainnetChainConfig
+	config.OsakaTime = new(uint64)
+	*config.OsakaTime = *config.CancunTime + 2
+	config.BlobScheduleConfig = &params.BlobScheduleConfig{
+		Cancun: params.DefaultCancunBlobConfig,
+		Prague: params.DefaultPragueBlobConfig,
+		Osaka:  params.DefaultPragueBlobConfig,
+	}
+	var (
+		key1, _    = crypto.GenerateKey()
+		key2, _    = crypto.GenerateKey()
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	)
+	statedb.AddBalance(crypto.PubkeyToAddress(key1.PublicKey), uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.AddBalance(crypto.PubkeyToAddress(key2.PublicKey), uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.Commit(0, true, false)
+
+	chain := &testBlockChain{
+		config:  &config,
+		basefee: uint256.NewInt(1050),
+		blobfee: uint256.NewInt(105),
+		statedb: statedb,
+	}
+	pool := New(Config{Datadir: t.TempDir()}, chain, nil)
+	if err := pool.Init(1, chain.CurrentBlock(), newReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	txs := []*types.Transaction{
+		types.MustSignNewTx(key1, types.LatestSigner(&config), makeUnsignedTxWithTestBlob(0, 10, 2000, 200, 0)),
+		types.MustSignNewTx(key2, types.LatestSigner(&config), makeUnsignedTxWithTestBlob(0, 10, 2000, 200, 1)),
+	}
+	for i, err := range pool.Add(txs, true) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	// Shrink the data cap to the legacy sidecars and cross the fork
+	pool.lock.Lock()
+	pool.config.Datacap = pool.stored
+	pool.lock.Unlock()
+
+	parent := chain.CurrentBlock()
+	header := &types.Header{
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       *config.OsakaTime,
+		Difficulty: common.Big0,
+		BaseFee:    parent.BaseFee,
+	}
+	chain.blocks = map[uint64]*types.Block{header.Number.Uint64(): types.NewBlockWithHeader(header)}
+	pool.Reset(parent, header)
+	waitSidecarConversion(t, pool)
+
+	pool.lock.RLock()
+	stored, datacap, count := pool.stored, pool.config.Datacap, len(pool.index)
+	pool.lock.RUnlock()
+	if stored > datacap {
+		t.Fatalf("pool above data cap after conversion: stored %d, cap %d", stored, datacap)
+	}
+	if count != 1 {
+		t.Fatalf("pooled account count mismatch after conversion: have %d, want 1", count)
+	}
+	verifyPoolInternals(t, pool)
+}
+
+// waitSidecarConversion waits until the background converter brought every
+// pooled sidecar to the format of the current fork.
+func waitSidecarConversion(t *testing.T, pool *BlobPool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		pool.lock.RLock()
+		var (
+			version = pool.sidecarVersion(pool.head)
+			done    = true
+		)
+		for _, txs := range pool.index {
+			for _, meta := range txs {
+				done = done && meta.version == version
+			}
+		}
+		pool.lock.RUnlock()
+
+		if done {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for sidecar conversion")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// verifyPooledBlobs checks that the pool serves the test blobs behind the given
+// versioned hashes with valid proofs of the requested sidecar version.
+func verifyPooledBlobs(t *testing.T, pool *BlobPool, hashes []common.Hash, version byte) {
+	t.Helper()
+
+	blobs, proofs := pool.GetBlobs(hashes, version)
+	for i := range hashes {
+		if blobs[i] == nil || *blobs[i] != *testBlobs[i] {
+			t.Fatalf("version %d: blob %d mismatch", version, i)
+		}
+		if version == types.BlobSidecarVersion0 {
+			if len(proofs[i]) != 1 || proofs[i][0] != testBlobProofs[i] {
+				t.Fatalf("version %d: blob proof %d mismatch", version, i)
+			}
+			continue
+		}
+		if len(proofs[i]) != kzg4844.CellProofsPerBlob {
+			t.Fatalf("version %d: cell proof count %d mismatch: have %d, want %d", version, i, len(proofs[i]), kzg4844.CellProofsPerBlob)
+		}
+		if err := kzg4844.VerifyCellProofs([]kzg4844.Blob{*blobs[i]}, []kzg4844.Commitment{testBlobCommits[i]}, proofs[i]); err != nil {
+			t.Fatalf("version %d: cell proofs %d invalid: %v", version, i, err)
+		}
+	}
+}
+
 // fakeBilly is a billy.Database implementation which just drops data on the floor.
 type fakeBilly struct {
 	billy.Database
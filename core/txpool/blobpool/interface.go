@@ -42,3 +42,11 @@ type BlockChain interface {
 	// StateAt returns a state database for a given root hash (generally the head).
 	StateAt(root common.Hash) (*state.StateDB, error)
 }
+
+// Archiver defines the method needed to persist the blobs of finalized blob
+// transactions before the pool drops them from its limbo.
+type Archiver interface {
+	// ArchiveBlobTx stores the sidecar of a blob transaction that was included
+	// in the given finalized block.
+	ArchiveBlobTx(tx *types.Transaction, block uint64)
+}
@@ -18,6 +18,7 @@ package blobpool
 
 import (
 	"errors"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -112,18 +113,33 @@ func (l *limbo) parseBlob(id uint64, data []byte) error {
 }
 
 // finalize evicts all blobs belonging to a recently finalized block or older.
-func (l *limbo) finalize(final *types.Header) {
+// If an archive callback is provided, each evicted transaction is handed to it
+// before deletion, in ascending block order.
+func (l *limbo) finalize(final *types.Header, archive func(tx *types.Transaction, block uint64)) {
 	// Just in case there's no final block yet (network not yet merged, weird
 	// restart, sethead, etc), fail gracefully.
 	if final == nil {
 		log.Error("Nil finalized block cannot evict old blobs")
 		return
 	}
-	for block, ids := range l.groups {
-		if block > final.Number.Uint64() {
-			continue
+	var blocks []uint64
+	for block := range l.groups {
+		if block <= final.Number.Uint64() {
+			blocks = append(blocks, block)
 		}
-		for id, owner := range ids {
+	}
+	slices.Sort(blocks)
+
+	for _, block := range blocks {
+		for id, owner := range l.groups[block] {
+			if archive != nil {
+				item, err := l.get(id)
+				if err != nil {
+					log.Error("Failed to retrieve finalized blob", "block", block, "id", id, "err", err)
+				} else {
+					archive(item.Tx, block)
+				}
+			}
 			if err := l.store.Delete(id); err != nil {
 				log.Error("Failed to drop finalized blob", "block", block, "id", id, "err", err)
 			}
@@ -205,9 +221,8 @@ func (l *limbo) update(txhash common.Hash, block uint64) {
 	log.Trace("Blob transaction updated in limbo", "tx", txhash, "old-block", item.Block, "new-block", block)
 }
 
-// getAndDrop retrieves a blob item from the limbo store and deletes it both from
-// the store and indices.
-func (l *limbo) getAndDrop(id uint64) (*limboBlob, error) {
+// get retrieves a blob item from the limbo store without modifying it.
+func (l *limbo) get(id uint64) (*limboBlob, error) {
 	data, err := l.store.Get(id)
 	if err != nil {
 		return nil, err
@@ -216,6 +231,16 @@ func (l *limbo) getAndDrop(id uint64) (*limboBlob, error) {
 	if err = rlp.DecodeBytes(data, item); err != nil {
 		return nil, err
 	}
+	return item, nil
+}
+
+// getAndDrop retrieves a blob item from the limbo store and deletes it both from
+// the store and indices.
+func (l *limbo) getAndDrop(id uint64) (*limboBlob, error) {
+	item, err := l.get(id)
+	if err != nil {
+		return nil, err
+	}
 	delete(l.index, item.TxHash)
 	delete(l.groups[item.Block], id)
 	if len(l.groups[item.Block]) == 0 {
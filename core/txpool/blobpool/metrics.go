@@ -102,4 +102,9 @@ var (
 	addNoreplaceMeter    = metrics.NewRegisteredMeter("blobpool/add/noreplace", nil)    // Replacement fees or tips too low, neutral
 	addNonExclusiveMeter = metrics.NewRegisteredMeter("blobpool/add/nonexclusive", nil) // Plain transaction from same account exists, reject, neutral
 	addValidMeter        = metrics.NewRegisteredMeter("blobpool/add/valid", nil)        // Valid transaction, add, neutral
+	addConvertedMeter    = metrics.NewRegisteredMeter("blobpool/add/converted", nil)    // Sidecar queued for conversion to the current fork's format, neutral
+
+	// convertedMeter tracks the pooled transactions whose sidecars were rewritten
+	// in the background into the format of the current fork.
+	convertedMeter = metrics.NewRegisteredMeter("blobpool/converted", nil)
 )
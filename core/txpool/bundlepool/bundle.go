@@ -0,0 +1,100 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundlepool
+
+import (
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Bundle is an ordered list of transactions that must be included in a block
+// together, in the given order, or not at all.
+type Bundle struct {
+	Txs          []*types.Transaction // Transactions to include, in execution order
+	BlockNumber  uint64               // Block number the bundle is valid for
+	MinTimestamp uint64               // Minimum block timestamp to include the bundle at (0 = any)
+	MaxTimestamp uint64               // Maximum block timestamp to include the bundle at (0 = any)
+
+	RevertingTxHashes []common.Hash // Transactions allowed to revert without invalidating the bundle
+}
+
+// Hash returns the identifier of the bundle, computed as the keccak256 hash of
+// the concatenated hashes of the contained transactions.
+func (b *Bundle) Hash() common.Hash {
+	blob := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		blob = append(blob, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(blob)
+}
+
+// CanRevert returns whether the transaction with the given hash is permitted
+// to revert during execution without invalidating the whole bundle.
+func (b *Bundle) CanRevert(hash common.Hash) bool {
+	return slices.Contains(b.RevertingTxHashes, hash)
+}
+
+// Eligible returns whether the bundle may be included into a block with the
+// given number and timestamp.
+func (b *Bundle) Eligible(number uint64, time uint64) bool {
+	if b.BlockNumber != number {
+		return false
+	}
+	if b.MinTimestamp != 0 && time < b.MinTimestamp {
+		return false
+	}
+	if b.MaxTimestamp != 0 && time > b.MaxTimestamp {
+		return false
+	}
+	return true
+}
+
+// BundleState is the lifecycle stage a bundle is in.
+type BundleState string
+
+const (
+	// BundlePending is the state of a bundle waiting for its target block.
+	BundlePending BundleState = "pending"
+
+	// BundleBuilt is the state of a bundle that was successfully simulated and
+	// inserted into a locally built block, which is not yet canonical.
+	BundleBuilt BundleState = "built"
+
+	// BundleFailed is the state of a bundle whose last inclusion attempt was
+	// rejected. It is retried on every rebuild until its target block passes.
+	BundleFailed BundleState = "failed"
+
+	// BundleIncluded is the state of a bundle that landed in its target block.
+	BundleIncluded BundleState = "included"
+
+	// BundleExpired is the state of a bundle whose target block was imported
+	// without the bundle in it.
+	BundleExpired BundleState = "expired"
+)
+
+// BundleStatus is the inclusion status of a bundle.
+type BundleStatus struct {
+	State       BundleState // Lifecycle stage of the bundle
+	BlockNumber uint64      // Target block number of the bundle
+	BlockHash   common.Hash // Hash of the block the bundle was included in
+	Payment     *big.Int    // Coinbase payment of the last successful inclusion attempt
+	Error       string      // Reason of the last failed inclusion attempt
+}
@@ -0,0 +1,557 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bundlepool implements a pool of transaction bundles, which are only
+// ever included atomically by the local block builder and never propagated.
+package bundlepool
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// txMaxSize is the maximum size a single transaction can have inside a
+	// bundle, matching the limit of the legacy pool.
+	txMaxSize = 4 * 32 * 1024
+
+	// statusRetention is the number of blocks the status of a finished bundle
+	// is retained for after its target block.
+	statusRetention = 128
+)
+
+var (
+	// ErrEmptyBundle is returned if a bundle without transactions is submitted.
+	ErrEmptyBundle = errors.New("empty bundle")
+
+	// ErrBundleTooLarge is returned if a bundle exceeds the configured number of
+	// transactions.
+	ErrBundleTooLarge = errors.New("bundle too large")
+
+	// ErrBundleStale is returned if a bundle targets a block that is already
+	// part of the chain.
+	ErrBundleStale = errors.New("bundle target block already imported")
+
+	// ErrBundleTooFar is returned if a bundle targets a block too far into the
+	// future.
+	ErrBundleTooFar = errors.New("bundle target block too far in the future")
+
+	// ErrBundleKnown is returned if an identical bundle is already pooled.
+	ErrBundleKnown = errors.New("bundle already known")
+
+	// ErrBundlePoolFull is returned if the pool has no space for further bundles.
+	ErrBundlePoolFull = errors.New("bundle pool full")
+)
+
+var (
+	bundleGauge    = metrics.NewRegisteredGauge("txpool/bundles", nil)
+	includedMeter  = metrics.NewRegisteredMeter("txpool/bundles/included", nil)
+	expiredMeter   = metrics.NewRegisteredMeter("txpool/bundles/expired", nil)
+	rejectedMeter  = metrics.NewRegisteredMeter("txpool/bundles/rejected", nil)
+	submittedMeter = metrics.NewRegisteredMeter("txpool/bundles/submitted", nil)
+)
+
+// Config are the configuration parameters of the bundle pool.
+type Config struct {
+	Enabled     bool   // Whether bundles are accepted and built into blocks
+	MaxBundles  int    // Maximum number of bundles tracked by the pool
+	MaxTxs      int    // Maximum number of transactions within a single bundle
+	BlocksAhead uint64 // Maximum number of blocks ahead of the head a bundle may target
+}
+
+// DefaultConfig contains the default configurations for the bundle pool.
+var DefaultConfig = Config{
+	MaxBundles:  1024,
+	MaxTxs:      16,
+	BlocksAhead: 32,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *Config) sanitize() Config {
+	conf := *config
+	if conf.MaxBundles < 1 {
+		log.Warn("Sanitizing invalid bundlepool capacity", "provided", conf.MaxBundles, "updated", DefaultConfig.MaxBundles)
+		conf.MaxBundles = DefaultConfig.MaxBundles
+	}
+	if conf.MaxTxs < 1 {
+		log.Warn("Sanitizing invalid bundlepool bundle size", "provided", conf.MaxTxs, "updated", DefaultConfig.MaxTxs)
+		conf.MaxTxs = DefaultConfig.MaxTxs
+	}
+	if conf.BlocksAhead < 1 {
+		log.Warn("Sanitizing invalid bundlepool lookahead", "provided", conf.BlocksAhead, "updated", DefaultConfig.BlocksAhead)
+		conf.BlocksAhead = DefaultConfig.BlocksAhead
+	}
+	return conf
+}
+
+// BlockChain defines the minimal set of methods needed to back a bundle pool
+// with a chain.
+type BlockChain interface {
+	// Config retrieves the chain's fork configuration.
+	Config() *params.ChainConfig
+
+	// GetBlock retrieves a specific block, used during pool resets.
+	GetBlock(hash common.Hash, number uint64) *types.Block
+}
+
+// BundlePool is a pool of transaction bundles. It implements txpool.SubPool so
+// that it is kept in lockstep with the chain by the main transaction pool, but
+// it does not accept individual transactions, nor does it ever expose the ones
+// it contains to the network.
+type BundlePool struct {
+	config Config
+	chain  BlockChain
+	signer types.Signer
+
+	head     *types.Header                 // Current chain head to validate targets against
+	bundles  map[common.Hash]*Bundle       // Bundles waiting for their target block
+	finished map[common.Hash]*Bundle       // Resolved bundles, kept to re-examine them on reorgs
+	status   map[common.Hash]*BundleStatus // Status of both live and finished bundles
+	targets  map[uint64][]common.Hash      // Bundle and status hashes keyed by target block
+	resolved map[uint64]common.Hash        // Canonical block hash each target was resolved against
+
+	txFeed event.Feed // Never fired, bundled transactions are not announced
+	lock   sync.RWMutex
+}
+
+// New creates a new bundle pool.
+func New(config Config, chain BlockChain) *BundlePool {
+	config = (&config).sanitize()
+
+	return &BundlePool{
+		config:   config,
+		chain:    chain,
+		signer:   types.LatestSigner(chain.Config()),
+		bundles:  make(map[common.Hash]*Bundle),
+		finished: make(map[common.Hash]*Bundle),
+		status:   make(map[common.Hash]*BundleStatus),
+		targets:  make(map[uint64][]common.Hash),
+		resolved: make(map[uint64]common.Hash),
+	}
+}
+
+// Filter returns whether the given transaction can be consumed by the bundle
+// pool. Bundles can only be added as a whole via AddBundle, so this always
+// returns false.
+func (p *BundlePool) Filter(tx *types.Transaction) bool {
+	return false
+}
+
+// Init sets the chain head the bundle targets are validated against.
+func (p *BundlePool) Init(gasTip uint64, head *types.Header, reserver txpool.Reserver) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.head = head
+	return nil
+}
+
+// Close terminates the bundle pool.
+func (p *BundlePool) Close() error {
+	return nil
+}
+
+// Reset resolves the bundles that targeted the newly imported blocks, marking
+// them either included or expired, and drops stale status entries. On reorgs,
+// the bundles resolved against the dropped blocks are re-examined against the
+// new canonical ones, or become pending again if their target block is above
+// the new head.
+func (p *BundlePool) Reset(oldHead, newHead *types.Header) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.head = newHead
+
+	var (
+		number = newHead.Number.Uint64()
+		blocks = p.newBlocks(oldHead, newHead)
+	)
+	for target, hashes := range p.targets {
+		if target > number {
+			// Only reorgs to a shorter chain can drop an already resolved
+			// target, let the bundles compete for it again
+			if _, ok := p.resolved[target]; ok {
+				p.reopen(target, hashes)
+				delete(p.resolved, target)
+			}
+			continue
+		}
+		resolved, ok := p.resolved[target]
+		if block := blocks[target]; block != nil {
+			if !ok || resolved != block.Hash() {
+				p.resolve(target, hashes, block)
+			}
+		} else if !ok {
+			// The target block is unavailable, possibly after a long jump of
+			// the head, consider the bundles missed
+			p.resolve(target, hashes, nil)
+		}
+		// Keep the finished statuses around for a while so they can be queried,
+		// dropping them only after the retention window passed.
+		if target+statusRetention <= number {
+			for _, hash := range hashes {
+				if status, ok := p.status[hash]; ok && status.BlockNumber == target {
+					delete(p.status, hash)
+					delete(p.finished, hash)
+				}
+			}
+			delete(p.targets, target)
+			delete(p.resolved, target)
+		}
+	}
+	bundleGauge.Update(int64(len(p.bundles)))
+}
+
+// newBlocks returns the blocks of the new chain above its common ancestor with
+// the old one, keyed by number, at the heights bundles are still tracked for.
+func (p *BundlePool) newBlocks(oldHead, newHead *types.Header) map[uint64]*types.Block {
+	if len(p.targets) == 0 {
+		return nil
+	}
+	var (
+		number = newHead.Number.Uint64()
+		limit  = uint64(math.MaxUint64)
+	)
+	for target := range p.targets {
+		limit = min(limit, target)
+	}
+	if number > statusRetention {
+		limit = max(limit, number-statusRetention)
+	}
+	var (
+		blocks   = make(map[uint64]*types.Block)
+		oldHash  = oldHead.Hash()
+		oldNum   = oldHead.Number.Uint64()
+		newBlock = p.chain.GetBlock(newHead.Hash(), number)
+	)
+	for newBlock != nil && newBlock.NumberU64() >= limit {
+		n := newBlock.NumberU64()
+
+		// Step the old chain down to the same height, stopping at the common
+		// ancestor. If the old chain is unknown, no reorg is assumed.
+		for oldNum > n {
+			oldBlock := p.chain.GetBlock(oldHash, oldNum)
+			if oldBlock == nil {
+				break
+			}
+			oldHash, oldNum = oldBlock.ParentHash(), oldNum-1
+		}
+		if oldNum > n || (oldNum == n && oldHash == newBlock.Hash()) {
+			break
+		}
+		blocks[n] = newBlock
+		if n == 0 {
+			break
+		}
+		newBlock = p.chain.GetBlock(newBlock.ParentHash(), n-1)
+	}
+	return blocks
+}
+
+// resolve marks the bundles of the given target either included in the given
+// canonical block, or expired if they are missing from it or the block is nil.
+func (p *BundlePool) resolve(target uint64, hashes []common.Hash, block *types.Block) {
+	var included map[common.Hash]struct{}
+	if block != nil {
+		included = make(map[common.Hash]struct{}, len(block.Transactions()))
+		for _, tx := range block.Transactions() {
+			included[tx.Hash()] = struct{}{}
+		}
+		p.resolved[target] = block.Hash()
+	} else {
+		p.resolved[target] = common.Hash{}
+	}
+	for _, hash := range hashes {
+		status, ok := p.status[hash]
+		if !ok || status.BlockNumber != target {
+			continue // Resubmitted for another target
+		}
+		bundle, ok := p.bundles[hash]
+		if ok {
+			delete(p.bundles, hash)
+			p.finished[hash] = bundle
+		} else if bundle, ok = p.finished[hash]; !ok {
+			continue
+		}
+		if included != nil && containsAll(included, bundle.Txs) {
+			status.State, status.BlockHash, status.Error = BundleIncluded, block.Hash(), ""
+			includedMeter.Mark(1)
+		} else {
+			status.State, status.BlockHash = BundleExpired, common.Hash{}
+			expiredMeter.Mark(1)
+		}
+	}
+}
+
+// reopen moves the resolved bundles of the given target back into the pool as
+// pending, after their target block was reorged out.
+func (p *BundlePool) reopen(target uint64, hashes []common.Hash) {
+	for _, hash := range hashes {
+		status, ok := p.status[hash]
+		if !ok || status.BlockNumber != target {
+			continue
+		}
+		bundle, ok := p.finished[hash]
+		if !ok {
+			continue
+		}
+		delete(p.finished, hash)
+		p.bundles[hash] = bundle
+		p.status[hash] = &BundleStatus{State: BundlePending, BlockNumber: target}
+	}
+}
+
+// containsAll returns whether all the given transactions are in the set.
+func containsAll(set map[common.Hash]struct{}, txs []*types.Transaction) bool {
+	for _, tx := range txs {
+		if _, ok := set[tx.Hash()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// SetGasTip is a no-op, bundles pay the block builder directly and are ranked
+// by their simulated payment instead.
+func (p *BundlePool) SetGasTip(tip *big.Int) {}
+
+// Has always returns false, bundled transactions are kept private.
+func (p *BundlePool) Has(hash common.Hash) bool {
+	return false
+}
+
+// Get always returns nil, bundled transactions are kept private.
+func (p *BundlePool) Get(hash common.Hash) *types.Transaction {
+	return nil
+}
+
+// GetRLP always returns nil, bundled transactions are kept private.
+func (p *BundlePool) GetRLP(hash common.Hash) []byte {
+	return nil
+}
+
+// GetMetadata always returns nil, bundled transactions are kept private.
+func (p *BundlePool) GetMetadata(hash common.Hash) *txpool.TxMetadata {
+	return nil
+}
+
+// GetBlobs always returns nothing, blob transactions cannot be bundled.
+func (p *BundlePool) GetBlobs(vhashes []common.Hash, version byte) ([]*kzg4844.Blob, [][]kzg4844.Proof) {
+	return nil, nil
+}
+
+// ValidateTxBasics checks whether a transaction is valid according to the
+// consensus rules, but does not check state-dependent validation.
+func (p *BundlePool) ValidateTxBasics(tx *types.Transaction) error {
+	p.lock.RLock()
+	head := p.head
+	p.lock.RUnlock()
+
+	return p.validateTx(tx, head)
+}
+
+// validateTx checks a bundled transaction against the stateless rules at the
+// given head.
+func (p *BundlePool) validateTx(tx *types.Transaction, head *types.Header) error {
+	opts := &txpool.ValidationOptions{
+		Config: p.chain.Config(),
+		Accept: 0 |
+			1<<types.LegacyTxType |
+			1<<types.AccessListTxType |
+			1<<types.DynamicFeeTxType |
+			1<<types.SetCodeTxType,
+		MaxSize: txMaxSize,
+		MinTip:  new(big.Int),
+	}
+	return txpool.ValidateTransaction(tx, head, p.signer, opts)
+}
+
+// Add rejects all transactions, bundles can only be added via AddBundle.
+func (p *BundlePool) Add(txs []*types.Transaction, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i, tx := range txs {
+		errs[i] = fmt.Errorf("%w: received type %d", core.ErrTxTypeNotSupported, tx.Type())
+	}
+	return errs
+}
+
+// Pending returns nothing, bundles are not mixed into the regular transaction
+// ordering of the block builder. Use Bundles instead.
+func (p *BundlePool) Pending(filter txpool.PendingFilter) map[common.Address][]*txpool.LazyTransaction {
+	return nil
+}
+
+// SubscribeTransactions returns a subscription that never fires, bundled
+// transactions are not announced.
+func (p *BundlePool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription {
+	return p.txFeed.Subscribe(ch)
+}
+
+// Nonce returns 0, bundles don't influence the pool nonces of their senders.
+func (p *BundlePool) Nonce(addr common.Address) uint64 {
+	return 0
+}
+
+// Stats returns 0 for both pending and queued transactions, bundles are not
+// accounted as regular transactions.
+func (p *BundlePool) Stats() (int, int) {
+	return 0, 0
+}
+
+// Content returns empty maps, bundled transactions are kept private.
+func (p *BundlePool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
+	return make(map[common.Address][]*types.Transaction), make(map[common.Address][]*types.Transaction)
+}
+
+// ContentFrom returns empty lists, bundled transactions are kept private.
+func (p *BundlePool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
+	return []*types.Transaction{}, []*types.Transaction{}
+}
+
+// Status returns unknown for all transactions, use BundleStatus instead.
+func (p *BundlePool) Status(hash common.Hash) txpool.TxStatus {
+	return txpool.TxStatusUnknown
+}
+
+// Clear removes all tracked bundles and statuses from the pool.
+func (p *BundlePool) Clear() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	clear(p.bundles)
+	clear(p.finished)
+	clear(p.status)
+	clear(p.targets)
+	clear(p.resolved)
+	bundleGauge.Update(0)
+}
+
+// AddBundle validates a bundle and inserts it into the pool, returning its hash.
+func (p *BundlePool) AddBundle(bundle *Bundle) (common.Hash, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	hash, err := p.add(bundle)
+	if err != nil {
+		rejectedMeter.Mark(1)
+		return common.Hash{}, err
+	}
+	submittedMeter.Mark(1)
+	bundleGauge.Update(int64(len(p.bundles)))
+	return hash, nil
+}
+
+// add is the internal version of AddBundle, assuming the lock is held.
+func (p *BundlePool) add(bundle *Bundle) (common.Hash, error) {
+	if len(bundle.Txs) == 0 {
+		return common.Hash{}, ErrEmptyBundle
+	}
+	if len(bundle.Txs) > p.config.MaxTxs {
+		return common.Hash{}, fmt.Errorf("%w: %d txs, limit %d", ErrBundleTooLarge, len(bundle.Txs), p.config.MaxTxs)
+	}
+	number := p.head.Number.Uint64()
+	if bundle.BlockNumber <= number {
+		return common.Hash{}, fmt.Errorf("%w: target %d, head %d", ErrBundleStale, bundle.BlockNumber, number)
+	}
+	if bundle.BlockNumber > number+p.config.BlocksAhead {
+		return common.Hash{}, fmt.Errorf("%w: target %d, head %d", ErrBundleTooFar, bundle.BlockNumber, number)
+	}
+	if bundle.MaxTimestamp != 0 && bundle.MinTimestamp > bundle.MaxTimestamp {
+		return common.Hash{}, fmt.Errorf("invalid bundle timestamp range [%d, %d]", bundle.MinTimestamp, bundle.MaxTimestamp)
+	}
+	for i, tx := range bundle.Txs {
+		if err := p.validateTx(tx, p.head); err != nil {
+			return common.Hash{}, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+	}
+	hash := bundle.Hash()
+	if _, ok := p.bundles[hash]; ok {
+		return common.Hash{}, ErrBundleKnown
+	}
+	if len(p.bundles) >= p.config.MaxBundles {
+		return common.Hash{}, ErrBundlePoolFull
+	}
+	// A resubmitted bundle might still have a stale status from an earlier
+	// target, only track the target once.
+	if status, ok := p.status[hash]; !ok || status.BlockNumber != bundle.BlockNumber {
+		p.targets[bundle.BlockNumber] = append(p.targets[bundle.BlockNumber], hash)
+	}
+	delete(p.finished, hash)
+	p.bundles[hash] = bundle
+	p.status[hash] = &BundleStatus{State: BundlePending, BlockNumber: bundle.BlockNumber}
+	return hash, nil
+}
+
+// Bundles returns all the bundles eligible for inclusion into a block with the
+// given number and timestamp.
+func (p *BundlePool) Bundles(number uint64, time uint64) []*Bundle {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	var bundles []*Bundle
+	for _, hash := range p.targets[number] {
+		if bundle, ok := p.bundles[hash]; ok && bundle.Eligible(number, time) {
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles
+}
+
+// Report records the outcome of an attempt to include a bundle into a locally
+// built block. A nil error means the bundle was inserted with the given coinbase
+// payment. Reports for already resolved bundles are ignored.
+func (p *BundlePool) Report(hash common.Hash, payment *big.Int, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.bundles[hash]; !ok {
+		return
+	}
+	status := p.status[hash]
+	if err != nil {
+		status.State, status.Error = BundleFailed, err.Error()
+		return
+	}
+	status.State, status.Payment, status.Error = BundleBuilt, payment, ""
+}
+
+// BundleStatus returns the inclusion status of a bundle, or nil if the bundle
+// is unknown or its status was already dropped.
+func (p *BundlePool) BundleStatus(hash common.Hash) *BundleStatus {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	status, ok := p.status[hash]
+	if !ok {
+		return nil
+	}
+	cpy := *status
+	return &cpy
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundlepool
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// testBlockChain is a mock of the live chain for testing the pool.
+type testBlockChain struct {
+	config *params.ChainConfig
+	blocks map[common.Hash]*types.Block
+}
+
+func (bc *testBlockChain) Config() *params.ChainConfig {
+	return bc.config
+}
+
+func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return bc.blocks[hash]
+}
+
+// makeHeader creates a header with the given number, carrying a base fee so
+// dynamic fee transactions are accepted.
+func makeHeader(number uint64) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: common.Big0,
+		GasLimit:   30_000_000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Time:       number * 12,
+	}
+}
+
+// makeTx creates a signed dynamic fee transaction with the given nonce.
+func makeTx(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+	return types.MustSignNewTx(key, types.LatestSigner(params.TestChainConfig), &types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		Nonce:     nonce,
+		To:        &common.Address{},
+		Gas:       params.TxGas,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(10 * params.GWei),
+	})
+}
+
+func newTestPool(t *testing.T, head *types.Header) (*BundlePool, *testBlockChain) {
+	chain := &testBlockChain{
+		config: params.TestChainConfig,
+		blocks: make(map[common.Hash]*types.Block),
+	}
+	pool := New(Config{Enabled: true, MaxBundles: 2, MaxTxs: 2, BlocksAhead: 4}, chain)
+	if err := pool.Init(0, head, nil); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	return pool, chain
+}
+
+// Tests that bundles are validated against the pool limits and the chain head
+// before being accepted.
+func TestAddBundle(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pool, _ := newTestPool(t, makeHeader(10))
+
+	tests := []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{bundle: &Bundle{BlockNumber: 11}, err: ErrEmptyBundle},
+		{bundle: &Bundle{Txs: []*types.Transaction{makeTx(0, key), makeTx(1, key), makeTx(2, key)}, BlockNumber: 11}, err: ErrBundleTooLarge},
+		{bundle: &Bundle{Txs: []*types.Transaction{makeTx(0, key)}, BlockNumber: 10}, err: ErrBundleStale},
+		{bundle: &Bundle{Txs: []*types.Transaction{makeTx(0, key)}, BlockNumber: 15}, err: ErrBundleTooFar},
+		{bundle: &Bundle{Txs: []*types.Transaction{makeTx(0, key)}, BlockNumber: 11}},
+		{bundle: &Bundle{Txs: []*types.Transaction{makeTx(0, key)}, BlockNumber: 11}, err: ErrBundleKnown},
+		{bundle: &Bundle{Txs: []*types.Transaction{makeTx(1, key)}, BlockNumber: 14}},
+		{bundle: &Bundle{Txs: []*types.Transaction{makeTx(2, key)}, BlockNumber: 12}, err: ErrBundlePoolFull},
+	}
+	for i, tt := range tests {
+		hash, err := pool.AddBundle(tt.bundle)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if err == nil {
+			if status := pool.BundleStatus(hash); status == nil || status.State != BundlePending {
+				t.Errorf("test %d: accepted bundle not pending: %v", i, status)
+			}
+		}
+	}
+}
+
+// Tests that bundles are only handed out for their target block and timestamp
+// range and that they are resolved once their target block is imported.
+func TestBundleLifecycle(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pool, chain := newTestPool(t, makeHeader(10))
+
+	var (
+		landed = &Bundle{Txs: []*types.Transaction{makeTx(0, key)}, BlockNumber: 11, MinTimestamp: 130}
+		missed = &Bundle{Txs: []*types.Transaction{makeTx(1, key)}, BlockNumber: 11}
+	)
+	landedHash, err := pool.AddBundle(landed)
+	if err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	missedHash, err := pool.AddBundle(missed)
+	if err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	if bundles := pool.Bundles(11, 132); len(bundles) != 2 {
+		t.Fatalf("eligible bundle count mismatch: have %d, want 2", len(bundles))
+	}
+	if bundles := pool.Bundles(11, 120); len(bundles) != 1 {
+		t.Fatalf("eligible bundle count before min timestamp mismatch: have %d, want 1", len(bundles))
+	}
+	if bundles := pool.Bundles(12, 144); len(bundles) != 0 {
+		t.Fatalf("eligible bundle count for other block mismatch: have %d, want 0", len(bundles))
+	}
+	pool.Report(landedHash, big.NewInt(1), nil)
+	pool.Report(missedHash, nil, errors.New("nonce too low"))
+
+	if status := pool.BundleStatus(landedHash); status.State != BundleBuilt || status.Payment.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("built bundle status mismatch: %+v", status)
+	}
+	if status := pool.BundleStatus(missedHash); status.State != BundleFailed || status.Error != "nonce too low" {
+		t.Fatalf("failed bundle status mismatch: %+v", status)
+	}
+	// Import the target block with only the first bundle in it
+	header := makeHeader(11)
+	block := types.NewBlock(header, &types.Body{Transactions: landed.Txs}, nil, trie.NewStackTrie(nil))
+	chain.blocks[block.Hash()] = block
+	pool.Reset(makeHeader(10), block.Header())
+
+	if status := pool.BundleStatus(landedHash); status.State != BundleIncluded || status.BlockHash != block.Hash() {
+		t.Fatalf("included bundle status mismatch: %+v", status)
+	}
+	if status := pool.BundleStatus(missedHash); status.State != BundleExpired {
+		t.Fatalf("expired bundle status mismatch: %+v", status)
+	}
+	if bundles := pool.Bundles(11, 132); len(bundles) != 0 {
+		t.Fatalf("resolved bundles still eligible: %d", len(bundles))
+	}
+	// Statuses should be retained for a while and then dropped
+	pool.Reset(block.Header(), makeHeader(11+statusRetention-1))
+	if pool.BundleStatus(landedHash) == nil {
+		t.Fatalf("bundle status dropped before retention window passed")
+	}
+	pool.Reset(block.Header(), makeHeader(11+statusRetention))
+	if pool.BundleStatus(landedHash) != nil || pool.BundleStatus(missedHash) != nil {
+		t.Fatalf("bundle status retained after retention window passed")
+	}
+}
+
+// makeChain creates a chain of blocks on top of the given parent, each block
+// including the transactions at the same index.
+func makeChain(chain *testBlockChain, parent *types.Header, txs [][]*types.Transaction, extra string) []*types.Block {
+	var blocks []*types.Block
+	for _, body := range txs {
+		header := makeHeader(parent.Number.Uint64() + 1)
+		header.ParentHash = parent.Hash()
+		header.Extra = []byte(extra)
+
+		block := types.NewBlock(header, &types.Body{Transactions: body}, nil, trie.NewStackTrie(nil))
+		chain.blocks[block.Hash()] = block
+		blocks = append(blocks, block)
+		parent = block.Header()
+	}
+	return blocks
+}
+
+// Tests that bundles targeting blocks skipped over by a head jump are checked
+// for inclusion instead of being expired blindly.
+func TestBundleHeadJump(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pool, chain := newTestPool(t, makeHeader(10))
+
+	var (
+		landed = &Bundle{Txs: []*types.Transaction{makeTx(0, key)}, BlockNumber: 12}
+		missed = &Bundle{Txs: []*types.Transaction{makeTx(1, key)}, BlockNumber: 11}
+	)
+	landedHash, _ := pool.AddBundle(landed)
+	missedHash, _ := pool.AddBundle(missed)
+
+	blocks := makeChain(chain, makeHeader(10), [][]*types.Transaction{nil, landed.Txs, nil}, "")
+	pool.Reset(makeHeader(10), blocks[2].Header())
+
+	if status := pool.BundleStatus(landedHash); status.State != BundleIncluded || status.BlockHash != blocks[1].Hash() {
+		t.Fatalf("included bundle status mismatch: %+v", status)
+	}
+	if status := pool.BundleStatus(missedHash); status.State != BundleExpired {
+		t.Fatalf("expired bundle status mismatch: %+v", status)
+	}
+}
+
+// Tests that bundles resolved against reorged blocks are re-examined against
+// the new canonical chain, or reopened if their target is above the new head.
+func TestBundleReorg(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	genesis := makeHeader(10)
+	pool, chain := newTestPool(t, genesis)
+
+	var (
+		first  = &Bundle{Txs: []*types.Transaction{makeTx(0, key)}, BlockNumber: 11}
+		second = &Bundle{Txs: []*types.Transaction{makeTx(1, key)}, BlockNumber: 12}
+	)
+	firstHash, _ := pool.AddBundle(first)
+	secondHash, _ := pool.AddBundle(second)
+
+	// Include both bundles on the original chain
+	old := makeChain(chain, genesis, [][]*types.Transaction{first.Txs, second.Txs}, "old")
+	pool.Reset(genesis, old[1].Header())
+
+	for _, hash := range []common.Hash{firstHash, secondHash} {
+		if status := pool.BundleStatus(hash); status.State != BundleIncluded {
+			t.Fatalf("included bundle status mismatch: %+v", status)
+		}
+	}
+	// Reorg to a shorter chain without the first bundle
+	side := makeChain(chain, genesis, [][]*types.Transaction{nil}, "side")
+	pool.Reset(old[1].Header(), side[0].Header())
+
+	if status := pool.BundleStatus(firstHash); status.State != BundleExpired {
+		t.Fatalf("reorged bundle status mismatch: %+v", status)
+	}
+	if status := pool.BundleStatus(secondHash); status.State != BundlePending {
+		t.Fatalf("reopened bundle status mismatch: %+v", status)
+	}
+	if bundles := pool.Bundles(12, 144); len(bundles) != 1 || bundles[0].Hash() != secondHash {
+		t.Fatalf("reopened bundle not eligible: %v", bundles)
+	}
+	// Reorg back to the original chain, the bundles must be included again
+	pool.Reset(side[0].Header(), old[1].Header())
+
+	if status := pool.BundleStatus(firstHash); status.State != BundleIncluded || status.BlockHash != old[0].Hash() {
+		t.Fatalf("re-included bundle status mismatch: %+v", status)
+	}
+	if status := pool.BundleStatus(secondHash); status.State != BundleIncluded || status.BlockHash != old[1].Hash() {
+		t.Fatalf("re-included bundle status mismatch: %+v", status)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
@@ -151,6 +152,8 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	Throttle ThrottleConfig // Per sender and per contract gas throttling of promotions
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -167,6 +170,8 @@ var DefaultConfig = Config{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	Throttle: DefaultThrottleConfig,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -245,6 +250,8 @@ type LegacyPool struct {
 	all     *lookup                      // All transactions to allow lookups
 	priced  *pricedList                  // All transactions sorted by price
 
+	throttle *throttler // Gas budget enforcer for queued transaction promotion
+
 	reqResetCh      chan *txpoolResetRequest
 	reqPromoteCh    chan *accountSet
 	queueTxEventCh  chan *types.Transaction
@@ -284,6 +291,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		initDoneCh:      make(chan struct{}),
 	}
 	pool.priced = newPricedList(pool.all)
+	pool.throttle = newThrottler(config.Throttle, mclock.System{})
 
 	return pool
 }
@@ -381,6 +389,9 @@ func (pool *LegacyPool) loop() {
 				}
 			}
 			pool.mu.Unlock()
+
+			// Drop any idle gas budgets from the throttler
+			pool.throttle.cleanup()
 		}
 	}
 }
@@ -802,6 +813,11 @@ func (pool *LegacyPool) add(tx *types.Transaction) (replaced bool, err error) {
 	if err != nil {
 		return false, err
 	}
+	// Count it against its sender and destination if they're already over budget.
+	// Promotion retries never do, otherwise a delayed backlog would get penalized.
+	if pool.throttle.active() {
+		pool.throttle.check(from, pool.throttleContract(tx), tx.Gas())
+	}
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replaced, nil
@@ -1065,7 +1081,7 @@ func (pool *LegacyPool) GetMetadata(hash common.Hash) *txpool.TxMetadata {
 
 // GetBlobs is not supported by the legacy transaction pool, it is just here to
 // implement the txpool.SubPool interface.
-func (pool *LegacyPool) GetBlobs(vhashes []common.Hash) ([]*kzg4844.Blob, []*kzg4844.Proof) {
+func (pool *LegacyPool) GetBlobs(vhashes []common.Hash, version byte) ([]*kzg4844.Blob, [][]kzg4844.Proof) {
 	return nil, nil
 }
 
@@ -1144,6 +1160,16 @@ func (pool *LegacyPool) removeTx(hash common.Hash, outofbound bool, unreserve bo
 	return 0
 }
 
+// RemoveTx removes a single transaction from the pool, moving all subsequent
+// transactions of the same account back to the future queue. It returns whether
+// the transaction was found in the pool.
+func (pool *LegacyPool) RemoveTx(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.removeTx(hash, true, true) > 0
+}
+
 // requestReset requests a pool reset to the new head block.
 // The returned channel is closed when the reset has occurred.
 func (pool *LegacyPool) requestReset(oldHead *types.Header, newHead *types.Header) chan struct{} {
@@ -1454,9 +1480,21 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
 
-		// Gather all executable transactions and promote them
+		// Gather all executable transactions and promote them, holding back any
+		// which would exceed the gas budget of their sender or destination
 		readies := list.Ready(pool.pendingNonces.get(addr))
-		for _, tx := range readies {
+		for i, tx := range readies {
+			if pool.throttled(addr, tx) {
+				delayed := readies[i:]
+				for _, tx := range delayed {
+					list.Add(tx, pool.config.PriceBump)
+				}
+				throttleDelayMeter.Mark(int64(len(delayed)))
+				log.Trace("Delayed throttled queued transactions", "from", addr, "count", len(delayed))
+
+				readies = readies[:i]
+				break
+			}
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
 				promoted = append(promoted, tx)
@@ -1489,6 +1527,29 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 	return promoted
 }
 
+// throttled reports whether promoting the given transaction would exceed the gas
+// budget of its sender or destination contract. If not, the transaction's gas is
+// deducted from the budgets.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) throttled(from common.Address, tx *types.Transaction) bool {
+	if !pool.throttle.active() {
+		return false
+	}
+	return !pool.throttle.allow(from, pool.throttleContract(tx), tx.Gas())
+}
+
+// throttleContract returns the destination of the transaction if it's a contract
+// call, tracked by the gas throttler, or nil otherwise.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) throttleContract(tx *types.Transaction) *common.Address {
+	if to := tx.To(); to != nil && pool.currentState.GetCodeSize(*to) > 0 {
+		return to
+	}
+	return nil
+}
+
 // truncatePending removes transactions from the pending queue if the pool is above the
 // pending limit. The algorithm tries to reduce transaction counts by an approximately
 // equal number for all for accounts with many pending transactions.
@@ -0,0 +1,453 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// throttleDelayMeter counts the transactions held back in the queue due to
+	// their sender or destination running out of gas budget.
+	throttleDelayMeter = metrics.NewRegisteredMeter("txpool/gasthrottle/delayed", nil)
+
+	// throttlePenaltyMeter counts the number of offenders put into the penalty box.
+	throttlePenaltyMeter = metrics.NewRegisteredMeter("txpool/gasthrottle/penalized", nil)
+
+	// throttleBoxedGauge tracks the number of offenders currently in the penalty box.
+	throttleBoxedGauge = metrics.NewRegisteredGauge("txpool/gasthrottle/boxed", nil)
+
+	// throttleTrackedGauge tracks the number of senders and contracts with a
+	// non-idle gas budget.
+	throttleTrackedGauge = metrics.NewRegisteredGauge("txpool/gasthrottle/tracked", nil)
+)
+
+// ThrottleConfig are the gas throttling parameters of the transaction pool. The
+// throttler limits the rate at which transactions are promoted from the queue
+// into the executable set, both per sender and per destination contract. Txs
+// over budget are kept in the queue and retried on subsequent promotions. New
+// txs arriving while their sender or destination is over budget count as
+// violations, eventually penalizing the offender.
+type ThrottleConfig struct {
+	SenderGas   uint64        // Gas per second promotable from a single sender (0 = unlimited)
+	ContractGas uint64        // Gas per second promotable towards a single contract (0 = unlimited)
+	Burst       time.Duration // Time window of unused budget allowed to accumulate
+
+	PenaltyStrikes uint64        // Number of new txs over budget before an offender is penalized (0 = never)
+	PenaltyTime    time.Duration // Time an offender spends in the penalty box
+}
+
+// DefaultThrottleConfig contains the default gas throttling configurations, with
+// throttling disabled.
+var DefaultThrottleConfig = ThrottleConfig{
+	Burst:          10 * time.Second,
+	PenaltyStrikes: 16,
+	PenaltyTime:    5 * time.Minute,
+}
+
+// enabled returns whether any gas budget is enforced.
+func (config *ThrottleConfig) enabled() bool {
+	return config.SenderGas != 0 || config.ContractGas != 0
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *ThrottleConfig) sanitize() ThrottleConfig {
+	conf := *config
+	if conf.Burst < time.Second {
+		if conf.enabled() {
+			log.Warn("Sanitizing invalid txpool throttle burst", "provided", conf.Burst, "updated", DefaultThrottleConfig.Burst)
+		}
+		conf.Burst = DefaultThrottleConfig.Burst
+	}
+	if conf.PenaltyStrikes != 0 && conf.PenaltyTime <= 0 {
+		if conf.enabled() {
+			log.Warn("Sanitizing invalid txpool throttle penalty", "provided", conf.PenaltyTime, "updated", DefaultThrottleConfig.PenaltyTime)
+		}
+		conf.PenaltyTime = DefaultThrottleConfig.PenaltyTime
+	}
+	return conf
+}
+
+// ThrottleKind is the type of entity a gas budget is tracked for.
+type ThrottleKind string
+
+const (
+	ThrottleSender   ThrottleKind = "sender"   // Budget of a transaction sender
+	ThrottleContract ThrottleKind = "contract" // Budget of a transaction destination contract
+)
+
+// throttleKey identifies a tracked gas budget.
+type throttleKey struct {
+	kind ThrottleKind
+	addr common.Address
+}
+
+// gasBudget is a token bucket tracking the gas spending of a single entity.
+type gasBudget struct {
+	tokens  float64        // Gas currently available, may go negative for oversized txs
+	updated mclock.AbsTime // Time of the last refill
+	strikes uint64         // Number of budget violations since the budget was last full
+	boxed   mclock.AbsTime // Time until which the entity is penalized
+}
+
+// ThrottleStatus is the runtime status of a single tracked gas budget.
+type ThrottleStatus struct {
+	Kind      ThrottleKind   `json:"kind"`
+	Address   common.Address `json:"address"`
+	Rate      uint64         `json:"rate"`
+	Available int64          `json:"available"`
+	Strikes   uint64         `json:"strikes"`
+	BoxedFor  time.Duration  `json:"boxedFor"`
+}
+
+// throttler enforces per sender and per contract gas budgets on transaction
+// promotion. It is safe for concurrent use, which allows inspecting and updating
+// it without holding the pool lock.
+type throttler struct {
+	config    ThrottleConfig
+	overrides map[throttleKey]uint64 // Custom rates for specific entities (0 = unlimited)
+	budgets   map[throttleKey]*gasBudget
+	clock     mclock.Clock
+	lock      sync.Mutex
+}
+
+// newThrottler creates a gas throttler with the given configuration.
+func newThrottler(config ThrottleConfig, clock mclock.Clock) *throttler {
+	return &throttler{
+		config:    (&config).sanitize(),
+		overrides: make(map[throttleKey]uint64),
+		budgets:   make(map[throttleKey]*gasBudget),
+		clock:     clock,
+	}
+}
+
+// rate returns the gas per second allowed for the given entity.
+//
+// The caller must hold the throttler lock.
+func (t *throttler) rate(key throttleKey) uint64 {
+	if rate, ok := t.overrides[key]; ok {
+		return rate
+	}
+	if key.kind == ThrottleSender {
+		return t.config.SenderGas
+	}
+	return t.config.ContractGas
+}
+
+// budget retrieves the gas budget of the given entity, refilled up to the current
+// time. Nil is returned for unlimited entities.
+//
+// The caller must hold the throttler lock.
+func (t *throttler) budget(key throttleKey, now mclock.AbsTime) *gasBudget {
+	rate := t.rate(key)
+	if rate == 0 {
+		return nil
+	}
+	capacity := float64(rate) * t.config.Burst.Seconds()
+
+	b := t.budgets[key]
+	if b == nil {
+		b = &gasBudget{tokens: capacity, updated: now}
+		t.budgets[key] = b
+		return b
+	}
+	b.tokens += float64(rate) * time.Duration(now-b.updated).Seconds()
+	b.updated = now
+	if b.tokens >= capacity {
+		b.tokens = capacity
+		b.strikes = 0
+	}
+	return b
+}
+
+// active returns whether any gas budget is enforced, either globally or for a
+// specific entity.
+func (t *throttler) active() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.config.enabled() || len(t.overrides) > 0
+}
+
+// entities retrieves the keys and the budgets of a transaction's sender and
+// destination contract (nil if not a contract call).
+//
+// The caller must hold the throttler lock.
+func (t *throttler) entities(sender common.Address, contract *common.Address, now mclock.AbsTime) ([]throttleKey, []*gasBudget) {
+	var (
+		keys    = []throttleKey{{ThrottleSender, sender}}
+		budgets = []*gasBudget{t.budget(keys[0], now)}
+	)
+	if contract != nil {
+		keys = append(keys, throttleKey{ThrottleContract, *contract})
+		budgets = append(budgets, t.budget(keys[1], now))
+	}
+	return keys, budgets
+}
+
+// exceeds returns whether a transaction with the given gas limit exceeds the
+// available budget of an entity. A tx larger than the entire burst allowance
+// fits into a full budget, otherwise it would be stuck forever.
+//
+// The caller must hold the throttler lock.
+func (t *throttler) exceeds(key throttleKey, b *gasBudget, gas uint64) bool {
+	capacity := float64(t.rate(key)) * t.config.Burst.Seconds()
+	return b.tokens < min(float64(gas), capacity)
+}
+
+// allow checks whether a transaction with the given gas limit fits into the
+// budgets of its sender and destination contract (nil if not a contract call),
+// deducting it from both if so. Rejections are not counted as violations, as
+// delayed transactions are retried on every promotion.
+func (t *throttler) allow(sender common.Address, contract *common.Address, gas uint64) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Now()
+	keys, budgets := t.entities(sender, contract, now)
+
+	// Reject the transaction if any of the entities are in the penalty box or
+	// if it exceeds the available budget of any of them
+	for i, b := range budgets {
+		if b != nil && (b.boxed > now || t.exceeds(keys[i], b, gas)) {
+			return false
+		}
+	}
+	for _, b := range budgets {
+		if b != nil {
+			b.tokens -= float64(gas)
+		}
+	}
+	return true
+}
+
+// check records a budget violation for the sender and destination contract (nil
+// if not a contract call) of a newly arrived transaction if it exceeds their
+// currently available budgets, boxing repeat offenders. Nothing is deducted.
+func (t *throttler) check(sender common.Address, contract *common.Address, gas uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Now()
+	keys, budgets := t.entities(sender, contract, now)
+	for i, b := range budgets {
+		if b != nil && b.boxed <= now && t.exceeds(keys[i], b, gas) {
+			t.strike(keys[i], b, now)
+		}
+	}
+}
+
+// strike records a budget violation of an entity, boxing it if it's a repeat
+// offender.
+//
+// The caller must hold the throttler lock.
+func (t *throttler) strike(key throttleKey, b *gasBudget, now mclock.AbsTime) {
+	if t.config.PenaltyStrikes == 0 {
+		return
+	}
+	if b.strikes++; b.strikes < t.config.PenaltyStrikes {
+		return
+	}
+	b.strikes = 0
+	b.boxed = now.Add(t.config.PenaltyTime)
+
+	throttlePenaltyMeter.Mark(1)
+	log.Debug("Penalized txpool gas spammer", "kind", key.kind, "addr", key.addr, "duration", t.config.PenaltyTime)
+}
+
+// cleanup drops all the tracked budgets that are fully replenished and not in
+// the penalty box, also updating the throttling metrics.
+func (t *throttler) cleanup() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var (
+		now   = t.clock.Now()
+		boxed int64
+	)
+	for key := range t.budgets {
+		b := t.budget(key, now)
+		if b == nil {
+			delete(t.budgets, key) // rate changed to unlimited
+			continue
+		}
+		if b.boxed > now {
+			boxed++
+			continue
+		}
+		if b.strikes == 0 && b.tokens >= float64(t.rate(key))*t.config.Burst.Seconds() {
+			delete(t.budgets, key)
+		}
+	}
+	throttleBoxedGauge.Update(boxed)
+	throttleTrackedGauge.Update(int64(len(t.budgets)))
+}
+
+// status returns the runtime status of all the tracked gas budgets.
+func (t *throttler) status() []ThrottleStatus {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var (
+		now    = t.clock.Now()
+		status = make([]ThrottleStatus, 0, len(t.budgets))
+	)
+	for key := range t.budgets {
+		b := t.budget(key, now)
+		if b == nil {
+			continue
+		}
+		var boxed time.Duration
+		if b.boxed > now {
+			boxed = time.Duration(b.boxed - now)
+		}
+		status = append(status, ThrottleStatus{
+			Kind:      key.kind,
+			Address:   key.addr,
+			Rate:      t.rate(key),
+			Available: int64(b.tokens),
+			Strikes:   b.strikes,
+			BoxedFor:  boxed,
+		})
+	}
+	slices.SortFunc(status, func(a, b ThrottleStatus) int {
+		if a.Kind != b.Kind {
+			if a.Kind < b.Kind {
+				return -1
+			}
+			return 1
+		}
+		return a.Address.Cmp(b.Address)
+	})
+	return status
+}
+
+// setConfig replaces the throttling configuration. Already tracked budgets are
+// retained, but are subject to the new rates from now on.
+func (t *throttler) setConfig(config ThrottleConfig) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.config = (&config).sanitize()
+}
+
+// getConfig returns the current throttling configuration.
+func (t *throttler) getConfig() ThrottleConfig {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.config
+}
+
+// setOverride sets a custom gas rate for a specific entity, with 0 meaning no
+// limit at all. A nil rate removes any previously set override.
+func (t *throttler) setOverride(kind ThrottleKind, addr common.Address, rate *uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := throttleKey{kind, addr}
+	if rate == nil {
+		delete(t.overrides, key)
+	} else {
+		t.overrides[key] = *rate
+	}
+	delete(t.budgets, key) // start from a clean budget
+}
+
+// overrideList returns the custom gas rates set for specific entities.
+func (t *throttler) overrideList() []ThrottleStatus {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	list := make([]ThrottleStatus, 0, len(t.overrides))
+	for key, rate := range t.overrides {
+		list = append(list, ThrottleStatus{Kind: key.kind, Address: key.addr, Rate: rate})
+	}
+	return list
+}
+
+// release removes an entity from the penalty box and resets its budget.
+func (t *throttler) release(kind ThrottleKind, addr common.Address) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := throttleKey{kind, addr}
+	if _, ok := t.budgets[key]; !ok {
+		return false
+	}
+	delete(t.budgets, key)
+	return true
+}
+
+// ThrottleConfig returns the current gas throttling configuration of the pool.
+func (pool *LegacyPool) ThrottleConfig() ThrottleConfig {
+	return pool.throttle.getConfig()
+}
+
+// SetThrottleConfig updates the gas throttling configuration of the pool. Txs
+// delayed by a previous configuration are reconsidered on the next promotion.
+func (pool *LegacyPool) SetThrottleConfig(config ThrottleConfig) {
+	pool.throttle.setConfig(config)
+	log.Info("Transaction pool gas throttling updated", "sender", config.SenderGas, "contract", config.ContractGas)
+}
+
+// Throttles returns the status of all the senders and contracts currently being
+// tracked by the gas throttler, including the ones in the penalty box.
+func (pool *LegacyPool) Throttles() []ThrottleStatus {
+	return pool.throttle.status()
+}
+
+// ThrottleOverrides returns the custom gas rates set for specific senders and
+// contracts.
+func (pool *LegacyPool) ThrottleOverrides() []ThrottleStatus {
+	return pool.throttle.overrideList()
+}
+
+// SetThrottleOverride sets a custom gas rate for a specific sender or contract,
+// with 0 meaning unlimited. A nil rate reverts to the configured default.
+func (pool *LegacyPool) SetThrottleOverride(kind ThrottleKind, addr common.Address, rate *uint64) error {
+	if kind != ThrottleSender && kind != ThrottleContract {
+		return fmt.Errorf("unknown throttle kind %q", kind)
+	}
+	pool.throttle.setOverride(kind, addr, rate)
+	return nil
+}
+
+// ReleaseThrottle removes a sender or contract from the penalty box and resets
+// its gas budget. For senders, promotion of their queued transactions is also
+// rescheduled.
+func (pool *LegacyPool) ReleaseThrottle(kind ThrottleKind, addr common.Address) (bool, error) {
+	if kind != ThrottleSender && kind != ThrottleContract {
+		return false, fmt.Errorf("unknown throttle kind %q", kind)
+	}
+	if !pool.throttle.release(kind, addr) {
+		return false, nil
+	}
+	if kind == ThrottleSender {
+		pool.requestPromoteExecutables(newAccountSet(pool.signer, addr))
+	}
+	return true, nil
+}
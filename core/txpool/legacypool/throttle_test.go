@@ -0,0 +1,221 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that the throttler enforces the sender and contract gas budgets and
+// refills them over time.
+func TestThrottlerBudgets(t *testing.T) {
+	var (
+		clock    = new(mclock.Simulated)
+		sender1  = common.Address{0x01}
+		sender2  = common.Address{0x02}
+		contract = common.Address{0xcc}
+	)
+	throttle := newThrottler(ThrottleConfig{
+		SenderGas:   100_000,
+		ContractGas: 150_000,
+		Burst:       time.Second,
+	}, clock)
+
+	// The first sender can spend its full budget, but not more
+	if !throttle.allow(sender1, &contract, 100_000) {
+		t.Fatalf("sender 1: full budget rejected")
+	}
+	if throttle.allow(sender1, nil, 1) {
+		t.Fatalf("sender 1: exhausted budget accepted")
+	}
+	// The second sender is limited by the remaining contract budget
+	if throttle.allow(sender2, &contract, 60_000) {
+		t.Fatalf("sender 2: exhausted contract budget accepted")
+	}
+	if !throttle.allow(sender2, nil, 60_000) {
+		t.Fatalf("sender 2: non-contract transfer rejected")
+	}
+	// After a refill, budgets are available again but capped to the burst
+	clock.Run(10 * time.Second)
+	if !throttle.allow(sender1, &contract, 100_000) {
+		t.Fatalf("sender 1: refilled budget rejected")
+	}
+	if throttle.allow(sender1, nil, 1) {
+		t.Fatalf("sender 1: budget refilled above burst")
+	}
+	// Transactions larger than the burst are accepted on a full budget
+	clock.Run(time.Second)
+	if !throttle.allow(sender1, nil, 500_000) {
+		t.Fatalf("sender 1: oversized transaction rejected on full budget")
+	}
+	clock.Run(time.Second)
+	if throttle.allow(sender1, nil, 1) {
+		t.Fatalf("sender 1: budget debt not repaid")
+	}
+}
+
+// Tests that repeat offenders are put into the penalty box and can be released.
+func TestThrottlerPenalty(t *testing.T) {
+	var (
+		clock  = new(mclock.Simulated)
+		sender = common.Address{0x01}
+	)
+	throttle := newThrottler(ThrottleConfig{
+		SenderGas:      100_000,
+		Burst:          time.Second,
+		PenaltyStrikes: 3,
+		PenaltyTime:    time.Minute,
+	}, clock)
+
+	throttle.allow(sender, nil, 100_000)
+	for i := 0; i < 10; i++ {
+		if throttle.allow(sender, nil, 100_000) {
+			t.Fatalf("retry %d: exhausted budget accepted", i)
+		}
+	}
+	// Retries alone must not count as violations, only new transactions
+	if status := throttle.status(); len(status) != 1 || status[0].Strikes != 0 {
+		t.Fatalf("retries counted as strikes: %+v", status)
+	}
+	for i := 0; i < 3; i++ {
+		throttle.check(sender, nil, 100_000)
+	}
+	// The budget refilled, but the sender is boxed
+	clock.Run(2 * time.Second)
+	if throttle.allow(sender, nil, 1) {
+		t.Fatalf("boxed sender accepted")
+	}
+	status := throttle.status()
+	if len(status) != 1 || status[0].Address != sender || status[0].BoxedFor == 0 {
+		t.Fatalf("penalty box status mismatch: %+v", status)
+	}
+	// Once the penalty expires the sender can proceed
+	clock.Run(time.Minute)
+	if !throttle.allow(sender, nil, 1) {
+		t.Fatalf("released sender rejected")
+	}
+	// Manual release also clears the penalty
+	throttle.allow(sender, nil, 100_000)
+	for i := 0; i < 3; i++ {
+		throttle.check(sender, nil, 100_000)
+	}
+	if throttle.allow(sender, nil, 1) {
+		t.Fatalf("re-boxed sender accepted")
+	}
+	if !throttle.release(ThrottleSender, sender) {
+		t.Fatalf("failed to release boxed sender")
+	}
+	if !throttle.allow(sender, nil, 1) {
+		t.Fatalf("manually released sender rejected")
+	}
+	// Overrides exempt the sender entirely
+	unlimited := uint64(0)
+	throttle.setOverride(ThrottleSender, sender, &unlimited)
+	for i := 0; i < 10; i++ {
+		throttle.check(sender, nil, 100_000)
+		if !throttle.allow(sender, nil, 100_000) {
+			t.Fatalf("exempt sender rejected")
+		}
+	}
+}
+
+// Tests that transactions over the gas budget of their sender are delayed in
+// the queue instead of being dropped, and promoted once the budget refills.
+func TestThrottledPromotion(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	clock := new(mclock.Simulated)
+	pool.throttle = newThrottler(ThrottleConfig{SenderGas: 100_000, Burst: time.Second}, clock)
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	for i := uint64(0); i < 3; i++ {
+		if err := pool.addRemoteSync(transaction(i, 100_000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 2 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 1/2", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Refill the budget and retry the promotion
+	clock.Run(time.Second)
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, from))
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 2/1", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that a queued transaction over budget, retried on every promotion, only
+// counts as a single violation and doesn't get its sender penalized.
+func TestThrottledRetriesNotPenalized(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	clock := new(mclock.Simulated)
+	pool.throttle = newThrottler(ThrottleConfig{
+		SenderGas:      100_000,
+		Burst:          time.Second,
+		PenaltyStrikes: 3,
+		PenaltyTime:    time.Minute,
+	}, clock)
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	for i := uint64(0); i < 2; i++ {
+		if err := pool.addRemoteSync(transaction(i, 100_000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	// Retry the promotion of the delayed transaction many times
+	for i := 0; i < 10; i++ {
+		<-pool.requestPromoteExecutables(newAccountSet(pool.signer, from))
+	}
+	status := pool.Throttles()
+	if len(status) != 1 || status[0].Strikes != 1 || status[0].BoxedFor != 0 {
+		t.Fatalf("throttle status mismatch: %+v", status)
+	}
+	// Once the budget refills, the delayed transaction must be promoted
+	clock.Run(time.Second)
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, from))
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
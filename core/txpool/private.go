@@ -0,0 +1,148 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// DefaultPrivateTxLifetime is the default number of blocks a private transaction
+// is kept in the pool while waiting for inclusion.
+const DefaultPrivateTxLifetime = 25
+
+var (
+	// ErrPrivateBlobTx is returned if a blob transaction is submitted privately.
+	// Blob transactions are kept in a dedicated pool which doesn't support being
+	// excluded from propagation.
+	ErrPrivateBlobTx = errors.New("blob transactions cannot be private")
+
+	// ErrPrivateTxKnown is returned if a transaction is submitted privately, but
+	// it was already known to the pool (and might have been propagated).
+	ErrPrivateTxKnown = errors.New("transaction already known publicly")
+)
+
+var (
+	privateGauge = metrics.NewRegisteredGauge("txpool/private", nil)
+	expiredMeter = metrics.NewRegisteredMeter("txpool/private/expired", nil)
+	privateMeter = metrics.NewRegisteredMeter("txpool/private/added", nil)
+)
+
+// remover is implemented by subpools capable of removing transactions on request,
+// which is needed to expire private transactions that were not included.
+type remover interface {
+	// RemoveTx removes a transaction from the pool, returning whether it was
+	// found.
+	RemoveTx(hash common.Hash) bool
+}
+
+// privateSet tracks the transactions submitted privately, which must never be
+// propagated to the network, along with their inclusion deadlines.
+type privateSet struct {
+	deadlines map[common.Hash]uint64 // Block number after which a private tx is dropped
+	lock      sync.RWMutex
+}
+
+// newPrivateSet creates an empty private transaction tracker.
+func newPrivateSet() *privateSet {
+	return &privateSet{deadlines: make(map[common.Hash]uint64)}
+}
+
+// AddPrivate adds a transaction to the pool, marking it private. Private txs are
+// not propagated to the network nor revealed over public APIs, and are dropped
+// from the pool if not included within the given number of blocks.
+//
+// Private transactions are not guaranteed to stay private across a node restart.
+func (p *TxPool) AddPrivate(tx *types.Transaction, lifetime uint64) error {
+	if tx.Type() == types.BlobTxType {
+		return ErrPrivateBlobTx
+	}
+	hash := tx.Hash()
+	if p.Has(hash) {
+		return ErrPrivateTxKnown
+	}
+	// Mark the transaction private before adding it, otherwise the new tx event
+	// might be picked up by the broadcaster before it's marked.
+	deadline := p.chain.CurrentBlock().Number.Uint64() + lifetime
+
+	p.private.lock.Lock()
+	p.private.deadlines[hash] = deadline
+	privateGauge.Update(int64(len(p.private.deadlines)))
+	p.private.lock.Unlock()
+
+	if err := p.Add([]*types.Transaction{tx}, false)[0]; err != nil {
+		p.private.lock.Lock()
+		delete(p.private.deadlines, hash)
+		privateGauge.Update(int64(len(p.private.deadlines)))
+		p.private.lock.Unlock()
+		return err
+	}
+	privateMeter.Mark(1)
+	return nil
+}
+
+// IsPrivate returns whether a transaction was submitted privately and should
+// not be propagated or revealed.
+func (p *TxPool) IsPrivate(hash common.Hash) bool {
+	p.private.lock.RLock()
+	defer p.private.lock.RUnlock()
+
+	_, ok := p.private.deadlines[hash]
+	return ok
+}
+
+// expirePrivate drops all the private transactions from the pool whose inclusion
+// deadline passed. The private markers are retained until the deadline, even if
+// the transaction was included, so that a reorg cannot leak it to the network.
+func (p *TxPool) expirePrivate(head *types.Header) {
+	var expired []common.Hash
+
+	p.private.lock.Lock()
+	for hash, deadline := range p.private.deadlines {
+		if head.Number.Uint64() > deadline {
+			expired = append(expired, hash)
+		}
+	}
+	if len(expired) == 0 {
+		p.private.lock.Unlock()
+		return
+	}
+	// Drop any expired transactions still pooled, while they are still marked
+	// private, only then forget about them.
+	var dropped int
+	for _, hash := range expired {
+		for _, subpool := range p.subpools {
+			if r, ok := subpool.(remover); ok && r.RemoveTx(hash) {
+				dropped++
+				break
+			}
+		}
+		delete(p.private.deadlines, hash)
+	}
+	privateGauge.Update(int64(len(p.private.deadlines)))
+	p.private.lock.Unlock()
+
+	if dropped > 0 {
+		expiredMeter.Mark(int64(dropped))
+		log.Debug("Dropped expired private transactions", "count", dropped)
+	}
+}
@@ -71,6 +71,8 @@ type TxPool struct {
 	stateLock sync.RWMutex   // The lock for protecting state instance
 	state     *state.StateDB // Current state at the blockchain head
 
+	private *privateSet // Transactions submitted privately, excluded from propagation
+
 	subs event.SubscriptionScope // Subscription scope to unsubscribe all on shutdown
 	quit chan chan error         // Quit channel to tear down the head updater
 	term chan struct{}           // Termination channel to detect a closed pool
@@ -101,6 +103,7 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 		chain:    chain,
 		signer:   types.LatestSigner(chain.Config()),
 		state:    statedb,
+		private:  newPrivateSet(),
 		quit:     make(chan chan error),
 		term:     make(chan struct{}),
 		sync:     make(chan chan error),
@@ -199,6 +202,7 @@ func (p *TxPool) loop(head *types.Header) {
 					for _, subpool := range p.subpools {
 						subpool.Reset(oldHead, newHead)
 					}
+					p.expirePrivate(newHead)
 					select {
 					case resetDone <- newHead:
 					case <-p.term:
@@ -306,16 +310,17 @@ func (p *TxPool) GetMetadata(hash common.Hash) *TxMetadata {
 	return nil
 }
 
-// GetBlobs returns a number of blobs are proofs for the given versioned hashes.
-// This is a utility method for the engine API, enabling consensus clients to
-// retrieve blobs from the pools directly instead of the network.
-func (p *TxPool) GetBlobs(vhashes []common.Hash) ([]*kzg4844.Blob, []*kzg4844.Proof) {
+// GetBlobs returns a number of blobs and proofs for the given versioned hashes,
+// with the proofs in the format of the requested sidecar version. This is a
+// utility method for the engine API, enabling consensus clients to retrieve
+// blobs from the pools directly instead of the network.
+func (p *TxPool) GetBlobs(vhashes []common.Hash, version byte) ([]*kzg4844.Blob, [][]kzg4844.Proof) {
 	for _, subpool := range p.subpools {
 		// It's an ugly to assume that only one pool will be capable of returning
 		// anything meaningful for this call, but anythingh else requires merging
 		// partial responses and that's too annoying to do until we get a second
 		// blobpool (probably never).
-		if blobs, proofs := subpool.GetBlobs(vhashes); blobs != nil {
+		if blobs, proofs := subpool.GetBlobs(vhashes, version); blobs != nil {
 			return blobs, proofs
 		}
 	}
@@ -530,4 +535,8 @@ func (p *TxPool) Clear() {
 	for _, subpool := range p.subpools {
 		subpool.Clear()
 	}
+	p.private.lock.Lock()
+	clear(p.private.deadlines)
+	privateGauge.Update(0)
+	p.private.lock.Unlock()
 }
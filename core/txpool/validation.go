@@ -169,18 +169,30 @@ func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) err
 	if len(sidecar.Blobs) != len(hashes) {
 		return fmt.Errorf("invalid number of %d blobs compared to %d blob hashes", len(sidecar.Blobs), len(hashes))
 	}
-	if len(sidecar.Proofs) != len(hashes) {
-		return fmt.Errorf("invalid number of %d blob proofs compared to %d blob hashes", len(sidecar.Proofs), len(hashes))
-	}
 	if err := sidecar.ValidateBlobCommitmentHashes(hashes); err != nil {
 		return err
 	}
 	// Blob commitments match with the hashes in the transaction, verify the
 	// blobs themselves via KZG
-	for i := range sidecar.Blobs {
-		if err := kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
-			return fmt.Errorf("invalid blob %d: %v", i, err)
+	switch sidecar.Version {
+	case types.BlobSidecarVersion0:
+		if len(sidecar.Proofs) != len(hashes) {
+			return fmt.Errorf("invalid number of %d blob proofs compared to %d blob hashes", len(sidecar.Proofs), len(hashes))
+		}
+		for i := range sidecar.Blobs {
+			if err := kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
+				return fmt.Errorf("invalid blob %d: %v", i, err)
+			}
+		}
+	case types.BlobSidecarVersion1:
+		if len(sidecar.Proofs) != len(hashes)*kzg4844.CellProofsPerBlob {
+			return fmt.Errorf("invalid number of %d cell proofs compared to %d blob hashes", len(sidecar.Proofs), len(hashes))
+		}
+		if err := kzg4844.VerifyCellProofs(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+			return fmt.Errorf("invalid blobs: %v", err)
 		}
+	default:
+		return fmt.Errorf("unsupported blob sidecar version %d", sidecar.Version)
 	}
 	return nil
 }
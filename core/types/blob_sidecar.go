@@ -0,0 +1,48 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+//go:generate go run github.com/fjl/gencodec -type BlobSidecar -field-override blobSidecarMarshaling -out gen_blob_sidecar_json.go
+
+// BlobSidecar is a single blob of an included blob transaction, together with
+// its KZG commitment, proofs and inclusion information. It mirrors the blob
+// sidecar of the consensus layer, with the proofs being either a single blob
+// proof or the cell proofs of the blob, depending on the archived format.
+type BlobSidecar struct {
+	Index         uint64             `json:"index"             gencodec:"required"` // Index of the blob within the block
+	Blob          *kzg4844.Blob      `json:"blob"              gencodec:"required"`
+	Commitment    kzg4844.Commitment `json:"kzgCommitment"     gencodec:"required"`
+	Proofs        []kzg4844.Proof    `json:"kzgProofs"         gencodec:"required"`
+	VersionedHash common.Hash        `json:"blobVersionedHash" gencodec:"required"`
+
+	// Inclusion information
+	TxHash      common.Hash `json:"transactionHash" gencodec:"required"`
+	BlockHash   common.Hash `json:"blockHash"       gencodec:"required"`
+	BlockNumber uint64      `json:"blockNumber"     gencodec:"required"`
+}
+
+// field type overrides for gencodec
+type blobSidecarMarshaling struct {
+	Index       hexutil.Uint64
+	BlockNumber hexutil.Uint64
+}
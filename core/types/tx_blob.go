@@ -53,11 +53,30 @@ type BlobTx struct {
 	S *uint256.Int
 }
 
+// Blob sidecar versions. Version 0 is the original EIP-4844 format carrying one
+// blob proof per blob, version 1 is the EIP-7594 (PeerDAS) format carrying
+// kzg4844.CellProofsPerBlob cell proofs per blob.
+const (
+	BlobSidecarVersion0 = byte(0)
+	BlobSidecarVersion1 = byte(1)
+)
+
 // BlobTxSidecar contains the blobs of a blob transaction.
 type BlobTxSidecar struct {
+	Version     byte                 // Version of the sidecar (proof format)
 	Blobs       []kzg4844.Blob       // Blobs needed by the blob pool
 	Commitments []kzg4844.Commitment // Commitments needed by the blob pool
-	Proofs      []kzg4844.Proof      // Proofs needed by the blob pool
+	Proofs      []kzg4844.Proof      // Proofs needed by the blob pool (blob proofs in v0, cell proofs in v1)
+}
+
+// NewBlobTxSidecar initialises the BlobTxSidecar object with the provided fields.
+func NewBlobTxSidecar(version byte, blobs []kzg4844.Blob, commitments []kzg4844.Commitment, proofs []kzg4844.Proof) *BlobTxSidecar {
+	return &BlobTxSidecar{
+		Version:     version,
+		Blobs:       blobs,
+		Commitments: commitments,
+		Proofs:      proofs,
+	}
 }
 
 // BlobHashes computes the blob hashes of the given blobs.
@@ -83,7 +102,11 @@ func (sc *BlobTxSidecar) encodedSize() uint64 {
 	for i := range sc.Proofs {
 		proofs += rlp.BytesSize(sc.Proofs[i][:])
 	}
-	return rlp.ListSize(blobs) + rlp.ListSize(commitments) + rlp.ListSize(proofs)
+	size := rlp.ListSize(blobs) + rlp.ListSize(commitments) + rlp.ListSize(proofs)
+	if sc.Version != BlobSidecarVersion0 {
+		size += uint64(rlp.IntSize(uint64(sc.Version)))
+	}
+	return size
 }
 
 // ValidateBlobCommitmentHashes checks whether the given hashes correspond to the
@@ -102,6 +125,79 @@ func (sc *BlobTxSidecar) ValidateBlobCommitmentHashes(hashes []common.Hash) erro
 	return nil
 }
 
+// CellProofsAt returns the cell proofs of the blob at the given index. It is only
+// valid for version 1 sidecars.
+func (sc *BlobTxSidecar) CellProofsAt(idx int) ([]kzg4844.Proof, error) {
+	if sc.Version != BlobSidecarVersion1 {
+		return nil, fmt.Errorf("cell proofs unavailable in sidecar version %d", sc.Version)
+	}
+	if idx < 0 || idx >= len(sc.Blobs) {
+		return nil, fmt.Errorf("blob index %d out of range [0, %d)", idx, len(sc.Blobs))
+	}
+	if len(sc.Proofs) != len(sc.Blobs)*kzg4844.CellProofsPerBlob {
+		return nil, fmt.Errorf("invalid number of %d cell proofs for %d blobs", len(sc.Proofs), len(sc.Blobs))
+	}
+	index := idx * kzg4844.CellProofsPerBlob
+	return sc.Proofs[index : index+kzg4844.CellProofsPerBlob], nil
+}
+
+// ToV0 converts the sidecar into the version 0 format, recomputing a single
+// blob proof for each blob. The method is a noop for version 0 sidecars.
+//
+// This method does not verify that the commitments are correct with respect
+// to the blobs.
+func (sc *BlobTxSidecar) ToV0() error {
+	if sc.Version == BlobSidecarVersion0 {
+		return nil
+	}
+	if sc.Version != BlobSidecarVersion1 {
+		return fmt.Errorf("unsupported sidecar version %d", sc.Version)
+	}
+	proofs := make([]kzg4844.Proof, 0, len(sc.Blobs))
+	for i := range sc.Blobs {
+		proof, err := kzg4844.ComputeBlobProof(&sc.Blobs[i], sc.Commitments[i])
+		if err != nil {
+			return err
+		}
+		proofs = append(proofs, proof)
+	}
+	sc.Version = BlobSidecarVersion0
+	sc.Proofs = proofs
+	return nil
+}
+
+// ToV1 converts the sidecar into the version 1 format, computing the cell
+// proofs of each blob. The method is a noop for version 1 sidecars.
+func (sc *BlobTxSidecar) ToV1() error {
+	if sc.Version == BlobSidecarVersion1 {
+		return nil
+	}
+	if sc.Version != BlobSidecarVersion0 {
+		return fmt.Errorf("unsupported sidecar version %d", sc.Version)
+	}
+	proofs := make([]kzg4844.Proof, 0, len(sc.Blobs)*kzg4844.CellProofsPerBlob)
+	for i := range sc.Blobs {
+		cellProofs, err := kzg4844.ComputeCellProofs(&sc.Blobs[i])
+		if err != nil {
+			return err
+		}
+		proofs = append(proofs, cellProofs...)
+	}
+	sc.Version = BlobSidecarVersion1
+	sc.Proofs = proofs
+	return nil
+}
+
+// Copy returns a deep copy of the sidecar.
+func (sc *BlobTxSidecar) Copy() *BlobTxSidecar {
+	return &BlobTxSidecar{
+		Version:     sc.Version,
+		Blobs:       append([]kzg4844.Blob(nil), sc.Blobs...),
+		Commitments: append([]kzg4844.Commitment(nil), sc.Commitments...),
+		Proofs:      append([]kzg4844.Proof(nil), sc.Proofs...),
+	}
+}
+
 // blobTxWithBlobs is used for encoding of transactions when blobs are present.
 type blobTxWithBlobs struct {
 	BlobTx      *BlobTx
@@ -110,6 +206,17 @@ type blobTxWithBlobs struct {
 	Proofs      []kzg4844.Proof
 }
 
+// blobTxWithBlobsV1 is used for encoding of transactions when blobs with cell
+// proofs are present. The version byte distinguishes it from the original
+// network encoding.
+type blobTxWithBlobsV1 struct {
+	BlobTx      *BlobTx
+	Version     byte
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+}
+
 // copy creates a deep copy of the transaction data and initializes all fields.
 func (tx *BlobTx) copy() TxData {
 	cpy := &BlobTx{
@@ -157,11 +264,7 @@ func (tx *BlobTx) copy() TxData {
 		cpy.S.Set(tx.S)
 	}
 	if tx.Sidecar != nil {
-		cpy.Sidecar = &BlobTxSidecar{
-			Blobs:       append([]kzg4844.Blob(nil), tx.Sidecar.Blobs...),
-			Commitments: append([]kzg4844.Commitment(nil), tx.Sidecar.Commitments...),
-			Proofs:      append([]kzg4844.Proof(nil), tx.Sidecar.Proofs...),
-		}
+		cpy.Sidecar = tx.Sidecar.Copy()
 	}
 	return cpy
 }
@@ -215,30 +318,48 @@ func (tx *BlobTx) withSidecar(sideCar *BlobTxSidecar) *BlobTx {
 }
 
 func (tx *BlobTx) encode(b *bytes.Buffer) error {
-	if tx.Sidecar == nil {
+	switch {
+	case tx.Sidecar == nil:
 		return rlp.Encode(b, tx)
+
+	case tx.Sidecar.Version == BlobSidecarVersion0:
+		inner := &blobTxWithBlobs{
+			BlobTx:      tx,
+			Blobs:       tx.Sidecar.Blobs,
+			Commitments: tx.Sidecar.Commitments,
+			Proofs:      tx.Sidecar.Proofs,
+		}
+		return rlp.Encode(b, inner)
+
+	case tx.Sidecar.Version == BlobSidecarVersion1:
+		inner := &blobTxWithBlobsV1{
+			BlobTx:      tx,
+			Version:     tx.Sidecar.Version,
+			Blobs:       tx.Sidecar.Blobs,
+			Commitments: tx.Sidecar.Commitments,
+			Proofs:      tx.Sidecar.Proofs,
+		}
+		return rlp.Encode(b, inner)
+
+	default:
+		return fmt.Errorf("unsupported sidecar version %d", tx.Sidecar.Version)
 	}
-	inner := &blobTxWithBlobs{
-		BlobTx:      tx,
-		Blobs:       tx.Sidecar.Blobs,
-		Commitments: tx.Sidecar.Commitments,
-		Proofs:      tx.Sidecar.Proofs,
-	}
-	return rlp.Encode(b, inner)
 }
 
 func (tx *BlobTx) decode(input []byte) error {
-	// Here we need to support two formats: the network protocol encoding of the tx (with
-	// blobs) or the canonical encoding without blobs.
+	// Here we need to support three formats: the network protocol encodings of the
+	// tx (with blobs, optionally versioned) or the canonical encoding without blobs.
 	//
-	// The two encodings can be distinguished by checking whether the first element of the
-	// input list is itself a list.
+	// The canonical encoding is distinguished by the first element of the input list
+	// not being a list itself. The two network encodings are distinguished by the
+	// second element: the original format continues with the list of blobs, whereas
+	// the versioned format has a version byte in that position.
 
 	outerList, _, err := rlp.SplitList(input)
 	if err != nil {
 		return err
 	}
-	firstElemKind, _, _, err := rlp.Split(outerList)
+	firstElemKind, _, rest, err := rlp.Split(outerList)
 	if err != nil {
 		return err
 	}
@@ -246,17 +367,29 @@ func (tx *BlobTx) decode(input []byte) error {
 	if firstElemKind != rlp.List {
 		return rlp.DecodeBytes(input, tx)
 	}
-	// It's a tx with blobs.
-	var inner blobTxWithBlobs
+	secondElemKind, _, _, err := rlp.Split(rest)
+	if err != nil {
+		return err
+	}
+	// It's a tx with blobs, decode it based on the sidecar version.
+	if secondElemKind == rlp.List {
+		var inner blobTxWithBlobs
+		if err := rlp.DecodeBytes(input, &inner); err != nil {
+			return err
+		}
+		*tx = *inner.BlobTx
+		tx.Sidecar = NewBlobTxSidecar(BlobSidecarVersion0, inner.Blobs, inner.Commitments, inner.Proofs)
+		return nil
+	}
+	var inner blobTxWithBlobsV1
 	if err := rlp.DecodeBytes(input, &inner); err != nil {
 		return err
 	}
-	*tx = *inner.BlobTx
-	tx.Sidecar = &BlobTxSidecar{
-		Blobs:       inner.Blobs,
-		Commitments: inner.Commitments,
-		Proofs:      inner.Proofs,
+	if inner.Version != BlobSidecarVersion1 {
+		return fmt.Errorf("unsupported sidecar version %d", inner.Version)
 	}
+	*tx = *inner.BlobTx
+	tx.Sidecar = NewBlobTxSidecar(inner.Version, inner.Blobs, inner.Commitments, inner.Proofs)
 	return nil
 }
 
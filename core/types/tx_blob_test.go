@@ -74,6 +74,53 @@ func TestBlobTxSize(t *testing.T) {
 	}
 }
 
+// This test verifies that version 1 sidecars (cell proofs) survive an encoding
+// round trip and that tx.Size() accounts for the version byte.
+func TestBlobTxSidecarV1Encoding(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	withBlobs := createEmptyBlobTx(key, true)
+
+	sidecar := withBlobs.BlobTxSidecar().Copy()
+	if err := sidecar.ToV1(); err != nil {
+		t.Fatalf("failed to convert sidecar: %v", err)
+	}
+	if len(sidecar.Proofs) != kzg4844.CellProofsPerBlob {
+		t.Fatalf("cell proof count mismatch: have %d, want %d", len(sidecar.Proofs), kzg4844.CellProofsPerBlob)
+	}
+	withCells := withBlobs.WithBlobTxSidecar(sidecar)
+	if withCells.Hash() != withBlobs.Hash() {
+		t.Fatal("tx hash changed after sidecar conversion")
+	}
+	enc, err := withCells.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode tx: %v", err)
+	}
+	if size := withCells.Size(); size != uint64(len(enc)) {
+		t.Error("wrong size with cell proofs:", size, "encoded length:", len(enc))
+	}
+	dec := new(Transaction)
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode tx: %v", err)
+	}
+	sc := dec.BlobTxSidecar()
+	if sc == nil || sc.Version != BlobSidecarVersion1 {
+		t.Fatalf("decoded sidecar version mismatch: %v", sc)
+	}
+	if len(sc.Proofs) != kzg4844.CellProofsPerBlob || sc.Proofs[0] != sidecar.Proofs[0] {
+		t.Fatal("decoded cell proofs mismatch")
+	}
+	if err := kzg4844.VerifyCellProofs(sc.Blobs, sc.Commitments, sc.Proofs); err != nil {
+		t.Fatalf("failed to verify decoded cell proofs: %v", err)
+	}
+	// Converting back must yield the original blob proof
+	if err := sc.ToV0(); err != nil {
+		t.Fatalf("failed to convert sidecar back: %v", err)
+	}
+	if len(sc.Proofs) != 1 || sc.Proofs[0] != emptyBlobProof {
+		t.Fatal("blob proof mismatch after converting back")
+	}
+}
+
 var (
 	emptyBlob          = new(kzg4844.Blob)
 	emptyBlobCommit, _ = kzg4844.BlobToCommitment(emptyBlob)
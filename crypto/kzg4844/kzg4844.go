@@ -20,6 +20,7 @@ package kzg4844
 import (
 	"embed"
 	"errors"
+	"fmt"
 	"hash"
 	"reflect"
 	"sync/atomic"
@@ -36,6 +37,10 @@ var (
 	proofT      = reflect.TypeOf(Proof{})
 )
 
+// CellProofsPerBlob is the number of cell proofs (one per cell of the extended
+// blob) that EIP-7594 requires for each blob.
+const CellProofsPerBlob = 128
+
 // Blob represents a 4844 data blob.
 type Blob [131072]byte
 
@@ -95,11 +100,12 @@ func UseCKZG(use bool) error {
 
 	// Initializing the library can take 2-4 seconds - and can potentially crash
 	// on CKZG and non-ADX CPUs - so might as well do it now and don't wait until
-	// a crypto operation is actually needed live.
+	// a crypto operation is actually needed live. The Go library can't crash,
+	// so it's warmed up in the background without delaying startup.
 	if use {
 		ckzgIniter.Do(ckzgInit)
 	} else {
-		gokzgIniter.Do(gokzgInit)
+		go gokzgIniter.Do(gokzgInit)
 	}
 	return nil
 }
@@ -149,6 +155,31 @@ func VerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error {
 	return gokzgVerifyBlobProof(blob, commitment, proof)
 }
 
+// ComputeCellProofs returns the KZG cell proofs of all the cells of the extended
+// blob, as required by EIP-7594 (PeerDAS).
+func ComputeCellProofs(blob *Blob) ([]Proof, error) {
+	if useCKZG.Load() {
+		return ckzgComputeCellProofs(blob)
+	}
+	return gokzgComputeCellProofs(blob)
+}
+
+// VerifyCellProofs verifies a batch of cell proofs against the blobs and their
+// commitments. The number of blobs and commitments must match, and each blob
+// must be accompanied by exactly CellProofsPerBlob proofs.
+func VerifyCellProofs(blobs []Blob, commitments []Commitment, proofs []Proof) error {
+	if len(blobs) != len(commitments) {
+		return fmt.Errorf("blob/commitment count mismatch: %d blobs, %d commitments", len(blobs), len(commitments))
+	}
+	if len(proofs) != len(blobs)*CellProofsPerBlob {
+		return fmt.Errorf("invalid cell proof count: have %d, want %d", len(proofs), len(blobs)*CellProofsPerBlob)
+	}
+	if useCKZG.Load() {
+		return ckzgVerifyCellProofs(blobs, commitments, proofs)
+	}
+	return gokzgVerifyCellProofs(blobs, commitments, proofs)
+}
+
 // CalcBlobHashV1 calculates the 'versioned blob hash' of a commitment.
 // The given hasher must be a sha256 hash instance, otherwise the result will be invalid!
 func CalcBlobHashV1(hasher hash.Hash, commit *Commitment) (vh [32]byte) {
@@ -23,8 +23,8 @@ import (
 	"errors"
 	"sync"
 
-	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
-	ckzg4844 "github.com/ethereum/c-kzg-4844/bindings/go"
+	gokzg4844 "github.com/crate-crypto/go-eth-kzg"
+	ckzg4844 "github.com/ethereum/c-kzg-4844/v2/bindings/go"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
@@ -47,15 +47,21 @@ func ckzgInit() {
 	if err = gokzg4844.CheckTrustedSetupIsWellFormed(params); err != nil {
 		panic(err)
 	}
-	g1s := make([]byte, len(params.SetupG1Lagrange)*(len(params.SetupG1Lagrange[0])-2)/2)
+	g1Lag := make([]byte, len(params.SetupG1Lagrange)*(len(params.SetupG1Lagrange[0])-2)/2)
 	for i, g1 := range params.SetupG1Lagrange {
+		copy(g1Lag[i*(len(g1)-2)/2:], hexutil.MustDecode(g1))
+	}
+	g1s := make([]byte, len(params.SetupG1Monomial)*(len(params.SetupG1Monomial[0])-2)/2)
+	for i, g1 := range params.SetupG1Monomial {
 		copy(g1s[i*(len(g1)-2)/2:], hexutil.MustDecode(g1))
 	}
 	g2s := make([]byte, len(params.SetupG2)*(len(params.SetupG2[0])-2)/2)
 	for i, g2 := range params.SetupG2 {
 		copy(g2s[i*(len(g2)-2)/2:], hexutil.MustDecode(g2))
 	}
-	if err = ckzg4844.LoadTrustedSetup(g1s, g2s); err != nil {
+	// The precompute parameter trades memory for speed in the multiplication
+	// tables used by cell proof generation, 8 is the library's recommended value.
+	if err = ckzg4844.LoadTrustedSetup(g1s, g1Lag, g2s, 8); err != nil {
 		panic(err)
 	}
 }
@@ -125,3 +131,54 @@ func ckzgVerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error {
 	}
 	return nil
 }
+
+// ckzgComputeCellProofs returns the KZG cell proofs of all the cells of the
+// extended blob.
+func ckzgComputeCellProofs(blob *Blob) ([]Proof, error) {
+	ckzgIniter.Do(ckzgInit)
+
+	_, proofs, err := ckzg4844.ComputeCellsAndKZGProofs((*ckzg4844.Blob)(blob))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Proof, len(proofs))
+	for i, proof := range proofs {
+		res[i] = (Proof)(proof)
+	}
+	return res, nil
+}
+
+// ckzgVerifyCellProofs verifies a batch of cell proofs against the blobs and
+// their commitments.
+func ckzgVerifyCellProofs(blobs []Blob, commitments []Commitment, cellProofs []Proof) error {
+	ckzgIniter.Do(ckzgInit)
+
+	var (
+		proofs  = make([]ckzg4844.Bytes48, len(cellProofs))
+		commits = make([]ckzg4844.Bytes48, 0, len(cellProofs))
+		indices = make([]uint64, 0, len(cellProofs))
+		cells   = make([]ckzg4844.Cell, 0, len(cellProofs))
+	)
+	for i, proof := range cellProofs {
+		proofs[i] = (ckzg4844.Bytes48)(proof)
+	}
+	for i := range blobs {
+		blobCells, err := ckzg4844.ComputeCells((*ckzg4844.Blob)(&blobs[i]))
+		if err != nil {
+			return err
+		}
+		for j := range blobCells {
+			commits = append(commits, (ckzg4844.Bytes48)(commitments[i]))
+			indices = append(indices, uint64(j))
+			cells = append(cells, blobCells[j])
+		}
+	}
+	valid, err := ckzg4844.VerifyCellKZGProofBatch(commits, indices, cells, proofs)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("invalid proof")
+	}
+	return nil
+}
@@ -60,3 +60,15 @@ func ckzgComputeBlobProof(blob *Blob, commitment Commitment) (Proof, error) {
 func ckzgVerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error {
 	panic("unsupported platform")
 }
+
+// ckzgComputeCellProofs returns the KZG cell proofs of all the cells of the
+// extended blob.
+func ckzgComputeCellProofs(blob *Blob) ([]Proof, error) {
+	panic("unsupported platform")
+}
+
+// ckzgVerifyCellProofs verifies a batch of cell proofs against the blobs and
+// their commitments.
+func ckzgVerifyCellProofs(blobs []Blob, commitments []Commitment, proofs []Proof) error {
+	panic("unsupported platform")
+}
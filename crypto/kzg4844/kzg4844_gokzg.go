@@ -20,11 +20,12 @@ import (
 	"encoding/json"
 	"sync"
 
-	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	gokzg "github.com/crate-crypto/go-eth-kzg"
 )
 
 // context is the crypto primitive pre-seeded with the trusted setup parameters.
-var context *gokzg4844.Context
+// It serves both the EIP-4844 blob proofs and the EIP-7594 cell proofs.
+var context *gokzg.Context
 
 // gokzgIniter ensures that we initialize the KZG library once before using it.
 var gokzgIniter sync.Once
@@ -35,11 +36,11 @@ func gokzgInit() {
 	if err != nil {
 		panic(err)
 	}
-	params := new(gokzg4844.JSONTrustedSetup)
+	params := new(gokzg.JSONTrustedSetup)
 	if err = json.Unmarshal(config, params); err != nil {
 		panic(err)
 	}
-	context, err = gokzg4844.NewContext4096(params)
+	context, err = gokzg.NewContext4096(params)
 	if err != nil {
 		panic(err)
 	}
@@ -49,7 +50,7 @@ func gokzgInit() {
 func gokzgBlobToCommitment(blob *Blob) (Commitment, error) {
 	gokzgIniter.Do(gokzgInit)
 
-	commitment, err := context.BlobToKZGCommitment((*gokzg4844.Blob)(blob), 0)
+	commitment, err := context.BlobToKZGCommitment((*gokzg.Blob)(blob), 0)
 	if err != nil {
 		return Commitment{}, err
 	}
@@ -61,7 +62,7 @@ func gokzgBlobToCommitment(blob *Blob) (Commitment, error) {
 func gokzgComputeProof(blob *Blob, point Point) (Proof, Claim, error) {
 	gokzgIniter.Do(gokzgInit)
 
-	proof, claim, err := context.ComputeKZGProof((*gokzg4844.Blob)(blob), (gokzg4844.Scalar)(point), 0)
+	proof, claim, err := context.ComputeKZGProof((*gokzg.Blob)(blob), (gokzg.Scalar)(point), 0)
 	if err != nil {
 		return Proof{}, Claim{}, err
 	}
@@ -73,7 +74,7 @@ func gokzgComputeProof(blob *Blob, point Point) (Proof, Claim, error) {
 func gokzgVerifyProof(commitment Commitment, point Point, claim Claim, proof Proof) error {
 	gokzgIniter.Do(gokzgInit)
 
-	return context.VerifyKZGProof((gokzg4844.KZGCommitment)(commitment), (gokzg4844.Scalar)(point), (gokzg4844.Scalar)(claim), (gokzg4844.KZGProof)(proof))
+	return context.VerifyKZGProof((gokzg.KZGCommitment)(commitment), (gokzg.Scalar)(point), (gokzg.Scalar)(claim), (gokzg.KZGProof)(proof))
 }
 
 // gokzgComputeBlobProof returns the KZG proof that is used to verify the blob against
@@ -83,7 +84,7 @@ func gokzgVerifyProof(commitment Commitment, point Point, claim Claim, proof Pro
 func gokzgComputeBlobProof(blob *Blob, commitment Commitment) (Proof, error) {
 	gokzgIniter.Do(gokzgInit)
 
-	proof, err := context.ComputeBlobKZGProof((*gokzg4844.Blob)(blob), (gokzg4844.KZGCommitment)(commitment), 0)
+	proof, err := context.ComputeBlobKZGProof((*gokzg.Blob)(blob), (gokzg.KZGCommitment)(commitment), 0)
 	if err != nil {
 		return Proof{}, err
 	}
@@ -94,5 +95,49 @@ func gokzgComputeBlobProof(blob *Blob, commitment Commitment) (Proof, error) {
 func gokzgVerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error {
 	gokzgIniter.Do(gokzgInit)
 
-	return context.VerifyBlobKZGProof((*gokzg4844.Blob)(blob), (gokzg4844.KZGCommitment)(commitment), (gokzg4844.KZGProof)(proof))
+	return context.VerifyBlobKZGProof((*gokzg.Blob)(blob), (gokzg.KZGCommitment)(commitment), (gokzg.KZGProof)(proof))
+}
+
+// gokzgComputeCellProofs returns the KZG cell proofs of all the cells of the
+// extended blob.
+func gokzgComputeCellProofs(blob *Blob) ([]Proof, error) {
+	gokzgIniter.Do(gokzgInit)
+
+	_, proofs, err := context.ComputeCellsAndKZGProofs((*gokzg.Blob)(blob), 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Proof, len(proofs))
+	for i, proof := range proofs {
+		res[i] = (Proof)(proof)
+	}
+	return res, nil
+}
+
+// gokzgVerifyCellProofs verifies a batch of cell proofs against the blobs and
+// their commitments.
+func gokzgVerifyCellProofs(blobs []Blob, commitments []Commitment, cellProofs []Proof) error {
+	gokzgIniter.Do(gokzgInit)
+
+	var (
+		proofs  = make([]gokzg.KZGProof, len(cellProofs))
+		commits = make([]gokzg.KZGCommitment, 0, len(cellProofs))
+		indices = make([]uint64, 0, len(cellProofs))
+		cells   = make([]*gokzg.Cell, 0, len(cellProofs))
+	)
+	for i, proof := range cellProofs {
+		proofs[i] = (gokzg.KZGProof)(proof)
+	}
+	for i := range blobs {
+		blobCells, err := context.ComputeCells((*gokzg.Blob)(&blobs[i]), 0)
+		if err != nil {
+			return err
+		}
+		for j := range blobCells {
+			commits = append(commits, (gokzg.KZGCommitment)(commitments[i]))
+			indices = append(indices, uint64(j))
+			cells = append(cells, blobCells[j])
+		}
+	}
+	return context.VerifyCellKZGProofBatch(commits, indices, cells, proofs)
 }
@@ -21,7 +21,7 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
-	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	gokzg4844 "github.com/crate-crypto/go-eth-kzg"
 )
 
 func randFieldElement() [32]byte {
@@ -94,6 +94,55 @@ func testKZGWithBlob(t *testing.T, ckzg bool) {
 	}
 }
 
+func TestCKZGCellProofs(t *testing.T)  { testKZGCellProofs(t, true) }
+func TestGoKZGCellProofs(t *testing.T) { testKZGCellProofs(t, false) }
+func testKZGCellProofs(t *testing.T, ckzg bool) {
+	if ckzg && !ckzgAvailable {
+		t.Skip("CKZG unavailable in this test build")
+	}
+	defer func(old bool) { useCKZG.Store(old) }(useCKZG.Load())
+	useCKZG.Store(ckzg)
+
+	var (
+		blob1 = randBlob()
+		blob2 = randBlob()
+	)
+	commit1, err := BlobToCommitment(blob1)
+	if err != nil {
+		t.Fatalf("failed to create KZG commitment from blob: %v", err)
+	}
+	commit2, err := BlobToCommitment(blob2)
+	if err != nil {
+		t.Fatalf("failed to create KZG commitment from blob: %v", err)
+	}
+	proofs1, err := ComputeCellProofs(blob1)
+	if err != nil {
+		t.Fatalf("failed to create KZG cell proofs for blob: %v", err)
+	}
+	if len(proofs1) != CellProofsPerBlob {
+		t.Fatalf("cell proof count mismatch: have %d, want %d", len(proofs1), CellProofsPerBlob)
+	}
+	proofs2, err := ComputeCellProofs(blob2)
+	if err != nil {
+		t.Fatalf("failed to create KZG cell proofs for blob: %v", err)
+	}
+	var (
+		blobs   = []Blob{*blob1, *blob2}
+		commits = []Commitment{commit1, commit2}
+		proofs  = append(proofs1, proofs2...)
+	)
+	if err := VerifyCellProofs(blobs, commits, proofs); err != nil {
+		t.Fatalf("failed to verify KZG cell proofs: %v", err)
+	}
+	// Swap the commitments around and ensure verification fails
+	if err := VerifyCellProofs(blobs, []Commitment{commit2, commit1}, proofs); err == nil {
+		t.Fatalf("verified KZG cell proofs against mismatching commitments")
+	}
+	if err := VerifyCellProofs(blobs, commits, proofs[1:]); err == nil {
+		t.Fatalf("verified KZG cell proofs with missing proof")
+	}
+}
+
 func BenchmarkCKZGBlobToCommitment(b *testing.B)  { benchmarkBlobToCommitment(b, true) }
 func BenchmarkGoKZGBlobToCommitment(b *testing.B) { benchmarkBlobToCommitment(b, false) }
 func benchmarkBlobToCommitment(b *testing.B, ckzg bool) {
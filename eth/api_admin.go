@@ -24,7 +24,10 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -141,3 +144,58 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// TxPoolThrottleResult is the result of an admin_txPoolThrottles call.
+type TxPoolThrottleResult struct {
+	SenderGas      uint64                      `json:"senderGas"`
+	ContractGas    uint64                      `json:"contractGas"`
+	Burst          string                      `json:"burst"`
+	PenaltyStrikes uint64                      `json:"penaltyStrikes"`
+	PenaltyTime    string                      `json:"penaltyTime"`
+	Overrides      []legacypool.ThrottleStatus `json:"overrides"`
+	Tracked        []legacypool.ThrottleStatus `json:"tracked"`
+}
+
+// TxPoolThrottles returns the gas throttling configuration of the transaction
+// pool, along with all the senders and contracts currently being throttled.
+func (api *AdminAPI) TxPoolThrottles() *TxPoolThrottleResult {
+	config := api.eth.legacyPool.ThrottleConfig()
+	return &TxPoolThrottleResult{
+		SenderGas:      config.SenderGas,
+		ContractGas:    config.ContractGas,
+		Burst:          config.Burst.String(),
+		PenaltyStrikes: config.PenaltyStrikes,
+		PenaltyTime:    config.PenaltyTime.String(),
+		Overrides:      api.eth.legacyPool.ThrottleOverrides(),
+		Tracked:        api.eth.legacyPool.Throttles(),
+	}
+}
+
+// SetTxPoolThrottle updates the default per sender and per contract gas per
+// second budgets of the transaction pool. Zero disables the respective limit.
+func (api *AdminAPI) SetTxPoolThrottle(senderGas uint64, contractGas uint64) bool {
+	config := api.eth.legacyPool.ThrottleConfig()
+	config.SenderGas, config.ContractGas = senderGas, contractGas
+	api.eth.legacyPool.SetThrottleConfig(config)
+	return true
+}
+
+// SetTxPoolThrottleOverride sets a custom gas per second budget for a specific
+// sender or contract (kind), zero meaning unlimited. Omitting the rate reverts
+// the address to the default budget.
+func (api *AdminAPI) SetTxPoolThrottleOverride(kind string, addr common.Address, rate *hexutil.Uint64) (bool, error) {
+	var limit *uint64
+	if rate != nil {
+		limit = (*uint64)(rate)
+	}
+	if err := api.eth.legacyPool.SetThrottleOverride(legacypool.ThrottleKind(kind), addr, limit); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseTxPoolThrottle removes a sender or contract (kind) from the penalty box
+// of the transaction pool and resets its gas budget.
+func (api *AdminAPI) ReleaseTxPoolThrottle(kind string, addr common.Address) (bool, error) {
+	return api.eth.legacyPool.ReleaseThrottle(legacypool.ThrottleKind(kind), addr)
+}
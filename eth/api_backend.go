@@ -37,8 +37,10 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/live"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -156,7 +158,7 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 	}
 	block := b.eth.blockchain.GetBlockByNumber(bn)
 	if block == nil && bn < b.HistoryPruningCutoff() {
-		return nil, &history.PrunedHistoryError{}
+		return b.prunedBlock(b.eth.blockchain.GetCanonicalHash(bn), bn)
 	}
 	return block, nil
 }
@@ -168,7 +170,7 @@ func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*typ
 	}
 	block := b.eth.blockchain.GetBlock(hash, *number)
 	if block == nil && *number < b.HistoryPruningCutoff() {
-		return nil, &history.PrunedHistoryError{}
+		return b.prunedBlock(hash, *number)
 	}
 	return block, nil
 }
@@ -181,7 +183,11 @@ func (b *EthAPIBackend) GetBody(ctx context.Context, hash common.Hash, number rp
 	body := b.eth.blockchain.GetBody(hash)
 	if body == nil {
 		if uint64(number) < b.HistoryPruningCutoff() {
-			return nil, &history.PrunedHistoryError{}
+			block, err := b.prunedBlock(hash, uint64(number))
+			if err != nil {
+				return nil, err
+			}
+			return block.Body(), nil
 		}
 		return nil, errors.New("block body not found")
 	}
@@ -203,7 +209,7 @@ func (b *EthAPIBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash r
 		block := b.eth.blockchain.GetBlock(hash, header.Number.Uint64())
 		if block == nil {
 			if header.Number.Uint64() < b.HistoryPruningCutoff() {
-				return nil, &history.PrunedHistoryError{}
+				return b.prunedBlock(hash, header.Number.Uint64())
 			}
 			return nil, errors.New("header found, but block body is missing")
 		}
@@ -233,7 +239,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.B
 	if header == nil {
 		return nil, nil, errors.New("header not found")
 	}
-	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAt(header)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -255,7 +261,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number.Uint64()) != hash {
 			return nil, nil, errors.New("hash is not currently canonical")
 		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAt(header)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -264,13 +270,67 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 	return nil, nil, errors.New("invalid arguments; neither block nor hash specified")
 }
 
+// stateAt returns the state of the given block, falling back to reconstructing
+// it from the indexed state histories if it's no longer directly available.
+// Unindexed histories are not used, replaying them is too costly to be
+// exposed to arbitrary RPC requests.
+func (b *EthAPIBackend) stateAt(header *types.Header) (*state.StateDB, error) {
+	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	if err == nil {
+		return stateDb, nil
+	}
+	if !b.eth.BlockChain().HistoricStateIndexed() {
+		return nil, err
+	}
+	if historic, herr := b.eth.BlockChain().HistoricState(header.Root); herr == nil {
+		return historic, nil
+	}
+	return nil, err
+}
+
 func (b *EthAPIBackend) HistoryPruningCutoff() uint64 {
 	bn, _ := b.eth.blockchain.HistoryPruningCutoff()
 	return bn
 }
 
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
-	return b.eth.blockchain.GetReceiptsByHash(hash), nil
+	receipts := b.eth.blockchain.GetReceiptsByHash(hash)
+	if receipts == nil {
+		if number := b.eth.blockchain.GetBlockNumber(hash); number != nil && *number < b.HistoryPruningCutoff() {
+			return b.prunedReceipts(hash, *number)
+		}
+	}
+	return receipts, nil
+}
+
+// prunedBlock retrieves a block pruned from the local database from the era
+// history store, if one is configured.
+func (b *EthAPIBackend) prunedBlock(hash common.Hash, number uint64) (*types.Block, error) {
+	header := b.eth.blockchain.GetHeader(hash, number)
+	if b.eth.historyStore == nil || header == nil {
+		return nil, &history.PrunedHistoryError{}
+	}
+	block, err := b.eth.historyStore.Block(header)
+	if err != nil {
+		log.Warn("Failed to retrieve pruned block", "number", number, "hash", hash, "err", err)
+		return nil, &history.PrunedHistoryError{}
+	}
+	return block, nil
+}
+
+// prunedReceipts retrieves the receipts of a block pruned from the local
+// database from the era history store, if one is configured.
+func (b *EthAPIBackend) prunedReceipts(hash common.Hash, number uint64) (types.Receipts, error) {
+	header := b.eth.blockchain.GetHeader(hash, number)
+	if b.eth.historyStore == nil || header == nil {
+		return nil, &history.PrunedHistoryError{}
+	}
+	receipts, err := b.eth.historyStore.Receipts(header)
+	if err != nil {
+		log.Warn("Failed to retrieve pruned receipts", "number", number, "hash", hash, "err", err)
+		return nil, &history.PrunedHistoryError{}
+	}
+	return receipts, nil
 }
 
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number uint64) ([][]*types.Log, error) {
@@ -323,6 +383,17 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return nil
 }
 
+// SendPrivateTx adds a transaction to the pool without propagating it to the
+// network. Private transactions are deliberately not tracked as local ones, as
+// resubmissions by the tracker would make them public.
+func (b *EthAPIBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, lifetime uint64) error {
+	return b.eth.txPool.AddPrivate(signedTx, lifetime)
+}
+
+func (b *EthAPIBackend) IsPrivateTx(txHash common.Hash) bool {
+	return b.eth.txPool.IsPrivate(txHash)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending := b.eth.txPool.Pending(txpool.PendingFilter{})
 	var txs types.Transactions
@@ -356,6 +427,20 @@ func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash)
 		return false, nil, common.Hash{}, 0, 0, err
 	}
 	if lookup == nil || tx == nil {
+		// The transaction might be indexed, but its block pruned. Resolve
+		// it from the era history store in that case.
+		if b.eth.historyStore != nil {
+			if number := rawdb.ReadTxLookupEntry(b.eth.chainDb, txHash); number != nil && *number < b.HistoryPruningCutoff() {
+				hash := b.eth.blockchain.GetCanonicalHash(*number)
+				if block, err := b.prunedBlock(hash, *number); err == nil {
+					for i, tx := range block.Transactions() {
+						if tx.Hash() == txHash {
+							return true, tx, hash, *number, uint64(i), nil
+						}
+					}
+				}
+			}
+		}
 		return false, nil, common.Hash{}, 0, 0, nil
 	}
 	return true, tx, lookup.BlockHash, lookup.BlockIndex, lookup.Index, nil
@@ -456,3 +541,15 @@ func (b *EthAPIBackend) StateAtBlock(ctx context.Context, block *types.Block, re
 func (b *EthAPIBackend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (*types.Transaction, vm.BlockContext, *state.StateDB, tracers.StateReleaseFunc, error) {
 	return b.eth.stateAtTransaction(ctx, block, txIndex, reexec)
 }
+
+// TraceIndex returns the call trace index maintained by the traceindex live
+// tracer, if enabled, allowing trace_filter to skip re-executing blocks.
+func (b *EthAPIBackend) TraceIndex() tracers.TraceIndex {
+	return live.ActiveTraceIndex()
+}
+
+// TransferIndex returns the token transfer index maintained by the
+// transferindex live tracer, if enabled.
+func (b *EthAPIBackend) TransferIndex() tracers.TransferIndex {
+	return live.ActiveTransferIndex()
+}
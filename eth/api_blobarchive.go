@@ -0,0 +1,82 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/blobarchive"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxBlobSidecarsByHash is the maximum number of versioned hashes that can be
+// requested in a single call.
+const maxBlobSidecarsByHash = 128
+
+// BlobArchiveAPI exposes the blob sidecars retained by the local blob archive.
+type BlobArchiveAPI struct {
+	eth *Ethereum
+}
+
+// NewBlobArchiveAPI creates a new instance of BlobArchiveAPI.
+func NewBlobArchiveAPI(eth *Ethereum) *BlobArchiveAPI {
+	return &BlobArchiveAPI{eth: eth}
+}
+
+// GetBlobSidecars returns the sidecars of all the blobs included in the given
+// block. An error is returned if any of the block's blobs is not archived.
+func (api *BlobArchiveAPI) GetBlobSidecars(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.BlobSidecar, error) {
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	var vhashes []common.Hash
+	for _, tx := range block.Transactions() {
+		vhashes = append(vhashes, tx.BlobHashes()...)
+	}
+	if len(vhashes) == 0 {
+		return []*types.BlobSidecar{}, nil
+	}
+	blobs, err := api.eth.blobArchive.Blobs(vhashes)
+	if err != nil {
+		return nil, err
+	}
+	for i, blob := range blobs {
+		if blob == nil {
+			return nil, fmt.Errorf("%w: %x", blobarchive.ErrNotArchived, vhashes[i])
+		}
+		// The archive is keyed by versioned hash only, make sure the sidecar
+		// really belongs to the requested block and not a reorged sibling.
+		if blob.BlockHash != block.Hash() {
+			return nil, fmt.Errorf("%w: %x", blobarchive.ErrNotArchived, vhashes[i])
+		}
+	}
+	return blobs, nil
+}
+
+// GetBlobSidecarsByHash returns the archived sidecars of the blobs with the given
+// versioned hashes. Blobs not present in the archive are returned as null.
+func (api *BlobArchiveAPI) GetBlobSidecarsByHash(ctx context.Context, vhashes []common.Hash) ([]*types.BlobSidecar, error) {
+	if len(vhashes) > maxBlobSidecarsByHash {
+		return nil, errors.New("too many versioned hashes requested")
+	}
+	return api.eth.blobArchive.Blobs(vhashes)
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// BundleArgs represents the arguments to submit a new transaction bundle.
+type BundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	MinTimestamp      *hexutil.Uint64 `json:"minTimestamp"`
+	MaxTimestamp      *hexutil.Uint64 `json:"maxTimestamp"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+}
+
+// BundleStatus is the inclusion status of a bundle as returned over RPC.
+type BundleStatus struct {
+	State       bundlepool.BundleState `json:"state"`
+	BlockNumber hexutil.Uint64         `json:"blockNumber"`
+	BlockHash   *common.Hash           `json:"blockHash,omitempty"`
+	Payment     *hexutil.Big           `json:"payment,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// BundleAPI provides an API to submit transaction bundles to the local block
+// builder and to track their inclusion.
+type BundleAPI struct {
+	eth *Ethereum
+}
+
+// NewBundleAPI creates a new instance of BundleAPI.
+func NewBundleAPI(eth *Ethereum) *BundleAPI {
+	return &BundleAPI{eth: eth}
+}
+
+// SendBundle submits a bundle of signed transactions to be included atomically
+// and in order into the given target block. The bundle is never propagated to
+// the network. Its hash is returned, which can be used to query the status.
+func (api *BundleAPI) SendBundle(ctx context.Context, args BundleArgs) (common.Hash, error) {
+	bundle := &bundlepool.Bundle{
+		Txs:               make([]*types.Transaction, len(args.Txs)),
+		BlockNumber:       uint64(args.BlockNumber),
+		RevertingTxHashes: args.RevertingTxHashes,
+	}
+	if args.MinTimestamp != nil {
+		bundle.MinTimestamp = uint64(*args.MinTimestamp)
+	}
+	if args.MaxTimestamp != nil {
+		bundle.MaxTimestamp = uint64(*args.MaxTimestamp)
+	}
+	for i, input := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil {
+			return common.Hash{}, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		if !api.eth.APIBackend.UnprotectedAllowed() && !tx.Protected() {
+			return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+		}
+		bundle.Txs[i] = tx
+	}
+	hash, err := api.eth.bundlePool.AddBundle(bundle)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted transaction bundle", "hash", hash, "txs", len(bundle.Txs), "target", bundle.BlockNumber)
+	return hash, nil
+}
+
+// GetBundleStatus returns the inclusion status of a previously submitted bundle,
+// or null if the bundle is unknown.
+func (api *BundleAPI) GetBundleStatus(ctx context.Context, hash common.Hash) (*BundleStatus, error) {
+	status := api.eth.bundlePool.BundleStatus(hash)
+	if status == nil {
+		return nil, nil
+	}
+	result := &BundleStatus{
+		State:       status.State,
+		BlockNumber: hexutil.Uint64(status.BlockNumber),
+		Error:       status.Error,
+	}
+	if status.BlockHash != (common.Hash{}) {
+		result.BlockHash = &status.BlockHash
+	}
+	if status.Payment != nil {
+		result.Payment = (*hexutil.Big)(status.Payment)
+	}
+	return result, nil
+}
@@ -30,11 +30,14 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/blobarchive"
 	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/core/history"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/txpool/locals"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -62,6 +65,10 @@ import (
 	gethversion "github.com/ethereum/go-ethereum/version"
 )
 
+// replicaFollowInterval is the interval of updating the chain head of a replica
+// node to the one of its primary.
+const replicaFollowInterval = 500 * time.Millisecond
+
 // Config contains the configuration options of the ETH protocol.
 // Deprecated: use ethconfig.Config instead.
 type Config = ethconfig.Config
@@ -71,8 +78,12 @@ type Ethereum struct {
 	// core protocol objects
 	config         *ethconfig.Config
 	txPool         *txpool.TxPool
+	legacyPool     *legacypool.LegacyPool
+	bundlePool     *bundlepool.BundlePool
 	localTxTracker *locals.TxTracker
+	blobArchive    *blobarchive.Archive
 	blockchain     *core.BlockChain
+	historyStore   *history.EraStore // Optional source of the pruned chain history
 
 	handler *handler
 	discmix *enode.FairMix
@@ -88,6 +99,9 @@ type Ethereum struct {
 	filterMaps      *filtermaps.FilterMaps
 	closeFilterMaps chan chan struct{}
 
+	replica      bool               // Whether the chain database is shared with a primary node
+	closeReplica chan chan struct{} // Channel to stop following the primary's head
+
 	APIBackend *EthAPIBackend
 
 	miner    *miner.Miner
@@ -126,6 +140,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		}
 		config.TrieDirtyCache = 0
 	}
+	// Replicas don't maintain the derived data, as their writes to the chain
+	// database of the primary are only kept in memory.
+	replica := stack.Config().DBReplica != ""
+	if replica {
+		config.SyncMode = ethconfig.FullSync
+		config.LogNoHistory = true
+		config.SnapshotCache = 0
+	}
 	log.Info("Allocated trie memory caches", "clean", common.StorageSize(config.TrieCleanCache)*1024*1024, "dirty", common.StorageSize(config.TrieDirtyCache)*1024*1024)
 
 	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/", false)
@@ -171,6 +193,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		p2pServer:       stack.Server(),
 		discmix:         enode.NewFairMix(0),
 		shutdownTracker: shutdowncheck.NewShutdownTracker(chainDb),
+		replica:         replica,
 	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -203,11 +226,27 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
+			StateIndex:          config.StateIndex,
 			StateScheme:         scheme,
 			ChainHistoryMode:    config.HistoryMode,
+			ChainHistoryWindow:  config.HistoryWindow,
+
+			ChainHistoryKeepTxIndex: config.HistoryEraStore != "",
 		}
 	)
-	if config.VMTrace != "" {
+	// The standalone tracer is folded into the multiplexer if other live tracers
+	// are configured, each tracer must only be constructed once.
+	if len(config.LiveTracers) > 0 {
+		live := config.LiveTracers
+		if config.VMTrace != "" {
+			live = append([]tracers.LiveTracerConfig{{Name: config.VMTrace, Config: config.VMTraceJsonConfig}}, live...)
+		}
+		t, err := tracers.LiveDirectory.NewMux(live)
+		if err != nil {
+			return nil, err
+		}
+		vmConfig.Tracer = t
+	} else if config.VMTrace != "" {
 		traceConfig := json.RawMessage("{}")
 		if config.VMTraceJsonConfig != "" {
 			traceConfig = json.RawMessage(config.VMTraceJsonConfig)
@@ -226,10 +265,29 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.OverrideVerkle != nil {
 		overrides.OverrideVerkle = config.OverrideVerkle
 	}
-	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, &config.TransactionHistory)
+	txLookupLimit := &config.TransactionHistory
+	if replica {
+		txLookupLimit = nil // The transactions are indexed by the primary
+	}
+	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, txLookupLimit)
 	if err != nil {
 		return nil, err
 	}
+	if config.HistoryEraStore != "" {
+		network := "unknown"
+		if name, ok := params.NetworkNames[eth.blockchain.Config().ChainID.String()]; ok {
+			network = name
+		}
+		eth.historyStore, err = history.NewEraStore(history.EraStoreConfig{
+			Source:   config.HistoryEraStore,
+			Network:  network,
+			CacheDir: stack.ResolvePath("erastore"),
+		}, eth.blockchain.Config())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open era history store: %v", err)
+		}
+		log.Info("Serving pruned chain history from era files", "source", config.HistoryEraStore, "network", network)
+	}
 
 	// Initialize filtermaps log index.
 	fmConfig := filtermaps.Config{
@@ -252,13 +310,26 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
+	eth.legacyPool = legacyPool
 
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
 	}
 	blobPool := blobpool.New(config.BlobPool, eth.blockchain, legacyPool.HasPendingAuth)
 
-	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
+	if config.BlobArchive.Enabled {
+		if eth.blobArchive, err = blobarchive.New(config.BlobArchive, chainDb, eth.blockchain); err != nil {
+			return nil, err
+		}
+		blobPool.SetArchiver(eth.blobArchive)
+	}
+
+	subpools := []txpool.SubPool{legacyPool, blobPool}
+	if config.BundlePool.Enabled {
+		eth.bundlePool = bundlepool.New(config.BundlePool, eth.blockchain)
+		subpools = append(subpools, eth.bundlePool)
+	}
+	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, subpools)
 	if err != nil {
 		return nil, err
 	}
@@ -294,6 +365,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	eth.miner = miner.New(eth, config.Miner, eth.engine)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 	eth.miner.SetPrioAddresses(config.TxPool.Locals)
+	if eth.bundlePool != nil {
+		eth.miner.SetBundleSource(eth.bundlePool)
+	}
 
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
@@ -340,6 +414,20 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the blob archive APIs if the archive is enabled
+	if s.blobArchive != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Service:   NewBlobArchiveAPI(s),
+		})
+	}
+	// Append the bundle APIs if bundles are accepted
+	if s.bundlePool != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Service:   NewBundleAPI(s),
+		})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -407,9 +495,40 @@ func (s *Ethereum) Start() error {
 	// start log indexer
 	s.filterMaps.Start()
 	go s.updateFilterMapsHeads()
+
+	// Follow the head of the primary if the chain database is shared
+	if s.replica {
+		s.closeReplica = make(chan chan struct{})
+		go s.followPrimary()
+	}
 	return nil
 }
 
+// followPrimary periodically updates the chain head to the one written to the
+// shared chain database by the primary node.
+func (s *Ethereum) followPrimary() {
+	ticker := time.NewTicker(replicaFollowInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			updated, err := s.blockchain.FollowHead()
+			if err != nil {
+				log.Warn("Failed to follow primary chain head", "err", err)
+				continue
+			}
+			if updated {
+				head := s.blockchain.CurrentBlock()
+				log.Debug("Followed primary chain head", "number", head.Number, "hash", head.Hash())
+			}
+		case ch := <-s.closeReplica:
+			close(ch)
+			return
+		}
+	}
+}
+
 func (s *Ethereum) newChainView(head *types.Header) *filtermaps.ChainView {
 	if head == nil {
 		return nil
@@ -513,7 +632,15 @@ func (s *Ethereum) Stop() error {
 	s.closeFilterMaps <- ch
 	<-ch
 	s.filterMaps.Stop()
+	if s.closeReplica != nil {
+		ch := make(chan struct{})
+		s.closeReplica <- ch
+		<-ch
+	}
 	s.txPool.Close()
+	if s.blobArchive != nil {
+		s.blobArchive.Close()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 
@@ -94,6 +94,7 @@ var caps = []string{
 	"engine_getPayloadV3",
 	"engine_getPayloadV4",
 	"engine_getBlobsV1",
+	"engine_getBlobsV2",
 	"engine_newPayloadV1",
 	"engine_newPayloadV2",
 	"engine_newPayloadV3",
@@ -535,18 +536,47 @@ func (api *ConsensusAPI) GetBlobsV1(hashes []common.Hash) ([]*engine.BlobAndProo
 	}
 	res := make([]*engine.BlobAndProofV1, len(hashes))
 
-	blobs, proofs := api.eth.TxPool().GetBlobs(hashes)
+	blobs, proofs := api.eth.TxPool().GetBlobs(hashes, types.BlobSidecarVersion0)
 	for i := 0; i < len(blobs); i++ {
 		if blobs[i] != nil {
 			res[i] = &engine.BlobAndProofV1{
 				Blob:  (*blobs[i])[:],
-				Proof: (*proofs[i])[:],
+				Proof: proofs[i][0][:],
 			}
 		}
 	}
 	return res, nil
 }
 
+// GetBlobsV2 returns blobs with their EIP-7594 cell proofs from the transaction
+// pool. Unlike GetBlobsV1, the response is all-or-nothing: if any of the requested
+// blobs is unavailable, nil is returned.
+func (api *ConsensusAPI) GetBlobsV2(hashes []common.Hash) ([]*engine.BlobAndProofV2, error) {
+	if len(hashes) > 128 {
+		return nil, engine.TooLargeRequest.With(fmt.Errorf("requested blob count too large: %v", len(hashes)))
+	}
+	res := make([]*engine.BlobAndProofV2, len(hashes))
+
+	blobs, proofs := api.eth.TxPool().GetBlobs(hashes, types.BlobSidecarVersion1)
+	if len(blobs) != len(hashes) {
+		return nil, nil
+	}
+	for i := 0; i < len(blobs); i++ {
+		if blobs[i] == nil {
+			return nil, nil
+		}
+		cellProofs := make([]hexutil.Bytes, len(proofs[i]))
+		for j := range proofs[i] {
+			cellProofs[j] = proofs[i][j][:]
+		}
+		res[i] = &engine.BlobAndProofV2{
+			Blob:       (*blobs[i])[:],
+			CellProofs: cellProofs,
+		}
+	}
+	return res, nil
+}
+
 // NewPayloadV1 creates an Eth1 block, inserts it in the chain, and returns the status of the chain.
 func (api *ConsensusAPI) NewPayloadV1(params engine.ExecutableData) (engine.PayloadStatusV1, error) {
 	if params.Withdrawals != nil {
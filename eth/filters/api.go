@@ -119,14 +119,16 @@ func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
 }
 
 // NewPendingTransactionFilter creates a filter that fetches pending transactions
-// as transactions enter the pending state.
+// as transactions enter the pending state. Private transactions are only included
+// if the filter is created by a local caller.
 //
 // It is part of the filter package because this filter can be used through the
 // `eth_getFilterChanges` polling method that is also used for log filters.
-func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
+func (api *FilterAPI) NewPendingTransactionFilter(ctx context.Context, fullTx *bool) rpc.ID {
 	var (
 		pendingTxs   = make(chan []*types.Transaction)
 		pendingTxSub = api.events.SubscribePendingTxs(pendingTxs)
+		private      = ethapi.PrivateTxsVisible(ctx)
 	)
 
 	api.filtersMu.Lock()
@@ -137,6 +139,9 @@ func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
 		for {
 			select {
 			case pTx := <-pendingTxs:
+				if !private {
+					pTx = api.publicTxs(pTx)
+				}
 				api.filtersMu.Lock()
 				if f, found := api.filters[pendingTxSub.ID]; found {
 					f.txs = append(f.txs, pTx...)
@@ -156,7 +161,8 @@ func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
 
 // NewPendingTransactions creates a subscription that is triggered each time a
 // transaction enters the transaction pool. If fullTx is true the full tx is
-// sent to the client, otherwise the hash is sent.
+// sent to the client, otherwise the hash is sent. Private transactions are only
+// sent to local subscribers.
 func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -164,6 +170,7 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 	}
 
 	rpcSub := notifier.CreateSubscription()
+	private := ethapi.PrivateTxsVisible(ctx)
 
 	go func() {
 		txs := make(chan []*types.Transaction, 128)
@@ -175,6 +182,9 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 		for {
 			select {
 			case txs := <-txs:
+				if !private {
+					txs = api.publicTxs(txs)
+				}
 				// To keep the original behaviour, send a single tx hash in one notification.
 				// TODO(rjl493456442) Send a batch of tx hashes in one notification
 				latest := api.sys.backend.CurrentHeader()
@@ -195,6 +205,17 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 	return rpcSub, nil
 }
 
+// publicTxs filters the private transactions out of the given pending ones.
+func (api *FilterAPI) publicTxs(txs []*types.Transaction) []*types.Transaction {
+	public := make([]*types.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if !api.sys.backend.IsPrivateTx(tx.Hash()) {
+			public = append(public, tx)
+		}
+	}
+	return public
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 func (api *FilterAPI) NewBlockFilter() rpc.ID {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"net/http/httptest"
 	"reflect"
 	"runtime"
 	"testing"
@@ -47,12 +48,17 @@ type testBackend struct {
 	chainFeed       event.Feed
 	pendingBlock    *types.Block
 	pendingReceipts types.Receipts
+	private         map[common.Hash]bool
 }
 
 func (b *testBackend) ChainConfig() *params.ChainConfig {
 	return params.TestChainConfig
 }
 
+func (b *testBackend) IsPrivateTx(txHash common.Hash) bool {
+	return b.private[txHash]
+}
+
 func (b *testBackend) CurrentHeader() *types.Header {
 	hdr, _ := b.HeaderByNumber(context.TODO(), rpc.LatestBlockNumber)
 	return hdr
@@ -270,7 +276,7 @@ func TestPendingTxFilter(t *testing.T) {
 		hashes []common.Hash
 	)
 
-	fid0 := api.NewPendingTransactionFilter(nil)
+	fid0 := api.NewPendingTransactionFilter(context.Background(), nil)
 
 	time.Sleep(1 * time.Second)
 	backend.txFeed.Send(core.NewTxsEvent{Txs: transactions})
@@ -306,6 +312,67 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestPendingTxFilterPrivate tests that private transactions are only delivered
+// to the pending tx filters created by local callers.
+func TestPendingTxFilterPrivate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(db, Config{})
+		api          = NewFilterAPI(sys)
+
+		transactions = []*types.Transaction{
+			types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
+			types.NewTransaction(1, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
+		}
+	)
+	backend.private = map[common.Hash]bool{transactions[1].Hash(): true}
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := rpc.Dial(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var remote rpc.ID
+	if err := client.Call(&remote, "eth_newPendingTransactionFilter"); err != nil {
+		t.Fatalf("failed to create remote filter: %v", err)
+	}
+	local := api.NewPendingTransactionFilter(context.Background(), nil)
+
+	time.Sleep(1 * time.Second)
+	backend.txFeed.Send(core.NewTxsEvent{Txs: transactions})
+
+	collect := func(id rpc.ID, want int) []common.Hash {
+		var hashes []common.Hash
+		for timeout := time.Now().Add(time.Second); len(hashes) < want && time.Now().Before(timeout); {
+			results, err := api.GetFilterChanges(id)
+			if err != nil {
+				t.Fatalf("Unable to retrieve transactions: %v", err)
+			}
+			hashes = append(hashes, results.([]common.Hash)...)
+			time.Sleep(100 * time.Millisecond)
+		}
+		return hashes
+	}
+	if hashes := collect(local, 2); len(hashes) != 2 {
+		t.Errorf("local filter: invalid number of transactions, want 2, got %d", len(hashes))
+	}
+	hashes := collect(remote, 2)
+	if len(hashes) != 1 || hashes[0] != transactions[0].Hash() {
+		t.Errorf("remote filter: invalid transactions, want [%x], got %x", transactions[0].Hash(), hashes)
+	}
+}
+
 // TestPendingTxFilterFullTx tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilterFullTx(t *testing.T) {
 	t.Parallel()
@@ -327,7 +394,7 @@ func TestPendingTxFilterFullTx(t *testing.T) {
 	)
 
 	fullTx := true
-	fid0 := api.NewPendingTransactionFilter(&fullTx)
+	fid0 := api.NewPendingTransactionFilter(context.Background(), &fullTx)
 
 	time.Sleep(1 * time.Second)
 	backend.txFeed.Send(core.NewTxsEvent{Txs: transactions})
@@ -612,7 +679,7 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 	// timeout either in 100ms or 200ms
 	subs := make([]*Subscription, 20)
 	for i := range subs {
-		fid := api.NewPendingTransactionFilter(nil)
+		fid := api.NewPendingTransactionFilter(context.Background(), nil)
 		api.filtersMu.Lock()
 		f, ok := api.filters[fid]
 		api.filtersMu.Unlock()
@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/event"
@@ -434,3 +435,94 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that privately submitted transactions are not propagated to peers, neither
+// via direct broadcasts, nor via announcements.
+func TestPrivateTransactionPropagation68(t *testing.T) {
+	testPrivateTransactionPropagation(t, eth.ETH68)
+}
+
+func testPrivateTransactionPropagation(t *testing.T, protocol uint) {
+	t.Parallel()
+
+	source := newTestHandler()
+	source.handler.snapSync.Store(false) // Avoid requiring snap, otherwise some will be dropped below
+	defer source.close()
+
+	sinks := make([]*testHandler, 4)
+	for i := 0; i < len(sinks); i++ {
+		sinks[i] = newTestHandler()
+		defer sinks[i].close()
+
+		sinks[i].handler.synced.Store(true) // mark synced to accept transactions
+	}
+	// Create a batch of private transactions and add them before any peers are
+	// connected to also check the initial sync
+	var (
+		private = make([]*types.Transaction, 16)
+		public  = make([]*types.Transaction, 16)
+	)
+	for nonce := range private {
+		tx := types.NewTransaction(uint64(nonce), common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+		private[nonce] = tx
+	}
+	source.txpool.addPrivate(private[:len(private)/2])
+
+	for i, sink := range sinks {
+		sourcePipe, sinkPipe := p2p.MsgPipe()
+		defer sourcePipe.Close()
+		defer sinkPipe.Close()
+
+		sourcePeer := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{byte(i + 1)}, "", nil, sourcePipe), sourcePipe, source.txpool)
+		sinkPeer := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{0}, "", nil, sinkPipe), sinkPipe, sink.txpool)
+		defer sourcePeer.Close()
+		defer sinkPeer.Close()
+
+		go source.handler.runEthPeer(sourcePeer, func(peer *eth.Peer) error {
+			return eth.Handle((*ethHandler)(source.handler), peer)
+		})
+		go sink.handler.runEthPeer(sinkPeer, func(peer *eth.Peer) error {
+			return eth.Handle((*ethHandler)(sink.handler), peer)
+		})
+	}
+	txChs := make([]chan core.NewTxsEvent, len(sinks))
+	for i := 0; i < len(sinks); i++ {
+		txChs[i] = make(chan core.NewTxsEvent, 1024)
+
+		sub := sinks[i].txpool.SubscribeTransactions(txChs[i], false)
+		defer sub.Unsubscribe()
+	}
+	// Add the rest of the private transactions with the peers connected, followed
+	// by a batch of public ones
+	source.txpool.addPrivate(private[len(private)/2:])
+
+	key, _ := crypto.GenerateKey()
+	for nonce := range public {
+		tx := types.NewTransaction(uint64(nonce), common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+		public[nonce] = tx
+	}
+	source.txpool.Add(public, false)
+
+	// Ensure all sinks only receive the public transactions
+	for i := range sinks {
+		for arrived, timeout := 0, false; arrived < len(public) && !timeout; {
+			select {
+			case event := <-txChs[i]:
+				for _, tx := range event.Txs {
+					if source.txpool.IsPrivate(tx.Hash()) {
+						t.Fatalf("sink %d: private transaction propagated: %x", i, tx.Hash())
+					}
+				}
+				arrived += len(event.Txs)
+			case <-time.After(2 * time.Second):
+				t.Errorf("sink %d: transaction propagation timed out: have %d, want %d", i, arrived, len(public))
+				timeout = true
+			}
+		}
+		if pooled := len(sinks[i].txpool.pool); pooled != len(public) {
+			t.Errorf("sink %d: pooled transaction count mismatch: have %d, want %d", i, pooled, len(public))
+		}
+	}
+}
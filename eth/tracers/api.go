@@ -858,6 +858,32 @@ func containsTx(block *types.Block, hash common.Hash) bool {
 // TraceTransaction returns the structured logs created during the execution of EVM
 // and returns them as a JSON object.
 func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (interface{}, error) {
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	env, err := api.transactionEnv(ctx, hash, reexec)
+	if err != nil {
+		return nil, err
+	}
+	defer env.release()
+
+	return api.traceTx(ctx, env.tx, env.msg, env.txctx, env.vmctx, env.statedb, config, nil)
+}
+
+// traceEnv is the environment a transaction or call is traced in.
+type traceEnv struct {
+	tx          *types.Transaction
+	msg         *core.Message
+	txctx       *Context
+	vmctx       vm.BlockContext
+	statedb     *state.StateDB
+	precompiles vm.PrecompiledContracts
+	release     StateReleaseFunc
+}
+
+// transactionEnv recreates the environment a mined transaction was executed in.
+func (api *API) transactionEnv(ctx context.Context, hash common.Hash, reexec uint64) (*traceEnv, error) {
 	found, _, blockHash, blockNumber, index, err := api.backend.GetTransaction(ctx, hash)
 	if err != nil {
 		return nil, ethapi.NewTxIndexingError()
@@ -870,10 +896,6 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 	if blockNumber == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
-	reexec := defaultTraceReexec
-	if config != nil && config.Reexec != nil {
-		reexec = *config.Reexec
-	}
 	block, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(blockNumber), blockHash)
 	if err != nil {
 		return nil, err
@@ -882,19 +904,24 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 	if err != nil {
 		return nil, err
 	}
-	defer release()
 	msg, err := core.TransactionToMessage(tx, types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time()), block.BaseFee())
 	if err != nil {
+		release()
 		return nil, err
 	}
-
-	txctx := &Context{
-		BlockHash:   blockHash,
-		BlockNumber: block.Number(),
-		TxIndex:     int(index),
-		TxHash:      hash,
-	}
-	return api.traceTx(ctx, tx, msg, txctx, vmctx, statedb, config, nil)
+	return &traceEnv{
+		tx:  tx,
+		msg: msg,
+		txctx: &Context{
+			BlockHash:   blockHash,
+			BlockNumber: block.Number(),
+			TxIndex:     int(index),
+			TxHash:      hash,
+		},
+		vmctx:   vmctx,
+		statedb: statedb,
+		release: release,
+	}, nil
 }
 
 // TraceCall lets you trace a given eth_call. It collects the structured logs
@@ -905,6 +932,22 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 // the trace will be conducted on the state after executing the specified transaction
 // within the specified block.
 func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	env, err := api.callEnv(ctx, args, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	defer env.release()
+
+	var traceConfig *TraceConfig
+	if config != nil {
+		traceConfig = &config.TraceConfig
+	}
+	return api.traceTx(ctx, env.tx, env.msg, env.txctx, env.vmctx, env.statedb, traceConfig, env.precompiles)
+}
+
+// callEnv creates the environment to execute a call in on top of the given
+// block, with the overrides of the config applied.
+func (api *API) callEnv(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (*traceEnv, error) {
 	// Try to retrieve the specified block
 	var (
 		err         error
@@ -945,29 +988,29 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	if err != nil {
 		return nil, err
 	}
-	defer release()
-
 	vmctx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
 	// Apply the customization rules if required.
 	if config != nil {
 		if overrideErr := config.BlockOverrides.Apply(&vmctx); overrideErr != nil {
+			release()
 			return nil, overrideErr
 		}
 		rules := api.backend.ChainConfig().Rules(vmctx.BlockNumber, vmctx.Random != nil, vmctx.Time)
 
 		precompiles = vm.ActivePrecompiledContracts(rules)
 		if err := config.StateOverrides.Apply(statedb, precompiles); err != nil {
+			release()
 			return nil, err
 		}
 	}
 	// Execute the trace
 	if err := args.CallDefaults(api.backend.RPCGasCap(), vmctx.BaseFee, api.backend.ChainConfig().ChainID); err != nil {
+		release()
 		return nil, err
 	}
 	var (
-		msg         = args.ToMessage(vmctx.BaseFee, true, true)
-		tx          = args.ToTransaction(types.LegacyTxType)
-		traceConfig *TraceConfig
+		msg = args.ToMessage(vmctx.BaseFee, true, true)
+		tx  = args.ToTransaction(types.LegacyTxType)
 	)
 	// Lower the basefee to 0 to avoid breaking EVM
 	// invariants (basefee < feecap).
@@ -977,10 +1020,15 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	if msg.BlobGasFeeCap != nil && msg.BlobGasFeeCap.BitLen() == 0 {
 		vmctx.BlobBaseFee = new(big.Int)
 	}
-	if config != nil {
-		traceConfig = &config.TraceConfig
-	}
-	return api.traceTx(ctx, tx, msg, new(Context), vmctx, statedb, traceConfig, precompiles)
+	return &traceEnv{
+		tx:          tx,
+		msg:         msg,
+		txctx:       new(Context),
+		vmctx:       vmctx,
+		statedb:     statedb,
+		precompiles: precompiles,
+		release:     release,
+	}, nil
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
@@ -991,7 +1039,6 @@ func (api *API) traceTx(ctx context.Context, tx *types.Transaction, message *cor
 		tracer  *Tracer
 		err     error
 		timeout = defaultTraceTimeout
-		usedGas uint64
 	)
 	if config == nil {
 		config = &TraceConfig{}
@@ -1010,36 +1057,52 @@ func (api *API) traceTx(ctx context.Context, tx *types.Transaction, message *cor
 			return nil, err
 		}
 	}
-	tracingStateDB := state.NewHookedState(statedb, tracer.Hooks)
-	evm := vm.NewEVM(vmctx, tracingStateDB, api.backend.ChainConfig(), vm.Config{Tracer: tracer.Hooks, NoBaseFee: true})
-	if precompiles != nil {
-		evm.SetPrecompiles(precompiles)
-	}
-
 	// Define a meaningful timeout of a single transaction trace
 	if config.Timeout != nil {
 		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
 			return nil, err
 		}
 	}
+	if err := api.runTx(ctx, tracer, timeout, tx, message, txctx, vmctx, statedb, precompiles); err != nil {
+		return nil, err
+	}
+	return tracer.GetResult()
+}
+
+// runTx executes the given message in the provided environment with the tracer
+// attached, stopping the tracer and the execution if it exceeds the timeout.
+func (api *API) runTx(ctx context.Context, tracer *Tracer, timeout time.Duration, tx *types.Transaction, message *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, precompiles vm.PrecompiledContracts) error {
+	var usedGas uint64
+
+	tracingStateDB := state.NewHookedState(statedb, tracer.Hooks)
+	evm := vm.NewEVM(vmctx, tracingStateDB, api.backend.ChainConfig(), vm.Config{Tracer: tracer.Hooks, NoBaseFee: true})
+	if precompiles != nil {
+		evm.SetPrecompiles(precompiles)
+	}
 	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
 	go func() {
 		<-deadlineCtx.Done()
-		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+		switch {
+		case errors.Is(deadlineCtx.Err(), context.DeadlineExceeded):
 			tracer.Stop(errors.New("execution timeout"))
 			// Stop evm execution. Note cancellation is not necessarily immediate.
 			evm.Cancel()
+		case ctx.Err() != nil:
+			// The caller abandoned the execution, e.g. an ended debug session,
+			// don't keep running it untraced.
+			tracer.Stop(ctx.Err())
+			evm.Cancel()
 		}
 	}()
 	defer cancel()
 
 	// Call Prepare to clear out the statedb access list
 	statedb.SetTxContext(txctx.TxHash, txctx.TxIndex)
-	_, err = core.ApplyTransactionWithEVM(message, new(core.GasPool).AddGas(message.GasLimit), statedb, vmctx.BlockNumber, txctx.BlockHash, tx, &usedGas, evm)
+	_, err := core.ApplyTransactionWithEVM(message, new(core.GasPool).AddGas(message.GasLimit), statedb, vmctx.BlockNumber, txctx.BlockHash, tx, &usedGas, evm)
 	if err != nil {
-		return nil, fmt.Errorf("tracing failed: %w", err)
+		return fmt.Errorf("tracing failed: %w", err)
 	}
-	return tracer.GetResult()
+	return nil
 }
 
 // APIs return the collection of RPC services the tracer package offers.
@@ -1049,6 +1112,12 @@ func APIs(backend Backend) []rpc.API {
 		{
 			Namespace: "debug",
 			Service:   NewAPI(backend),
+		}, {
+			Namespace: "debug",
+			Service:   NewDebugSessionAPI(backend),
+		}, {
+			Namespace: "trace",
+			Service:   NewTraceAPI(backend),
 		},
 	}
 }
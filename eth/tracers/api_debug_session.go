@@ -0,0 +1,259 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/ethapi/override"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// defaultDebugSessionTimeout is the amount of time a debug session lives by
+	// default before being aborted.
+	defaultDebugSessionTimeout = 5 * time.Minute
+
+	// maxDebugSessionTimeout is the maximum amount of time a debug session can
+	// be configured to live.
+	maxDebugSessionTimeout = time.Hour
+
+	// maxDebugSessions is the maximum number of concurrently live debug
+	// sessions, each of them holding a state and a goroutine.
+	maxDebugSessions = 16
+)
+
+var (
+	errSessionNotFound = errors.New("debug session not found")
+	errTooManySessions = errors.New("too many debug sessions")
+)
+
+// DebugSessionConfig holds extra parameters to debug sessions.
+type DebugSessionConfig struct {
+	Timeout *string
+	Reexec  *uint64
+
+	// Parameters of call sessions only
+	StateOverrides *override.StateOverride
+	BlockOverrides *override.BlockOverrides
+	TxIndex        *hexutil.Uint
+}
+
+// DebugSessionResult is the response to starting a debug session.
+type DebugSessionResult struct {
+	ID    string      `json:"id"`
+	State *DebugState `json:"state"`
+}
+
+// DebugSessionAPI implements interactive debugging of transactions and calls,
+// pausing their execution at every instruction or at breakpoints and allowing
+// to inspect the state of the paused EVM.
+type DebugSessionAPI struct {
+	api *API
+
+	lock     sync.Mutex
+	sessions map[string]*debugSession
+}
+
+// NewDebugSessionAPI creates a new API definition for the debug sessions.
+func NewDebugSessionAPI(backend Backend) *DebugSessionAPI {
+	return &DebugSessionAPI{
+		api:      NewAPI(backend),
+		sessions: make(map[string]*debugSession),
+	}
+}
+
+// StartTransactionSession replays a mined transaction on top of the state it
+// was executed on, pausing at its first instruction.
+func (api *DebugSessionAPI) StartTransactionSession(ctx context.Context, hash common.Hash, config *DebugSessionConfig) (*DebugSessionResult, error) {
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	return api.start(ctx, config, func(ctx context.Context) (*traceEnv, error) {
+		return api.api.transactionEnv(ctx, hash, reexec)
+	})
+}
+
+// StartCallSession executes a call on top of the state of the given block,
+// pausing at its first instruction. Like debug_traceCall, the state and block
+// context can be overridden.
+func (api *DebugSessionAPI) StartCallSession(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *DebugSessionConfig) (*DebugSessionResult, error) {
+	var callConfig *TraceCallConfig
+	if config != nil {
+		callConfig = &TraceCallConfig{
+			TraceConfig:    TraceConfig{Reexec: config.Reexec},
+			StateOverrides: config.StateOverrides,
+			BlockOverrides: config.BlockOverrides,
+			TxIndex:        config.TxIndex,
+		}
+	}
+	return api.start(ctx, config, func(ctx context.Context) (*traceEnv, error) {
+		return api.api.callEnv(ctx, args, blockNrOrHash, callConfig)
+	})
+}
+
+// start creates a debug session executing in the environment created by the
+// given function and waits for it to pause at the first instruction.
+func (api *DebugSessionAPI) start(ctx context.Context, config *DebugSessionConfig, newEnv func(ctx context.Context) (*traceEnv, error)) (*DebugSessionResult, error) {
+	timeout := defaultDebugSessionTimeout
+	if config != nil && config.Timeout != nil {
+		var err error
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+		if timeout > maxDebugSessionTimeout {
+			return nil, fmt.Errorf("session timeout %v exceeds limit %v", timeout, maxDebugSessionTimeout)
+		}
+	}
+	// The session outlives the request starting it, so its environment can't
+	// be bound to the request context. Ending the session cancels it.
+	sessionCtx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	// Reserve a session slot before doing the expensive state regeneration
+	id := string(rpc.NewID())
+	session := newDebugSession(time.Now().Add(timeout), cancel)
+
+	api.lock.Lock()
+	api.expire()
+	if len(api.sessions) >= maxDebugSessions {
+		api.lock.Unlock()
+		cancel()
+		return nil, errTooManySessions
+	}
+	api.sessions[id] = session
+	api.lock.Unlock()
+
+	stop := context.AfterFunc(ctx, cancel)
+
+	env, err := newEnv(sessionCtx)
+	if !stop() && err == nil {
+		env.release()
+		err = ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		api.remove(id)
+		return nil, err
+	}
+	tracer := session.tracer()
+	go func() {
+		defer cancel()
+		defer env.release()
+
+		err := api.api.runTx(sessionCtx, tracer, timeout, env.tx, env.msg, env.txctx, env.vmctx, env.statedb, env.precompiles)
+		session.finish(err)
+	}()
+	state, err := session.wait(ctx)
+	if err != nil {
+		api.EndSession(id)
+		return nil, err
+	}
+	return &DebugSessionResult{ID: id, State: state}, nil
+}
+
+// SessionStep resumes a debug session until the next instruction.
+func (api *DebugSessionAPI) SessionStep(ctx context.Context, id string) (*DebugState, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	return session.resume(ctx, debugStep, nil)
+}
+
+// SessionStepOver resumes a debug session until the next instruction in the
+// current or a shallower call frame, stepping over any call made.
+func (api *DebugSessionAPI) SessionStepOver(ctx context.Context, id string) (*DebugState, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	return session.resume(ctx, debugStepOver, nil)
+}
+
+// SessionContinue resumes a debug session until one of the given breakpoints
+// triggers, or the execution ends if there are none.
+func (api *DebugSessionAPI) SessionContinue(ctx context.Context, id string, breakpoints []DebugBreakpoint) (*DebugState, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	return session.resume(ctx, debugContinue, breakpoints)
+}
+
+// SessionInspect returns the stack, memory and call stack of a paused debug
+// session, along with the given storage and transient storage slots of the
+// executing contract.
+func (api *DebugSessionAPI) SessionInspect(ctx context.Context, id string, storage []common.Hash, transient []common.Hash) (*DebugInspection, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	return session.inspectState(ctx, storage, transient)
+}
+
+// EndSession aborts a debug session and releases its resources.
+func (api *DebugSessionAPI) EndSession(id string) error {
+	session := api.remove(id)
+	if session == nil {
+		return errSessionNotFound
+	}
+	session.close()
+	return nil
+}
+
+// session retrieves a live debug session.
+func (api *DebugSessionAPI) session(id string) (*debugSession, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	api.expire()
+	session, ok := api.sessions[id]
+	if !ok {
+		return nil, errSessionNotFound
+	}
+	return session, nil
+}
+
+// remove unregisters a debug session, returning it if it was registered.
+func (api *DebugSessionAPI) remove(id string) *debugSession {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	session := api.sessions[id]
+	delete(api.sessions, id)
+	return session
+}
+
+// expire drops the sessions which outlived their timeout. The lock must be
+// held by the caller.
+func (api *DebugSessionAPI) expire() {
+	now := time.Now()
+	for id, session := range api.sessions {
+		if now.After(session.expires) {
+			session.close()
+			delete(api.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/ethapi/override"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestDebugSession(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(1)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {})
+	defer backend.teardown()
+
+	var (
+		caller = common.HexToAddress("0xaa")
+		callee = common.HexToAddress("0xbb")
+
+		// The caller calls the callee, then stores 7 at slot 2
+		callerCode = hexutil.Bytes(common.FromHex("6000600060006000600060bb5af1600760025500"))
+		// The callee stores 42 at slot 1
+		calleeCode = hexutil.Bytes(common.FromHex("602a60015500"))
+	)
+
+	ctx := context.Background()
+	api := NewDebugSessionAPI(backend)
+	start := func() string {
+		res, err := api.StartCallSession(ctx, ethapi.TransactionArgs{From: &accounts[0].addr, To: &caller}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), &DebugSessionConfig{
+			StateOverrides: &override.StateOverride{
+				caller: override.OverrideAccount{Code: &callerCode},
+				callee: override.OverrideAccount{Code: &calleeCode},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to start session: %v", err)
+		}
+		if res.State.Pc != 0 || res.State.Op != "PUSH1" || res.State.Depth != 1 || res.State.Address != caller {
+			t.Fatalf("unexpected initial state: %+v", res.State)
+		}
+		return res.ID
+	}
+	check := func(state *DebugState, err error, pc uint64, op string, depth int, addr common.Address) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("failed to resume session: %v", err)
+		}
+		if state.Done || state.Pc != pc || state.Op != op || state.Depth != depth || state.Address != addr {
+			t.Fatalf("unexpected state: have %+v, want pc %d op %s depth %d address %v", state, pc, op, depth, addr)
+		}
+	}
+	u64 := func(n uint64) *uint64 { return &n }
+	slot := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+	slotPtr := func(n int64) *common.Hash { h := slot(n); return &h }
+
+	// Step into the call, then step over the remaining instructions of the caller
+	id := start()
+	state, err := api.SessionStep(ctx, id)
+	check(state, err, 2, "PUSH1", 1, caller)
+	state, err = api.SessionContinue(ctx, id, []DebugBreakpoint{{Pc: u64(13)}})
+	check(state, err, 13, "CALL", 1, caller)
+	state, err = api.SessionStep(ctx, id)
+	check(state, err, 0, "PUSH1", 2, callee)
+
+	inspection, err := api.SessionInspect(ctx, id, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to inspect session: %v", err)
+	}
+	if len(inspection.CallStack) != 2 || inspection.CallStack[0] != caller || inspection.CallStack[1] != callee {
+		t.Fatalf("unexpected call stack: %v", inspection.CallStack)
+	}
+	state, err = api.SessionContinue(ctx, id, []DebugBreakpoint{{Address: &caller, Op: "sstore"}})
+	check(state, err, 18, "SSTORE", 1, caller)
+	state, err = api.SessionStepOver(ctx, id)
+	check(state, err, 19, "STOP", 1, caller)
+
+	inspection, err = api.SessionInspect(ctx, id, []common.Hash{slot(2)}, []common.Hash{slot(2)})
+	if err != nil {
+		t.Fatalf("failed to inspect session: %v", err)
+	}
+	if inspection.Storage[slot(2)] != slot(7) || inspection.TransientStorage[slot(2)] != (common.Hash{}) {
+		t.Fatalf("unexpected storage: %v, transient %v", inspection.Storage, inspection.TransientStorage)
+	}
+	state, err = api.SessionContinue(ctx, id, nil)
+	if err != nil || !state.Done || state.Error != "" {
+		t.Fatalf("unexpected final state: %+v, %v", state, err)
+	}
+	if _, err := api.SessionInspect(ctx, id, nil, nil); !errors.Is(err, errSessionFinished) {
+		t.Fatalf("unexpected inspection error after finish: %v", err)
+	}
+	if err := api.EndSession(id); err != nil {
+		t.Fatalf("failed to end session: %v", err)
+	}
+	if _, err := api.SessionStep(ctx, id); !errors.Is(err, errSessionNotFound) {
+		t.Fatalf("unexpected error after end: %v", err)
+	}
+
+	// Step over the call entirely and break on the storage write of the callee
+	id = start()
+	state, err = api.SessionContinue(ctx, id, []DebugBreakpoint{{Pc: u64(13)}})
+	check(state, err, 13, "CALL", 1, caller)
+	state, err = api.SessionStepOver(ctx, id)
+	check(state, err, 14, "PUSH1", 1, caller)
+	if err := api.EndSession(id); err != nil {
+		t.Fatalf("failed to end session: %v", err)
+	}
+
+	id = start()
+	state, err = api.SessionContinue(ctx, id, []DebugBreakpoint{{Address: &callee}})
+	check(state, err, 0, "PUSH1", 2, callee)
+	state, err = api.SessionContinue(ctx, id, []DebugBreakpoint{{Slot: slotPtr(2)}, {StorageWrite: true}})
+	check(state, err, 4, "SSTORE", 2, callee)
+	state, err = api.SessionContinue(ctx, id, []DebugBreakpoint{{Slot: slotPtr(2)}})
+	check(state, err, 18, "SSTORE", 1, caller)
+	if err := api.EndSession(id); err != nil {
+		t.Fatalf("failed to end session: %v", err)
+	}
+}
+
+// Tests that ending a session stops the EVM instead of letting the execution
+// run on untraced until it exhausts its gas.
+func TestDebugSessionEndStopsExecution(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(1)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {})
+	defer backend.teardown()
+
+	var (
+		looper = common.HexToAddress("0xaa")
+		// JUMPDEST PUSH1 0 JUMP, looping until the gas runs out
+		looperCode = hexutil.Bytes(common.FromHex("5b600056"))
+	)
+	api := NewDebugSessionAPI(backend)
+	env, err := api.api.callEnv(context.Background(), ethapi.TransactionArgs{From: &accounts[0].addr, To: &looper}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), &TraceCallConfig{
+		StateOverrides: &override.StateOverride{
+			looper: override.OverrideAccount{Code: &looperCode},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create environment: %v", err)
+	}
+	defer env.release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session := newDebugSession(time.Now().Add(time.Minute), cancel)
+
+	// End the session on the very first opcode and count the ones executed after
+	var (
+		tracer = session.tracer()
+		hooks  = *tracer.Hooks
+		steps  int
+	)
+	hooks.OnOpcode = func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+		switch steps++; steps {
+		case 1:
+			session.close()
+		case 2:
+			// Give the runner a moment to notice the cancellation
+			time.Sleep(50 * time.Millisecond)
+		}
+		tracer.OnOpcode(pc, op, gas, cost, scope, rData, depth, err)
+	}
+	if err := api.api.runTx(ctx, &Tracer{Hooks: &hooks, GetResult: tracer.GetResult, Stop: tracer.Stop}, time.Minute, env.tx, env.msg, env.txctx, env.vmctx, env.statedb, env.precompiles); err != nil {
+		t.Fatalf("failed to run transaction: %v", err)
+	}
+	// The gas allows for millions of steps, the cancellation should hit way sooner
+	if steps > 100000 {
+		t.Fatalf("execution kept running after the session ended: %d steps", steps)
+	}
+}
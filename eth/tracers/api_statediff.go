@@ -0,0 +1,164 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BlockStateDiffConfig holds extra parameters to block state diff functions.
+type BlockStateDiffConfig struct {
+	Reexec *uint64
+
+	// Preimages annotates changed storage slots with the preimages of their
+	// keys, e.g. the mapping key and slot a mapping entry was hashed from.
+	// Preimages are the keccak256 inputs recorded while re-executing the
+	// block, slots whose keys were not hashed by the block stay unannotated.
+	Preimages bool
+}
+
+// TraceBlockStateDiff re-executes a block and returns its net state diff: the
+// balance, nonce, code and storage of every account whose state differs
+// between the parent block and the block, including the changes made by
+// system calls and block finalization.
+func (api *API) TraceBlockStateDiff(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *BlockStateDiffConfig) (*BlockStateDiff, error) {
+	var (
+		block *types.Block
+		err   error
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		if number == rpc.PendingBlockNumber {
+			return nil, errors.New("tracing on top of pending is not supported")
+		}
+		block, err = api.blockByNumber(ctx, number)
+	} else {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, release, err := api.backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		preimages   = config != nil && config.Preimages
+		recorder    = NewStateDiffRecorder()
+		hooks       = recorder.Hooks()
+		chainConfig = api.backend.ChainConfig()
+		signer      = types.MakeSigner(chainConfig, block.Number(), block.Time())
+		vmctx       = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		tracingDB   = state.NewHookedState(statedb, hooks)
+		evm         = vm.NewEVM(vmctx, tracingDB, chainConfig, vm.Config{Tracer: hooks, EnablePreimageRecording: preimages})
+		usedGas     uint64
+	)
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		core.ProcessBeaconBlockRoot(*beaconRoot, evm)
+	}
+	if chainConfig.IsPrague(block.Number(), block.Time()) {
+		core.ProcessParentBlockHash(block.ParentHash(), evm)
+	}
+	gp := new(core.GasPool).AddGas(block.GasLimit())
+	for i, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msg, err := core.TransactionToMessage(tx, signer, block.BaseFee())
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+		if _, err := core.ApplyTransactionWithEVM(msg, gp, statedb, block.Number(), block.Hash(), tx, &usedGas, evm); err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+	}
+	if chainConfig.IsPrague(block.Number(), block.Time()) {
+		var requests [][]byte
+		core.ProcessWithdrawalQueue(&requests, evm)
+		core.ProcessConsolidationQueue(&requests, evm)
+	}
+	api.backend.Engine().Finalize(&chainHeaderReader{api: api, ctx: ctx}, block.Header(), tracingDB, block.Body())
+	statedb.Finalise(chainConfig.IsEIP158(block.Number()))
+
+	var resolve func(common.Hash) []byte
+	if preimages {
+		recorded := statedb.Preimages()
+		resolve = func(hash common.Hash) []byte {
+			return recorded[hash]
+		}
+	}
+	return recorder.Diff(block, statedb, resolve), nil
+}
+
+// chainHeaderReader implements consensus.ChainHeaderReader on top of the
+// tracing backend, for consensus engines to finalize re-executed blocks.
+type chainHeaderReader struct {
+	api *API
+	ctx context.Context
+}
+
+func (r *chainHeaderReader) Config() *params.ChainConfig {
+	return r.api.backend.ChainConfig()
+}
+
+func (r *chainHeaderReader) CurrentHeader() *types.Header {
+	header, _ := r.api.backend.HeaderByNumber(r.ctx, rpc.LatestBlockNumber)
+	return header
+}
+
+func (r *chainHeaderReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header, _ := r.api.backend.HeaderByHash(r.ctx, hash)
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+func (r *chainHeaderReader) GetHeaderByNumber(number uint64) *types.Header {
+	header, _ := r.api.backend.HeaderByNumber(r.ctx, rpc.BlockNumber(number))
+	return header
+}
+
+func (r *chainHeaderReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	header, _ := r.api.backend.HeaderByHash(r.ctx, hash)
+	return header
+}
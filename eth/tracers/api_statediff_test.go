@@ -0,0 +1,154 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestTraceBlockStateDiff(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		contract = common.HexToAddress("0xc0de")
+
+		// The contract stores 7 at the slot of key 0x2a in the mapping at slot 1,
+		// i.e. keccak256(0x2a . 1), then 1 at the plain slot 5.
+		code = common.FromHex("602a6000526001602052604060002060079055600160055500")
+	)
+
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+			contract:         {Code: code},
+		},
+	}
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 2, genesis, func(i int, b *core.BlockGen) {
+		if i != 1 {
+			return
+		}
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    0,
+			To:       &accounts[1].addr,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: b.BaseFee(),
+		}), signer, accounts[0].key)
+		b.AddTx(tx)
+
+		tx, _ = types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    1,
+			To:       &contract,
+			Gas:      100000,
+			GasPrice: b.BaseFee(),
+		}), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	defer backend.teardown()
+
+	api := NewAPI(backend)
+	diff, err := api.TraceBlockStateDiff(context.Background(), rpc.BlockNumberOrHashWithNumber(2), &BlockStateDiffConfig{Preimages: true})
+	if err != nil {
+		t.Fatalf("failed to trace block state diff: %v", err)
+	}
+	block := backend.chain.GetBlockByNumber(2)
+	if diff.Hash != block.Hash() || uint64(diff.Number) != 2 {
+		t.Fatalf("unexpected block: %d %x", diff.Number, diff.Hash)
+	}
+	// The diff must lead from the parent state to the block state
+	pre, err := backend.chain.StateAt(backend.chain.GetBlockByNumber(1).Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := backend.chain.StateAt(block.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAccountDiffs(t, diff, pre, post)
+
+	// The sender, recipient, contract and coinbase must all have changed
+	for _, addr := range []common.Address{accounts[0].addr, accounts[1].addr, contract, block.Coinbase()} {
+		if diff.Accounts[addr] == nil {
+			t.Errorf("account %x missing from diff", addr)
+		}
+	}
+	if nonce := diff.Accounts[accounts[0].addr].Nonce; nonce == nil || nonce.From != 0 || nonce.To != 2 {
+		t.Errorf("unexpected sender nonce diff: %+v", nonce)
+	}
+	key := append(common.LeftPadBytes([]byte{0x2a}, 32), common.LeftPadBytes([]byte{1}, 32)...)
+	slot := diff.Accounts[contract].Storage[crypto.Keccak256Hash(key)]
+	if slot == nil || slot.To != common.BigToHash(big.NewInt(7)) || !bytes.Equal(slot.Preimage, key) {
+		t.Errorf("unexpected mapping slot diff: %+v", slot)
+	}
+	// Slots not hashed by the block have no preimage to annotate them with
+	slot = diff.Accounts[contract].Storage[common.BigToHash(big.NewInt(5))]
+	if slot == nil || slot.To != common.BigToHash(big.NewInt(1)) || slot.Preimage != nil {
+		t.Errorf("unexpected plain slot diff: %+v", slot)
+	}
+	// Blocks without transactions only credit the coinbase
+	diff, err = api.TraceBlockStateDiff(context.Background(), rpc.BlockNumberOrHashWithNumber(1), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block state diff: %v", err)
+	}
+	if len(diff.Accounts) != 1 || diff.Accounts[block.Coinbase()] == nil {
+		t.Errorf("unexpected empty block diff: %v", diff.Accounts)
+	}
+}
+
+// checkAccountDiffs verifies that every field of a diff matches the states
+// before and after the block.
+func checkAccountDiffs(t *testing.T, diff *BlockStateDiff, pre, post *state.StateDB) {
+	t.Helper()
+
+	for addr, acc := range diff.Accounts {
+		if acc.Balance != nil {
+			if pre.GetBalance(addr).ToBig().Cmp(acc.Balance.From.ToInt()) != 0 || post.GetBalance(addr).ToBig().Cmp(acc.Balance.To.ToInt()) != 0 {
+				t.Errorf("account %x: balance mismatch: %v -> %v", addr, acc.Balance.From, acc.Balance.To)
+			}
+		}
+		if acc.Nonce != nil {
+			if pre.GetNonce(addr) != uint64(acc.Nonce.From) || post.GetNonce(addr) != uint64(acc.Nonce.To) {
+				t.Errorf("account %x: nonce mismatch: %d -> %d", addr, acc.Nonce.From, acc.Nonce.To)
+			}
+		}
+		if acc.Code != nil {
+			if !bytes.Equal(pre.GetCode(addr), acc.Code.From) || !bytes.Equal(post.GetCode(addr), acc.Code.To) {
+				t.Errorf("account %x: code mismatch", addr)
+			}
+		}
+		for slot, value := range acc.Storage {
+			if pre.GetState(addr, slot) != value.From || post.GetState(addr, slot) != value.To {
+				t.Errorf("account %x: slot %x mismatch: %x -> %x", addr, slot, value.From, value.To)
+			}
+		}
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxTraceFilterBlocks is the maximum number of blocks a single trace_filter
+	// call is allowed to trace.
+	maxTraceFilterBlocks = 1000
+
+	// Names of the tracers backing the individual replay modes.
+	flatCallTracerName  = "flatCallTracer"
+	stateDiffTracerName = "stateDiffTracer"
+	vmTracerName        = "vmTracer"
+)
+
+// Replay modes supported by the trace_replay* methods.
+const (
+	TraceModeTrace     = "trace"
+	TraceModeStateDiff = "stateDiff"
+	TraceModeVMTrace   = "vmTrace"
+)
+
+var errInvalidTraceMode = errors.New("invalid trace mode")
+
+// flatCallTracerConfig makes the flat call tracer report errors the way Parity
+// did, which is what consumers of the trace namespace expect.
+var flatCallTracerConfig = json.RawMessage(`{"convertParityErrors":true}`)
+
+// TraceResults is the result of replaying a single transaction.
+type TraceResults struct {
+	Output          hexutil.Bytes   `json:"output"`
+	StateDiff       json.RawMessage `json:"stateDiff"`
+	Trace           json.RawMessage `json:"trace"`
+	VMTrace         json.RawMessage `json:"vmTrace"`
+	TransactionHash *common.Hash    `json:"transactionHash,omitempty"`
+}
+
+// TraceFilterArgs are the arguments of trace_filter.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// flatTrace is the subset of a flat call frame needed to filter traces.
+type flatTrace struct {
+	Action struct {
+		From *common.Address `json:"from"`
+		To   *common.Address `json:"to"`
+	} `json:"action"`
+	Result *struct {
+		Address *common.Address `json:"address"`
+		Output  hexutil.Bytes   `json:"output"`
+	} `json:"result"`
+}
+
+// TraceIndex is a persistent index of the flat call traces of processed blocks,
+// allowing trace_filter to skip re-executing the blocks it covers. Blocks are
+// identified by both number and hash, so the traces of reorged blocks are never
+// mistaken for canonical ones.
+type TraceIndex interface {
+	// HasBlock reports whether the traces of the given block are indexed.
+	HasBlock(number uint64, hash common.Hash) bool
+
+	// BlockTraces returns the indexed flat call traces of the given block.
+	BlockTraces(number uint64, hash common.Hash) ([]json.RawMessage, error)
+
+	// AddressBlocks returns the hashes of the indexed blocks within the given
+	// range, both limits inclusive, containing a trace sent from (sender) or
+	// addressed to the given account.
+	AddressBlocks(addr common.Address, sender bool, first, last uint64) []common.Hash
+}
+
+// traceIndexBackend is implemented by backends maintaining a trace index.
+type traceIndexBackend interface {
+	TraceIndex() TraceIndex
+}
+
+// TraceAPI implements the Parity-style trace namespace on top of the native
+// flat call, state diff and vm tracers.
+type TraceAPI struct {
+	api *API
+}
+
+// NewTraceAPI creates a new API definition for the trace namespace.
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{api: NewAPI(backend)}
+}
+
+// Block returns the flat call traces of all the transactions in a block.
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]json.RawMessage, error) {
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.blockTraces(ctx, block)
+}
+
+// Transaction returns the flat call traces of a transaction.
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]json.RawMessage, error) {
+	tracer := flatCallTracerName
+	res, err := api.api.TraceTransaction(ctx, hash, &TraceConfig{Tracer: &tracer, TracerConfig: flatCallTracerConfig})
+	if err != nil {
+		return nil, err
+	}
+	var traces []json.RawMessage
+	if err := json.Unmarshal(res.(json.RawMessage), &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// ReplayTransaction replays a transaction, returning the traces requested by
+// the given modes: "trace", "stateDiff" and "vmTrace".
+func (api *TraceAPI) ReplayTransaction(ctx context.Context, hash common.Hash, modes []string) (*TraceResults, error) {
+	config, err := replayConfig(modes)
+	if err != nil {
+		return nil, err
+	}
+	res, err := api.api.TraceTransaction(ctx, hash, config)
+	if err != nil {
+		return nil, err
+	}
+	return newTraceResults(res.(json.RawMessage), modes)
+}
+
+// ReplayBlockTransactions replays all the transactions in a block, returning
+// the traces requested by the given modes: "trace", "stateDiff" and "vmTrace".
+func (api *TraceAPI) ReplayBlockTransactions(ctx context.Context, number rpc.BlockNumber, modes []string) ([]*TraceResults, error) {
+	config, err := replayConfig(modes)
+	if err != nil {
+		return nil, err
+	}
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := api.api.traceBlock(ctx, block, config)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*TraceResults, len(txs))
+	for i, tx := range txs {
+		if results[i], err = newTraceResults(tx.Result.(json.RawMessage), modes); err != nil {
+			return nil, err
+		}
+		results[i].TransactionHash = &tx.TxHash
+	}
+	return results, nil
+}
+
+// Filter returns the flat call traces within a block range matching the given
+// sender and recipient addresses. A trace matches if its sender is any of the
+// from addresses and its recipient is any of the to addresses, an empty list
+// matching everything.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]json.RawMessage, error) {
+	from, err := api.resolveNumber(ctx, args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.resolveNumber(ctx, args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %d > %d", from, to)
+	}
+	if to-from >= maxTraceFilterBlocks {
+		return nil, fmt.Errorf("block range too large, %d > %d", to-from+1, maxTraceFilterBlocks)
+	}
+	// The genesis block has no transactions to trace
+	from = max(from, 1)
+
+	var (
+		after   = uint64(0)
+		results = []json.RawMessage{}
+		index   TraceIndex
+	)
+	if args.After != nil {
+		after = *args.After
+	}
+	if backend, ok := api.api.backend.(traceIndexBackend); ok {
+		index = backend.TraceIndex()
+	}
+	var (
+		senders    = addressBlocks(index, args.FromAddress, true, from, to)
+		recipients = addressBlocks(index, args.ToAddress, false, from, to)
+	)
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if args.Count != nil && uint64(len(results)) >= *args.Count {
+			break
+		}
+		var traces []json.RawMessage
+		if index != nil {
+			header, err := api.api.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if err != nil {
+				return nil, err
+			}
+			if header == nil {
+				return nil, fmt.Errorf("block #%d not found", number)
+			}
+			hash := header.Hash()
+			if index.HasBlock(number, hash) {
+				// Indexed blocks without any trace of the filtered accounts are skipped
+				// without loading their traces at all.
+				if !hasBlock(senders, hash) || !hasBlock(recipients, hash) {
+					continue
+				}
+				if traces, err = index.BlockTraces(number, hash); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if traces == nil {
+			block, err := api.api.blockByNumber(ctx, rpc.BlockNumber(number))
+			if err != nil {
+				return nil, err
+			}
+			if len(block.Transactions()) == 0 {
+				continue
+			}
+			if traces, err = api.blockTraces(ctx, block); err != nil {
+				return nil, err
+			}
+		}
+		for _, trace := range traces {
+			ok, err := matchTrace(trace, args.FromAddress, args.ToAddress)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if after > 0 {
+				after--
+				continue
+			}
+			if args.Count != nil && uint64(len(results)) >= *args.Count {
+				break
+			}
+			results = append(results, trace)
+		}
+	}
+	return results, nil
+}
+
+// addressBlocks returns the set of indexed blocks within the range containing
+// a trace sent from (sender) or addressed to any of the given accounts. A nil
+// set is returned if there is no index or no address to filter on, matching
+// every block.
+func addressBlocks(index TraceIndex, addrs []common.Address, sender bool, first, last uint64) map[common.Hash]struct{} {
+	if index == nil || len(addrs) == 0 {
+		return nil
+	}
+	blocks := make(map[common.Hash]struct{})
+	for _, addr := range addrs {
+		for _, hash := range index.AddressBlocks(addr, sender, first, last) {
+			blocks[hash] = struct{}{}
+		}
+	}
+	return blocks
+}
+
+// hasBlock reports whether a block is contained in a set returned by
+// addressBlocks.
+func hasBlock(blocks map[common.Hash]struct{}, hash common.Hash) bool {
+	if blocks == nil {
+		return true
+	}
+	_, ok := blocks[hash]
+	return ok
+}
+
+// blockTraces returns the flat call traces of all the transactions in a block.
+func (api *TraceAPI) blockTraces(ctx context.Context, block *types.Block) ([]json.RawMessage, error) {
+	tracer := flatCallTracerName
+	txs, err := api.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer, TracerConfig: flatCallTracerConfig})
+	if err != nil {
+		return nil, err
+	}
+	traces := []json.RawMessage{}
+	for _, tx := range txs {
+		var frames []json.RawMessage
+		if err := json.Unmarshal(tx.Result.(json.RawMessage), &frames); err != nil {
+			return nil, err
+		}
+		traces = append(traces, frames...)
+	}
+	return traces, nil
+}
+
+// resolveNumber converts a block number, potentially a tag, into an absolute
+// one. Nil is treated as the latest block.
+func (api *TraceAPI) resolveNumber(ctx context.Context, number *rpc.BlockNumber) (uint64, error) {
+	if number == nil {
+		latest := rpc.LatestBlockNumber
+		number = &latest
+	}
+	return api.api.resolveNumber(ctx, *number)
+}
+
+// replayConfig creates the trace config running all the tracers needed for
+// the given replay modes at once.
+func replayConfig(modes []string) (*TraceConfig, error) {
+	// The flat call tracer is always run, the output is extracted from it
+	tracers := map[string]json.RawMessage{flatCallTracerName: flatCallTracerConfig}
+	for _, mode := range modes {
+		switch mode {
+		case TraceModeTrace:
+		case TraceModeStateDiff:
+			tracers[stateDiffTracerName] = json.RawMessage("{}")
+		case TraceModeVMTrace:
+			tracers[vmTracerName] = json.RawMessage("{}")
+		default:
+			return nil, fmt.Errorf("%w: %q", errInvalidTraceMode, mode)
+		}
+	}
+	config, err := json.Marshal(tracers)
+	if err != nil {
+		return nil, err
+	}
+	tracer := "muxTracer"
+	return &TraceConfig{Tracer: &tracer, TracerConfig: config}, nil
+}
+
+// newTraceResults assembles the replay result of a transaction from the output
+// of the tracers configured by replayConfig.
+func newTraceResults(res json.RawMessage, modes []string) (*TraceResults, error) {
+	var outputs map[string]json.RawMessage
+	if err := json.Unmarshal(res, &outputs); err != nil {
+		return nil, err
+	}
+	var frames []flatTrace
+	if err := json.Unmarshal(outputs[flatCallTracerName], &frames); err != nil {
+		return nil, err
+	}
+	results := &TraceResults{Output: hexutil.Bytes{}}
+	if len(frames) > 0 && frames[0].Result != nil && frames[0].Result.Output != nil {
+		results.Output = frames[0].Result.Output
+	}
+	if slices.Contains(modes, TraceModeTrace) {
+		results.Trace = outputs[flatCallTracerName]
+	}
+	if slices.Contains(modes, TraceModeStateDiff) {
+		results.StateDiff = outputs[stateDiffTracerName]
+	}
+	if slices.Contains(modes, TraceModeVMTrace) {
+		results.VMTrace = outputs[vmTracerName]
+	}
+	return results, nil
+}
+
+// matchTrace returns whether a flat call trace matches the given sender and
+// recipient address filters.
+func matchTrace(trace json.RawMessage, from, to []common.Address) (bool, error) {
+	if len(from) == 0 && len(to) == 0 {
+		return true, nil
+	}
+	var frame flatTrace
+	if err := json.Unmarshal(trace, &frame); err != nil {
+		return false, err
+	}
+	if len(from) > 0 && (frame.Action.From == nil || !slices.Contains(from, *frame.Action.From)) {
+		return false, nil
+	}
+	if len(to) > 0 {
+		// Contract creations match on the address of the created contract
+		recipient := frame.Action.To
+		if recipient == nil && frame.Result != nil {
+			recipient = frame.Result.Address
+		}
+		if recipient == nil || !slices.Contains(to, *recipient) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
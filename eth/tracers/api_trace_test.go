@@ -0,0 +1,82 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMatchTrace(t *testing.T) {
+	var (
+		alice   = common.HexToAddress("0xa11ce")
+		bob     = common.HexToAddress("0xb0b")
+		created = common.HexToAddress("0xc0de")
+
+		call   = json.RawMessage(`{"action":{"from":"0x00000000000000000000000000000000000a11ce","to":"0x0000000000000000000000000000000000000b0b"},"type":"call"}`)
+		create = json.RawMessage(`{"action":{"from":"0x00000000000000000000000000000000000a11ce"},"result":{"address":"0x000000000000000000000000000000000000c0de"},"type":"create"}`)
+	)
+	tests := []struct {
+		trace json.RawMessage
+		from  []common.Address
+		to    []common.Address
+		want  bool
+	}{
+		{call, nil, nil, true},
+		{call, []common.Address{alice}, nil, true},
+		{call, []common.Address{bob}, nil, false},
+		{call, nil, []common.Address{bob}, true},
+		{call, []common.Address{alice}, []common.Address{alice}, false},
+		{create, nil, []common.Address{created}, true},
+		{create, nil, []common.Address{bob}, false},
+	}
+	for i, tt := range tests {
+		have, err := matchTrace(tt.trace, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("test %d: failed to match trace: %v", i, err)
+		}
+		if have != tt.want {
+			t.Errorf("test %d: match mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestReplayConfig(t *testing.T) {
+	config, err := replayConfig([]string{TraceModeTrace, TraceModeVMTrace})
+	if err != nil {
+		t.Fatalf("failed to create replay config: %v", err)
+	}
+	var tracers map[string]json.RawMessage
+	if err := json.Unmarshal(config.TracerConfig, &tracers); err != nil {
+		t.Fatalf("failed to unmarshal tracer config: %v", err)
+	}
+	if _, ok := tracers[flatCallTracerName]; !ok {
+		t.Errorf("flat call tracer missing from replay config")
+	}
+	if _, ok := tracers[vmTracerName]; !ok {
+		t.Errorf("vm tracer missing from replay config")
+	}
+	if _, ok := tracers[stateDiffTracerName]; ok {
+		t.Errorf("unrequested state diff tracer in replay config")
+	}
+	if _, err := replayConfig([]string{"bogus"}); !errors.Is(err, errInvalidTraceMode) {
+		t.Errorf("invalid mode error mismatch: have %v, want %v", err, errInvalidTraceMode)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TraceJobAPI controls tracing jobs, which trace a range of blocks like
+// debug_traceChain but run on the node independently of the requester
+// connection, writing the results into files.
+type TraceJobAPI struct {
+	jobs *TraceJobManager
+}
+
+// StartTraceJob starts tracing the blocks between start and end (excluding
+// start) in the background, returning the id of the job. The results of each
+// block with transactions are written as a JSON line to the output files in
+// the job directory.
+func (api *TraceJobAPI) StartTraceJob(ctx context.Context, start, end rpc.BlockNumber, config *TraceJobConfig) (*TraceJobStatus, error) {
+	from, err := api.jobs.api.blockByNumber(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.jobs.api.blockByNumber(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+	if from.NumberU64() >= to.NumberU64() {
+		return nil, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", to.NumberU64(), from.NumberU64())
+	}
+	if config == nil {
+		config = new(TraceJobConfig)
+	}
+	switch config.Compression {
+	case "", "gzip":
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", config.Compression)
+	}
+	if config.MaxFileSize != nil && *config.MaxFileSize == 0 {
+		return nil, errors.New("invalid maximum file size")
+	}
+	// Make sure the tracer can be constructed, not to fail every transaction
+	if config.Tracer != nil {
+		if _, err := DefaultDirectory.New(*config.Tracer, new(Context), config.TracerConfig, api.jobs.api.backend.ChainConfig()); err != nil {
+			return nil, err
+		}
+	}
+	job, err := api.jobs.create(from.NumberU64(), to.NumberU64(), config)
+	if err != nil {
+		return nil, err
+	}
+	return job.status(), nil
+}
+
+// TraceJobStatus returns the progress of a tracing job.
+func (api *TraceJobAPI) TraceJobStatus(id string) (*TraceJobStatus, error) {
+	job, err := api.jobs.job(id)
+	if err != nil {
+		return nil, err
+	}
+	return job.status(), nil
+}
+
+// TraceJobs returns the progress of all the tracing jobs.
+func (api *TraceJobAPI) TraceJobs() []*TraceJobStatus {
+	api.jobs.lock.Lock()
+	jobs := make([]*traceJob, 0, len(api.jobs.jobs))
+	for _, job := range api.jobs.jobs {
+		jobs = append(jobs, job)
+	}
+	api.jobs.lock.Unlock()
+
+	statuses := make([]*TraceJobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		statuses = append(statuses, job.status())
+	}
+	slices.SortFunc(statuses, func(a, b *TraceJobStatus) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return statuses
+}
+
+// PauseTraceJob stops a running tracing job after checkpointing its progress.
+func (api *TraceJobAPI) PauseTraceJob(id string) (*TraceJobStatus, error) {
+	job, err := api.jobs.job(id)
+	if err != nil {
+		return nil, err
+	}
+	job.halt(traceJobPaused)
+	return job.status(), nil
+}
+
+// ResumeTraceJob restarts a paused or failed tracing job from its last
+// checkpoint.
+func (api *TraceJobAPI) ResumeTraceJob(id string) (*TraceJobStatus, error) {
+	job, err := api.jobs.resume(id)
+	if err != nil {
+		return nil, err
+	}
+	return job.status(), nil
+}
+
+// CancelTraceJob stops a tracing job for good. The results written so far
+// are kept.
+func (api *TraceJobAPI) CancelTraceJob(id string) (*TraceJobStatus, error) {
+	job, err := api.jobs.cancel(id)
+	if err != nil {
+		return nil, err
+	}
+	return job.status(), nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTraceJobBackend creates a chain with a transfer in every block.
+func newTraceJobBackend(t *testing.T, blocks int) *testBackend {
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	signer := types.HomesteadSigner{}
+	return newTestBackend(t, blocks, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+}
+
+// waitTraceJob waits for a running job to terminate.
+func waitTraceJob(t *testing.T, m *TraceJobManager, id string) *TraceJobStatus {
+	t.Helper()
+
+	job, err := m.job(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.lock.Lock()
+	done := job.done
+	job.lock.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("tracing job timed out")
+	}
+	return job.status()
+}
+
+// readTraceJobResults reads the block results from the output files of a job.
+func readTraceJobResults(t *testing.T, files []string) []blockTraceResult {
+	t.Helper()
+
+	var results []blockTraceResult
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r io.Reader = file
+		if filepath.Ext(path) == ".gz" {
+			if r, err = gzip.NewReader(file); err != nil {
+				t.Fatal(err)
+			}
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			var result blockTraceResult
+			if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+				t.Fatalf("%s: invalid result: %v", path, err)
+			}
+			results = append(results, result)
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		file.Close()
+	}
+	return results
+}
+
+func TestTraceJob(t *testing.T) {
+	t.Parallel()
+
+	backend := newTraceJobBackend(t, 20)
+	defer backend.teardown()
+
+	m := NewTraceJobManager(backend, t.TempDir())
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+	api := &TraceJobAPI{jobs: m}
+
+	if _, err := api.StartTraceJob(context.Background(), 5, 5, nil); err == nil {
+		t.Error("empty range accepted")
+	}
+	if _, err := api.StartTraceJob(context.Background(), 0, 5, &TraceJobConfig{Compression: "lz4"}); err == nil {
+		t.Error("unknown compression accepted")
+	}
+	size := uint64(1024)
+	status, err := api.StartTraceJob(context.Background(), 0, 20, &TraceJobConfig{Compression: "gzip", MaxFileSize: &size})
+	if err != nil {
+		t.Fatalf("failed to start tracing job: %v", err)
+	}
+	status = waitTraceJob(t, m, status.ID)
+	if status.State != traceJobDone || status.Current != 20 {
+		t.Fatalf("unexpected job status: %+v", status)
+	}
+	if len(status.Files) < 2 {
+		t.Errorf("output files not rotated: %v", status.Files)
+	}
+	results := readTraceJobResults(t, status.Files)
+	if len(results) != 20 {
+		t.Fatalf("unexpected result count: have %d, want 20", len(results))
+	}
+	for i, result := range results {
+		if uint64(result.Block) != uint64(i+1) || len(result.Traces) != 1 {
+			t.Errorf("result %d: unexpected block %d with %d traces", i, result.Block, len(result.Traces))
+		}
+	}
+	// Finished jobs can be neither resumed nor cancelled
+	if _, err := api.ResumeTraceJob(status.ID); err == nil {
+		t.Error("finished job resumed")
+	}
+	if _, err := api.CancelTraceJob(status.ID); err == nil {
+		t.Error("finished job cancelled")
+	}
+	if _, err := api.TraceJobStatus("missing"); err != errTraceJobNotFound {
+		t.Errorf("unexpected error for missing job: %v", err)
+	}
+}
+
+func TestTraceJobRestart(t *testing.T) {
+	t.Parallel()
+
+	backend := newTraceJobBackend(t, 10)
+	defer backend.teardown()
+
+	// Trace the whole range as a reference
+	m := NewTraceJobManager(backend, t.TempDir())
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	status, err := (&TraceJobAPI{jobs: m}).StartTraceJob(context.Background(), 0, 10, nil)
+	if err != nil {
+		t.Fatalf("failed to start tracing job: %v", err)
+	}
+	status = waitTraceJob(t, m, status.ID)
+	m.Stop()
+
+	want, err := os.ReadFile(status.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a node crashing while running the job, after checkpointing the
+	// first four blocks and writing a partial result
+	var (
+		dir    = t.TempDir()
+		jobdir = filepath.Join(dir, "crashed")
+		offset = 0
+	)
+	for i := 0; i < 4; i++ {
+		offset += bytes.IndexByte(want[offset:], '\n') + 1
+	}
+	if err := os.MkdirAll(jobdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	partial := append(bytes.Clone(want[:offset]), `{"block":"0x5","hash":`...)
+	if err := os.WriteFile(filepath.Join(jobdir, "traces-000000.jsonl"), partial, 0644); err != nil {
+		t.Fatal(err)
+	}
+	job := &traceJob{
+		id:  "crashed",
+		dir: jobdir,
+		record: traceJobRecord{
+			Start:  0,
+			End:    10,
+			Config: new(TraceJobConfig),
+			State:  traceJobRunning,
+			Block:  4,
+			Offset: int64(offset),
+			Size:   uint64(offset),
+		},
+	}
+	if err := job.save(); err != nil {
+		t.Fatal(err)
+	}
+	// Restart the node, the job must continue after the checkpoint
+	m = NewTraceJobManager(backend, dir)
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	status = waitTraceJob(t, m, "crashed")
+	if status.State != traceJobDone {
+		t.Fatalf("unexpected job status: %+v", status)
+	}
+	have, err := os.ReadFile(status.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("resumed output mismatch:\nhave %s\nwant %s", have, want)
+	}
+	// Running jobs can be paused, and paused ones cancelled
+	status, err = (&TraceJobAPI{jobs: m}).StartTraceJob(context.Background(), 0, 10, nil)
+	if err != nil {
+		t.Fatalf("failed to start tracing job: %v", err)
+	}
+	if status, err = (&TraceJobAPI{jobs: m}).PauseTraceJob(status.ID); err != nil {
+		t.Fatalf("failed to pause job: %v", err)
+	}
+	if status.State != traceJobPaused && status.State != traceJobDone {
+		t.Errorf("unexpected paused job state: %s", status.State)
+	}
+	if status.State == traceJobPaused {
+		if status, err = (&TraceJobAPI{jobs: m}).CancelTraceJob(status.ID); err != nil || status.State != traceJobCancelled {
+			t.Errorf("failed to cancel paused job: %v %v", status, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var errNoTransferIndex = errors.New("token transfer index not enabled")
+
+// TransferIndex is a persistent index of the ether and token transfers of
+// processed blocks by the accounts involved. Like the TraceIndex, blocks are
+// identified by both number and hash.
+type TransferIndex interface {
+	// AddressBlocks returns the numbers and hashes of the indexed blocks within
+	// the given range, both limits inclusive, containing a transfer from or to
+	// the given account.
+	AddressBlocks(addr common.Address, first, last uint64) []*rawdb.NumberHash
+
+	// BlockTransfers returns the indexed transfers of the given block.
+	BlockTransfers(number uint64, hash common.Hash) ([]json.RawMessage, error)
+}
+
+// transferIndexBackend is implemented by backends maintaining a transfer index.
+type transferIndexBackend interface {
+	TransferIndex() TransferIndex
+}
+
+// indexedTransfer is the subset of an indexed transfer needed to filter it.
+type indexedTransfer struct {
+	From common.Address `json:"from"`
+	To   common.Address `json:"to"`
+}
+
+// TokenTransfers returns the history of ether, ERC-20, ERC-721 and ERC-1155
+// transfers from or to the given account within a block range, in the order
+// they were executed. Missing range limits default to the genesis and the
+// latest block. Only the transfers of canonical blocks are returned.
+func (api *API) TokenTransfers(ctx context.Context, address common.Address, fromBlock, toBlock *rpc.BlockNumber) ([]json.RawMessage, error) {
+	backend, ok := api.backend.(transferIndexBackend)
+	if !ok {
+		return nil, errNoTransferIndex
+	}
+	index := backend.TransferIndex()
+	if index == nil {
+		return nil, errNoTransferIndex
+	}
+	var from uint64
+	if fromBlock != nil {
+		number, err := api.resolveNumber(ctx, *fromBlock)
+		if err != nil {
+			return nil, err
+		}
+		from = number
+	}
+	latest := rpc.LatestBlockNumber
+	if toBlock == nil {
+		toBlock = &latest
+	}
+	to, err := api.resolveNumber(ctx, *toBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %d > %d", from, to)
+	}
+	results := []json.RawMessage{}
+	for _, block := range index.AddressBlocks(address, from, to) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header, err := api.backend.HeaderByNumber(ctx, rpc.BlockNumber(block.Number))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil || header.Hash() != block.Hash {
+			continue // reorged out
+		}
+		transfers, err := index.BlockTransfers(block.Number, block.Hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, transfer := range transfers {
+			var parties indexedTransfer
+			if err := json.Unmarshal(transfer, &parties); err != nil {
+				return nil, err
+			}
+			if parties.From == address || parties.To == address {
+				results = append(results, transfer)
+			}
+		}
+	}
+	return results, nil
+}
+
+// resolveNumber converts a block number, potentially a tag, into an absolute
+// one.
+func (api *API) resolveNumber(ctx context.Context, number rpc.BlockNumber) (uint64, error) {
+	if number >= 0 {
+		return uint64(number), nil
+	}
+	header, err := api.backend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block %s not found", number)
+	}
+	return header.Number.Uint64(), nil
+}
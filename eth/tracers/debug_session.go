@@ -0,0 +1,356 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+var (
+	errSessionFinished = errors.New("debug session finished")
+	errSessionExpired  = errors.New("debug session expired")
+)
+
+// debugMode is the condition on which a debug session pauses next.
+type debugMode int
+
+const (
+	debugStep     debugMode = iota // Pause at the next instruction
+	debugStepOver                  // Pause at the next instruction not in a deeper call
+	debugContinue                  // Pause at the next breakpoint
+)
+
+// DebugBreakpoint is a condition pausing a debug session. All the fields set
+// must match for the breakpoint to trigger. A breakpoint with only an address
+// triggers when a call frame executing the code of the address is entered.
+type DebugBreakpoint struct {
+	Address      *common.Address `json:"address"`      // Address of the executing code
+	Pc           *uint64         `json:"pc"`           // Program counter
+	Op           string          `json:"op"`           // Opcode name, e.g. "SLOAD"
+	StorageWrite bool            `json:"storageWrite"` // Whether to break on SSTORE
+	Slot         *common.Hash    `json:"slot"`         // Storage slot written, implies storageWrite
+}
+
+// matches returns whether the breakpoint triggers on the given instruction.
+func (bp *DebugBreakpoint) matches(pc uint64, op vm.OpCode, scope tracing.OpContext, entered bool) bool {
+	if bp.Address != nil && *bp.Address != scope.Address() {
+		return false
+	}
+	if bp.Pc != nil && *bp.Pc != pc {
+		return false
+	}
+	if bp.Op != "" && !strings.EqualFold(bp.Op, op.String()) {
+		return false
+	}
+	if bp.StorageWrite || bp.Slot != nil {
+		if op != vm.SSTORE {
+			return false
+		}
+		if bp.Slot != nil {
+			stack := scope.StackData()
+			if len(stack) == 0 || common.Hash(stack[len(stack)-1].Bytes32()) != *bp.Slot {
+				return false
+			}
+		}
+	}
+	// Address-only breakpoints would trigger on every instruction of the code,
+	// they only trigger on entering it instead.
+	if bp.Pc == nil && bp.Op == "" && !bp.StorageWrite && bp.Slot == nil {
+		return bp.Address != nil && entered
+	}
+	return true
+}
+
+// DebugState is the state of a debug session, either paused before executing
+// an instruction or finished.
+type DebugState struct {
+	Pc      uint64         `json:"pc"`
+	Op      string         `json:"op"`
+	Gas     uint64         `json:"gas"`
+	GasCost uint64         `json:"gasCost"`
+	Depth   int            `json:"depth"`
+	Address common.Address `json:"address"`
+
+	Done        bool          `json:"done"`
+	GasUsed     uint64        `json:"gasUsed,omitempty"`
+	ReturnValue hexutil.Bytes `json:"returnValue,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// DebugInspection is the detailed state of a paused debug session.
+type DebugInspection struct {
+	Pc               uint64                      `json:"pc"`
+	Op               string                      `json:"op"`
+	Address          common.Address              `json:"address"`
+	CallStack        []common.Address            `json:"callStack"`
+	Stack            []string                    `json:"stack"`
+	Memory           hexutil.Bytes               `json:"memory"`
+	Storage          map[common.Hash]common.Hash `json:"storage"`
+	TransientStorage map[common.Hash]common.Hash `json:"transientStorage"`
+}
+
+// debugCommand is sent to a paused session to either resume the execution or
+// inspect its state.
+type debugCommand struct {
+	mode        debugMode
+	breakpoints []DebugBreakpoint
+
+	// Inspection requests are answered without resuming the execution
+	storage   []common.Hash
+	transient []common.Hash
+	inspect   chan *DebugInspection
+}
+
+// debugSession is an interactive execution of a transaction or call. The
+// execution runs in its own goroutine, pausing within the OnOpcode hook until
+// a command resumes it.
+type debugSession struct {
+	lock     sync.Mutex  // Serializes the commands sent to the session
+	final    *DebugState // Final state once the execution finished
+	expires  time.Time   // Time the session is aborted at
+	commands chan *debugCommand
+	states   chan *DebugState // States the execution paused at or finished with
+	abort    chan struct{}    // Closed when the session is aborted
+	once     sync.Once
+	cancel   context.CancelFunc // Cancels the execution context, stopping the EVM
+
+	// Fields below are only accessed by the executing goroutine
+	env         *tracing.VMContext
+	frames      []common.Address // Addresses of the executing call frames
+	entered     bool             // Whether a call frame was just entered
+	mode        debugMode
+	depth       int // Depth of the frame the last command was issued in
+	breakpoints []DebugBreakpoint
+	output      []byte
+	gasUsed     uint64
+	err         error
+}
+
+// newDebugSession creates a session pausing at the first instruction, calling
+// cancel to stop the execution once aborted.
+func newDebugSession(expires time.Time, cancel context.CancelFunc) *debugSession {
+	return &debugSession{
+		expires:  expires,
+		cancel:   cancel,
+		commands: make(chan *debugCommand),
+		states:   make(chan *DebugState, 1),
+		abort:    make(chan struct{}),
+		mode:     debugStep,
+	}
+}
+
+// tracer returns the tracer driving the session.
+func (s *debugSession) tracer() *Tracer {
+	return &Tracer{
+		Hooks: &tracing.Hooks{
+			OnTxStart: s.onTxStart,
+			OnEnter:   s.onEnter,
+			OnExit:    s.onExit,
+			OnOpcode:  s.onOpcode,
+		},
+		GetResult: func() (json.RawMessage, error) { return nil, nil },
+		Stop:      func(error) { s.close() },
+	}
+}
+
+// close aborts the session, resuming the execution and cancelling the EVM so
+// it doesn't keep running untraced.
+func (s *debugSession) close() {
+	s.once.Do(func() {
+		close(s.abort)
+		s.cancel()
+	})
+}
+
+func (s *debugSession) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	s.env = env
+}
+
+func (s *debugSession) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	s.frames = append(s.frames, to)
+	s.entered = true
+}
+
+func (s *debugSession) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(s.frames) > 0 {
+		s.frames = s.frames[:len(s.frames)-1]
+	}
+	if depth == 0 {
+		s.output, s.gasUsed, s.err = common.CopyBytes(output), gasUsed, err
+	}
+}
+
+func (s *debugSession) onOpcode(pc uint64, opcode byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	entered := s.entered
+	s.entered = false
+
+	select {
+	case <-s.abort:
+		return
+	default:
+	}
+	op := vm.OpCode(opcode)
+	switch s.mode {
+	case debugStepOver:
+		if depth > s.depth {
+			return
+		}
+	case debugContinue:
+		var hit bool
+		for i := range s.breakpoints {
+			if s.breakpoints[i].matches(pc, op, scope, entered) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return
+		}
+	}
+	state := &DebugState{
+		Pc:      pc,
+		Op:      op.String(),
+		Gas:     gas,
+		GasCost: cost,
+		Depth:   depth,
+		Address: scope.Address(),
+	}
+	select {
+	case s.states <- state:
+	case <-s.abort:
+		return
+	}
+	for {
+		select {
+		case cmd := <-s.commands:
+			if cmd.inspect != nil {
+				cmd.inspect <- s.inspect(pc, op, scope, cmd.storage, cmd.transient)
+				continue
+			}
+			s.mode, s.breakpoints, s.depth = cmd.mode, cmd.breakpoints, depth
+			return
+		case <-s.abort:
+			return
+		}
+	}
+}
+
+// inspect collects the detailed state of the paused execution.
+func (s *debugSession) inspect(pc uint64, op vm.OpCode, scope tracing.OpContext, storage, transient []common.Hash) *DebugInspection {
+	res := &DebugInspection{
+		Pc:               pc,
+		Op:               op.String(),
+		Address:          scope.Address(),
+		CallStack:        append([]common.Address{}, s.frames...),
+		Stack:            make([]string, 0, len(scope.StackData())),
+		Memory:           common.CopyBytes(scope.MemoryData()),
+		Storage:          make(map[common.Hash]common.Hash, len(storage)),
+		TransientStorage: make(map[common.Hash]common.Hash, len(transient)),
+	}
+	for _, item := range scope.StackData() {
+		res.Stack = append(res.Stack, item.Hex())
+	}
+	for _, slot := range storage {
+		res.Storage[slot] = s.env.StateDB.GetState(scope.Address(), slot)
+	}
+	for _, slot := range transient {
+		res.TransientStorage[slot] = s.env.StateDB.GetTransientState(scope.Address(), slot)
+	}
+	return res
+}
+
+// finish reports the end of the execution, with the error preventing it from
+// running if any.
+func (s *debugSession) finish(err error) {
+	state := &DebugState{Done: true, GasUsed: s.gasUsed, ReturnValue: s.output}
+	switch {
+	case err != nil:
+		state.Error = err.Error()
+	case s.err != nil:
+		state.Error = s.err.Error()
+	}
+	select {
+	case s.states <- state:
+	case <-s.abort:
+	}
+}
+
+// wait waits for the execution to pause or finish.
+func (s *debugSession) wait(ctx context.Context) (*DebugState, error) {
+	select {
+	case state := <-s.states:
+		if state.Done {
+			s.final = state
+		}
+		return state, nil
+	case <-s.abort:
+		return nil, errSessionExpired
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resume resumes a paused execution until it pauses again according to the
+// given mode.
+func (s *debugSession) resume(ctx context.Context, mode debugMode, breakpoints []DebugBreakpoint) (*DebugState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.final != nil {
+		return s.final, nil
+	}
+	select {
+	case s.commands <- &debugCommand{mode: mode, breakpoints: breakpoints}:
+	case <-s.abort:
+		return nil, errSessionExpired
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.wait(ctx)
+}
+
+// inspectState retrieves the detailed state of a paused execution, along
+// with the given storage and transient storage slots of the executing code.
+func (s *debugSession) inspectState(ctx context.Context, storage, transient []common.Hash) (*DebugInspection, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.final != nil {
+		return nil, errSessionFinished
+	}
+	cmd := &debugCommand{storage: storage, transient: transient, inspect: make(chan *DebugInspection, 1)}
+	select {
+	case s.commands <- cmd:
+	case <-s.abort:
+		return nil, errSessionExpired
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return <-cmd.inspect, nil
+}
@@ -391,3 +391,37 @@ func TestInternals(t *testing.T) {
 		})
 	}
 }
+
+// Tests that the call tracer annotates failed calls with the source location
+// they failed at, when given the compiler artifacts of the executed code.
+func TestCallTracerErrorSource(t *testing.T) {
+	artifacts := `{
+		"input": {"sources": {"A.sol": {"content": "contract A {\n    function f() public {\n        revert();\n    }\n}\n"}}},
+		"output": {
+			"sources": {"A.sol": {"id": 0, "ast": {"nodeType": "SourceUnit", "src": "0:65:0", "nodes": [
+				{"nodeType": "ContractDefinition", "name": "A", "src": "0:64:0", "nodes": [
+					{"nodeType": "FunctionDefinition", "name": "f", "kind": "function", "src": "17:45:0"}
+				]}
+			]}}},
+			"contracts": {"A.sol": {"A": {"evm": {"deployedBytecode": {"object": "60006000fd", "sourceMap": "0:64:0;47:8;"}}}}}
+		}
+	}`
+	code := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)}
+
+	res := runTracerWithConfig(t, "callTracer", json.RawMessage(`{"artifacts":`+artifacts+`}`), code)
+	var have struct {
+		Error       string `json:"error"`
+		ErrorSource string `json:"errorSource"`
+	}
+	if err := json.Unmarshal(res, &have); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if have.Error != vm.ErrExecutionReverted.Error() || have.ErrorSource != "A.sol:3:9 (A.f)" {
+		t.Fatalf("unexpected failure: %s at %q", have.Error, have.ErrorSource)
+	}
+	// Without artifacts, no source is reported
+	res = runParityTracer(t, "callTracer", code)
+	if strings.Contains(string(res), "errorSource") {
+		t.Fatalf("unexpected error source: %s", res)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+func TestGasProfiler(t *testing.T) {
+	// Store 0x2a into slot 1, then call the identity precompile
+	code := append(storeCode[:len(storeCode)-1:len(storeCode)-1],
+		byte(vm.PUSH1), 0x00, // retSize
+		byte(vm.PUSH1), 0x00, // retOffset
+		byte(vm.PUSH1), 0x00, // argSize
+		byte(vm.PUSH1), 0x00, // argOffset
+		byte(vm.PUSH1), 0x00, // value
+		byte(vm.PUSH1), 0x04, // address
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.STOP),
+	)
+	res := runParityTracer(t, "gasProfiler", code)
+
+	var profile struct {
+		TotalGas uint64 `json:"totalGas"`
+		Frames   []struct {
+			Stack    string `json:"stack"`
+			Selector string `json:"selector"`
+			Calls    uint64 `json:"calls"`
+			SelfGas  uint64 `json:"selfGas"`
+			TotalGas uint64 `json:"totalGas"`
+		} `json:"frames"`
+		Opcodes map[string]struct {
+			Count uint64 `json:"count"`
+			Gas   uint64 `json:"gas"`
+		} `json:"opcodes"`
+		Folded string `json:"folded"`
+	}
+	if err := json.Unmarshal(res, &profile); err != nil {
+		t.Fatalf("failed to unmarshal gas profile: %v", err)
+	}
+	if len(profile.Frames) != 2 {
+		t.Fatalf("frame count mismatch: have %d, want 2", len(profile.Frames))
+	}
+	root, identity := profile.Frames[0], profile.Frames[1]
+	if root.TotalGas != profile.TotalGas || root.SelfGas+identity.TotalGas != root.TotalGas {
+		t.Errorf("frame gas mismatch: root %+v, identity %+v, total %d", root, identity, profile.TotalGas)
+	}
+	if identity.Selector != "fallback" || identity.TotalGas != 15 || !strings.HasPrefix(identity.Stack, root.Stack+";") {
+		t.Errorf("identity frame mismatch: %+v", identity)
+	}
+	if op := profile.Opcodes["SSTORE"]; op.Count != 1 || op.Gas != 20000 {
+		t.Errorf("sstore stats mismatch: %+v", op)
+	}
+	// The folded stacks must account for all the gas spent
+	var folded uint64
+	for _, line := range strings.Split(strings.TrimSpace(profile.Folded), "\n") {
+		var gas uint64
+		idx := strings.LastIndexByte(line, ' ')
+		if err := json.Unmarshal([]byte(line[idx+1:]), &gas); err != nil {
+			t.Fatalf("invalid folded line %q: %v", line, err)
+		}
+		folded += gas
+	}
+	if folded != profile.TotalGas {
+		t.Errorf("folded gas mismatch: have %d, want %d", folded, profile.TotalGas)
+	}
+	if !strings.Contains(profile.Folded, root.Stack+";SSTORE 20000\n") {
+		t.Errorf("sstore missing from folded stacks:\n%s", profile.Folded)
+	}
+}
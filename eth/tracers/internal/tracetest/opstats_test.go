@@ -0,0 +1,135 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/live"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestOpcodeStats(t *testing.T) {
+	var (
+		config   = *params.AllEthashProtocolChanges
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		contract = common.HexToAddress("0xc0de")
+		sha256   = common.BytesToAddress([]byte{0x2})
+		eth1     = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+
+		// Hash a word with the sha256 precompile
+		code = []byte{
+			byte(vm.PUSH1), 0x20, // retSize
+			byte(vm.PUSH1), 0x00, // retOffset
+			byte(vm.PUSH1), 0x20, // argSize
+			byte(vm.PUSH1), 0x00, // argOffset
+			byte(vm.PUSH1), 0x02, // address
+			byte(vm.GAS),
+			byte(vm.STATICCALL),
+			byte(vm.POP),
+			byte(vm.STOP),
+		}
+		genesis = &core.Genesis{
+			Config:  &config,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+			Alloc: types.GenesisAlloc{
+				crypto.PubkeyToAddress(key.PublicKey): {Balance: eth1},
+				contract:                              {Balance: eth1, Code: code},
+			},
+		}
+		engine = beacon.New(ethash.NewFaker())
+		signer = types.LatestSigner(&config)
+		dir    = t.TempDir()
+	)
+	tracer, err := tracers.LiveDirectory.New("opstats", json.RawMessage(fmt.Sprintf(`{"path":%q,"window":2}`, dir)))
+	if err != nil {
+		t.Fatalf("failed to create opstats tracer: %v", err)
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), core.DefaultCacheConfigWithScheme(rawdb.PathScheme), genesis, nil, engine, vm.Config{Tracer: tracer}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 3, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			Nonce:     uint64(i),
+			To:        &contract,
+			Gas:       100000,
+			GasFeeCap: b.BaseFee(),
+		})
+		b.AddTx(tx)
+	})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	chain.Stop()
+
+	// The first window only holds block 1, the last one is flushed on close
+	f, err := os.Open(filepath.Join(dir, "opstats.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to open stats: %v", err)
+	}
+	defer f.Close()
+
+	var windows []*live.OpcodeStats
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		stats := new(live.OpcodeStats)
+		if err := json.Unmarshal(scanner.Bytes(), stats); err != nil {
+			t.Fatalf("failed to decode stats: %v", err)
+		}
+		windows = append(windows, stats)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("window count mismatch: have %d, want 2", len(windows))
+	}
+	if w := windows[0]; w.First != 1 || w.Last != 1 || w.Blocks != 1 || w.Transactions != 1 {
+		t.Errorf("first window mismatch: %+v", w)
+	}
+	if w := windows[1]; w.First != 2 || w.Last != 3 || w.Blocks != 2 || w.Transactions != 2 {
+		t.Errorf("second window mismatch: %+v", w)
+	}
+	total := new(live.OpcodeStats)
+	for _, w := range windows {
+		total.Merge(w)
+	}
+	// The gas forwarded to the precompile is excluded from the call cost,
+	// leaving the warm access and the memory expansion.
+	if op := total.Opcodes["STATICCALL"]; op == nil || op.Count != 3 || op.Gas != 3*(params.WarmStorageReadCostEIP2929+3) {
+		t.Errorf("STATICCALL stats mismatch: %+v", op)
+	}
+	if op := total.Opcodes["PUSH1"]; op == nil || op.Count != 15 || op.Gas != 45 {
+		t.Errorf("PUSH1 stats mismatch: %+v", op)
+	}
+	want := live.PrecompileStat{Calls: 3, Gas: 3 * 72, InputBytes: 3 * 32, MaxInput: 32}
+	if p := total.Precompiles[sha256]; p == nil || *p != want {
+		t.Errorf("sha256 stats mismatch: have %+v, want %+v", p, want)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+// runParityTracer executes a transaction calling the given code with the named
+// tracer attached, returning the tracer's result.
+func runParityTracer(t *testing.T, name string, code []byte) json.RawMessage {
+	return runTracerWithConfig(t, name, nil, code)
+}
+
+// runTracerWithConfig is like runParityTracer, configuring the tracer with the
+// given JSON configuration.
+func runTracerWithConfig(t *testing.T, name string, cfg json.RawMessage, code []byte) json.RawMessage {
+	var (
+		config  = params.MainnetChainConfig
+		to      = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		origin  = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.LatestSigner(config)
+		context = vm.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			Coinbase:    common.Address{},
+			BlockNumber: new(big.Int).SetUint64(8000000),
+			Time:        5,
+			Difficulty:  big.NewInt(0x30000),
+			GasLimit:    uint64(6000000),
+			BaseFee:     new(big.Int),
+		}
+	)
+	tracer, err := tracers.DefaultDirectory.New(name, nil, cfg, config)
+	if err != nil {
+		t.Fatalf("failed to create tracer: %v", err)
+	}
+	st := tests.MakePreState(rawdb.NewMemoryDatabase(), types.GenesisAlloc{
+		to:     types.Account{Code: code},
+		origin: types.Account{Balance: big.NewInt(500000000000000)},
+	}, false, rawdb.HashScheme)
+	defer st.Close()
+
+	tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+		To:       &to,
+		Value:    big.NewInt(0),
+		Gas:      80000,
+		GasPrice: big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	evm := vm.NewEVM(context, state.NewHookedState(st.StateDB, tracer.Hooks), config, vm.Config{Tracer: tracer.Hooks})
+	msg, err := core.TransactionToMessage(tx, signer, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	if tracer.OnTxStart != nil {
+		tracer.OnTxStart(evm.GetVMContext(), tx, msg.From)
+	}
+	res, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	st.StateDB.Finalise(true)
+	if tracer.OnTxEnd != nil {
+		tracer.OnTxEnd(&types.Receipt{GasUsed: res.UsedGas}, nil)
+	}
+	out, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	return out
+}
+
+// storeCode writes 0x2a into storage slot 1.
+var storeCode = []byte{
+	byte(vm.PUSH1), 0x2a,
+	byte(vm.PUSH1), 0x01,
+	byte(vm.SSTORE),
+	byte(vm.STOP),
+}
+
+func TestStateDiffTracer(t *testing.T) {
+	res := runParityTracer(t, "stateDiffTracer", storeCode)
+
+	var diff map[common.Address]struct {
+		Balance json.RawMessage            `json:"balance"`
+		Nonce   json.RawMessage            `json:"nonce"`
+		Code    json.RawMessage            `json:"code"`
+		Storage map[string]json.RawMessage `json:"storage"`
+	}
+	if err := json.Unmarshal(res, &diff); err != nil {
+		t.Fatalf("failed to unmarshal state diff: %v", err)
+	}
+	var (
+		to     = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		origin = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	)
+	contract, ok := diff[to]
+	if !ok {
+		t.Fatalf("contract missing from state diff: %s", res)
+	}
+	if have, want := string(contract.Balance), `"="`; have != want {
+		t.Errorf("contract balance diff mismatch: have %s, want %s", have, want)
+	}
+	slot := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	if have, want := string(contract.Storage[slot]), `{"*":{"from":"0x0000000000000000000000000000000000000000000000000000000000000000","to":"0x000000000000000000000000000000000000000000000000000000000000002a"}}`; have != want {
+		t.Errorf("contract storage diff mismatch: have %s, want %s", have, want)
+	}
+	sender, ok := diff[origin]
+	if !ok {
+		t.Fatalf("sender missing from state diff: %s", res)
+	}
+	if have, want := string(sender.Nonce), `{"*":{"from":"0x0","to":"0x1"}}`; have != want {
+		t.Errorf("sender nonce diff mismatch: have %s, want %s", have, want)
+	}
+	if have, want := string(sender.Code), `"="`; have != want {
+		t.Errorf("sender code diff mismatch: have %s, want %s", have, want)
+	}
+}
+
+func TestVMTracer(t *testing.T) {
+	res := runParityTracer(t, "vmTracer", storeCode)
+
+	var trace struct {
+		Code string `json:"code"`
+		Ops  []struct {
+			Pc   uint64 `json:"pc"`
+			Op   string `json:"op"`
+			Cost uint64 `json:"cost"`
+			Ex   struct {
+				Push  []string `json:"push"`
+				Store *struct {
+					Key string `json:"key"`
+					Val string `json:"val"`
+				} `json:"store"`
+				Used uint64 `json:"used"`
+			} `json:"ex"`
+		} `json:"ops"`
+	}
+	if err := json.Unmarshal(res, &trace); err != nil {
+		t.Fatalf("failed to unmarshal vm trace: %v", err)
+	}
+	if trace.Code != "0x602a60015500" {
+		t.Errorf("code mismatch: have %s", trace.Code)
+	}
+	if len(trace.Ops) != 4 {
+		t.Fatalf("op count mismatch: have %d, want 4", len(trace.Ops))
+	}
+	if op := trace.Ops[0]; op.Op != "PUSH1" || len(op.Ex.Push) != 1 || op.Ex.Push[0] != "0x2a" {
+		t.Errorf("first op mismatch: %+v", op)
+	}
+	if op := trace.Ops[2]; op.Op != "SSTORE" || op.Pc != 4 || op.Ex.Store == nil || op.Ex.Store.Key != "0x1" || op.Ex.Store.Val != "0x2a" {
+		t.Errorf("sstore op mismatch: %+v", op)
+	}
+	for i := 1; i < len(trace.Ops); i++ {
+		if prev, op := trace.Ops[i-1], trace.Ops[i]; prev.Ex.Used < op.Ex.Used {
+			t.Errorf("op %d: gas increased from %d to %d", i, prev.Ex.Used, op.Ex.Used)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/live"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestTraceIndex(t *testing.T) {
+	var (
+		config    = *params.AllEthashProtocolChanges
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		contract  = common.HexToAddress("0xc0de")
+		recipient = common.HexToAddress("0xb0b")
+		eth1      = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+
+		// Forward 1 wei to the recipient
+		code = []byte{
+			byte(vm.PUSH1), 0x00, // retSize
+			byte(vm.PUSH1), 0x00, // retOffset
+			byte(vm.PUSH1), 0x00, // argSize
+			byte(vm.PUSH1), 0x00, // argOffset
+			byte(vm.PUSH1), 0x01, // value
+			byte(vm.PUSH2), 0x0b, 0x0b, // address
+			byte(vm.GAS),
+			byte(vm.CALL),
+			byte(vm.STOP),
+		}
+		genesis = &core.Genesis{
+			Config:  &config,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+			Alloc: types.GenesisAlloc{
+				sender:   {Balance: eth1},
+				contract: {Balance: eth1, Code: code},
+			},
+		}
+		engine = beacon.New(ethash.NewFaker())
+		signer = types.LatestSigner(&config)
+	)
+	tracer, err := tracers.LiveDirectory.New("traceindex", json.RawMessage(fmt.Sprintf(`{"path":%q,"retention":2}`, filepath.Join(t.TempDir(), "traceindex"))))
+	if err != nil {
+		t.Fatalf("failed to create trace index: %v", err)
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), core.DefaultCacheConfigWithScheme(rawdb.PathScheme), genesis, nil, engine, vm.Config{Tracer: tracer}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	db, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 3, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			Nonce:     uint64(i),
+			To:        &contract,
+			Gas:       100000,
+			GasFeeCap: b.BaseFee(),
+		})
+		b.AddTx(tx)
+	})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	index := live.ActiveTraceIndex()
+	if index == nil {
+		t.Fatal("trace index not active")
+	}
+	// The first block is out of the retention window
+	if index.HasBlock(1, blocks[0].Hash()) {
+		t.Errorf("block 1 not pruned")
+	}
+	for _, block := range blocks[1:] {
+		if !index.HasBlock(block.NumberU64(), block.Hash()) {
+			t.Fatalf("block %d not indexed", block.NumberU64())
+		}
+	}
+	traces, err := index.BlockTraces(3, blocks[2].Hash())
+	if err != nil {
+		t.Fatalf("failed to read block traces: %v", err)
+	}
+	if len(traces) != 2 {
+		t.Fatalf("trace count mismatch: have %d, want 2", len(traces))
+	}
+	want := []common.Hash{blocks[1].Hash(), blocks[2].Hash()}
+	if have := index.AddressBlocks(contract, true, 0, 10); !slices.Equal(have, want) {
+		t.Errorf("sender blocks mismatch: have %v, want %v", have, want)
+	}
+	if have := index.AddressBlocks(recipient, false, 0, 10); !slices.Equal(have, want) {
+		t.Errorf("recipient blocks mismatch: have %v, want %v", have, want)
+	}
+	if have := index.AddressBlocks(recipient, false, 3, 10); !slices.Equal(have, want[1:]) {
+		t.Errorf("ranged recipient blocks mismatch: have %v, want %v", have, want[1:])
+	}
+	if have := index.AddressBlocks(recipient, true, 0, 10); len(have) != 0 {
+		t.Errorf("unexpected sender blocks for recipient: %v", have)
+	}
+	// An invalid sibling of the head must not evict the canonical traces.
+	siblings, _ := core.GenerateChain(&config, blocks[1], engine, db, 1, func(i int, b *core.BlockGen) {
+		b.SetExtra([]byte("sibling"))
+	})
+	header := siblings[0].Header()
+	header.Root = common.Hash{0x1}
+	invalid := types.NewBlockWithHeader(header).WithBody(*siblings[0].Body())
+	if _, err := chain.InsertChain(types.Blocks{invalid}); err == nil {
+		t.Fatal("invalid block inserted")
+	}
+	if !index.HasBlock(3, blocks[2].Hash()) {
+		t.Fatal("canonical block traces removed by invalid sibling")
+	}
+	if have := index.AddressBlocks(recipient, false, 0, 10); !slices.Equal(have, want) {
+		t.Errorf("recipient blocks mismatch after invalid sibling: have %v, want %v", have, want)
+	}
+}
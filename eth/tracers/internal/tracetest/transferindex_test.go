@@ -0,0 +1,141 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/live"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestTransferIndex(t *testing.T) {
+	var (
+		config    = *params.AllEthashProtocolChanges
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		contract  = common.HexToAddress("0xc0de")
+		recipient = common.HexToAddress("0xb0b")
+		eth1      = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+
+		// Forward 1 wei to the recipient, then emit an ERC-20 transfer of 42
+		// tokens to it
+		code = append([]byte{
+			byte(vm.PUSH1), 0x00, // retSize
+			byte(vm.PUSH1), 0x00, // retOffset
+			byte(vm.PUSH1), 0x00, // argSize
+			byte(vm.PUSH1), 0x00, // argOffset
+			byte(vm.PUSH1), 0x01, // value
+			byte(vm.PUSH2), 0x0b, 0x0b, // address
+			byte(vm.GAS),
+			byte(vm.CALL),
+			byte(vm.POP),
+			byte(vm.PUSH1), 0x2a,
+			byte(vm.PUSH1), 0x00,
+			byte(vm.MSTORE),
+			byte(vm.PUSH2), 0x0b, 0x0b, // to
+			byte(vm.PUSH2), 0xc0, 0xde, // from
+			byte(vm.PUSH32),
+		}, append(crypto.Keccak256([]byte("Transfer(address,address,uint256)")),
+			byte(vm.PUSH1), 0x20, // size
+			byte(vm.PUSH1), 0x00, // offset
+			byte(vm.LOG3),
+			byte(vm.STOP),
+		)...)
+		genesis = &core.Genesis{
+			Config:  &config,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+			Alloc: types.GenesisAlloc{
+				sender:   {Balance: eth1},
+				contract: {Balance: eth1, Code: code},
+			},
+		}
+		engine = beacon.New(ethash.NewFaker())
+		signer = types.LatestSigner(&config)
+	)
+	tracer, err := tracers.LiveDirectory.New("transferindex", json.RawMessage(fmt.Sprintf(`{"path":%q}`, filepath.Join(t.TempDir(), "transferindex"))))
+	if err != nil {
+		t.Fatalf("failed to create transfer index: %v", err)
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), core.DefaultCacheConfigWithScheme(rawdb.PathScheme), genesis, nil, engine, vm.Config{Tracer: tracer}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 2, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			Nonce:     uint64(i),
+			To:        &contract,
+			Gas:       100000,
+			GasFeeCap: b.BaseFee(),
+		})
+		b.AddTx(tx)
+	})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	index := live.ActiveTransferIndex()
+	if index == nil {
+		t.Fatal("transfer index not active")
+	}
+	if have := index.AddressBlocks(recipient, 0, 10); len(have) != 2 {
+		t.Fatalf("recipient block count mismatch: have %d, want 2", len(have))
+	}
+	if have := index.AddressBlocks(sender, 2, 2); len(have) != 0 {
+		t.Errorf("unexpected blocks for sender: %v", have)
+	}
+	transfers, err := index.BlockTransfers(2, blocks[1].Hash())
+	if err != nil {
+		t.Fatalf("failed to read block transfers: %v", err)
+	}
+	var have []struct {
+		TxHash common.Hash    `json:"transactionHash"`
+		Type   string         `json:"type"`
+		From   common.Address `json:"from"`
+		To     common.Address `json:"to"`
+		Value  *hexutil.Big   `json:"value"`
+	}
+	blob, _ := json.Marshal(transfers)
+	if err := json.Unmarshal(blob, &have); err != nil {
+		t.Fatalf("failed to decode transfers: %v", err)
+	}
+	if len(have) != 2 {
+		t.Fatalf("transfer count mismatch: have %d, want 2", len(have))
+	}
+	txHash := blocks[1].Transactions()[0].Hash()
+	if tr := have[0]; tr.Type != "native" || tr.From != contract || tr.To != recipient || tr.Value.ToInt().Int64() != 1 || tr.TxHash != txHash {
+		t.Errorf("native transfer mismatch: %+v", tr)
+	}
+	if tr := have[1]; tr.Type != "erc20" || tr.From != contract || tr.To != recipient || tr.Value.ToInt().Int64() != 42 || tr.TxHash != txHash {
+		t.Errorf("erc20 transfer mismatch: %+v", tr)
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package live
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func init() {
+	tracers.LiveDirectory.Register("opstats", newOpStatsTracer)
+}
+
+// OpcodeStat is the usage of an opcode.
+type OpcodeStat struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"` // Gas charged by the opcode itself, excluding the gas forwarded to calls
+}
+
+// PrecompileStat is the usage of a precompiled contract.
+type PrecompileStat struct {
+	Calls      uint64 `json:"calls"`
+	Gas        uint64 `json:"gas"`
+	InputBytes uint64 `json:"inputBytes"`
+	MaxInput   uint64 `json:"maxInput"`
+}
+
+// OpcodeStats is the opcode and precompile usage over a range of blocks.
+type OpcodeStats struct {
+	First        uint64                             `json:"first"`
+	Last         uint64                             `json:"last"`
+	Blocks       uint64                             `json:"blocks"`
+	Transactions uint64                             `json:"transactions"`
+	Opcodes      map[string]*OpcodeStat             `json:"opcodes"`
+	Precompiles  map[common.Address]*PrecompileStat `json:"precompiles"`
+}
+
+// Merge adds the usage of another range of blocks to the stats.
+func (s *OpcodeStats) Merge(other *OpcodeStats) {
+	if other.Blocks == 0 {
+		return
+	}
+	if s.Blocks == 0 || other.First < s.First {
+		s.First = other.First
+	}
+	if other.Last > s.Last {
+		s.Last = other.Last
+	}
+	s.Blocks += other.Blocks
+	s.Transactions += other.Transactions
+
+	if s.Opcodes == nil {
+		s.Opcodes = make(map[string]*OpcodeStat)
+	}
+	for name, stat := range other.Opcodes {
+		if s.Opcodes[name] == nil {
+			s.Opcodes[name] = new(OpcodeStat)
+		}
+		s.Opcodes[name].Count += stat.Count
+		s.Opcodes[name].Gas += stat.Gas
+	}
+	if s.Precompiles == nil {
+		s.Precompiles = make(map[common.Address]*PrecompileStat)
+	}
+	for addr, stat := range other.Precompiles {
+		if s.Precompiles[addr] == nil {
+			s.Precompiles[addr] = new(PrecompileStat)
+		}
+		s.Precompiles[addr].Calls += stat.Calls
+		s.Precompiles[addr].Gas += stat.Gas
+		s.Precompiles[addr].InputBytes += stat.InputBytes
+		s.Precompiles[addr].MaxInput = max(s.Precompiles[addr].MaxInput, stat.MaxInput)
+	}
+}
+
+// OpcodeStatsCSVHeader is the header of the CSV records written by WriteCSV.
+var OpcodeStatsCSVHeader = []string{"first", "last", "kind", "name", "count", "gas", "inputBytes", "maxInput"}
+
+// WriteCSV writes the stats as CSV records, one per opcode and precompile,
+// sorted by name.
+func (s *OpcodeStats) WriteCSV(w *csv.Writer) error {
+	var (
+		first = strconv.FormatUint(s.First, 10)
+		last  = strconv.FormatUint(s.Last, 10)
+		u64   = func(n uint64) string { return strconv.FormatUint(n, 10) }
+	)
+	for _, name := range slices.Sorted(maps.Keys(s.Opcodes)) {
+		stat := s.Opcodes[name]
+		if err := w.Write([]string{first, last, "opcode", name, u64(stat.Count), u64(stat.Gas), "", ""}); err != nil {
+			return err
+		}
+	}
+	for _, addr := range slices.SortedFunc(maps.Keys(s.Precompiles), common.Address.Cmp) {
+		stat := s.Precompiles[addr]
+		if err := w.Write([]string{first, last, "precompile", addr.Hex(), u64(stat.Calls), u64(stat.Gas), u64(stat.InputBytes), u64(stat.MaxInput)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpcodeStatsCollector gathers the opcode and precompile usage of the blocks
+// executed with its hooks.
+type OpcodeStatsCollector struct {
+	chainConfig *params.ChainConfig
+
+	first, last  uint64
+	blocks       uint64
+	transactions uint64
+	opcodes      [256]OpcodeStat
+	precompiles  map[common.Address]*PrecompileStat
+
+	active   map[common.Address]bool // Precompiles active in the current block
+	frames   []*PrecompileStat       // Precompile called by every open call frame, nil if none
+	lastCall *OpcodeStat             // Stat of the call opcode just executed, if any
+	lastCost uint64                  // Cost charged by the call opcode just executed
+}
+
+// NewOpcodeStatsCollector creates a collector. The chain config may be nil if
+// the collector is used as a live tracer, which is initialized by the chain.
+func NewOpcodeStatsCollector(chainConfig *params.ChainConfig) *OpcodeStatsCollector {
+	return &OpcodeStatsCollector{
+		chainConfig: chainConfig,
+		precompiles: make(map[common.Address]*PrecompileStat),
+	}
+}
+
+// Hooks returns the hooks feeding the collector.
+func (c *OpcodeStatsCollector) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBlockchainInit: c.OnBlockchainInit,
+		OnBlockStart:     c.OnBlockStart,
+		OnTxStart:        c.OnTxStart,
+		OnEnter:          c.OnEnter,
+		OnExit:           c.OnExit,
+		OnOpcode:         c.OnOpcode,
+	}
+}
+
+// Stats returns the usage gathered since the last reset.
+func (c *OpcodeStatsCollector) Stats() *OpcodeStats {
+	stats := &OpcodeStats{
+		First:        c.first,
+		Last:         c.last,
+		Blocks:       c.blocks,
+		Transactions: c.transactions,
+		Opcodes:      make(map[string]*OpcodeStat),
+		Precompiles:  make(map[common.Address]*PrecompileStat, len(c.precompiles)),
+	}
+	for op, stat := range c.opcodes {
+		if stat.Count > 0 {
+			stat := stat
+			stats.Opcodes[vm.OpCode(op).String()] = &stat
+		}
+	}
+	for addr, stat := range c.precompiles {
+		stat := *stat
+		stats.Precompiles[addr] = &stat
+	}
+	return stats
+}
+
+// Reset clears the gathered usage.
+func (c *OpcodeStatsCollector) Reset() {
+	c.first, c.last, c.blocks, c.transactions = 0, 0, 0, 0
+	c.opcodes = [256]OpcodeStat{}
+	c.precompiles = make(map[common.Address]*PrecompileStat)
+}
+
+func (c *OpcodeStatsCollector) OnBlockchainInit(chainConfig *params.ChainConfig) {
+	c.chainConfig = chainConfig
+}
+
+func (c *OpcodeStatsCollector) OnBlockStart(ev tracing.BlockEvent) {
+	number := ev.Block.NumberU64()
+	if c.blocks == 0 {
+		c.first = number
+	}
+	c.last = number
+	c.blocks++
+
+	// Track the precompiles active in the block to recognize their calls
+	rules := c.chainConfig.Rules(ev.Block.Number(), ev.Block.Difficulty().Sign() == 0, ev.Block.Time())
+	c.active = make(map[common.Address]bool)
+	for _, addr := range vm.ActivePrecompiles(rules) {
+		c.active[addr] = true
+	}
+	c.frames = c.frames[:0]
+	c.lastCall = nil
+}
+
+func (c *OpcodeStatsCollector) OnTxStart(vm *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	c.transactions++
+}
+
+func (c *OpcodeStatsCollector) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	stat := &c.opcodes[op]
+	stat.Count++
+	stat.Gas += cost
+
+	switch vm.OpCode(op) {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		c.lastCall, c.lastCost = stat, cost
+	default:
+		c.lastCall = nil
+	}
+}
+
+func (c *OpcodeStatsCollector) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// The cost of the call opcodes includes the gas forwarded to the callee,
+	// which is accounted to the opcodes of the callee instead. The stipend
+	// given to value transfers isn't charged to the caller.
+	if c.lastCall != nil && depth > 0 {
+		forwarded := gas
+		if value != nil && value.Sign() > 0 && (vm.OpCode(typ) == vm.CALL || vm.OpCode(typ) == vm.CALLCODE) {
+			forwarded -= min(forwarded, params.CallStipend)
+		}
+		c.lastCall.Gas -= min(forwarded, c.lastCost)
+	}
+	c.lastCall = nil
+
+	var precompile *PrecompileStat
+	if c.active[to] && vm.OpCode(typ) != vm.CREATE && vm.OpCode(typ) != vm.CREATE2 {
+		if precompile = c.precompiles[to]; precompile == nil {
+			precompile = new(PrecompileStat)
+			c.precompiles[to] = precompile
+		}
+		precompile.Calls++
+		precompile.InputBytes += uint64(len(input))
+		precompile.MaxInput = max(precompile.MaxInput, uint64(len(input)))
+	}
+	c.frames = append(c.frames, precompile)
+}
+
+func (c *OpcodeStatsCollector) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	c.lastCall = nil
+	if len(c.frames) == 0 {
+		return
+	}
+	precompile := c.frames[len(c.frames)-1]
+	c.frames = c.frames[:len(c.frames)-1]
+	if precompile != nil {
+		precompile.Gas += gasUsed
+	}
+}
+
+// opStatsTracer is a live tracer writing the opcode and precompile usage of
+// every window of blocks as a JSON line. The windows are aligned on multiples
+// of their size. Note the blocks of reorged chains are accounted too.
+type opStatsTracer struct {
+	*OpcodeStatsCollector
+	window uint64
+	logger *lumberjack.Logger
+}
+
+type opStatsTracerConfig struct {
+	Path    string `json:"path"`    // Path to the directory where the stats will be stored
+	MaxSize int    `json:"maxSize"` // MaxSize is the maximum size in megabytes of the stats file before it gets rotated. It defaults to 100 megabytes.
+	Window  uint64 `json:"window"`  // Window is the number of blocks aggregated by each stats record. It defaults to 1000 blocks.
+}
+
+func newOpStatsTracer(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config opStatsTracerConfig
+	if err := json.Unmarshal(cfg, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if config.Path == "" {
+		return nil, errors.New("opstats tracer output path is required")
+	}
+	if config.Window == 0 {
+		config.Window = 1000
+	}
+	logger := &lumberjack.Logger{
+		Filename: filepath.Join(config.Path, "opstats.jsonl"),
+	}
+	if config.MaxSize > 0 {
+		logger.MaxSize = config.MaxSize
+	}
+	t := &opStatsTracer{
+		OpcodeStatsCollector: NewOpcodeStatsCollector(nil),
+		window:               config.Window,
+		logger:               logger,
+	}
+	hooks := t.Hooks()
+	hooks.OnBlockEnd = t.onBlockEnd
+	hooks.OnClose = t.onClose
+	return hooks, nil
+}
+
+func (t *opStatsTracer) onBlockEnd(err error) {
+	if (t.last+1)%t.window == 0 {
+		t.flush()
+	}
+}
+
+func (t *opStatsTracer) onClose() {
+	t.flush()
+	if err := t.logger.Close(); err != nil {
+		log.Warn("Failed to close opstats tracer log file", "error", err)
+	}
+}
+
+// flush writes the stats of the current window, and starts a new one.
+func (t *opStatsTracer) flush() {
+	if t.blocks == 0 {
+		return
+	}
+	if err := writeJSONLine(t.logger, t.Stats()); err != nil {
+		log.Warn("Failed to write opcode stats", "error", err)
+	}
+	t.Reset()
+}
+
+// writeJSONLine writes a value as a JSON line.
+func writeJSONLine(w io.Writer, v any) error {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(blob, '\n'))
+	return err
+}
@@ -0,0 +1,376 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/pebble"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+
+	// Force-load the native tracers, the index is built by the flat call tracer
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+)
+
+func init() {
+	tracers.LiveDirectory.Register("traceindex", newTraceIndexTracer)
+}
+
+const (
+	// traceIndexCache and traceIndexHandles are the resources allocated to the
+	// trace index database.
+	traceIndexCache   = 16
+	traceIndexHandles = 16
+)
+
+// activeTraceIndex is the index maintained by the running traceindex tracer.
+var activeTraceIndex atomic.Pointer[traceIndex]
+
+// ActiveTraceIndex returns the trace index maintained by the traceindex live
+// tracer, or nil if the tracer is not running.
+func ActiveTraceIndex() tracers.TraceIndex {
+	if index := activeTraceIndex.Load(); index != nil {
+		return index
+	}
+	return nil
+}
+
+type traceIndexConfig struct {
+	Path      string `json:"path"`      // Path to the directory where the trace index database is stored
+	Retention uint64 `json:"retention"` // Number of recent blocks to retain the traces of, zero retaining all
+}
+
+// indexedTrace is the subset of a flat call frame needed to index it.
+type indexedTrace struct {
+	Action struct {
+		From *common.Address `json:"from"`
+		To   *common.Address `json:"to"`
+	} `json:"action"`
+	Result *struct {
+		Address *common.Address `json:"address"`
+	} `json:"result"`
+}
+
+// traceIndex is a live tracer persisting the flat call traces of all processed
+// blocks, indexed by the sender and recipient of every call and value transfer.
+// The traces are identical to those returned by trace_filter, which uses the
+// index to answer queries without re-executing blocks.
+//
+// Entries are keyed by block number and hash, so the traces of reorged blocks
+// are never served for canonical ones. Stale siblings are removed whenever a
+// block at the same height is successfully processed or skipped, and blocks
+// missing from the index are simply re-executed by trace_filter.
+type traceIndex struct {
+	db          ethdb.Database
+	retention   uint64
+	chainConfig *params.ChainConfig
+
+	block   *types.Block      // Block being processed, nil outside of blocks
+	tracer  *tracers.Tracer   // Flat call tracer of the transaction being processed
+	txIndex int               // Index of the transaction being processed
+	traces  []json.RawMessage // Flat call traces of the block being processed
+	failed  bool              // Whether tracing any transaction of the block failed
+}
+
+func newTraceIndexTracer(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config traceIndexConfig
+	if err := json.Unmarshal(cfg, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if config.Path == "" {
+		return nil, errors.New("trace index path is required")
+	}
+	kvdb, err := pebble.New(config.Path, traceIndexCache, traceIndexHandles, "eth/tracers/traceindex/", false, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace index: %v", err)
+	}
+	t := &traceIndex{
+		db:        rawdb.NewDatabase(kvdb),
+		retention: config.Retention,
+	}
+	if !activeTraceIndex.CompareAndSwap(nil, t) {
+		kvdb.Close()
+		return nil, errors.New("trace index is already running")
+	}
+	log.Info("Opened trace index", "path", config.Path, "retention", config.Retention)
+
+	return &tracing.Hooks{
+		OnBlockchainInit: t.onBlockchainInit,
+		OnBlockStart:     t.onBlockStart,
+		OnBlockEnd:       t.onBlockEnd,
+		OnSkippedBlock:   t.onSkippedBlock,
+		OnTxStart:        t.onTxStart,
+		OnTxEnd:          t.onTxEnd,
+		OnEnter:          t.onEnter,
+		OnExit:           t.onExit,
+		OnClose:          t.onClose,
+	}, nil
+}
+
+func (t *traceIndex) onBlockchainInit(chainConfig *params.ChainConfig) {
+	t.chainConfig = chainConfig
+}
+
+func (t *traceIndex) onBlockStart(ev tracing.BlockEvent) {
+	t.block = ev.Block
+	t.tracer = nil
+	t.txIndex = 0
+	t.traces = []json.RawMessage{}
+	t.failed = false
+}
+
+func (t *traceIndex) onSkippedBlock(ev tracing.BlockEvent) {
+	// Skipped blocks were already processed, either during an earlier run or on
+	// a previous fork. Their traces are kept if still indexed, but any sibling
+	// they replace is stale.
+	batch := t.db.NewBatch()
+	t.removeSiblings(batch, ev.Block.NumberU64(), ev.Block.Hash())
+	if batch.ValueSize() == 0 {
+		return
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to remove reorged traces", "number", ev.Block.NumberU64(), "err", err)
+	}
+}
+
+func (t *traceIndex) onBlockEnd(err error) {
+	defer func() { t.block, t.tracer, t.traces = nil, nil, nil }()
+
+	// Invalid blocks must leave the index untouched, in particular the traces
+	// of the canonical block at their height.
+	if t.block == nil || err != nil {
+		return
+	}
+	var (
+		number = t.block.NumberU64()
+		hash   = t.block.Hash()
+		batch  = t.db.NewBatch()
+	)
+	t.removeSiblings(batch, number, hash)
+	if t.failed {
+		// The block is valid but its traces are unavailable, only drop the
+		// stale siblings and leave the block to re-execution.
+		if batch.ValueSize() == 0 {
+			return
+		}
+		if err := batch.Write(); err != nil {
+			log.Error("Failed to remove reorged traces", "number", number, "err", err)
+		}
+		return
+	}
+	blob, err := json.Marshal(t.traces)
+	if err != nil {
+		log.Error("Failed to encode block traces", "number", number, "hash", hash, "err", err)
+		return
+	}
+	for _, trace := range t.traces {
+		from, to, err := traceAddresses(trace)
+		if err != nil {
+			log.Error("Failed to index block traces", "number", number, "hash", hash, "err", err)
+			return
+		}
+		if from != nil {
+			rawdb.WriteTraceIndexAddress(batch, *from, true, number, hash)
+		}
+		if to != nil {
+			rawdb.WriteTraceIndexAddress(batch, *to, false, number, hash)
+		}
+	}
+	rawdb.WriteTraceIndexBlock(batch, number, hash, blob)
+	t.prune(batch, number)
+
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to write trace index", "number", number, "hash", hash, "err", err)
+	}
+}
+
+func (t *traceIndex) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	if t.block == nil || t.failed {
+		return
+	}
+	tracer, err := tracers.DefaultDirectory.New("flatCallTracer", &tracers.Context{
+		BlockHash:   t.block.Hash(),
+		BlockNumber: t.block.Number(),
+		TxIndex:     t.txIndex,
+		TxHash:      tx.Hash(),
+	}, json.RawMessage(`{"convertParityErrors":true}`), t.chainConfig)
+	if err != nil {
+		log.Error("Failed to create flat call tracer", "err", err)
+		t.failed = true
+		return
+	}
+	t.tracer = tracer
+	t.tracer.OnTxStart(env, tx, from)
+}
+
+func (t *traceIndex) onTxEnd(receipt *types.Receipt, err error) {
+	if t.tracer == nil {
+		return
+	}
+	defer func() { t.tracer = nil }()
+	t.txIndex++
+
+	t.tracer.OnTxEnd(receipt, err)
+	if err != nil {
+		t.failed = true
+		return
+	}
+	res, err := t.tracer.GetResult()
+	if err != nil {
+		log.Error("Failed to retrieve flat call traces", "err", err)
+		t.failed = true
+		return
+	}
+	var traces []json.RawMessage
+	if err := json.Unmarshal(res, &traces); err != nil {
+		log.Error("Failed to decode flat call traces", "err", err)
+		t.failed = true
+		return
+	}
+	t.traces = append(t.traces, traces...)
+}
+
+func (t *traceIndex) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// System calls happen outside of transactions and are not traced
+	if t.tracer != nil {
+		t.tracer.OnEnter(depth, typ, from, to, input, gas, value)
+	}
+}
+
+func (t *traceIndex) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.tracer != nil {
+		t.tracer.OnExit(depth, output, gasUsed, err, reverted)
+	}
+}
+
+func (t *traceIndex) onClose() {
+	activeTraceIndex.CompareAndSwap(t, nil)
+	if err := t.db.Close(); err != nil {
+		log.Error("Failed to close trace index", "err", err)
+	}
+}
+
+// removeSiblings removes the indexed traces of all the blocks at the given
+// height other than the one with the given hash.
+func (t *traceIndex) removeSiblings(batch ethdb.Batch, number uint64, hash common.Hash) {
+	for _, block := range rawdb.ReadTraceIndexBlocksInRange(t.db, number, number) {
+		if block.Hash != hash {
+			t.deleteBlock(batch, block.Number, block.Hash)
+		}
+	}
+}
+
+// prune removes the indexed traces of all the blocks falling out of the
+// retention window once the block with the given number is indexed.
+func (t *traceIndex) prune(batch ethdb.Batch, number uint64) {
+	if t.retention == 0 || number < t.retention {
+		return
+	}
+	var (
+		cutoff = number - t.retention + 1 // Oldest block to retain
+		tail   uint64
+	)
+	if stored := rawdb.ReadTraceIndexTail(t.db); stored != nil {
+		tail = *stored
+	}
+	if tail >= cutoff {
+		return
+	}
+	for _, block := range rawdb.ReadTraceIndexBlocksInRange(t.db, tail, cutoff-1) {
+		t.deleteBlock(batch, block.Number, block.Hash)
+	}
+	rawdb.WriteTraceIndexTail(batch, cutoff)
+}
+
+// deleteBlock removes the indexed traces of a block along with its address
+// index entries.
+func (t *traceIndex) deleteBlock(batch ethdb.Batch, number uint64, hash common.Hash) {
+	traces, err := t.BlockTraces(number, hash)
+	if err != nil {
+		log.Error("Failed to read indexed block traces", "number", number, "hash", hash, "err", err)
+	}
+	for _, trace := range traces {
+		from, to, err := traceAddresses(trace)
+		if err != nil {
+			continue
+		}
+		if from != nil {
+			rawdb.DeleteTraceIndexAddress(batch, *from, true, number, hash)
+		}
+		if to != nil {
+			rawdb.DeleteTraceIndexAddress(batch, *to, false, number, hash)
+		}
+	}
+	rawdb.DeleteTraceIndexBlock(batch, number, hash)
+}
+
+// HasBlock implements tracers.TraceIndex, reporting whether the traces of the
+// given block are indexed.
+func (t *traceIndex) HasBlock(number uint64, hash common.Hash) bool {
+	return rawdb.HasTraceIndexBlock(t.db, number, hash)
+}
+
+// BlockTraces implements tracers.TraceIndex, returning the indexed flat call
+// traces of the given block.
+func (t *traceIndex) BlockTraces(number uint64, hash common.Hash) ([]json.RawMessage, error) {
+	blob := rawdb.ReadTraceIndexBlock(t.db, number, hash)
+	if blob == nil {
+		return nil, fmt.Errorf("traces of block #%d [%x..] not indexed", number, hash.Bytes()[:4])
+	}
+	var traces []json.RawMessage
+	if err := json.Unmarshal(blob, &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// AddressBlocks implements tracers.TraceIndex, returning the hashes of the
+// indexed blocks within the given range containing a trace sent from (sender)
+// or addressed to the given account.
+func (t *traceIndex) AddressBlocks(addr common.Address, sender bool, first, last uint64) []common.Hash {
+	blocks := rawdb.ReadTraceIndexAddressBlocks(t.db, addr, sender, first, last)
+	hashes := make([]common.Hash, len(blocks))
+	for i, block := range blocks {
+		hashes[i] = block.Hash
+	}
+	return hashes
+}
+
+// traceAddresses returns the sender and the recipient of a flat call trace.
+// Contract creations are addressed to the created contract.
+func traceAddresses(trace json.RawMessage) (*common.Address, *common.Address, error) {
+	var frame indexedTrace
+	if err := json.Unmarshal(trace, &frame); err != nil {
+		return nil, nil, err
+	}
+	to := frame.Action.To
+	if to == nil && frame.Result != nil {
+		to = frame.Result.Address
+	}
+	return frame.Action.From, to, nil
+}
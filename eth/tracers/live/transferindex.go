@@ -0,0 +1,335 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/pebble"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.LiveDirectory.Register("transferindex", newTransferIndexTracer)
+}
+
+// activeTransferIndex is the index maintained by the running transferindex
+// tracer.
+var activeTransferIndex atomic.Pointer[transferIndex]
+
+// ActiveTransferIndex returns the token transfer index maintained by the
+// transferindex live tracer, or nil if the tracer is not running.
+func ActiveTransferIndex() tracers.TransferIndex {
+	if index := activeTransferIndex.Load(); index != nil {
+		return index
+	}
+	return nil
+}
+
+type transferIndexConfig struct {
+	Path      string `json:"path"`      // Path to the directory where the transfer index database is stored
+	Retention uint64 `json:"retention"` // Number of recent blocks to retain the transfers of, zero retaining all
+}
+
+// indexedTransfer is a transfer reported by the tokenTransferTracer, along
+// with the transaction it was made in.
+type indexedTransfer struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	TxIndex     hexutil.Uint   `json:"transactionIndex"`
+
+	Type     string          `json:"type"`
+	Token    *common.Address `json:"token,omitempty"`
+	Operator *common.Address `json:"operator,omitempty"`
+	From     common.Address  `json:"from"`
+	To       common.Address  `json:"to"`
+	TokenID  *hexutil.Big    `json:"tokenId,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+}
+
+// transferIndex is a live tracer persisting the ether and token transfers of
+// all processed blocks, as reported by the tokenTransferTracer, indexed by the
+// sender and recipient of every transfer.
+//
+// Reorgs and retention are handled the same way as by the traceIndex: entries
+// are keyed by block number and hash, stale siblings are dropped when a block
+// at the same height is processed or skipped and queries only return the
+// transfers of canonical blocks.
+type transferIndex struct {
+	db          ethdb.Database
+	retention   uint64
+	chainConfig *params.ChainConfig
+
+	block     *types.Block       // Block being processed, nil outside of blocks
+	tracer    *tracers.Tracer    // Transfer tracer of the transaction being processed
+	txHash    common.Hash        // Hash of the transaction being processed
+	txIndex   int                // Index of the transaction being processed
+	transfers []*indexedTransfer // Transfers of the block being processed
+	failed    bool               // Whether tracing any transaction of the block failed
+}
+
+func newTransferIndexTracer(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config transferIndexConfig
+	if err := json.Unmarshal(cfg, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if config.Path == "" {
+		return nil, errors.New("transfer index path is required")
+	}
+	kvdb, err := pebble.New(config.Path, traceIndexCache, traceIndexHandles, "eth/tracers/transferindex/", false, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transfer index: %v", err)
+	}
+	t := &transferIndex{
+		db:        rawdb.NewDatabase(kvdb),
+		retention: config.Retention,
+	}
+	if !activeTransferIndex.CompareAndSwap(nil, t) {
+		kvdb.Close()
+		return nil, errors.New("transfer index is already running")
+	}
+	log.Info("Opened token transfer index", "path", config.Path, "retention", config.Retention)
+
+	return &tracing.Hooks{
+		OnBlockchainInit: t.onBlockchainInit,
+		OnBlockStart:     t.onBlockStart,
+		OnBlockEnd:       t.onBlockEnd,
+		OnSkippedBlock:   t.onSkippedBlock,
+		OnTxStart:        t.onTxStart,
+		OnTxEnd:          t.onTxEnd,
+		OnEnter:          t.onEnter,
+		OnExit:           t.onExit,
+		OnLog:            t.onLog,
+		OnClose:          t.onClose,
+	}, nil
+}
+
+func (t *transferIndex) onBlockchainInit(chainConfig *params.ChainConfig) {
+	t.chainConfig = chainConfig
+}
+
+func (t *transferIndex) onBlockStart(ev tracing.BlockEvent) {
+	t.block = ev.Block
+	t.tracer = nil
+	t.txIndex = 0
+	t.transfers = []*indexedTransfer{}
+	t.failed = false
+
+	t.removeSiblings(ev.Block.NumberU64(), ev.Block.Hash())
+}
+
+func (t *transferIndex) onSkippedBlock(ev tracing.BlockEvent) {
+	t.removeSiblings(ev.Block.NumberU64(), ev.Block.Hash())
+}
+
+func (t *transferIndex) onBlockEnd(err error) {
+	defer func() { t.block, t.tracer, t.transfers = nil, nil, nil }()
+
+	if t.block == nil || err != nil || t.failed {
+		return
+	}
+	var (
+		number = t.block.NumberU64()
+		hash   = t.block.Hash()
+		batch  = t.db.NewBatch()
+	)
+	blob, err := json.Marshal(t.transfers)
+	if err != nil {
+		log.Error("Failed to encode block transfers", "number", number, "hash", hash, "err", err)
+		return
+	}
+	for _, transfer := range t.transfers {
+		rawdb.WriteTransferIndexAddress(batch, transfer.From, number, hash)
+		rawdb.WriteTransferIndexAddress(batch, transfer.To, number, hash)
+	}
+	rawdb.WriteTransferIndexBlock(batch, number, hash, blob)
+	t.prune(batch, number)
+
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to write transfer index", "number", number, "hash", hash, "err", err)
+	}
+}
+
+func (t *transferIndex) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	if t.block == nil || t.failed {
+		return
+	}
+	tracer, err := tracers.DefaultDirectory.New("tokenTransferTracer", &tracers.Context{
+		BlockHash:   t.block.Hash(),
+		BlockNumber: t.block.Number(),
+		TxIndex:     t.txIndex,
+		TxHash:      tx.Hash(),
+	}, nil, t.chainConfig)
+	if err != nil {
+		log.Error("Failed to create token transfer tracer", "err", err)
+		t.failed = true
+		return
+	}
+	t.tracer, t.txHash = tracer, tx.Hash()
+}
+
+func (t *transferIndex) onTxEnd(receipt *types.Receipt, err error) {
+	if t.tracer == nil {
+		return
+	}
+	defer func() { t.tracer = nil }()
+	txIndex := t.txIndex
+	t.txIndex++
+
+	if err != nil {
+		t.failed = true
+		return
+	}
+	res, err := t.tracer.GetResult()
+	if err != nil {
+		log.Error("Failed to retrieve token transfers", "err", err)
+		t.failed = true
+		return
+	}
+	var transfers []*indexedTransfer
+	if err := json.Unmarshal(res, &transfers); err != nil {
+		log.Error("Failed to decode token transfers", "err", err)
+		t.failed = true
+		return
+	}
+	for _, transfer := range transfers {
+		transfer.BlockNumber = hexutil.Uint64(t.block.NumberU64())
+		transfer.BlockHash = t.block.Hash()
+		transfer.TxHash = t.txHash
+		transfer.TxIndex = hexutil.Uint(txIndex)
+	}
+	t.transfers = append(t.transfers, transfers...)
+}
+
+func (t *transferIndex) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// System calls happen outside of transactions and are not traced
+	if t.tracer != nil {
+		t.tracer.OnEnter(depth, typ, from, to, input, gas, value)
+	}
+}
+
+func (t *transferIndex) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.tracer != nil {
+		t.tracer.OnExit(depth, output, gasUsed, err, reverted)
+	}
+}
+
+func (t *transferIndex) onLog(log *types.Log) {
+	if t.tracer != nil {
+		t.tracer.OnLog(log)
+	}
+}
+
+func (t *transferIndex) onClose() {
+	activeTransferIndex.CompareAndSwap(t, nil)
+	if err := t.db.Close(); err != nil {
+		log.Error("Failed to close transfer index", "err", err)
+	}
+}
+
+// removeSiblings removes the indexed transfers of all the blocks at the given
+// height other than the one with the given hash.
+func (t *transferIndex) removeSiblings(number uint64, hash common.Hash) {
+	var (
+		batch   = t.db.NewBatch()
+		removed bool
+	)
+	for _, block := range rawdb.ReadTransferIndexBlocksInRange(t.db, number, number) {
+		if block.Hash != hash {
+			t.deleteBlock(batch, block.Number, block.Hash)
+			removed = true
+		}
+	}
+	if !removed {
+		return
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to remove reorged transfers", "number", number, "err", err)
+	}
+}
+
+// prune removes the indexed transfers of all the blocks falling out of the
+// retention window once the block with the given number is indexed.
+func (t *transferIndex) prune(batch ethdb.Batch, number uint64) {
+	if t.retention == 0 || number < t.retention {
+		return
+	}
+	var (
+		cutoff = number - t.retention + 1 // Oldest block to retain
+		tail   uint64
+	)
+	if stored := rawdb.ReadTransferIndexTail(t.db); stored != nil {
+		tail = *stored
+	}
+	if tail >= cutoff {
+		return
+	}
+	for _, block := range rawdb.ReadTransferIndexBlocksInRange(t.db, tail, cutoff-1) {
+		t.deleteBlock(batch, block.Number, block.Hash)
+	}
+	rawdb.WriteTransferIndexTail(batch, cutoff)
+}
+
+// deleteBlock removes the indexed transfers of a block along with its address
+// index entries.
+func (t *transferIndex) deleteBlock(batch ethdb.Batch, number uint64, hash common.Hash) {
+	var transfers []*indexedTransfer
+	if blob := rawdb.ReadTransferIndexBlock(t.db, number, hash); blob != nil {
+		if err := json.Unmarshal(blob, &transfers); err != nil {
+			log.Error("Failed to decode indexed block transfers", "number", number, "hash", hash, "err", err)
+		}
+	}
+	for _, transfer := range transfers {
+		rawdb.DeleteTransferIndexAddress(batch, transfer.From, number, hash)
+		rawdb.DeleteTransferIndexAddress(batch, transfer.To, number, hash)
+	}
+	rawdb.DeleteTransferIndexBlock(batch, number, hash)
+}
+
+// AddressBlocks implements tracers.TransferIndex, returning the numbers and
+// hashes of the indexed blocks within the given range containing a transfer
+// from or to the given account.
+func (t *transferIndex) AddressBlocks(addr common.Address, first, last uint64) []*rawdb.NumberHash {
+	return rawdb.ReadTransferIndexAddressBlocks(t.db, addr, first, last)
+}
+
+// BlockTransfers implements tracers.TransferIndex, returning the indexed
+// transfers of the given block.
+func (t *transferIndex) BlockTransfers(number uint64, hash common.Hash) ([]json.RawMessage, error) {
+	blob := rawdb.ReadTransferIndexBlock(t.db, number, hash)
+	if blob == nil {
+		return nil, fmt.Errorf("transfers of block #%d [%x..] not indexed", number, hash.Bytes()[:4])
+	}
+	var transfers []json.RawMessage
+	if err := json.Unmarshal(blob, &transfers); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
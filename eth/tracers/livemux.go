@@ -0,0 +1,297 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// LiveTracerConfig selects a live tracer by name, along with its configuration
+// in JSON.
+type LiveTracerConfig struct {
+	Name   string
+	Config string `toml:",omitempty"`
+}
+
+// NewMux instantiates the given live tracers and combines them into a single
+// set of hooks, which invokes the hooks of every tracer in order.
+//
+// The tracers are isolated from each other: a tracer panicking is disabled for
+// the rest of the node lifetime instead of crashing it, the other tracers keep
+// receiving events. The number of hooks invoked and the panics of every tracer
+// are reported as metrics.
+func (d *liveDirectory) NewMux(configs []LiveTracerConfig) (*tracing.Hooks, error) {
+	var (
+		tracers = make([]*muxedTracer, 0, len(configs))
+		names   = make(map[string]bool)
+	)
+	for _, config := range configs {
+		if names[config.Name] {
+			return nil, fmt.Errorf("duplicate live tracer %q", config.Name)
+		}
+		names[config.Name] = true
+
+		hooks, err := d.New(config.Name, json.RawMessage(config.Config))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tracer %s: %v", config.Name, err)
+		}
+		tracers = append(tracers, newMuxedTracer(config.Name, hooks))
+	}
+	return newLiveMux(tracers), nil
+}
+
+// muxedTracer is a live tracer combined with others by a multiplexer.
+type muxedTracer struct {
+	name  string
+	hooks *tracing.Hooks
+
+	disabled      atomic.Bool
+	callCounter   *metrics.Counter
+	panicCounter  *metrics.Counter
+	disabledGauge *metrics.Gauge
+}
+
+func newMuxedTracer(name string, hooks *tracing.Hooks) *muxedTracer {
+	prefix := "eth/tracers/live/" + name
+	return &muxedTracer{
+		name:          name,
+		hooks:         hooks,
+		callCounter:   metrics.GetOrRegisterCounter(prefix+"/calls", nil),
+		panicCounter:  metrics.GetOrRegisterCounter(prefix+"/panics", nil),
+		disabledGauge: metrics.GetOrRegisterGauge(prefix+"/disabled", nil),
+	}
+}
+
+// call invokes a hook of the tracer unless it's disabled, disabling it if the
+// hook panics.
+func (t *muxedTracer) call(hook string, fn func()) {
+	if t.disabled.Load() {
+		return
+	}
+	defer t.recover(hook)
+
+	t.callCounter.Inc(1)
+	fn()
+}
+
+// recover disables the tracer if the hook it was deferred by panicked.
+func (t *muxedTracer) recover(hook string) {
+	if r := recover(); r != nil {
+		t.disabled.Store(true)
+		t.panicCounter.Inc(1)
+		t.disabledGauge.Update(1)
+		log.Error("Live tracer panicked, disabling it", "tracer", t.name, "hook", hook, "err", r, "stack", string(debug.Stack()))
+	}
+}
+
+// newLiveMux builds the hooks dispatching events to the given tracers. Only the
+// hooks implemented by at least one of the tracers are set, not to make the
+// chain emit events nobody listens to.
+func newLiveMux(tracers []*muxedTracer) *tracing.Hooks {
+	var (
+		hooks = new(tracing.Hooks)
+		with  = func(has func(h *tracing.Hooks) bool) []*muxedTracer {
+			var subset []*muxedTracer
+			for _, t := range tracers {
+				if has(t.hooks) {
+					subset = append(subset, t)
+				}
+			}
+			return subset
+		}
+	)
+	// VM events
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnTxStart != nil }); len(ts) > 0 {
+		hooks.OnTxStart = func(vm *tracing.VMContext, tx *types.Transaction, from common.Address) {
+			for _, t := range ts {
+				t.call("OnTxStart", func() { t.hooks.OnTxStart(vm, tx, from) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnTxEnd != nil }); len(ts) > 0 {
+		hooks.OnTxEnd = func(receipt *types.Receipt, err error) {
+			for _, t := range ts {
+				t.call("OnTxEnd", func() { t.hooks.OnTxEnd(receipt, err) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnEnter != nil }); len(ts) > 0 {
+		hooks.OnEnter = func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			for _, t := range ts {
+				t.call("OnEnter", func() { t.hooks.OnEnter(depth, typ, from, to, input, gas, value) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnExit != nil }); len(ts) > 0 {
+		hooks.OnExit = func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+			for _, t := range ts {
+				t.call("OnExit", func() { t.hooks.OnExit(depth, output, gasUsed, err, reverted) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnOpcode != nil }); len(ts) > 0 {
+		hooks.OnOpcode = func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+			for _, t := range ts {
+				t.call("OnOpcode", func() { t.hooks.OnOpcode(pc, op, gas, cost, scope, rData, depth, err) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnFault != nil }); len(ts) > 0 {
+		hooks.OnFault = func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+			for _, t := range ts {
+				t.call("OnFault", func() { t.hooks.OnFault(pc, op, gas, cost, scope, depth, err) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnGasChange != nil }); len(ts) > 0 {
+		hooks.OnGasChange = func(old, new uint64, reason tracing.GasChangeReason) {
+			for _, t := range ts {
+				t.call("OnGasChange", func() { t.hooks.OnGasChange(old, new, reason) })
+			}
+		}
+	}
+	// Chain events
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnBlockchainInit != nil }); len(ts) > 0 {
+		hooks.OnBlockchainInit = func(chainConfig *params.ChainConfig) {
+			for _, t := range ts {
+				t.call("OnBlockchainInit", func() { t.hooks.OnBlockchainInit(chainConfig) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnClose != nil }); len(ts) > 0 {
+		hooks.OnClose = func() {
+			for _, t := range ts {
+				// Disabled tracers are closed too, to release their resources
+				func() {
+					defer t.recover("OnClose")
+					t.hooks.OnClose()
+				}()
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnBlockStart != nil }); len(ts) > 0 {
+		hooks.OnBlockStart = func(event tracing.BlockEvent) {
+			for _, t := range ts {
+				t.call("OnBlockStart", func() { t.hooks.OnBlockStart(event) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnBlockEnd != nil }); len(ts) > 0 {
+		hooks.OnBlockEnd = func(err error) {
+			for _, t := range ts {
+				t.call("OnBlockEnd", func() { t.hooks.OnBlockEnd(err) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnSkippedBlock != nil }); len(ts) > 0 {
+		hooks.OnSkippedBlock = func(event tracing.BlockEvent) {
+			for _, t := range ts {
+				t.call("OnSkippedBlock", func() { t.hooks.OnSkippedBlock(event) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnGenesisBlock != nil }); len(ts) > 0 {
+		hooks.OnGenesisBlock = func(genesis *types.Block, alloc types.GenesisAlloc) {
+			for _, t := range ts {
+				t.call("OnGenesisBlock", func() { t.hooks.OnGenesisBlock(genesis, alloc) })
+			}
+		}
+	}
+	// Both versions of the system call start hook are dispatched through the
+	// latest one, which takes precedence when invoked.
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnSystemCallStartV2 != nil || h.OnSystemCallStart != nil }); len(ts) > 0 {
+		hooks.OnSystemCallStartV2 = func(vm *tracing.VMContext) {
+			for _, t := range ts {
+				t.call("OnSystemCallStart", func() {
+					if t.hooks.OnSystemCallStartV2 != nil {
+						t.hooks.OnSystemCallStartV2(vm)
+					} else {
+						t.hooks.OnSystemCallStart()
+					}
+				})
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnSystemCallEnd != nil }); len(ts) > 0 {
+		hooks.OnSystemCallEnd = func() {
+			for _, t := range ts {
+				t.call("OnSystemCallEnd", func() { t.hooks.OnSystemCallEnd() })
+			}
+		}
+	}
+	// State events
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnBalanceChange != nil }); len(ts) > 0 {
+		hooks.OnBalanceChange = func(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+			for _, t := range ts {
+				t.call("OnBalanceChange", func() { t.hooks.OnBalanceChange(addr, prev, new, reason) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnNonceChangeV2 != nil || h.OnNonceChange != nil }); len(ts) > 0 {
+		hooks.OnNonceChangeV2 = func(addr common.Address, prev, new uint64, reason tracing.NonceChangeReason) {
+			for _, t := range ts {
+				t.call("OnNonceChange", func() {
+					if t.hooks.OnNonceChangeV2 != nil {
+						t.hooks.OnNonceChangeV2(addr, prev, new, reason)
+					} else {
+						t.hooks.OnNonceChange(addr, prev, new)
+					}
+				})
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnCodeChange != nil }); len(ts) > 0 {
+		hooks.OnCodeChange = func(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+			for _, t := range ts {
+				t.call("OnCodeChange", func() { t.hooks.OnCodeChange(addr, prevCodeHash, prevCode, codeHash, code) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnStorageChange != nil }); len(ts) > 0 {
+		hooks.OnStorageChange = func(addr common.Address, slot common.Hash, prev, new common.Hash) {
+			for _, t := range ts {
+				t.call("OnStorageChange", func() { t.hooks.OnStorageChange(addr, slot, prev, new) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnLog != nil }); len(ts) > 0 {
+		hooks.OnLog = func(log *types.Log) {
+			for _, t := range ts {
+				t.call("OnLog", func() { t.hooks.OnLog(log) })
+			}
+		}
+	}
+	if ts := with(func(h *tracing.Hooks) bool { return h.OnBlockHashRead != nil }); len(ts) > 0 {
+		hooks.OnBlockHashRead = func(blockNumber uint64, hash common.Hash) {
+			for _, t := range ts {
+				t.call("OnBlockHashRead", func() { t.hooks.OnBlockHashRead(blockNumber, hash) })
+			}
+		}
+	}
+	return hooks
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestLiveMux(t *testing.T) {
+	var (
+		txs, nonces, closed int
+		faulty              = newMuxedTracer("test-faulty", &tracing.Hooks{
+			OnTxStart: func(vm *tracing.VMContext, tx *types.Transaction, from common.Address) {
+				panic("faulty tracer")
+			},
+			OnNonceChangeV2: func(addr common.Address, prev, new uint64, reason tracing.NonceChangeReason) {
+				nonces++
+			},
+			OnClose: func() { closed++ },
+		})
+		healthy = newMuxedTracer("test-healthy", &tracing.Hooks{
+			OnTxStart: func(vm *tracing.VMContext, tx *types.Transaction, from common.Address) {
+				txs++
+			},
+			OnNonceChange: func(addr common.Address, prev, new uint64) {
+				nonces++
+			},
+			OnClose: func() { closed++ },
+		})
+		hooks = newLiveMux([]*muxedTracer{faulty, healthy})
+	)
+	// Hooks not implemented by any tracer must not be set
+	if hooks.OnOpcode != nil || hooks.OnNonceChange != nil {
+		t.Fatal("unimplemented hooks set")
+	}
+	// Nonce changes reach both versions of the hook
+	hooks.OnNonceChangeV2(common.Address{}, 0, 1, tracing.NonceChangeEoACall)
+	if nonces != 2 {
+		t.Errorf("unexpected nonce change count: have %d, want 2", nonces)
+	}
+	// A panicking tracer is disabled without affecting the others
+	hooks.OnTxStart(nil, nil, common.Address{})
+	hooks.OnTxStart(nil, nil, common.Address{})
+	if txs != 2 {
+		t.Errorf("unexpected tx count: have %d, want 2", txs)
+	}
+	if !faulty.disabled.Load() || healthy.disabled.Load() {
+		t.Fatalf("unexpected disabled states: faulty %v, healthy %v", faulty.disabled.Load(), healthy.disabled.Load())
+	}
+	if faulty.panicCounter.Snapshot().Count() != 1 || faulty.disabledGauge.Snapshot().Value() != 1 {
+		t.Error("panic not reported in metrics")
+	}
+	hooks.OnNonceChangeV2(common.Address{}, 1, 2, tracing.NonceChangeEoACall)
+	if nonces != 3 {
+		t.Errorf("disabled tracer invoked: have %d nonce changes, want 3", nonces)
+	}
+	// Disabled tracers are still closed
+	hooks.OnClose()
+	if closed != 2 {
+		t.Errorf("unexpected close count: have %d, want 2", closed)
+	}
+}
+
+func TestLiveMuxConfig(t *testing.T) {
+	if _, err := LiveDirectory.NewMux([]LiveTracerConfig{{Name: "missing"}}); err == nil {
+		t.Error("unknown tracer accepted")
+	}
+	LiveDirectory.Register("test-mux", func(config json.RawMessage) (*tracing.Hooks, error) {
+		return new(tracing.Hooks), nil
+	})
+	if _, err := LiveDirectory.NewMux([]LiveTracerConfig{{Name: "test-mux"}, {Name: "test-mux"}}); err == nil {
+		t.Error("duplicate tracer accepted")
+	}
+	if _, err := LiveDirectory.NewMux([]LiveTracerConfig{{Name: "test-mux", Config: `{"a": 1}`}}); err != nil {
+		t.Errorf("failed to create mux: %v", err)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/sourcemap"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
 )
@@ -49,6 +50,8 @@ type Config struct {
 	Limit            int  // maximum size of output, but zero means unlimited
 	// Chain overrides, can be used to execute a trace using future fork rules
 	Overrides *params.ChainConfig `json:"overrides,omitempty"`
+	// Compiler artifacts, used to annotate the logs with source locations
+	Artifacts *sourcemap.Registry `json:"artifacts,omitempty"`
 }
 
 //go:generate go run github.com/fjl/gencodec -type StructLog -field-override structLogMarshaling -out gen_structlog.go
@@ -68,6 +71,7 @@ type StructLog struct {
 	Depth         int                         `json:"depth"`
 	RefundCounter uint64                      `json:"refund"`
 	Err           error                       `json:"-"`
+	Source        string                      `json:"source,omitempty"`
 }
 
 // overrides for gencodec
@@ -102,6 +106,10 @@ func (s *StructLog) WriteTo(writer io.Writer) {
 	}
 	fmt.Fprintln(writer)
 
+	if s.Source != "" {
+		fmt.Fprintf(writer, "Source: %s\n", s.Source)
+	}
+
 	if len(s.Stack) > 0 {
 		fmt.Fprintln(writer, "Stack:")
 		for i := len(s.Stack) - 1; i >= 0; i-- {
@@ -154,6 +162,7 @@ type structLogLegacy struct {
 	Memory        *[]string          `json:"memory,omitempty"`
 	Storage       *map[string]string `json:"storage,omitempty"`
 	RefundCounter uint64             `json:"refund,omitempty"`
+	Source        string             `json:"source,omitempty"`
 }
 
 // toLegacyJSON converts the structLog to legacy json-encoded legacy form.
@@ -166,6 +175,7 @@ func (s *StructLog) toLegacyJSON() json.RawMessage {
 		Depth:         s.Depth,
 		Error:         s.ErrorString(),
 		RefundCounter: s.RefundCounter,
+		Source:        s.Source,
 	}
 	if s.Stack != nil {
 		stack := make([]string, len(s.Stack))
@@ -204,8 +214,9 @@ func (s *StructLog) toLegacyJSON() json.RawMessage {
 // A StructLogger can either yield it's output immediately (streaming) or store for
 // later output.
 type StructLogger struct {
-	cfg Config
-	env *tracing.VMContext
+	cfg     Config
+	env     *tracing.VMContext
+	sources *sourcemap.Tracker // Source location resolver, nil without artifacts
 
 	storage map[common.Address]Storage
 	output  []byte
@@ -237,6 +248,9 @@ func NewStructLogger(cfg *Config) *StructLogger {
 	if cfg != nil {
 		logger.cfg = *cfg
 	}
+	if logger.cfg.Artifacts != nil {
+		logger.sources = sourcemap.NewTracker(logger.cfg.Artifacts)
+	}
 	return logger
 }
 
@@ -274,7 +288,7 @@ func (l *StructLogger) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope
 		stack        = scope.StackData()
 		stackLen     = len(stack)
 	)
-	log := StructLog{pc, op, gas, cost, nil, len(memory), nil, nil, nil, depth, l.env.StateDB.GetRefund(), err}
+	log := StructLog{pc, op, gas, cost, nil, len(memory), nil, nil, nil, depth, l.env.StateDB.GetRefund(), err, ""}
 	if l.cfg.EnableMemory {
 		log.Memory = memory
 	}
@@ -284,6 +298,11 @@ func (l *StructLogger) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope
 	if l.cfg.EnableReturnData {
 		log.ReturnData = rData
 	}
+	if l.sources != nil {
+		if loc := l.sources.Locate(pc, scope, depth); loc != nil {
+			log.Source = loc.String()
+		}
+	}
 
 	// Copy a snapshot of the current storage to a new container
 	var storage Storage
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/sourcemap"
 )
 
 //go:generate go run github.com/fjl/gencodec -type callFrame -field-override callFrameMarshaling -out gen_callframe.go
@@ -59,6 +60,7 @@ type jsonLogger struct {
 	cfg     *Config
 	env     *tracing.VMContext
 	hooks   *tracing.Hooks
+	sources *sourcemap.Tracker // Source location resolver, nil without artifacts
 }
 
 // NewJSONLogger creates a new EVM tracer that prints execution steps as JSON objects
@@ -68,6 +70,9 @@ func NewJSONLogger(cfg *Config, writer io.Writer) *tracing.Hooks {
 	if l.cfg == nil {
 		l.cfg = &Config{}
 	}
+	if l.cfg.Artifacts != nil {
+		l.sources = sourcemap.NewTracker(l.cfg.Artifacts)
+	}
 	l.hooks = &tracing.Hooks{
 		OnTxStart:         l.OnTxStart,
 		OnSystemCallStart: l.onSystemCallStart,
@@ -85,6 +90,9 @@ func NewJSONLoggerWithCallFrames(cfg *Config, writer io.Writer) *tracing.Hooks {
 	if l.cfg == nil {
 		l.cfg = &Config{}
 	}
+	if l.cfg.Artifacts != nil {
+		l.sources = sourcemap.NewTracker(l.cfg.Artifacts)
+	}
 	l.hooks = &tracing.Hooks{
 		OnTxStart:         l.OnTxStart,
 		OnSystemCallStart: l.onSystemCallStart,
@@ -124,6 +132,11 @@ func (l *jsonLogger) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tracin
 	if l.cfg.EnableReturnData {
 		log.ReturnData = rData
 	}
+	if l.sources != nil {
+		if loc := l.sources.Locate(pc, scope, depth); loc != nil {
+			log.Source = loc.String()
+		}
+	}
 	l.encoder.Encode(log)
 }
 
@@ -78,6 +78,8 @@ func TestStructLogMarshalingOmitEmpty(t *testing.T) {
 			`{"pc":0,"op":0,"gas":"0x0","gasCost":"0x0","memory":"0x0000","memSize":2,"stack":null,"depth":0,"refund":0,"opName":"STOP"}`},
 		{"with 0-size mem", &StructLog{Memory: make([]byte, 0)},
 			`{"pc":0,"op":0,"gas":"0x0","gasCost":"0x0","memSize":0,"stack":null,"depth":0,"refund":0,"opName":"STOP"}`},
+		{"with source", &StructLog{Source: "A.sol:3:9 (A.f)"},
+			`{"pc":0,"op":0,"gas":"0x0","gasCost":"0x0","memSize":0,"stack":null,"depth":0,"refund":0,"source":"A.sol:3:9 (A.f)","opName":"STOP"}`},
 	}
 
 	for _, tt := range tests {
@@ -92,3 +94,48 @@ func TestStructLogMarshalingOmitEmpty(t *testing.T) {
 		})
 	}
 }
+
+// Tests that struct logs are annotated with source locations when the logger
+// is configured with the compiler artifacts of the executed code.
+func TestStructLogSource(t *testing.T) {
+	var cfg Config
+	err := json.Unmarshal([]byte(`{"artifacts": {
+		"input": {"sources": {"A.sol": {"content": "contract A {\n    function f() public {\n        revert();\n    }\n}\n"}}},
+		"output": {
+			"sources": {"A.sol": {"id": 0, "ast": {"nodeType": "SourceUnit", "src": "0:65:0", "nodes": [
+				{"nodeType": "ContractDefinition", "name": "A", "src": "0:64:0", "nodes": [
+					{"nodeType": "FunctionDefinition", "name": "f", "kind": "function", "src": "17:45:0"}
+				]}
+			]}}},
+			"contracts": {"A.sol": {"A": {"evm": {"deployedBytecode": {"object": "60006000fd", "sourceMap": "0:64:0;47:8;"}}}}}
+		}
+	}}`), &cfg)
+	if err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	var (
+		logger   = NewStructLogger(&cfg)
+		evm      = vm.NewEVM(vm.BlockContext{BlockNumber: big.NewInt(1)}, &dummyStatedb{}, params.TestChainConfig, vm.Config{Tracer: logger.Hooks()})
+		contract = vm.NewContract(common.Address{}, common.Address{}, new(uint256.Int), 100000, nil)
+	)
+	contract.Code = []byte{byte(vm.PUSH1), 0x0, byte(vm.PUSH1), 0x0, byte(vm.REVERT)}
+	logger.OnTxStart(evm.GetVMContext(), nil, common.Address{})
+	if _, err := evm.Interpreter().Run(contract, []byte{}, false); !errors.Is(err, vm.ErrExecutionReverted) {
+		t.Fatalf("unexpected execution error: %v", err)
+	}
+	want := []string{"A.sol:1:1 (A)", "A.sol:3:9 (A.f)", "A.sol:3:9 (A.f)"}
+	if len(logger.logs) != len(want) {
+		t.Fatalf("unexpected log count: have %d, want %d", len(logger.logs), len(want))
+	}
+	for i, entry := range logger.logs {
+		var log struct {
+			Source string `json:"source"`
+		}
+		if err := json.Unmarshal(entry, &log); err != nil {
+			t.Fatal(err)
+		}
+		if log.Source != want[i] {
+			t.Errorf("log %d: unexpected source: have %q, want %q", i, log.Source, want[i])
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/sourcemap"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -57,12 +58,14 @@ type callFrame struct {
 	Output       []byte          `json:"output,omitempty" rlp:"optional"`
 	Error        string          `json:"error,omitempty" rlp:"optional"`
 	RevertReason string          `json:"revertReason,omitempty"`
+	ErrorSource  string          `json:"errorSource,omitempty" rlp:"-"`
 	Calls        []callFrame     `json:"calls,omitempty" rlp:"optional"`
 	Logs         []callLog       `json:"logs,omitempty" rlp:"optional"`
 	// Placed at end on purpose. The RLP will be decoded to 0 instead of
 	// nil if there are non-empty elements after in the struct.
 	Value            *big.Int `json:"value,omitempty" rlp:"optional"`
 	revertedSnapshot bool
+	source           *sourcemap.Location // Location of the last executed instruction
 }
 
 func (f callFrame) TypeString() string {
@@ -86,6 +89,9 @@ func (f *callFrame) processOutput(output []byte, err error, reverted bool) {
 	}
 	f.Error = err.Error()
 	f.revertedSnapshot = reverted
+	if f.source != nil {
+		f.ErrorSource = f.source.String()
+	}
 	if f.Type == vm.CREATE || f.Type == vm.CREATE2 {
 		f.To = nil
 	}
@@ -113,6 +119,7 @@ type callFrameMarshaling struct {
 type callTracer struct {
 	callstack []callFrame
 	config    callTracerConfig
+	sources   *sourcemap.Tracker // Source location resolver, nil without artifacts
 	gasLimit  uint64
 	depth     int
 	interrupt atomic.Bool // Atomic flag to signal execution interruption
@@ -122,6 +129,10 @@ type callTracer struct {
 type callTracerConfig struct {
 	OnlyTopCall bool `json:"onlyTopCall"` // If true, call tracer won't collect any subcalls
 	WithLog     bool `json:"withLog"`     // If true, call tracer will collect event logs
+
+	// Compiler artifacts, used to annotate failed calls with the source
+	// location they failed at
+	Artifacts *sourcemap.Registry `json:"artifacts,omitempty"`
 }
 
 // newCallTracer returns a native go tracer which tracks
@@ -131,14 +142,19 @@ func newCallTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *param
 	if err != nil {
 		return nil, err
 	}
+	hooks := &tracing.Hooks{
+		OnTxStart: t.OnTxStart,
+		OnTxEnd:   t.OnTxEnd,
+		OnEnter:   t.OnEnter,
+		OnExit:    t.OnExit,
+		OnLog:     t.OnLog,
+	}
+	// Instructions are only tracked when they can be mapped to sources
+	if t.sources != nil {
+		hooks.OnOpcode = t.OnOpcode
+	}
 	return &tracers.Tracer{
-		Hooks: &tracing.Hooks{
-			OnTxStart: t.OnTxStart,
-			OnTxEnd:   t.OnTxEnd,
-			OnEnter:   t.OnEnter,
-			OnExit:    t.OnExit,
-			OnLog:     t.OnLog,
-		},
+		Hooks:     hooks,
 		GetResult: t.GetResult,
 		Stop:      t.Stop,
 	}, nil
@@ -151,7 +167,11 @@ func newCallTracerObject(ctx *tracers.Context, cfg json.RawMessage) (*callTracer
 	}
 	// First callframe contains tx context info
 	// and is populated on start and end.
-	return &callTracer{callstack: make([]callFrame, 0, 1), config: config}, nil
+	t := &callTracer{callstack: make([]callFrame, 0, 1), config: config}
+	if config.Artifacts != nil {
+		t.sources = sourcemap.NewTracker(config.Artifacts)
+	}
+	return t, nil
 }
 
 // OnEnter is called when EVM enters a new scope (via call, create or selfdestruct).
@@ -180,6 +200,18 @@ func (t *callTracer) OnEnter(depth int, typ byte, from common.Address, to common
 	t.callstack = append(t.callstack, call)
 }
 
+// OnOpcode records the source location of the instruction being executed, so
+// a failing frame can be annotated with it.
+func (t *callTracer) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.interrupt.Load() {
+		return
+	}
+	// Instructions of a frame run at a depth one above its index in the stack
+	if index := depth - 1; index >= 0 && index < len(t.callstack) {
+		t.callstack[index].source = t.sources.Locate(pc, scope, depth)
+	}
+}
+
 // OnExit is called when EVM exits a scope, even if the scope didn't
 // execute any code.
 func (t *callTracer) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
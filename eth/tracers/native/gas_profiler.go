@@ -0,0 +1,256 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("gasProfiler", newGasProfiler, false)
+}
+
+// gasProfile is the result of the gasProfiler.
+type gasProfile struct {
+	TotalGas uint64                     `json:"totalGas"` // Execution gas, excluding intrinsic gas and refunds
+	Frames   []*gasProfileFrame         `json:"frames"`   // Call stacks, sorted by total gas descending
+	Opcodes  map[string]*gasProfileStat `json:"opcodes"`  // Gas spent per opcode across all frames
+	Folded   string                     `json:"folded"`   // Folded stacks for flame graph tools
+}
+
+// gasProfileFrame is the gas spent by all the calls with an identical call
+// stack, a stack being identified by the address and selector of every frame.
+type gasProfileFrame struct {
+	Stack    string         `json:"stack"`
+	Address  common.Address `json:"address"`
+	Selector string         `json:"selector"`
+	Calls    uint64         `json:"calls"`
+	SelfGas  uint64         `json:"selfGas"`  // Gas spent by the frame itself
+	TotalGas uint64         `json:"totalGas"` // Gas spent by the frame and its callees
+}
+
+// gasProfileStat is the gas spent by a single opcode.
+type gasProfileStat struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+// gasProfilerFrame is the profiling state of a call frame being executed.
+type gasProfilerFrame struct {
+	stack    string // Folded call stack of the frame
+	gas      uint64 // Gas available to the frame on entry
+	children uint64 // Gas used by the callees of the frame
+	executed bool   // Whether the frame executed any instruction
+
+	// The gas spent by an instruction is only known once the next one starts,
+	// so the last one is kept pending until then.
+	pending    bool
+	op         vm.OpCode
+	opGas      uint64 // Gas available before the pending instruction
+	opChildren uint64 // Gas used by callees of the pending instruction
+}
+
+// gasProfiler aggregates the gas spent by a transaction per call stack and per
+// opcode, producing both a JSON summary and folded stacks which can be fed
+// into flame graph tools. Folded stacks are leafed by opcode, so a flame graph
+// shows both the expensive functions and the instructions within them.
+//
+// Gas spent by an instruction is measured as the difference in available gas
+// before it and before the next instruction of the same frame, net of the gas
+// used by any call it makes, which correctly accounts for dynamic costs and
+// returned call gas.
+type gasProfiler struct {
+	frames    []*gasProfilerFrame
+	profile   map[string]*gasProfileFrame
+	opcodes   map[string]*gasProfileStat
+	folded    map[string]uint64
+	totalGas  uint64
+	interrupt atomic.Bool // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
+}
+
+// newGasProfiler returns a new gasProfiler.
+func newGasProfiler(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	t := &gasProfiler{
+		profile: make(map[string]*gasProfileFrame),
+		opcodes: make(map[string]*gasProfileStat),
+		folded:  make(map[string]uint64),
+	}
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnEnter:  t.OnEnter,
+			OnExit:   t.OnExit,
+			OnOpcode: t.OnOpcode,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *gasProfiler) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	selector := gasProfileSelector(vm.OpCode(typ), input)
+	stack := fmt.Sprintf("%s:%s", to.Hex(), selector)
+	if len(t.frames) > 0 {
+		stack = t.frames[len(t.frames)-1].stack + ";" + stack
+	}
+	t.frames = append(t.frames, &gasProfilerFrame{stack: stack, gas: gas})
+
+	frame, ok := t.profile[stack]
+	if !ok {
+		frame = &gasProfileFrame{Stack: stack, Address: to, Selector: selector}
+		t.profile[stack] = frame
+	}
+	frame.Calls++
+}
+
+func (t *gasProfiler) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	// The last instruction spent whatever the frame did not return
+	if frame.pending {
+		t.spend(frame, frame.gas-min(gasUsed, frame.gas))
+	}
+	self := gasUsed - min(frame.children, gasUsed)
+	if !frame.executed && self > 0 {
+		// Frames without any code, like precompiles or plain transfers, only
+		// appear as a whole in the flame graph.
+		t.folded[frame.stack] += self
+	}
+	profile := t.profile[frame.stack]
+	profile.SelfGas += self
+	profile.TotalGas += gasUsed
+
+	if len(t.frames) > 0 {
+		parent := t.frames[len(t.frames)-1]
+		parent.children += gasUsed
+		parent.opChildren += gasUsed
+	} else {
+		t.totalGas += gasUsed
+	}
+}
+
+func (t *gasProfiler) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	if frame.pending {
+		t.spend(frame, gas)
+	}
+	frame.pending, frame.executed = true, true
+	frame.op, frame.opGas, frame.opChildren = vm.OpCode(opcode), gas, 0
+}
+
+// spend accounts the gas spent by the pending instruction of a frame, given
+// the gas remaining after it.
+func (t *gasProfiler) spend(frame *gasProfilerFrame, remaining uint64) {
+	var spent uint64
+	if frame.opGas > remaining {
+		spent = frame.opGas - remaining
+	}
+	spent -= min(frame.opChildren, spent)
+	frame.pending = false
+
+	name := frame.op.String()
+	stat, ok := t.opcodes[name]
+	if !ok {
+		stat = new(gasProfileStat)
+		t.opcodes[name] = stat
+	}
+	stat.Count++
+	stat.Gas += spent
+
+	if spent > 0 {
+		t.folded[frame.stack+";"+name] += spent
+	}
+}
+
+// GetResult returns the json-encoded gas profile, and any error arising from
+// the encoding or forceful termination (via `Stop`).
+func (t *gasProfiler) GetResult() (json.RawMessage, error) {
+	profile := &gasProfile{
+		TotalGas: t.totalGas,
+		Frames:   make([]*gasProfileFrame, 0, len(t.profile)),
+		Opcodes:  t.opcodes,
+	}
+	for _, frame := range t.profile {
+		profile.Frames = append(profile.Frames, frame)
+	}
+	slices.SortFunc(profile.Frames, func(a, b *gasProfileFrame) int {
+		if c := cmp.Compare(b.TotalGas, a.TotalGas); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Stack, b.Stack)
+	})
+	stacks := make([]string, 0, len(t.folded))
+	for stack := range t.folded {
+		stacks = append(stacks, stack)
+	}
+	slices.Sort(stacks)
+
+	var folded strings.Builder
+	for _, stack := range stacks {
+		fmt.Fprintf(&folded, "%s %d\n", stack, t.folded[stack])
+	}
+	profile.Folded = folded.String()
+
+	res, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *gasProfiler) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+// gasProfileSelector returns the label of the function invoked by a call
+// frame: its 4-byte selector, or the kind of frame if there is none.
+func gasProfileSelector(typ vm.OpCode, input []byte) string {
+	switch typ {
+	case vm.CREATE, vm.CREATE2:
+		return "constructor"
+	case vm.SELFDESTRUCT:
+		return "selfdestruct"
+	}
+	if len(input) < 4 {
+		return "fallback"
+	}
+	return fmt.Sprintf("%#x", input[:4])
+}
@@ -0,0 +1,248 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("stateDiffTracer", newStateDiffTracer, false)
+}
+
+// diffValue is a single field of a Parity-style state diff. It is marshalled
+// as "=" if the value is unchanged, {"+": new} if it was created, {"-": old}
+// if it was deleted and {"*": {"from": old, "to": new}} if it was modified.
+type diffValue struct {
+	kind byte // One of '=', '+', '-' or '*'
+	from any
+	to   any
+}
+
+func (d diffValue) MarshalJSON() ([]byte, error) {
+	switch d.kind {
+	case '+':
+		return json.Marshal(map[string]any{"+": d.to})
+	case '-':
+		return json.Marshal(map[string]any{"-": d.from})
+	case '*':
+		return json.Marshal(map[string]any{"*": map[string]any{"from": d.from, "to": d.to}})
+	default:
+		return json.Marshal("=")
+	}
+}
+
+// newDiffValue creates a diff between the old and new value of a field, taking
+// into account whether the owning account was born or died.
+func newDiffValue(born, died, changed bool, from, to any) diffValue {
+	switch {
+	case born:
+		return diffValue{kind: '+', to: to}
+	case died:
+		return diffValue{kind: '-', from: from}
+	case changed:
+		return diffValue{kind: '*', from: from, to: to}
+	default:
+		return diffValue{kind: '='}
+	}
+}
+
+// accountDiff is the Parity-style state diff of a single account.
+type accountDiff struct {
+	Balance diffValue                 `json:"balance"`
+	Nonce   diffValue                 `json:"nonce"`
+	Code    diffValue                 `json:"code"`
+	Storage map[common.Hash]diffValue `json:"storage"`
+}
+
+// accountPrestate tracks the values of an account's fields before the first
+// modification within the transaction.
+type accountPrestate struct {
+	balance *big.Int
+	nonce   *uint64
+	code    []byte
+	hasCode bool
+	storage map[common.Hash]common.Hash
+}
+
+// stateDiffTracer collects the state modifications of a transaction in the
+// format of the Parity `trace_replayTransaction` stateDiff output.
+type stateDiffTracer struct {
+	env       *tracing.VMContext
+	pre       map[common.Address]*accountPrestate
+	diff      map[common.Address]*accountDiff
+	interrupt atomic.Bool // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
+}
+
+// newStateDiffTracer returns a new stateDiffTracer.
+func newStateDiffTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	t := &stateDiffTracer{
+		pre: make(map[common.Address]*accountPrestate),
+	}
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnTxStart:       t.OnTxStart,
+			OnTxEnd:         t.OnTxEnd,
+			OnBalanceChange: t.OnBalanceChange,
+			OnNonceChange:   t.OnNonceChange,
+			OnCodeChange:    t.OnCodeChange,
+			OnStorageChange: t.OnStorageChange,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *stateDiffTracer) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.env = env
+}
+
+// account returns the prestate tracker of an account, creating it if needed.
+func (t *stateDiffTracer) account(addr common.Address) *accountPrestate {
+	acc, ok := t.pre[addr]
+	if !ok {
+		acc = &accountPrestate{storage: make(map[common.Hash]common.Hash)}
+		t.pre[addr] = acc
+	}
+	return acc
+}
+
+func (t *stateDiffTracer) OnBalanceChange(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+	if t.interrupt.Load() {
+		return
+	}
+	if acc := t.account(addr); acc.balance == nil {
+		acc.balance = new0(prev)
+	}
+}
+
+func (t *stateDiffTracer) OnNonceChange(addr common.Address, prev, new uint64) {
+	if t.interrupt.Load() {
+		return
+	}
+	if acc := t.account(addr); acc.nonce == nil {
+		acc.nonce = &prev
+	}
+}
+
+func (t *stateDiffTracer) OnCodeChange(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+	if t.interrupt.Load() {
+		return
+	}
+	if acc := t.account(addr); !acc.hasCode {
+		acc.code, acc.hasCode = common.CopyBytes(prevCode), true
+	}
+}
+
+func (t *stateDiffTracer) OnStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {
+	if t.interrupt.Load() {
+		return
+	}
+	acc := t.account(addr)
+	if _, ok := acc.storage[slot]; !ok {
+		acc.storage[slot] = prev
+	}
+}
+
+func (t *stateDiffTracer) OnTxEnd(receipt *types.Receipt, err error) {
+	if err != nil || t.interrupt.Load() {
+		return
+	}
+	t.diff = make(map[common.Address]*accountDiff, len(t.pre))
+	for addr, acc := range t.pre {
+		var (
+			balance = t.env.StateDB.GetBalance(addr).ToBig()
+			nonce   = t.env.StateDB.GetNonce(addr)
+			code    = t.env.StateDB.GetCode(addr)
+		)
+		// Fields that were never modified retain their current value
+		if acc.balance == nil {
+			acc.balance = balance
+		}
+		if acc.nonce == nil {
+			acc.nonce = &nonce
+		}
+		if !acc.hasCode {
+			acc.code = code
+		}
+		var (
+			existed = acc.balance.Sign() != 0 || *acc.nonce != 0 || len(acc.code) != 0
+			exists  = t.env.StateDB.Exist(addr)
+			born    = !existed && exists
+			died    = existed && !exists
+		)
+		diff := &accountDiff{
+			Balance: newDiffValue(born, died, acc.balance.Cmp(balance) != 0, (*hexutil.Big)(acc.balance), (*hexutil.Big)(balance)),
+			Nonce:   newDiffValue(born, died, *acc.nonce != nonce, hexutil.Uint64(*acc.nonce), hexutil.Uint64(nonce)),
+			Code:    newDiffValue(born, died, !bytes.Equal(acc.code, code), hexutil.Bytes(acc.code), hexutil.Bytes(code)),
+			Storage: make(map[common.Hash]diffValue),
+		}
+		for slot, prev := range acc.storage {
+			var val common.Hash
+			if !died {
+				val = t.env.StateDB.GetState(addr, slot)
+			}
+			if prev == val {
+				continue
+			}
+			diff.Storage[slot] = newDiffValue(born, died, true, prev, val)
+		}
+		// Accounts only touched without any effective change are omitted
+		if !born && !died && diff.Balance.kind == '=' && diff.Nonce.kind == '=' && diff.Code.kind == '=' && len(diff.Storage) == 0 {
+			continue
+		}
+		t.diff[addr] = diff
+	}
+}
+
+// GetResult returns the json-encoded state diff, and any error arising from
+// the encoding or forceful termination (via `Stop`).
+func (t *stateDiffTracer) GetResult() (json.RawMessage, error) {
+	if t.diff == nil {
+		t.diff = make(map[common.Address]*accountDiff)
+	}
+	res, err := json.Marshal(t.diff)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *stateDiffTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+// new0 returns a copy of the given big integer, or zero if it's nil.
+func new0(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(x)
+}
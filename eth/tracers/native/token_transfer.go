@@ -0,0 +1,258 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("tokenTransferTracer", newTokenTransferTracer, false)
+}
+
+// Kinds of transfers reported by the tokenTransferTracer.
+const (
+	transferNative  = "native"
+	transferERC20   = "erc20"
+	transferERC721  = "erc721"
+	transferERC1155 = "erc1155"
+)
+
+var (
+	// transferTopic is the topic of the ERC-20 and ERC-721 Transfer event, the
+	// two being told apart by whether the amount or token id is indexed.
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// transferSingleTopic and transferBatchTopic are the topics of the ERC-1155
+	// TransferSingle and TransferBatch events.
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// tokenTransfer is a single normalized transfer of ether or tokens.
+type tokenTransfer struct {
+	Type     string          `json:"type"`
+	Token    *common.Address `json:"token,omitempty"`
+	Operator *common.Address `json:"operator,omitempty"`
+	From     common.Address  `json:"from"`
+	To       common.Address  `json:"to"`
+	TokenID  *hexutil.Big    `json:"tokenId,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+}
+
+// tokenTransferTracer reports all the ether and token transfers of a
+// transaction in a uniform format. Ether transfers are collected from the
+// call frames, including internal ones, while ERC-20, ERC-721 and ERC-1155
+// transfers are decoded from their standard events. Transfers made within
+// reverted call frames are dropped.
+type tokenTransferTracer struct {
+	transfers []*tokenTransfer   // Transfers of all the completed top level frames
+	frames    [][]*tokenTransfer // Transfers of the call frames currently executing
+	interrupt atomic.Bool        // Atomic flag to signal execution interruption
+	reason    error              // Textual reason for the interruption
+}
+
+// newTokenTransferTracer returns a new tokenTransferTracer.
+func newTokenTransferTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	t := &tokenTransferTracer{
+		transfers: []*tokenTransfer{},
+	}
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnEnter: t.OnEnter,
+			OnExit:  t.OnExit,
+			OnLog:   t.OnLog,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *tokenTransferTracer) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	var frame []*tokenTransfer
+
+	// Delegate and static calls can't carry value, and callcode only moves it
+	// back to the caller.
+	switch vm.OpCode(typ) {
+	case vm.CALL, vm.CREATE, vm.CREATE2, vm.SELFDESTRUCT:
+		if value != nil && value.Sign() > 0 && from != to {
+			frame = append(frame, &tokenTransfer{
+				Type:  transferNative,
+				From:  from,
+				To:    to,
+				Value: (*hexutil.Big)(new(big.Int).Set(value)),
+			})
+		}
+	}
+	t.frames = append(t.frames, frame)
+}
+
+func (t *tokenTransferTracer) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	if reverted {
+		return
+	}
+	if len(t.frames) == 0 {
+		t.transfers = append(t.transfers, frame...)
+	} else {
+		t.frames[len(t.frames)-1] = append(t.frames[len(t.frames)-1], frame...)
+	}
+}
+
+func (t *tokenTransferTracer) OnLog(log *types.Log) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	transfers := decodeTokenTransfers(log)
+	if len(transfers) == 0 {
+		return
+	}
+	t.frames[len(t.frames)-1] = append(t.frames[len(t.frames)-1], transfers...)
+}
+
+// GetResult returns the json-encoded list of transfers, and any error arising
+// from the encoding or forceful termination (via `Stop`).
+func (t *tokenTransferTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(t.transfers)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *tokenTransferTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+// decodeTokenTransfers decodes the token transfers announced by a log, if it
+// is one of the standard ERC-20, ERC-721 or ERC-1155 transfer events. Logs not
+// matching the standard layouts are ignored.
+func decodeTokenTransfers(log *types.Log) []*tokenTransfer {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	token := log.Address
+
+	switch log.Topics[0] {
+	case transferTopic:
+		switch {
+		case len(log.Topics) == 3 && len(log.Data) == 32:
+			return []*tokenTransfer{{
+				Type:  transferERC20,
+				Token: &token,
+				From:  common.BytesToAddress(log.Topics[1].Bytes()),
+				To:    common.BytesToAddress(log.Topics[2].Bytes()),
+				Value: (*hexutil.Big)(new(big.Int).SetBytes(log.Data)),
+			}}
+		case len(log.Topics) == 4 && len(log.Data) == 0:
+			return []*tokenTransfer{{
+				Type:    transferERC721,
+				Token:   &token,
+				From:    common.BytesToAddress(log.Topics[1].Bytes()),
+				To:      common.BytesToAddress(log.Topics[2].Bytes()),
+				TokenID: (*hexutil.Big)(log.Topics[3].Big()),
+			}}
+		}
+	case transferSingleTopic:
+		if len(log.Topics) != 4 || len(log.Data) != 64 {
+			return nil
+		}
+		operator := common.BytesToAddress(log.Topics[1].Bytes())
+		return []*tokenTransfer{{
+			Type:     transferERC1155,
+			Token:    &token,
+			Operator: &operator,
+			From:     common.BytesToAddress(log.Topics[2].Bytes()),
+			To:       common.BytesToAddress(log.Topics[3].Bytes()),
+			TokenID:  (*hexutil.Big)(new(big.Int).SetBytes(log.Data[:32])),
+			Value:    (*hexutil.Big)(new(big.Int).SetBytes(log.Data[32:])),
+		}}
+	case transferBatchTopic:
+		if len(log.Topics) != 4 || len(log.Data) < 64 {
+			return nil
+		}
+		ids, ok := decodeUintArray(log.Data, 0)
+		if !ok {
+			return nil
+		}
+		values, ok := decodeUintArray(log.Data, 32)
+		if !ok || len(ids) != len(values) {
+			return nil
+		}
+		var (
+			operator  = common.BytesToAddress(log.Topics[1].Bytes())
+			from      = common.BytesToAddress(log.Topics[2].Bytes())
+			to        = common.BytesToAddress(log.Topics[3].Bytes())
+			transfers = make([]*tokenTransfer, len(ids))
+		)
+		for i := range ids {
+			transfers[i] = &tokenTransfer{
+				Type:     transferERC1155,
+				Token:    &token,
+				Operator: &operator,
+				From:     from,
+				To:       to,
+				TokenID:  (*hexutil.Big)(ids[i]),
+				Value:    (*hexutil.Big)(values[i]),
+			}
+		}
+		return transfers
+	}
+	return nil
+}
+
+// decodeUintArray decodes an ABI encoded dynamic uint256 array from the data,
+// whose offset is stored in the word at the given position.
+func decodeUintArray(data []byte, pos int) ([]*big.Int, bool) {
+	offset := new(big.Int).SetBytes(data[pos : pos+32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return nil, false
+	}
+	start := int(offset.Uint64())
+
+	length := new(big.Int).SetBytes(data[start : start+32])
+	if !length.IsUint64() || length.Uint64() > uint64(len(data)-start-32)/32 {
+		return nil, false
+	}
+	items := make([]*big.Int, length.Uint64())
+	for i := range items {
+		word := start + 32 + 32*i
+		items[i] = new(big.Int).SetBytes(data[word : word+32])
+	}
+	return items, true
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenTransferTracer(t *testing.T) {
+	tracer, err := tracers.DefaultDirectory.New("tokenTransferTracer", &tracers.Context{}, nil, params.MainnetChainConfig)
+	require.NoError(t, err)
+
+	var (
+		alice    = common.HexToAddress("0xa11ce")
+		bob      = common.HexToAddress("0xb0b")
+		carol    = common.HexToAddress("0xca401")
+		token    = common.HexToAddress("0x70ce")
+		operator = common.HexToAddress("0x0e7a")
+
+		transfer = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+		single   = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+		batch    = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+		word     = func(n int64) []byte { return common.BigToHash(big.NewInt(n)).Bytes() }
+		topic    = func(addr common.Address) common.Hash { return common.BytesToHash(addr.Bytes()) }
+	)
+	// Alice calls bob with some ether, who transfers ERC-20 tokens to carol
+	tracer.OnEnter(0, byte(vm.CALL), alice, bob, nil, 0, big.NewInt(5))
+	tracer.OnLog(&types.Log{
+		Address: token,
+		Topics:  []common.Hash{transfer, topic(bob), topic(carol)},
+		Data:    word(42),
+	})
+	// A reverted ERC-1155 transfer with ether attached is dropped
+	tracer.OnEnter(1, byte(vm.CALL), bob, carol, nil, 0, big.NewInt(1))
+	tracer.OnLog(&types.Log{
+		Address: token,
+		Topics:  []common.Hash{single, topic(operator), topic(bob), topic(carol)},
+		Data:    append(word(1), word(2)...),
+	})
+	tracer.OnExit(1, nil, 0, vm.ErrExecutionReverted, true)
+
+	// A delegate call carries no ether, but does an ERC-721 and an ERC-1155
+	// batch transfer
+	tracer.OnEnter(1, byte(vm.DELEGATECALL), bob, carol, nil, 0, big.NewInt(5))
+	tracer.OnLog(&types.Log{
+		Address: token,
+		Topics:  []common.Hash{transfer, topic(bob), topic(alice), common.BigToHash(big.NewInt(7))},
+	})
+	var data []byte
+	for _, n := range []int64{64, 160, 2, 3, 4, 2, 10, 20} {
+		data = append(data, word(n)...)
+	}
+	tracer.OnLog(&types.Log{
+		Address: token,
+		Topics:  []common.Hash{batch, topic(operator), topic(bob), topic(alice)},
+		Data:    data,
+	})
+	tracer.OnExit(1, nil, 0, nil, false)
+	tracer.OnExit(0, nil, 0, nil, false)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+
+	var have []map[string]any
+	require.NoError(t, json.Unmarshal(res, &have))
+
+	hex := func(n int64) string { return hexutil.EncodeBig(big.NewInt(n)) }
+	addr := func(a common.Address) string { return hexutil.Encode(a.Bytes()) }
+	want := []map[string]any{
+		{"type": "native", "from": addr(alice), "to": addr(bob), "value": hex(5)},
+		{"type": "erc20", "token": addr(token), "from": addr(bob), "to": addr(carol), "value": hex(42)},
+		{"type": "erc721", "token": addr(token), "from": addr(bob), "to": addr(alice), "tokenId": hex(7)},
+		{"type": "erc1155", "token": addr(token), "operator": addr(operator), "from": addr(bob), "to": addr(alice), "tokenId": hex(3), "value": hex(10)},
+		{"type": "erc1155", "token": addr(token), "operator": addr(operator), "from": addr(bob), "to": addr(alice), "tokenId": hex(4), "value": hex(20)},
+	}
+	require.Len(t, have, len(want))
+	for i := range want {
+		for key, value := range want[i] {
+			require.EqualValuesf(t, value, have[i][key], "transfer %d field %s", i, key)
+		}
+		require.Lenf(t, have[i], len(want[i]), "transfer %d fields", i)
+	}
+}
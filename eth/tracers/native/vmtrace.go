@@ -0,0 +1,257 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/internal"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("vmTracer", newVMTracer, false)
+}
+
+// vmTrace is the Parity-style execution trace of a single call frame.
+type vmTrace struct {
+	Code hexutil.Bytes `json:"code"`
+	Ops  []*vmOp       `json:"ops"`
+}
+
+// vmOp is a single executed instruction within a vmTrace.
+type vmOp struct {
+	Cost uint64      `json:"cost"`
+	Ex   *vmExecuted `json:"ex"`
+	Pc   uint64      `json:"pc"`
+	Sub  *vmTrace    `json:"sub"`
+	Op   string      `json:"op"`
+}
+
+// vmExecuted holds the effects of an executed instruction.
+type vmExecuted struct {
+	Mem   *vmMem   `json:"mem"`
+	Push  []string `json:"push"`
+	Store *vmStore `json:"store"`
+	Used  uint64   `json:"used"`
+}
+
+// vmMem is a memory write done by an instruction.
+type vmMem struct {
+	Data hexutil.Bytes `json:"data"`
+	Off  uint64        `json:"off"`
+}
+
+// vmStore is a storage write done by an instruction.
+type vmStore struct {
+	Key string `json:"key"`
+	Val string `json:"val"`
+}
+
+// vmFrame is the tracing state of a single call frame. The effects of an
+// instruction are only known once the next instruction starts, so the last
+// one is kept pending until then.
+type vmFrame struct {
+	trace   *vmTrace
+	pending *vmOp
+	op      vm.OpCode
+	memOff  uint64
+	memSize uint64
+}
+
+// vmTracer produces the Parity-style vmTrace of a transaction, which is the
+// tree of all executed instructions along with their effects on the stack,
+// memory and storage.
+type vmTracer struct {
+	env       *tracing.VMContext
+	root      *vmTrace
+	frames    []*vmFrame
+	interrupt atomic.Bool // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
+}
+
+// newVMTracer returns a new vmTracer.
+func newVMTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	t := new(vmTracer)
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnTxStart: t.OnTxStart,
+			OnEnter:   t.OnEnter,
+			OnExit:    t.OnExit,
+			OnOpcode:  t.OnOpcode,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *vmTracer) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.env = env
+}
+
+func (t *vmTracer) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	trace := &vmTrace{Ops: []*vmOp{}}
+	switch vm.OpCode(typ) {
+	case vm.CREATE, vm.CREATE2:
+		trace.Code = common.CopyBytes(input)
+	default:
+		trace.Code = t.env.StateDB.GetCode(to)
+	}
+	if len(t.frames) == 0 {
+		t.root = trace
+	} else if parent := t.frames[len(t.frames)-1]; parent.pending != nil {
+		parent.pending.Sub = trace
+	}
+	t.frames = append(t.frames, &vmFrame{trace: trace})
+}
+
+func (t *vmTracer) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	t.frames[len(t.frames)-1].finish(nil, nil)
+	t.frames = t.frames[:len(t.frames)-1]
+}
+
+func (t *vmTracer) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	frame.finish(scope, &gas)
+
+	op := vm.OpCode(opcode)
+	entry := &vmOp{
+		Cost: cost,
+		Ex:   &vmExecuted{Push: []string{}},
+		Pc:   pc,
+		Op:   op.String(),
+	}
+	if gas > cost {
+		entry.Ex.Used = gas - cost
+	}
+	frame.trace.Ops = append(frame.trace.Ops, entry)
+	frame.pending, frame.op = entry, op
+	frame.memOff, frame.memSize = 0, 0
+
+	// Capture the operands of storage and memory writes, they are gone from the
+	// stack by the time the instruction completes.
+	var (
+		stack = scope.StackData()
+		size  = len(stack)
+	)
+	peek := func(n int) uint64 {
+		if n >= size {
+			return 0
+		}
+		return stack[size-1-n].Uint64()
+	}
+	switch op {
+	case vm.SSTORE:
+		if size >= 2 {
+			entry.Ex.Store = &vmStore{Key: stack[size-1].Hex(), Val: stack[size-2].Hex()}
+		}
+	case vm.MSTORE:
+		frame.memOff, frame.memSize = peek(0), 32
+	case vm.MSTORE8:
+		frame.memOff, frame.memSize = peek(0), 1
+	case vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY, vm.MCOPY:
+		frame.memOff, frame.memSize = peek(0), peek(2)
+	case vm.EXTCODECOPY:
+		frame.memOff, frame.memSize = peek(1), peek(3)
+	case vm.CALL, vm.CALLCODE:
+		frame.memOff, frame.memSize = peek(5), peek(6)
+	case vm.DELEGATECALL, vm.STATICCALL:
+		frame.memOff, frame.memSize = peek(4), peek(5)
+	}
+}
+
+// finish fills in the effects of the pending instruction of the frame from the
+// state at the start of the next instruction. If the frame is exiting, scope
+// and gas are nil and only the already known effects are retained.
+func (f *vmFrame) finish(scope tracing.OpContext, gas *uint64) {
+	if f.pending == nil {
+		return
+	}
+	entry := f.pending
+	f.pending = nil
+
+	if scope == nil {
+		return
+	}
+	entry.Ex.Used = *gas
+
+	stack := scope.StackData()
+	if n := min(vmPushCount(f.op), len(stack)); n > 0 {
+		for _, item := range stack[len(stack)-n:] {
+			entry.Ex.Push = append(entry.Ex.Push, item.Hex())
+		}
+	}
+	if f.memSize > 0 {
+		if data, err := internal.GetMemoryCopyPadded(scope.MemoryData(), int64(f.memOff), int64(f.memSize)); err == nil {
+			entry.Ex.Mem = &vmMem{Data: data, Off: f.memOff}
+		}
+	}
+}
+
+// vmPushCount returns the number of stack items reported as pushed by an
+// instruction. Following Parity, DUPn and SWAPn report all the items they
+// touched.
+func vmPushCount(op vm.OpCode) int {
+	switch {
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		return int(op-vm.DUP1) + 2
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		return int(op-vm.SWAP1) + 2
+	case op.IsPush():
+		return 1
+	}
+	switch op {
+	case vm.STOP, vm.POP, vm.MSTORE, vm.MSTORE8, vm.SSTORE, vm.TSTORE, vm.JUMP, vm.JUMPI, vm.JUMPDEST,
+		vm.LOG0, vm.LOG1, vm.LOG2, vm.LOG3, vm.LOG4, vm.RETURN, vm.REVERT, vm.INVALID, vm.SELFDESTRUCT,
+		vm.CALLDATACOPY, vm.CODECOPY, vm.EXTCODECOPY, vm.RETURNDATACOPY, vm.MCOPY:
+		return 0
+	}
+	return 1
+}
+
+// GetResult returns the json-encoded vmTrace, and any error arising from the
+// encoding or forceful termination (via `Stop`).
+func (t *vmTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(t.root)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *vmTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
@@ -0,0 +1,530 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package sourcemap maps the program counters of executing EVM code back to
+// Solidity sources, using the source maps and ASTs of compiler artifacts.
+package sourcemap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Location is a position within a Solidity source.
+type Location struct {
+	File     string // Source file name, as known to the compiler
+	Line     int    // 1-based line, zero if the source content is unknown
+	Column   int    // 1-based column, zero if the source content is unknown
+	Contract string // Innermost contract containing the position, if any
+	Function string // Innermost function or modifier containing the position, if any
+}
+
+// String formats the location as file:line:column, followed by the contract
+// and function names.
+func (l *Location) String() string {
+	var b strings.Builder
+	b.WriteString(l.File)
+	if l.Line > 0 {
+		fmt.Fprintf(&b, ":%d:%d", l.Line, l.Column)
+	}
+	switch {
+	case l.Contract != "" && l.Function != "":
+		fmt.Fprintf(&b, " (%s.%s)", l.Contract, l.Function)
+	case l.Contract != "":
+		fmt.Fprintf(&b, " (%s)", l.Contract)
+	case l.Function != "":
+		fmt.Fprintf(&b, " (%s)", l.Function)
+	}
+	return b.String()
+}
+
+// Program is a compiled bytecode along with the source locations of its
+// instructions.
+type Program struct {
+	Contract string // Fully qualified name of the contract, file:name
+
+	code      []byte
+	masks     [][2]int    // Byte ranges filled in at link or deploy time
+	prefix    bool        // Whether the code may be followed by other data
+	pcs       []int       // Instruction index of every code byte, -1 for push data
+	locations []*Location // Source location of every instruction
+}
+
+// Locate returns the source location of the instruction at the given program
+// counter, or nil if it doesn't map to any source.
+func (p *Program) Locate(pc uint64) *Location {
+	if pc >= uint64(len(p.pcs)) {
+		return nil
+	}
+	index := p.pcs[pc]
+	if index < 0 || index >= len(p.locations) {
+		return nil
+	}
+	return p.locations[index]
+}
+
+// matches returns whether the given code is the program, ignoring the bytes
+// filled in at link or deploy time.
+func (p *Program) matches(code []byte) bool {
+	if len(code) != len(p.code) && (!p.prefix || len(code) < len(p.code)) {
+		return false
+	}
+	var start int
+	for _, mask := range p.masks {
+		if !bytes.Equal(code[start:mask[0]], p.code[start:mask[0]]) {
+			return false
+		}
+		start = mask[1]
+	}
+	return bytes.Equal(code[start:len(p.code)], p.code[start:])
+}
+
+// Registry is a collection of compiled programs, from which the program of an
+// executing code can be retrieved. Runtime programs are indexed by code hash,
+// while programs containing linked libraries or immutables, and constructors
+// followed by their arguments, are matched byte by byte.
+//
+// A registry is immutable once loaded, so it can be shared across tracers.
+type Registry struct {
+	programs map[common.Hash]*Program // Runtime programs, by code hash
+	partial  []*Program               // Programs which can't be matched by hash
+	docs     []json.RawMessage        // Loaded documents, for re-encoding
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{programs: make(map[common.Hash]*Program)}
+}
+
+// Program retrieves the program of the given code, or nil if it is unknown.
+func (r *Registry) Program(code []byte) *Program {
+	if r == nil || len(code) == 0 {
+		return nil
+	}
+	if p, ok := r.programs[crypto.Keccak256Hash(code)]; ok {
+		return p
+	}
+	for _, p := range r.partial {
+		if p.matches(code) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Len returns the number of programs in the registry.
+func (r *Registry) Len() int {
+	return len(r.programs) + len(r.partial)
+}
+
+// MarshalJSON encodes the registry as the list of documents it was loaded
+// from, with their source contents embedded.
+func (r *Registry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.docs)
+}
+
+// UnmarshalJSON loads a registry from either a single or a list of artifact
+// documents. See Add for the supported formats.
+func (r *Registry) UnmarshalJSON(input []byte) error {
+	*r = *NewRegistry()
+
+	input = bytes.TrimSpace(input)
+	if len(input) > 0 && input[0] != '[' {
+		return r.Add(input, nil)
+	}
+	var docs []json.RawMessage
+	if err := json.Unmarshal(input, &docs); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := r.Add(doc, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// artifactsDoc is a Hardhat-style build info, bundling the standard-JSON
+// input and output of a compilation.
+type artifactsDoc struct {
+	Input  *artifactsInput `json:"input,omitempty"`
+	Output json.RawMessage `json:"output"`
+}
+
+type artifactsInput struct {
+	Sources map[string]artifactsSource `json:"sources"`
+}
+
+type artifactsSource struct {
+	Content string `json:"content"`
+}
+
+// standardOutput is the subset of the solc standard-JSON output needed to map
+// instructions to sources.
+type standardOutput struct {
+	Sources map[string]struct {
+		ID  int      `json:"id"`
+		AST *astNode `json:"ast"`
+	} `json:"sources"`
+	Contracts map[string]map[string]struct {
+		EVM struct {
+			Bytecode         bytecodeOutput `json:"bytecode"`
+			DeployedBytecode bytecodeOutput `json:"deployedBytecode"`
+		} `json:"evm"`
+	} `json:"contracts"`
+}
+
+type bytecodeOutput struct {
+	Object              string                                  `json:"object"`
+	SourceMap           string                                  `json:"sourceMap"`
+	LinkReferences      map[string]map[string][]referenceOutput `json:"linkReferences"`
+	ImmutableReferences map[string][]referenceOutput            `json:"immutableReferences"`
+}
+
+type referenceOutput struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// astNode is the subset of a compact AST node needed to name locations.
+type astNode struct {
+	NodeType string     `json:"nodeType"`
+	Name     string     `json:"name"`
+	Kind     string     `json:"kind"`
+	Src      string     `json:"src"`
+	Nodes    []*astNode `json:"nodes"`
+}
+
+// Add loads the programs of a document, which is either the standard-JSON
+// output of solc, or a build info bundling the standard-JSON input and output
+// of a compilation. Source contents are taken from the input if present, or
+// otherwise read with the given function, if any. Without source contents,
+// locations only carry file, contract and function names.
+func (r *Registry) Add(doc []byte, readSource func(path string) ([]byte, error)) error {
+	var bundle artifactsDoc
+	if err := json.Unmarshal(doc, &bundle); err != nil {
+		return err
+	}
+	if bundle.Output == nil {
+		bundle.Output = doc
+	}
+	var output standardOutput
+	if err := json.Unmarshal(bundle.Output, &output); err != nil {
+		return err
+	}
+	if len(output.Contracts) == 0 {
+		return errors.New("no contracts in compiler output")
+	}
+	contents := make(map[string]artifactsSource)
+	if bundle.Input != nil {
+		maps.Copy(contents, bundle.Input.Sources)
+	}
+	files := make(map[int]*sourceFile, len(output.Sources))
+	for path, source := range output.Sources {
+		content, ok := contents[path]
+		if !ok && readSource != nil {
+			if data, err := readSource(path); err == nil {
+				content, ok = artifactsSource{Content: string(data)}, true
+				contents[path] = content
+			}
+		}
+		files[source.ID] = newSourceFile(path, source.ID, content.Content, ok, source.AST)
+	}
+	for path, contracts := range output.Contracts {
+		for name, contract := range contracts {
+			qualified := path + ":" + name
+			for _, code := range []*bytecodeOutput{&contract.EVM.DeployedBytecode, &contract.EVM.Bytecode} {
+				if code.Object == "" {
+					continue // abstract contract or interface
+				}
+				p, err := newProgram(qualified, code, files)
+				if err != nil {
+					return fmt.Errorf("contract %s: %w", qualified, err)
+				}
+				if code == &contract.EVM.Bytecode {
+					p.prefix = true // constructor arguments are appended
+				}
+				if len(p.masks) > 0 || p.prefix {
+					r.partial = append(r.partial, p)
+				} else {
+					r.programs[crypto.Keccak256Hash(p.code)] = p
+				}
+			}
+		}
+	}
+	// Keep the document around for re-encoding, bundled with the contents of
+	// its sources so it can be reloaded without reading any file.
+	bundle.Input = nil
+	if len(contents) > 0 {
+		bundle.Input = &artifactsInput{Sources: contents}
+	}
+	enc, err := json.Marshal(&bundle)
+	if err != nil {
+		return err
+	}
+	r.docs = append(r.docs, enc)
+	return nil
+}
+
+// newProgram decodes a compiled bytecode and its source map.
+func newProgram(name string, code *bytecodeOutput, files map[int]*sourceFile) (*Program, error) {
+	object := strings.TrimPrefix(code.Object, "0x")
+
+	// Unlinked library addresses are placeholders in the hex encoding, replace
+	// them with zeroes and mask them out of the comparison.
+	var masks [][2]int
+	for _, libs := range code.LinkReferences {
+		for _, refs := range libs {
+			for _, ref := range refs {
+				if start, end := 2*ref.Start, 2*(ref.Start+ref.Length); end <= len(object) {
+					object = object[:start] + strings.Repeat("0", end-start) + object[end:]
+				}
+				masks = append(masks, [2]int{ref.Start, ref.Start + ref.Length})
+			}
+		}
+	}
+	for _, refs := range code.ImmutableReferences {
+		for _, ref := range refs {
+			masks = append(masks, [2]int{ref.Start, ref.Start + ref.Length})
+		}
+	}
+	bin, err := hex.DecodeString(object)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(masks, func(a, b [2]int) int { return a[0] - b[0] })
+	for _, mask := range masks {
+		if mask[1] > len(bin) {
+			return nil, fmt.Errorf("reference %d:%d out of bounds", mask[0], mask[1])
+		}
+	}
+	p := &Program{
+		Contract: name,
+		code:     bin,
+		masks:    masks,
+		pcs:      make([]int, len(bin)),
+	}
+	var index int
+	for pc := 0; pc < len(bin); index++ {
+		p.pcs[pc] = index
+		pc++
+		if op := vm.OpCode(bin[pc-1]); op.IsPush() {
+			for end := min(pc+int(op-vm.PUSH0), len(bin)); pc < end; pc++ {
+				p.pcs[pc] = -1
+			}
+		}
+	}
+	entries, err := parseSourceMap(code.SourceMap)
+	if err != nil {
+		return nil, err
+	}
+	p.locations = make([]*Location, len(entries))
+	for i, e := range entries {
+		if file := files[e.file]; file != nil {
+			p.locations[i] = file.locate(e.start, e.length)
+		}
+	}
+	return p, nil
+}
+
+// sourceMapEntry is a decoded source map element, the range of the source
+// an instruction was generated from.
+type sourceMapEntry struct {
+	start  int
+	length int
+	file   int // -1 for code generated by the compiler
+}
+
+// parseSourceMap decodes a compressed source map, in which every instruction
+// is described by s:l:f:j:m fields, empty fields inheriting from the previous
+// instruction.
+func parseSourceMap(sourceMap string) ([]sourceMapEntry, error) {
+	if sourceMap == "" {
+		return nil, nil
+	}
+	var (
+		elems   = strings.Split(sourceMap, ";")
+		entries = make([]sourceMapEntry, len(elems))
+		last    = sourceMapEntry{file: -1}
+	)
+	for i, elem := range elems {
+		fields := strings.Split(elem, ":")
+		for j, dst := range []*int{&last.start, &last.length, &last.file} {
+			if j >= len(fields) || fields[j] == "" {
+				continue
+			}
+			n, err := strconv.Atoi(fields[j])
+			if err != nil {
+				return nil, fmt.Errorf("invalid source map element %d: %q", i, elem)
+			}
+			*dst = n
+		}
+		entries[i] = last
+	}
+	return entries, nil
+}
+
+// sourceFile is a compiled source, with the ranges of its definitions.
+type sourceFile struct {
+	path        string
+	content     bool  // Whether the line offsets are known
+	lines       []int // Byte offset of every line start
+	contracts   []definition
+	functions   []definition
+	locationMap map[[2]int]*Location // Deduplicated locations
+}
+
+// definition is a named range of a source.
+type definition struct {
+	name       string
+	start, end int
+}
+
+func newSourceFile(path string, id int, content string, known bool, ast *astNode) *sourceFile {
+	f := &sourceFile{path: path, content: known, locationMap: make(map[[2]int]*Location)}
+	if known {
+		f.lines = []int{0}
+		for i := 0; i < len(content); i++ {
+			if content[i] == '\n' {
+				f.lines = append(f.lines, i+1)
+			}
+		}
+	}
+	if ast != nil {
+		f.collect(ast, id)
+	}
+	return f
+}
+
+// collect gathers the contract and function definitions of an AST, which are
+// found at the top levels of source units and contracts.
+func (f *sourceFile) collect(node *astNode, id int) {
+	var def *[]definition
+	name := node.Name
+	switch node.NodeType {
+	case "ContractDefinition":
+		def = &f.contracts
+	case "FunctionDefinition", "ModifierDefinition":
+		def = &f.functions
+		if name == "" {
+			name = node.Kind // constructor, fallback or receive
+		}
+	}
+	if def != nil {
+		if start, length, file, ok := parseSrc(node.Src); ok && file == id {
+			*def = append(*def, definition{name: name, start: start, end: start + length})
+		}
+	}
+	for _, child := range node.Nodes {
+		f.collect(child, id)
+	}
+}
+
+// locate returns the location of the given range of the source.
+func (f *sourceFile) locate(start, length int) *Location {
+	key := [2]int{start, length}
+	if loc, ok := f.locationMap[key]; ok {
+		return loc
+	}
+	loc := &Location{
+		File:     f.path,
+		Contract: innermost(f.contracts, start, start+length),
+		Function: innermost(f.functions, start, start+length),
+	}
+	if f.content {
+		line := sort.SearchInts(f.lines, start+1) - 1
+		if line >= 0 {
+			loc.Line, loc.Column = line+1, start-f.lines[line]+1
+		}
+	}
+	f.locationMap[key] = loc
+	return loc
+}
+
+// innermost returns the name of the smallest definition containing a range.
+func innermost(defs []definition, start, end int) string {
+	var (
+		name string
+		size = -1
+	)
+	for _, def := range defs {
+		if def.start <= start && end <= def.end && (size < 0 || def.end-def.start < size) {
+			name, size = def.name, def.end-def.start
+		}
+	}
+	return name
+}
+
+// parseSrc decodes an AST source range in the start:length:file format.
+func parseSrc(src string) (start, length, file int, ok bool) {
+	parts := strings.Split(src, ":")
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+	var err error
+	if start, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, 0, false
+	}
+	if length, err = strconv.Atoi(parts[1]); err != nil {
+		return 0, 0, 0, false
+	}
+	if file, err = strconv.Atoi(parts[2]); err != nil {
+		return 0, 0, 0, false
+	}
+	return start, length, file, true
+}
+
+// Tracker resolves the source locations of the instructions executed by a
+// transaction, caching the program of the executing code between them.
+type Tracker struct {
+	registry *Registry
+	depth    int
+	address  common.Address
+	program  *Program
+}
+
+// NewTracker creates a tracker resolving locations from the given registry.
+func NewTracker(registry *Registry) *Tracker {
+	return &Tracker{registry: registry}
+}
+
+// Locate returns the source location of the instruction executed at the given
+// program counter and call depth, as reported by the OnOpcode hook.
+func (t *Tracker) Locate(pc uint64, scope tracing.OpContext, depth int) *Location {
+	// Consecutive instructions at the same depth and address belong to the same
+	// frame, any other sequence means the executing code changed.
+	if addr := scope.Address(); depth != t.depth || addr != t.address {
+		t.depth, t.address = depth, addr
+		t.program = t.registry.Program(scope.ContractCode())
+	}
+	if t.program == nil {
+		return nil
+	}
+	return t.program.Locate(pc)
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package sourcemap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// testSource is a contract reverting unconditionally.
+	testSource = "contract A {\n    function f() public {\n        revert();\n    }\n}\n"
+
+	// testOutput is the compiler output of testSource, with the runtime code
+	// PUSH1 0 PUSH1 0 REVERT, and a library B containing an immutable and
+	// deployed with constructor arguments.
+	testOutput = `{
+		"sources": {"A.sol": {"id": 0, "ast": {"nodeType": "SourceUnit", "src": "0:65:0", "nodes": [
+			{"nodeType": "ContractDefinition", "name": "A", "src": "0:64:0", "nodes": [
+				{"nodeType": "FunctionDefinition", "name": "f", "kind": "function", "src": "17:45:0"}
+			]}
+		]}}},
+		"contracts": {"A.sol": {
+			"A": {"evm": {"deployedBytecode": {"object": "60006000fd", "sourceMap": "0:64:0;47:8;"}}},
+			"B": {"evm": {
+				"bytecode": {"object": "600a600c", "sourceMap": "0:64:0"},
+				"deployedBytecode": {"object": "7f000000000000000000000000000000000000000000000000000000000000000000", "sourceMap": "0:64:0", "immutableReferences": {"3": [{"start": 1, "length": 32}]}}
+			}}
+		}}
+	}`
+)
+
+func TestRegistry(t *testing.T) {
+	code := common.FromHex("60006000fd")
+
+	// Without the source content, locations only carry names
+	registry := NewRegistry()
+	if err := registry.Add([]byte(testOutput), nil); err != nil {
+		t.Fatalf("failed to load artifacts: %v", err)
+	}
+	program := registry.Program(code)
+	if program == nil {
+		t.Fatal("program not found")
+	}
+	if program.Contract != "A.sol:A" {
+		t.Errorf("unexpected contract: %s", program.Contract)
+	}
+	if loc := program.Locate(4); loc == nil || loc.String() != "A.sol (A.f)" {
+		t.Errorf("unexpected location: %v", loc)
+	}
+	if loc := program.Locate(1); loc != nil {
+		t.Errorf("push data mapped to %v", loc)
+	}
+	if registry.Program(common.FromHex("60016000fd")) != nil {
+		t.Error("unknown code mapped to a program")
+	}
+	// Immutables and constructor arguments are ignored when matching code
+	immutable := common.FromHex("7f00000000000000000000000000000000000000000000000000000000000000ff00")
+	if p := registry.Program(immutable); p == nil || p.Contract != "A.sol:B" {
+		t.Errorf("code with immutables not matched: %v", p)
+	}
+	if p := registry.Program(common.FromHex("600a600c0000000000000000000000000000000000000000000000000000000000000001")); p == nil || p.Contract != "A.sol:B" {
+		t.Errorf("constructor with arguments not matched: %v", p)
+	}
+	// With the source content, locations carry lines and columns
+	registry = NewRegistry()
+	err := registry.Add([]byte(testOutput), func(path string) ([]byte, error) {
+		if path != "A.sol" {
+			return nil, errors.New("not found")
+		}
+		return []byte(testSource), nil
+	})
+	if err != nil {
+		t.Fatalf("failed to load artifacts: %v", err)
+	}
+	for pc, want := range map[uint64]string{0: "A.sol:1:1 (A)", 2: "A.sol:3:9 (A.f)", 4: "A.sol:3:9 (A.f)"} {
+		if loc := registry.Program(code).Locate(pc); loc == nil || loc.String() != want {
+			t.Errorf("pc %d: unexpected location: have %v, want %s", pc, loc, want)
+		}
+	}
+	// Encoded registries embed the source content
+	enc, err := json.Marshal(registry)
+	if err != nil {
+		t.Fatalf("failed to encode registry: %v", err)
+	}
+	var decoded Registry
+	if err := json.Unmarshal(enc, &decoded); err != nil {
+		t.Fatalf("failed to decode registry: %v", err)
+	}
+	if decoded.Len() != registry.Len() {
+		t.Errorf("program count mismatch: have %d, want %d", decoded.Len(), registry.Len())
+	}
+	if loc := decoded.Program(code).Locate(4); loc == nil || loc.String() != "A.sol:3:9 (A.f)" {
+		t.Errorf("unexpected decoded location: %v", loc)
+	}
+}
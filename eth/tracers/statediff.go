@@ -0,0 +1,177 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ValueDiff is the value of a state field before and after a block.
+type ValueDiff[T any] struct {
+	From T `json:"from"`
+	To   T `json:"to"`
+}
+
+// StorageDiff is the value of a storage slot before and after a block, along
+// with the preimage of the slot key if known.
+type StorageDiff struct {
+	From     common.Hash   `json:"from"`
+	To       common.Hash   `json:"to"`
+	Preimage hexutil.Bytes `json:"preimage,omitempty"`
+}
+
+// AccountDiff is the net change of an account over a block. Only the fields
+// which changed are set.
+type AccountDiff struct {
+	Balance *ValueDiff[*hexutil.Big]     `json:"balance,omitempty"`
+	Nonce   *ValueDiff[hexutil.Uint64]   `json:"nonce,omitempty"`
+	Code    *ValueDiff[hexutil.Bytes]    `json:"code,omitempty"`
+	Storage map[common.Hash]*StorageDiff `json:"storage,omitempty"`
+}
+
+// BlockStateDiff is the net state change of a block, the accounts whose state
+// differs between the parent block and the block.
+type BlockStateDiff struct {
+	Number   hexutil.Uint64                  `json:"number"`
+	Hash     common.Hash                     `json:"hash"`
+	Accounts map[common.Address]*AccountDiff `json:"accounts"`
+}
+
+// StateDiffRecorder records the state touched by the execution of a block,
+// along with its value before the first change. Intermediate values are not
+// kept: once the block is executed, the recorded values are compared against
+// the final state to produce the net diff of the block.
+type StateDiffRecorder struct {
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+	codes    map[common.Address][]byte
+	storage  map[common.Address]map[common.Hash]common.Hash
+}
+
+// NewStateDiffRecorder creates a recorder, whose hooks must be attached to the
+// state and EVM executing the block.
+func NewStateDiffRecorder() *StateDiffRecorder {
+	return &StateDiffRecorder{
+		balances: make(map[common.Address]*big.Int),
+		nonces:   make(map[common.Address]uint64),
+		codes:    make(map[common.Address][]byte),
+		storage:  make(map[common.Address]map[common.Hash]common.Hash),
+	}
+}
+
+// Hooks returns the state change hooks feeding the recorder.
+func (r *StateDiffRecorder) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBalanceChange: r.onBalanceChange,
+		OnNonceChange:   r.onNonceChange,
+		OnCodeChange:    r.onCodeChange,
+		OnStorageChange: r.onStorageChange,
+	}
+}
+
+func (r *StateDiffRecorder) onBalanceChange(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+	if _, ok := r.balances[addr]; !ok {
+		r.balances[addr] = copyBalance(prev)
+	}
+}
+
+func (r *StateDiffRecorder) onNonceChange(addr common.Address, prev, new uint64) {
+	if _, ok := r.nonces[addr]; !ok {
+		r.nonces[addr] = prev
+	}
+}
+
+func (r *StateDiffRecorder) onCodeChange(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+	if _, ok := r.codes[addr]; !ok {
+		r.codes[addr] = common.CopyBytes(prevCode)
+	}
+}
+
+func (r *StateDiffRecorder) onStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {
+	slots, ok := r.storage[addr]
+	if !ok {
+		slots = make(map[common.Hash]common.Hash)
+		r.storage[addr] = slots
+	}
+	if _, ok := slots[slot]; !ok {
+		slots[slot] = prev
+	}
+}
+
+// Diff compares the recorded values against the state after the block,
+// returning the net diff of the block. If a preimage resolver is given, the
+// changed storage slots are annotated with the preimages of their keys.
+func (r *StateDiffRecorder) Diff(block *types.Block, statedb tracing.StateDB, preimage func(common.Hash) []byte) *BlockStateDiff {
+	diff := &BlockStateDiff{
+		Number:   hexutil.Uint64(block.NumberU64()),
+		Hash:     block.Hash(),
+		Accounts: make(map[common.Address]*AccountDiff),
+	}
+	account := func(addr common.Address) *AccountDiff {
+		if diff.Accounts[addr] == nil {
+			diff.Accounts[addr] = new(AccountDiff)
+		}
+		return diff.Accounts[addr]
+	}
+	for addr, prev := range r.balances {
+		if cur := statedb.GetBalance(addr).ToBig(); cur.Cmp(prev) != 0 {
+			account(addr).Balance = &ValueDiff[*hexutil.Big]{From: (*hexutil.Big)(prev), To: (*hexutil.Big)(cur)}
+		}
+	}
+	for addr, prev := range r.nonces {
+		if cur := statedb.GetNonce(addr); cur != prev {
+			account(addr).Nonce = &ValueDiff[hexutil.Uint64]{From: hexutil.Uint64(prev), To: hexutil.Uint64(cur)}
+		}
+	}
+	for addr, prev := range r.codes {
+		if cur := statedb.GetCode(addr); !bytes.Equal(cur, prev) {
+			account(addr).Code = &ValueDiff[hexutil.Bytes]{From: prev, To: common.CopyBytes(cur)}
+		}
+	}
+	for addr, slots := range r.storage {
+		for slot, prev := range slots {
+			cur := statedb.GetState(addr, slot)
+			if cur == prev {
+				continue
+			}
+			entry := &StorageDiff{From: prev, To: cur}
+			if preimage != nil {
+				entry.Preimage = preimage(slot)
+			}
+			acc := account(addr)
+			if acc.Storage == nil {
+				acc.Storage = make(map[common.Hash]*StorageDiff)
+			}
+			acc.Storage[slot] = entry
+		}
+	}
+	return diff
+}
+
+// copyBalance returns a copy of a balance, zero if nil.
+func copyBalance(balance *big.Int) *big.Int {
+	if balance == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(balance)
+}
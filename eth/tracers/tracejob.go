@@ -0,0 +1,628 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// defaultTraceJobFileSize is the amount of uncompressed trace results
+	// written to an output file by default before rotating to the next one.
+	defaultTraceJobFileSize = 256 * 1024 * 1024
+
+	// traceJobCheckpointInterval is the time interval between two checkpoints
+	// of a running tracing job.
+	traceJobCheckpointInterval = 8 * time.Second
+
+	// maxRunningTraceJobs is the maximum number of concurrently running tracing
+	// jobs, each of them tracing blocks on all cores.
+	maxRunningTraceJobs = 4
+
+	// traceJobFile is the name of the file holding the parameters and progress
+	// of a job within its directory.
+	traceJobFile = "job.json"
+)
+
+// States of a tracing job.
+const (
+	traceJobRunning   = "running"
+	traceJobPaused    = "paused"
+	traceJobDone      = "done"
+	traceJobCancelled = "cancelled"
+	traceJobFailed    = "failed"
+)
+
+var (
+	errTraceJobNotFound  = errors.New("tracing job not found")
+	errTooManyTraceJobs  = errors.New("too many running tracing jobs")
+	errTraceJobsDisabled = errors.New("tracing jobs require a data directory")
+)
+
+// TraceJobConfig holds the parameters of a tracing job.
+type TraceJobConfig struct {
+	TraceConfig
+
+	// Compression of the output files, either empty or "gzip".
+	Compression string
+
+	// MaxFileSize is the amount of uncompressed trace results written to an
+	// output file before rotating to the next one.
+	MaxFileSize *uint64
+}
+
+// TraceJobStatus is the progress of a tracing job.
+type TraceJobStatus struct {
+	ID      string   `json:"id"`
+	State   string   `json:"state"`
+	Start   uint64   `json:"start"`
+	End     uint64   `json:"end"`
+	Current uint64   `json:"current"` // Last block whose results were checkpointed
+	Files   []string `json:"files"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// traceJobRecord is the persisted state of a tracing job. The checkpoint is
+// the last block whose results are written, along with the position in the
+// output files right after them.
+type traceJobRecord struct {
+	Start  uint64          `json:"start"`
+	End    uint64          `json:"end"`
+	Config *TraceJobConfig `json:"config"`
+	State  string          `json:"state"`
+	Error  string          `json:"error,omitempty"`
+
+	Block   uint64 `json:"block"`   // Last block whose results are written
+	Segment int    `json:"segment"` // Index of the output file being written
+	Offset  int64  `json:"offset"`  // Size of the output file after the block
+	Size    uint64 `json:"size"`    // Uncompressed results in the output file
+}
+
+// traceJob is a tracing job over a range of blocks, writing the results to
+// rotating files in its own directory.
+type traceJob struct {
+	id  string
+	dir string
+
+	lock      sync.Mutex
+	record    traceJobRecord
+	stop      chan struct{} // Closed to stop the running job
+	stopState string        // State to persist when the job stops
+	done      chan struct{} // Closed when the running job terminates
+}
+
+// status returns the progress of the job.
+func (job *traceJob) status() *TraceJobStatus {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
+	status := &TraceJobStatus{
+		ID:      job.id,
+		State:   job.record.State,
+		Start:   job.record.Start,
+		End:     job.record.End,
+		Current: job.record.Block,
+		Error:   job.record.Error,
+	}
+	for i := 0; i <= job.record.Segment; i++ {
+		status.Files = append(status.Files, traceJobPath(job.dir, job.record.Config, i))
+	}
+	return status
+}
+
+// save persists the record of the job. The lock must be held.
+func (job *traceJob) save() error {
+	blob, err := json.MarshalIndent(&job.record, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(job.dir, traceJobFile+".tmp")
+	if err := os.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(job.dir, traceJobFile))
+}
+
+// halt stops the job if it's running, persisting the given state, and waits
+// for it to terminate.
+func (job *traceJob) halt(state string) {
+	job.lock.Lock()
+	if job.record.State != traceJobRunning || job.stopState != "" {
+		done := job.done
+		job.lock.Unlock()
+		if done != nil {
+			<-done
+		}
+		return
+	}
+	job.stopState = state
+	close(job.stop)
+	done := job.done
+	job.lock.Unlock()
+
+	<-done
+}
+
+// run traces the blocks of the job after its checkpoint, and persists the
+// final state of the job once tracing terminates.
+func (job *traceJob) run(api *API, stop <-chan struct{}) {
+	err := job.trace(api, stop)
+
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
+	switch {
+	case err != nil:
+		job.record.State, job.record.Error = traceJobFailed, err.Error()
+		log.Warn("Tracing job failed", "id", job.id, "block", job.record.Block, "err", err)
+	case job.record.Block == job.record.End:
+		job.record.State = traceJobDone
+		log.Info("Tracing job finished", "id", job.id, "start", job.record.Start, "end", job.record.End)
+	default:
+		job.record.State = job.stopState
+		log.Info("Tracing job stopped", "id", job.id, "block", job.record.Block, "state", job.stopState)
+	}
+	if err := job.save(); err != nil {
+		log.Error("Failed to persist tracing job", "id", job.id, "err", err)
+	}
+}
+
+// trace streams the results of the chain tracer into the output files,
+// checkpointing them periodically and when stopped.
+func (job *traceJob) trace(api *API, stop <-chan struct{}) error {
+	job.lock.Lock()
+	record := job.record
+	job.lock.Unlock()
+
+	if record.Block >= record.End {
+		return nil
+	}
+	ctx := context.Background()
+	from, err := api.blockByNumber(ctx, rpc.BlockNumber(record.Block))
+	if err != nil {
+		return err
+	}
+	to, err := api.blockByNumber(ctx, rpc.BlockNumber(record.End))
+	if err != nil {
+		return err
+	}
+	out := &traceJobOutput{dir: job.dir, config: record.Config}
+	if err := out.open(record.Segment, record.Offset, record.Size); err != nil {
+		return err
+	}
+	defer out.close()
+
+	var (
+		closed  = make(chan error)
+		results = api.traceChain(from, to, &record.Config.TraceConfig, closed)
+		block   = record.Block
+		saved   = time.Now()
+	)
+	// abort stops the chain tracer and drains the results it already produced,
+	// letting it release its resources.
+	abort := func() {
+		close(closed)
+		for range results {
+		}
+	}
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				if err := job.checkpoint(out, block); err != nil {
+					return err
+				}
+				if block != record.End {
+					return fmt.Errorf("chain tracing aborted after block #%d", block)
+				}
+				return nil
+			}
+			if err := out.write(result); err != nil {
+				abort()
+				return err
+			}
+			block = uint64(result.Block)
+
+			if time.Since(saved) > traceJobCheckpointInterval {
+				if err := job.checkpoint(out, block); err != nil {
+					abort()
+					return err
+				}
+				saved = time.Now()
+				log.Info("Tracing job in progress", "id", job.id, "start", record.Start, "end", record.End, "current", block)
+			}
+		case <-stop:
+			abort()
+			return job.checkpoint(out, block)
+		}
+	}
+}
+
+// checkpoint syncs the output files and persists the progress of the job up
+// to the given block.
+func (job *traceJob) checkpoint(out *traceJobOutput, block uint64) error {
+	if err := out.sync(); err != nil {
+		return err
+	}
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
+	job.record.Block = block
+	job.record.Segment, job.record.Offset, job.record.Size = out.segment, out.offset, out.size
+	return job.save()
+}
+
+// traceJobPath returns the path of an output file of a job.
+func traceJobPath(dir string, config *TraceJobConfig, segment int) string {
+	name := fmt.Sprintf("traces-%06d.jsonl", segment)
+	if config.Compression == "gzip" {
+		name += ".gz"
+	}
+	return filepath.Join(dir, name)
+}
+
+// traceJobOutput writes block trace results as JSON lines into rotating
+// output files.
+//
+// Compressed files consist of one gzip member per sync, so that the file is
+// valid up to the offset of every sync and anything written after the last
+// checkpoint can simply be truncated away when resuming.
+type traceJobOutput struct {
+	dir    string
+	config *TraceJobConfig
+
+	segment int    // Index of the file being written
+	offset  int64  // Size of the file at the last sync
+	size    uint64 // Uncompressed results written into the file
+
+	file   *os.File
+	buf    *bufio.Writer
+	gz     *gzip.Writer // Compressor if compressing, nil otherwise
+	member bool         // Whether a gzip member is open
+}
+
+// open opens an output file for writing at the given offset, discarding any
+// content after it.
+func (out *traceJobOutput) open(segment int, offset int64, size uint64) error {
+	file, err := os.OpenFile(traceJobPath(out.dir, out.config, segment), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return err
+	}
+	out.file, out.buf = file, bufio.NewWriter(file)
+	if out.config.Compression == "gzip" && out.gz == nil {
+		out.gz = gzip.NewWriter(out.buf)
+	}
+	out.member = false
+	out.segment, out.offset, out.size = segment, offset, size
+	return nil
+}
+
+// write appends the results of a block, rotating to the next file if the
+// current one is full.
+func (out *traceJobOutput) write(result *blockTraceResult) error {
+	blob, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	blob = append(blob, '\n')
+
+	limit := uint64(defaultTraceJobFileSize)
+	if out.config.MaxFileSize != nil {
+		limit = *out.config.MaxFileSize
+	}
+	if out.size > 0 && out.size+uint64(len(blob)) > limit {
+		if err := out.close(); err != nil {
+			return err
+		}
+		if err := out.open(out.segment+1, 0, 0); err != nil {
+			return err
+		}
+	}
+	var w io.Writer = out.buf
+	if out.gz != nil {
+		if !out.member {
+			out.gz.Reset(out.buf)
+			out.member = true
+		}
+		w = out.gz
+	}
+	if _, err := w.Write(blob); err != nil {
+		return err
+	}
+	out.size += uint64(len(blob))
+	return nil
+}
+
+// sync terminates the open gzip member and flushes the written results to
+// disk.
+func (out *traceJobOutput) sync() error {
+	if out.member {
+		if err := out.gz.Close(); err != nil {
+			return err
+		}
+		out.member = false
+	}
+	if err := out.buf.Flush(); err != nil {
+		return err
+	}
+	if err := out.file.Sync(); err != nil {
+		return err
+	}
+	offset, err := out.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	out.offset = offset
+	return nil
+}
+
+// close syncs and closes the current output file.
+func (out *traceJobOutput) close() error {
+	if out.file == nil {
+		return nil
+	}
+	err := out.sync()
+	if cerr := out.file.Close(); err == nil {
+		err = cerr
+	}
+	out.file = nil
+	return err
+}
+
+// TraceJobManager runs tracing jobs over block ranges on the node, writing the
+// results to files instead of streaming them to the requester. The progress of
+// the jobs is checkpointed, so they can be paused and resumed, and the running
+// jobs continue from their last checkpoint after a restart.
+type TraceJobManager struct {
+	api *API
+	dir string
+
+	lock   sync.Mutex
+	jobs   map[string]*traceJob
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewTraceJobManager creates a manager for the tracing jobs stored in the
+// given directory. Jobs are disabled if the directory is empty.
+func NewTraceJobManager(backend Backend, dir string) *TraceJobManager {
+	return &TraceJobManager{
+		api:  NewAPI(backend),
+		dir:  dir,
+		jobs: make(map[string]*traceJob),
+	}
+}
+
+// APIs returns the RPC services controlling the tracing jobs.
+func (m *TraceJobManager) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "debug",
+			Service:   &TraceJobAPI{jobs: m},
+		},
+	}
+}
+
+// Start implements node.Lifecycle, loading the persisted jobs and resuming
+// the ones which were running when the node stopped.
+func (m *TraceJobManager) Start() error {
+	if m.dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		job := &traceJob{id: entry.Name(), dir: filepath.Join(m.dir, entry.Name())}
+		blob, err := os.ReadFile(filepath.Join(job.dir, traceJobFile))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Warn("Failed to load tracing job", "id", job.id, "err", err)
+			}
+			continue
+		}
+		if err := json.Unmarshal(blob, &job.record); err != nil || job.record.Config == nil {
+			log.Warn("Failed to load tracing job", "id", job.id, "err", err)
+			continue
+		}
+		m.jobs[job.id] = job
+		if job.record.State == traceJobRunning {
+			log.Info("Resuming tracing job", "id", job.id, "block", job.record.Block, "end", job.record.End)
+			m.launch(job)
+		}
+	}
+	return nil
+}
+
+// Stop implements node.Lifecycle, stopping the running jobs. They are kept in
+// the running state so they resume on the next start.
+func (m *TraceJobManager) Stop() error {
+	m.lock.Lock()
+	m.closed = true
+	jobs := make([]*traceJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.lock.Unlock()
+
+	for _, job := range jobs {
+		job.halt(traceJobRunning)
+	}
+	m.wg.Wait()
+	return nil
+}
+
+// launch starts running a job from its checkpoint. The manager lock must be
+// held.
+func (m *TraceJobManager) launch(job *traceJob) {
+	job.lock.Lock()
+	job.record.State, job.record.Error = traceJobRunning, ""
+	job.stop, job.stopState = make(chan struct{}), ""
+	job.done = make(chan struct{})
+	stop, done := job.stop, job.done
+	job.lock.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(done)
+		job.run(m.api, stop)
+	}()
+}
+
+// running returns the number of running jobs. The manager lock must be held.
+func (m *TraceJobManager) running() int {
+	var n int
+	for _, job := range m.jobs {
+		job.lock.Lock()
+		if job.record.State == traceJobRunning {
+			n++
+		}
+		job.lock.Unlock()
+	}
+	return n
+}
+
+// job returns the job with the given id.
+func (m *TraceJobManager) job(id string) (*traceJob, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, errTraceJobNotFound
+	}
+	return job, nil
+}
+
+// create persists a new job and starts running it.
+func (m *TraceJobManager) create(start, end uint64, config *TraceJobConfig) (*traceJob, error) {
+	if m.dir == "" {
+		return nil, errTraceJobsDisabled
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return nil, errors.New("tracing job manager stopped")
+	}
+	if m.running() >= maxRunningTraceJobs {
+		return nil, errTooManyTraceJobs
+	}
+	id := string(rpc.NewID())
+	job := &traceJob{
+		id:  id,
+		dir: filepath.Join(m.dir, id),
+		record: traceJobRecord{
+			Start:  start,
+			End:    end,
+			Config: config,
+			State:  traceJobPaused,
+			Block:  start,
+		},
+	}
+	if err := os.MkdirAll(job.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := job.save(); err != nil {
+		return nil, err
+	}
+	m.jobs[id] = job
+	m.launch(job)
+	return job, nil
+}
+
+// resume restarts a paused or failed job from its checkpoint.
+func (m *TraceJobManager) resume(id string) (*traceJob, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, errTraceJobNotFound
+	}
+	if m.closed {
+		return nil, errors.New("tracing job manager stopped")
+	}
+	job.lock.Lock()
+	state := job.record.State
+	job.lock.Unlock()
+
+	if state != traceJobPaused && state != traceJobFailed {
+		return nil, fmt.Errorf("tracing job is %s", state)
+	}
+	if m.running() >= maxRunningTraceJobs {
+		return nil, errTooManyTraceJobs
+	}
+	m.launch(job)
+	return job, nil
+}
+
+// cancel stops a job for good, keeping the results written so far.
+func (m *TraceJobManager) cancel(id string) (*traceJob, error) {
+	job, err := m.job(id)
+	if err != nil {
+		return nil, err
+	}
+	job.lock.Lock()
+	state := job.record.State
+	job.lock.Unlock()
+
+	switch state {
+	case traceJobDone, traceJobCancelled:
+		return nil, fmt.Errorf("tracing job is %s", state)
+	case traceJobRunning:
+		job.halt(traceJobCancelled)
+		return job, nil
+	}
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
+	job.record.State = traceJobCancelled
+	return job, job.save()
+}
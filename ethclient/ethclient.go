@@ -130,6 +130,25 @@ func (ec *Client) BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumb
 	return r, err
 }
 
+// BlobSidecars returns the archived blob sidecars of all the blobs included in
+// the given block. The node must have its blob archive enabled.
+func (ec *Client) BlobSidecars(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.BlobSidecar, error) {
+	var r []*types.BlobSidecar
+	err := ec.c.CallContext(ctx, &r, "eth_getBlobSidecars", blockNrOrHash.String())
+	if err == nil && r == nil {
+		return nil, ethereum.NotFound
+	}
+	return r, err
+}
+
+// BlobSidecarsByHash returns the archived blob sidecars of the blobs with the
+// given versioned hashes. Blobs unknown to the node are returned as nil.
+func (ec *Client) BlobSidecarsByHash(ctx context.Context, vhashes []common.Hash) ([]*types.BlobSidecar, error) {
+	var r []*types.BlobSidecar
+	err := ec.c.CallContext(ctx, &r, "eth_getBlobSidecarsByHash", vhashes)
+	return r, err
+}
+
 type rpcBlock struct {
 	Hash         common.Hash         `json:"hash"`
 	Transactions []rpcTransaction    `json:"transactions"`
@@ -690,6 +709,17 @@ func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) er
 	return ec.c.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
 }
 
+// SendPrivateTransaction injects a signed transaction into the pending pool of the
+// node without it being propagated to the network. The node drops the transaction
+// if it's not included within maxBlocks blocks.
+func (ec *Client) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlocks uint64) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return ec.c.CallContext(ctx, nil, "eth_sendPrivateRawTransaction", hexutil.Encode(data), hexutil.Uint64(maxBlocks))
+}
+
 // RevertErrorData returns the 'revert reason' data of a contract call.
 //
 // This can be used with CallContract and EstimateGas, and only when the server is Geth.
@@ -0,0 +1,795 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package netdb implements the key-value database layer on top of a database
+// served over the network, with a simple framed protocol over TCP.
+//
+// The reads are cached in-process. The cache is kept coherent with the writes of
+// the other processes by polling the server for the modified keys, so the reads
+// might lag behind these writes by the polling interval.
+//
+// In replica mode, the writes are kept in an in-memory overlay instead of being
+// sent to the server, so the node reads back its own writes while the database
+// of the primary stays untouched.
+package netdb
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	errNotFound        = errors.New("not found")
+	errClosed          = errors.New("database closed")
+	errAncientReadOnly = errors.New("ancient store is read-only over the network")
+	errNoAncientDir    = errors.New("ancient store is remote")
+
+	cacheHitMeter  = metrics.NewRegisteredMeter("netdb/cache/hit", nil)
+	cacheMissMeter = metrics.NewRegisteredMeter("netdb/cache/miss", nil)
+)
+
+const (
+	// iteratePageCount and iteratePageBytes are the size limits of the pages
+	// retrieved by the iterators.
+	iteratePageCount = 1024
+	iteratePageBytes = 1024 * 1024
+
+	// cacheItemOverhead is the approximate memory overhead of a cached item.
+	cacheItemOverhead = 64
+)
+
+// Config contains the settings of a networked database.
+type Config struct {
+	Cache        int           // Size of the read cache in megabytes, 0 disables the cache
+	Connections  int           // Maximum number of idle connections kept open
+	SyncInterval time.Duration // Interval of polling the server for the modified keys
+	Timeout      time.Duration // Timeout of the requests
+
+	// Replica keeps the writes in memory instead of sending them to the
+	// server, so that a node can run on top of the database of another one,
+	// without modifying it. The local writes shadow the remote content and
+	// are lost on restart.
+	Replica bool
+}
+
+// DefaultConfig contains the default settings of a networked database.
+var DefaultConfig = Config{
+	Cache:        512,
+	Connections:  16,
+	SyncInterval: 250 * time.Millisecond,
+	Timeout:      time.Minute,
+}
+
+// Database is a key-value store accessed over the network. The ancient store of
+// the server is accessible for reading as well.
+type Database struct {
+	addr    string
+	config  Config
+	cache   *cache
+	overlay *overlay // Local writes of a replica, nil if not a replica
+
+	lock   sync.Mutex
+	idle   []*conn // Idle connections to the server
+	closed bool
+
+	synced  uint64 // Version of the database the cache is invalidated up to
+	quit    chan struct{}
+	stopped chan struct{}
+}
+
+// conn is a buffered connection to the server.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// New connects to the database served at the given address.
+func New(addr string, config Config) (*Database, error) {
+	if config.Connections <= 0 {
+		config.Connections = DefaultConfig.Connections
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = DefaultConfig.SyncInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultConfig.Timeout
+	}
+	db := &Database{
+		addr:    addr,
+		config:  config,
+		cache:   newCache(uint64(config.Cache) * 1024 * 1024),
+		quit:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if config.Replica {
+		db.overlay = newOverlay()
+	}
+	// Retrieve the current version of the database, the cache being empty,
+	// there's nothing to invalidate.
+	var resp changesResponse
+	if err := db.request(opChanges, &changesRequest{Since: math.MaxUint64}, &resp); err != nil {
+		db.Close()
+		return nil, err
+	}
+	db.synced = resp.Version
+	go db.syncLoop()
+	return db, nil
+}
+
+// Close disconnects from the server.
+func (db *Database) Close() error {
+	db.lock.Lock()
+	if db.closed {
+		db.lock.Unlock()
+		return nil
+	}
+	db.closed = true
+	for _, c := range db.idle {
+		c.Close()
+	}
+	db.idle = nil
+	db.lock.Unlock()
+
+	close(db.quit)
+	select {
+	case <-db.stopped:
+	case <-time.After(db.config.Timeout):
+	}
+	return nil
+}
+
+// syncLoop periodically polls the server for the modified keys, invalidating
+// them in the cache.
+func (db *Database) syncLoop() {
+	defer close(db.stopped)
+
+	ticker := time.NewTicker(db.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			var resp changesResponse
+			if err := db.request(opChanges, &changesRequest{Since: db.synced}, &resp); err != nil {
+				log.Debug("Failed to retrieve remote database changes", "addr", db.addr, "err", err)
+				continue
+			}
+			if resp.Reset {
+				db.cache.purge(resp.Version)
+			} else {
+				db.cache.invalidate(resp.Keys, resp.Version)
+			}
+			db.synced = resp.Version
+
+		case <-db.quit:
+			return
+		}
+	}
+}
+
+// getConn returns an idle connection to the server or opens a new one.
+func (db *Database) getConn() (*conn, error) {
+	db.lock.Lock()
+	if db.closed {
+		db.lock.Unlock()
+		return nil, errClosed
+	}
+	if n := len(db.idle); n > 0 {
+		c := db.idle[n-1]
+		db.idle = db.idle[:n-1]
+		db.lock.Unlock()
+		return c, nil
+	}
+	db.lock.Unlock()
+
+	nc, err := net.DialTimeout("tcp", db.addr, db.config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}, nil
+}
+
+// putConn returns a connection to the idle ones, or closes it if there are
+// enough of them already.
+func (db *Database) putConn(c *conn) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed || len(db.idle) >= db.config.Connections {
+		c.Close()
+		return
+	}
+	db.idle = append(db.idle, c)
+}
+
+// request sends a request to the server and decodes the response into resp,
+// unless it's nil.
+func (db *Database) request(op byte, req interface{}, resp interface{}) error {
+	var payload []byte
+	if req != nil {
+		enc, err := rlp.EncodeToBytes(req)
+		if err != nil {
+			return err
+		}
+		payload = enc
+	}
+	c, err := db.getConn()
+	if err != nil {
+		return err
+	}
+	c.SetDeadline(time.Now().Add(db.config.Timeout))
+	if err := writeFrame(c.w, op, payload); err != nil {
+		c.Close()
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		c.Close()
+		return err
+	}
+	status, payload, err := readFrame(c.r)
+	if err != nil {
+		c.Close()
+		return err
+	}
+	db.putConn(c)
+
+	if status != statusOK {
+		return responseError(status, payload)
+	}
+	if resp == nil {
+		return nil
+	}
+	return rlp.DecodeBytes(payload, resp)
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *Database) Has(key []byte) (bool, error) {
+	if err := db.checkOpen(); err != nil {
+		return false, err
+	}
+	if db.overlay != nil {
+		if _, found, ok := db.overlay.get(key); ok {
+			return found, nil
+		}
+	}
+	if _, found, ok := db.cache.get(key); ok {
+		cacheHitMeter.Mark(1)
+		return found, nil
+	}
+	cacheMissMeter.Mark(1)
+
+	var resp getResponse
+	if err := db.request(opGet, &getRequest{Keys: [][]byte{key}, NoValues: true}, &resp); err != nil {
+		return false, err
+	}
+	if len(resp.Found) != 1 {
+		return false, errors.New("invalid response")
+	}
+	// Only the absence of a key can be cached without the value.
+	if !resp.Found[0] {
+		db.cache.add(resp.Version, key, nil, false)
+	}
+	return resp.Found[0], nil
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	values, err := db.GetMany([][]byte{key})
+	if err != nil {
+		return nil, err
+	}
+	if values[0] == nil {
+		return nil, errNotFound
+	}
+	return values[0], nil
+}
+
+// GetMany retrieves the given keys in a single round trip to the server. The
+// values of the missing keys are nil, while the empty values are returned as
+// empty, non-nil slices.
+func (db *Database) GetMany(keys [][]byte) ([][]byte, error) {
+	if err := db.checkOpen(); err != nil {
+		return nil, err
+	}
+	var (
+		values = make([][]byte, len(keys))
+		misses [][]byte
+		index  []int
+	)
+	for i, key := range keys {
+		if db.overlay != nil {
+			if value, found, ok := db.overlay.get(key); ok {
+				if found {
+					values[i] = common.CopyBytes(value)
+				}
+				continue
+			}
+		}
+		if value, found, ok := db.cache.get(key); ok {
+			if found {
+				values[i] = common.CopyBytes(value)
+			}
+			continue
+		}
+		misses = append(misses, key)
+		index = append(index, i)
+	}
+	cacheHitMeter.Mark(int64(len(keys) - len(misses)))
+	if len(misses) == 0 {
+		return values, nil
+	}
+	cacheMissMeter.Mark(int64(len(misses)))
+
+	var resp getResponse
+	if err := db.request(opGet, &getRequest{Keys: misses}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Found) != len(misses) || len(resp.Values) != len(misses) {
+		return nil, errors.New("invalid response")
+	}
+	for i, key := range misses {
+		if !resp.Found[i] {
+			db.cache.add(resp.Version, key, nil, false)
+			continue
+		}
+		value := resp.Values[i]
+		if value == nil {
+			value = []byte{}
+		}
+		db.cache.add(resp.Version, key, value, true)
+		values[index[i]] = common.CopyBytes(value)
+	}
+	return values, nil
+}
+
+// Put inserts the given value into the key-value store.
+func (db *Database) Put(key []byte, value []byte) error {
+	return db.write([]writeOp{{Key: key, Value: value}})
+}
+
+// Delete removes the key from the key-value store.
+func (db *Database) Delete(key []byte) error {
+	return db.write([]writeOp{{Key: key, Delete: true}})
+}
+
+// write applies the given writes atomically.
+func (db *Database) write(ops []writeOp) error {
+	if db.overlay != nil {
+		if err := db.checkOpen(); err != nil {
+			return err
+		}
+		db.overlay.apply(ops)
+		return nil
+	}
+	var version uint64
+	if err := db.request(opWrite, &writeRequest{Ops: ops}, &version); err != nil {
+		return err
+	}
+	keys := make([][]byte, len(ops))
+	for i, op := range ops {
+		keys[i] = op.Key
+	}
+	db.cache.invalidate(keys, version)
+	return nil
+}
+
+// DeleteRange deletes all of the keys (and values) in the range [start,end)
+// (inclusive on start, exclusive on end).
+func (db *Database) DeleteRange(start, end []byte) error {
+	if db.overlay != nil {
+		if err := db.checkOpen(); err != nil {
+			return err
+		}
+		db.overlay.deleteRange(start, end)
+		return nil
+	}
+	var version uint64
+	err := db.request(opDeleteRange, &deleteRangeRequest{Start: start, End: end}, &version)
+
+	// The range might be partially deleted even on failure, the cache will
+	// be purged by the sync loop if the version is unknown.
+	db.cache.purge(version)
+	return err
+}
+
+// checkOpen returns an error if the database is closed.
+func (db *Database) checkOpen() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return errClosed
+	}
+	return nil
+}
+
+// Stat returns the statistic data of the database.
+func (db *Database) Stat() (string, error) {
+	var stat string
+	if err := db.request(opStat, nil, &stat); err != nil {
+		return "", err
+	}
+	return stat, nil
+}
+
+// Compact flattens the underlying data store for the given key range. The
+// database of the primary is not compacted by a replica, its own writes being
+// held in memory, so there is nothing to do.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	if db.overlay != nil {
+		return db.checkOpen()
+	}
+	return db.request(opCompact, &compactRequest{Start: start, Limit: limit, HasLimit: limit != nil}, nil)
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (db *Database) NewBatch() ethdb.Batch {
+	return &batch{db: db}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
+	return &batch{db: db, data: make([]byte, 0, size)}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+//
+// The iterator retrieves the content from the server in pages, so it doesn't
+// provide a consistent snapshot of the database.
+func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	it := &iterator{
+		db:     db,
+		prefix: common.CopyBytes(prefix),
+		start:  common.CopyBytes(start),
+		index:  -1,
+		more:   true,
+	}
+	if db.overlay != nil {
+		return db.overlay.iterator(prefix, start, it)
+	}
+	return it
+}
+
+// HasAncient returns an indicator whether the specified data exists in the
+// ancient store.
+func (db *Database) HasAncient(kind string, number uint64) (bool, error) {
+	if _, err := db.Ancient(kind, number); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (db *Database) Ancient(kind string, number uint64) ([]byte, error) {
+	items, err := db.AncientRange(kind, number, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != 1 {
+		return nil, errors.New("invalid response")
+	}
+	return items[0], nil
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index 'start'.
+func (db *Database) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	var items [][]byte
+	if err := db.request(opAncient, &ancientRequest{Kind: kind, Start: start, Count: count, MaxBytes: maxBytes}, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Ancients returns the ancient item numbers in the ancient store.
+func (db *Database) Ancients() (uint64, error) {
+	var resp ancientsResponse
+	if err := db.request(opAncients, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Items, nil
+}
+
+// Tail returns the number of first stored item in the ancient store.
+func (db *Database) Tail() (uint64, error) {
+	var resp ancientsResponse
+	if err := db.request(opAncients, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Tail, nil
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (db *Database) AncientSize(kind string) (uint64, error) {
+	var size uint64
+	if err := db.request(opAncientSize, kind, &size); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// ReadAncients runs the given read operation. Note that the writes to the remote
+// ancient store are not blocked in the meantime.
+func (db *Database) ReadAncients(fn func(ethdb.AncientReaderOp) error) error {
+	return fn(db)
+}
+
+// ModifyAncients is not supported, the ancient store is read-only.
+func (db *Database) ModifyAncients(func(ethdb.AncientWriteOp) error) (int64, error) {
+	return 0, errAncientReadOnly
+}
+
+// TruncateHead is not supported, the ancient store is read-only.
+func (db *Database) TruncateHead(n uint64) (uint64, error) {
+	return 0, errAncientReadOnly
+}
+
+// TruncateTail is not supported, the ancient store is read-only.
+func (db *Database) TruncateTail(n uint64) (uint64, error) {
+	return 0, errAncientReadOnly
+}
+
+// Sync is a noop, the ancient store is read-only.
+func (db *Database) Sync() error {
+	return nil
+}
+
+// AncientDatadir returns an error, as the ancient store is not local.
+func (db *Database) AncientDatadir() (string, error) {
+	return "", errNoAncientDir
+}
+
+// batch is a write-only batch that commits changes to the remote database
+// when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	db   *Database
+	ops  []writeOp
+	data []byte // Buffer holding the keys and values referenced by the ops
+	size int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.ops = append(b.ops, writeOp{Key: b.copy(key), Value: b.copy(value)})
+	b.size += len(key) + len(value)
+	return nil
+}
+
+// Delete inserts the key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.ops = append(b.ops, writeOp{Key: b.copy(key), Delete: true})
+	b.size += len(key)
+	return nil
+}
+
+// copy appends the given bytes to the batch buffer, returning the copy.
+func (b *batch) copy(data []byte) []byte {
+	start := len(b.data)
+	b.data = append(b.data, data...)
+	return b.data[start:len(b.data):len(b.data)]
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to the remote database.
+func (b *batch) Write() error {
+	return b.db.write(b.ops)
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.ops = b.ops[:0]
+	b.data = b.data[:0]
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, op := range b.ops {
+		if op.Delete {
+			if err := w.Delete(op.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.Put(op.Key, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterator walks over the key-value pairs of the remote database, retrieving
+// them in pages.
+type iterator struct {
+	db     *Database
+	prefix []byte
+	start  []byte // Key to start the next page from, without the prefix
+	keys   [][]byte
+	values [][]byte
+	index  int
+	more   bool // Whether there might be further pages
+	err    error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.index+1 < len(it.keys) {
+		it.index++
+		return true
+	}
+	if !it.more {
+		it.index = len(it.keys)
+		return false
+	}
+	var resp iterateResponse
+	req := &iterateRequest{Prefix: it.prefix, Start: it.start, Count: iteratePageCount, MaxBytes: iteratePageBytes}
+	if err := it.db.request(opIterate, req, &resp); err != nil {
+		it.err, it.keys, it.values = err, nil, nil
+		return false
+	}
+	it.keys, it.values, it.more, it.index = resp.Keys, resp.Values, resp.More, 0
+	if len(it.keys) == 0 {
+		it.more = false
+		return false
+	}
+	// Continue after the last key of the page.
+	last := it.keys[len(it.keys)-1]
+	if !bytes.HasPrefix(last, it.prefix) {
+		it.err = errors.New("invalid response")
+		return false
+	}
+	it.start = append(common.CopyBytes(last[len(it.prefix):]), 0)
+	return true
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	if it.index < 0 || it.index >= len(it.keys) {
+		return nil
+	}
+	return it.keys[it.index]
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	if it.index < 0 || it.index >= len(it.keys) {
+		return nil
+	}
+	return it.values[it.index]
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (it *iterator) Release() {
+	it.keys, it.values, it.more = nil, nil, false
+}
+
+// cache is a size-constrained LRU cache of the values retrieved from the server,
+// including the absence of keys.
+type cache struct {
+	lock    sync.Mutex
+	items   lru.BasicLRU[string, []byte] // Cached values, nil if the key is absent
+	size    uint64
+	maxSize uint64
+
+	// floor is the oldest version of the database the retrieved values can be
+	// cached from, as the keys modified later might have been invalidated
+	// before the value is added.
+	floor uint64
+}
+
+func newCache(maxSize uint64) *cache {
+	return &cache{
+		items:   lru.NewBasicLRU[string, []byte](math.MaxInt),
+		maxSize: maxSize,
+	}
+}
+
+// get retrieves a cached value, returning whether the key is present and
+// whether it is cached at all.
+func (c *cache) get(key []byte) ([]byte, bool, bool) {
+	if c.maxSize == 0 {
+		return nil, false, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	value, ok := c.items.Get(string(key))
+	return value, value != nil, ok
+}
+
+// add caches a value retrieved at the given version of the database.
+func (c *cache) add(version uint64, key []byte, value []byte, found bool) {
+	if c.maxSize == 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if version < c.floor {
+		return
+	}
+	if found && value == nil {
+		value = []byte{}
+	}
+	if old, ok := c.items.Peek(string(key)); ok {
+		c.size -= uint64(len(key) + len(old) + cacheItemOverhead)
+	}
+	c.items.Add(string(key), common.CopyBytes(value))
+	c.size += uint64(len(key) + len(value) + cacheItemOverhead)
+
+	for c.size > c.maxSize {
+		key, value, ok := c.items.RemoveOldest()
+		if !ok {
+			break
+		}
+		c.size -= uint64(len(key) + len(value) + cacheItemOverhead)
+	}
+}
+
+// invalidate removes the keys modified up to the given version.
+func (c *cache) invalidate(keys [][]byte, version uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, key := range keys {
+		if value, ok := c.items.Peek(string(key)); ok {
+			c.items.Remove(string(key))
+			c.size -= uint64(len(key) + len(value) + cacheItemOverhead)
+		}
+	}
+	c.floor = max(c.floor, version)
+}
+
+// purge removes all the cached values, after a write of unknown keys up to the
+// given version.
+func (c *cache) purge(version uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.items.Purge()
+	c.size = 0
+	c.floor = max(c.floor, version)
+}
@@ -0,0 +1,248 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netdb
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/dbtest"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+// newTestServer serves the given database on a local port, returning the
+// tracked database and the address of the server.
+func newTestServer(t testing.TB, db ethdb.Database) (ethdb.Database, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := NewServer(db, false)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return server.Database(), listener.Addr().String()
+}
+
+// newTestClient connects to the server with a quick cache invalidation.
+func newTestClient(t testing.TB, addr string, replica bool) *Database {
+	db, err := New(addr, Config{Cache: 16, SyncInterval: 10 * time.Millisecond, Replica: replica})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	return db
+}
+
+func TestNetDB(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			_, addr := newTestServer(t, rawdb.NewMemoryDatabase())
+			return newTestClient(t, addr, false)
+		})
+	})
+	t.Run("ReplicaSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			_, addr := newTestServer(t, rawdb.NewMemoryDatabase())
+			return newTestClient(t, addr, true)
+		})
+	})
+}
+
+// waitValue waits until the client returns the expected value of a key, nil
+// meaning the key is absent.
+func waitValue(t *testing.T, db *Database, key []byte, want []byte) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		have, err := db.Get(key)
+		if (want == nil && err != nil) || (err == nil && bytes.Equal(have, want)) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("value of %q mismatch: have %q (err %v), want %q", key, have, err, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	local, addr := newTestServer(t, rawdb.NewMemoryDatabase())
+	db := newTestClient(t, addr, false)
+	defer db.Close()
+
+	// Cache a value and the absence of a key.
+	local.Put([]byte("a"), []byte("1"))
+	waitValue(t, db, []byte("a"), []byte("1"))
+	if has, _ := db.Has([]byte("b")); has {
+		t.Fatal("absent key reported present")
+	}
+	if _, _, ok := db.cache.get([]byte("a")); !ok {
+		t.Fatal("value not cached")
+	}
+	if _, found, ok := db.cache.get([]byte("b")); !ok || found {
+		t.Fatal("absence not cached")
+	}
+	// The writes of the server process must be picked up.
+	batch := local.NewBatch()
+	batch.Put([]byte("a"), []byte("2"))
+	batch.Put([]byte("b"), []byte("3"))
+	batch.Write()
+	waitValue(t, db, []byte("a"), []byte("2"))
+	waitValue(t, db, []byte("b"), []byte("3"))
+
+	local.Delete([]byte("a"))
+	waitValue(t, db, []byte("a"), nil)
+
+	local.DeleteRange([]byte("a"), []byte("c"))
+	waitValue(t, db, []byte("b"), nil)
+
+	// The writes of another client must be picked up.
+	other := newTestClient(t, addr, false)
+	defer other.Close()
+
+	other.Put([]byte("c"), []byte("4"))
+	waitValue(t, db, []byte("c"), []byte("4"))
+	other.Put([]byte("c"), []byte("5"))
+	waitValue(t, db, []byte("c"), []byte("5"))
+}
+
+func TestGetMany(t *testing.T) {
+	local, addr := newTestServer(t, rawdb.NewMemoryDatabase())
+	db := newTestClient(t, addr, false)
+	defer db.Close()
+
+	local.Put([]byte("a"), []byte("1"))
+	local.Put([]byte("b"), nil)
+	if _, err := db.Get([]byte("a")); err != nil {
+		t.Fatalf("failed to get key: %v", err)
+	}
+	values, err := db.GetMany([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	if err != nil {
+		t.Fatalf("failed to get keys: %v", err)
+	}
+	if !bytes.Equal(values[0], []byte("1")) || values[1] == nil || len(values[1]) != 0 || values[2] != nil {
+		t.Fatalf("unexpected values: %q", values)
+	}
+}
+
+func TestReplica(t *testing.T) {
+	local, addr := newTestServer(t, rawdb.NewMemoryDatabase())
+	db := newTestClient(t, addr, true)
+	defer db.Close()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		local.Put([]byte(key), []byte("remote-"+key))
+	}
+	// The writes of the replica must be read back, without reaching the server.
+	if err := db.Put([]byte("a"), []byte("local-a")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if err := db.Delete([]byte("b")); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := db.DeleteRange([]byte("c"), []byte("d")); err != nil {
+		t.Fatalf("failed to delete range: %v", err)
+	}
+	if err := db.Put([]byte("d"), []byte("local-d")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	waitValue(t, db, []byte("a"), []byte("local-a"))
+	waitValue(t, db, []byte("b"), nil)
+	waitValue(t, db, []byte("c"), nil)
+	waitValue(t, db, []byte("d"), []byte("local-d"))
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if value, _ := local.Get([]byte(key)); !bytes.Equal(value, []byte("remote-"+key)) {
+			t.Fatalf("replica modified the database: %q", value)
+		}
+	}
+	// The remote writes must not override the local ones, but must be picked
+	// up for the other keys.
+	local.Put([]byte("a"), []byte("remote-a2"))
+	local.Put([]byte("e"), []byte("remote-e"))
+	waitValue(t, db, []byte("e"), []byte("remote-e"))
+	waitValue(t, db, []byte("a"), []byte("local-a"))
+
+	// The iteration must merge the local and remote content.
+	var have []string
+	it := db.NewIterator(nil, nil)
+	for it.Next() {
+		have = append(have, fmt.Sprintf("%s=%s", it.Key(), it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	it.Release()
+
+	want := []string{"a=local-a", "d=local-d", "e=remote-e"}
+	if fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Fatalf("iterated content mismatch: have %v, want %v", have, want)
+	}
+}
+
+func TestAncients(t *testing.T) {
+	frdb, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), "", "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer frdb.Close()
+
+	_, err = frdb.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			for _, kind := range []string{rawdb.ChainFreezerHashTable, rawdb.ChainFreezerHeaderTable, rawdb.ChainFreezerBodiesTable, rawdb.ChainFreezerReceiptTable} {
+				if err := op.AppendRaw(kind, i, []byte(fmt.Sprintf("%s-%d", kind, i))); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to write ancients: %v", err)
+	}
+	frdb.TruncateTail(2)
+
+	_, addr := newTestServer(t, frdb)
+	db := newTestClient(t, addr, true)
+	defer db.Close()
+
+	if items, _ := db.Ancients(); items != 10 {
+		t.Fatalf("ancient count mismatch: have %d, want 10", items)
+	}
+	if tail, _ := db.Tail(); tail != 2 {
+		t.Fatalf("ancient tail mismatch: have %d, want 2", tail)
+	}
+	item, err := db.Ancient(rawdb.ChainFreezerBodiesTable, 5)
+	if err != nil || string(item) != "bodies-5" {
+		t.Fatalf("unexpected ancient item: %q, %v", item, err)
+	}
+	if has, _ := db.HasAncient(rawdb.ChainFreezerBodiesTable, 1); has {
+		t.Fatal("truncated ancient item reported present")
+	}
+	items, err := db.AncientRange(rawdb.ChainFreezerHeaderTable, 3, 4, 0)
+	if err != nil || len(items) != 4 || string(items[3]) != "headers-6" {
+		t.Fatalf("unexpected ancient items: %q, %v", items, err)
+	}
+	if _, err := db.TruncateHead(5); err == nil {
+		t.Fatal("ancient store modified over the network")
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netdb
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// keyRange is a range of keys [start, end), a nil end meaning unbounded.
+type keyRange struct {
+	start []byte
+	end   []byte
+}
+
+// contains returns whether the key is within the range.
+func (r keyRange) contains(key []byte) bool {
+	return bytes.Compare(key, r.start) >= 0 && (r.end == nil || bytes.Compare(key, r.end) < 0)
+}
+
+// overlay keeps the writes of a replica in memory, shadowing the content of the
+// remote database, so that the node reads back its own writes without modifying
+// the database of the primary.
+//
+// The overlay is never flushed nor evicted, it grows with the writes of the node
+// and is lost on restart.
+type overlay struct {
+	lock   sync.RWMutex
+	items  map[string][]byte // Written values, nil if the key was deleted
+	ranges []keyRange        // Deleted ranges, shadowing the remote keys not written since
+}
+
+func newOverlay() *overlay {
+	return &overlay{items: make(map[string][]byte)}
+}
+
+// get retrieves a value from the overlay, returning whether the key is present
+// and whether the overlay shadows the remote database for the key at all.
+func (o *overlay) get(key []byte) ([]byte, bool, bool) {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+
+	if value, ok := o.items[string(key)]; ok {
+		return value, value != nil, true
+	}
+	for _, r := range o.ranges {
+		if r.contains(key) {
+			return nil, false, true
+		}
+	}
+	return nil, false, false
+}
+
+// apply stores the given writes in the overlay.
+func (o *overlay) apply(ops []writeOp) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	for _, op := range ops {
+		if op.Delete {
+			o.items[string(op.Key)] = nil
+			continue
+		}
+		value := common.CopyBytes(op.Value)
+		if value == nil {
+			value = []byte{}
+		}
+		o.items[string(op.Key)] = value
+	}
+}
+
+// deleteRange deletes the keys in the range [start, end) from the overlay and
+// shadows the remote keys in it.
+func (o *overlay) deleteRange(start, end []byte) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	r := keyRange{start: common.CopyBytes(start), end: common.CopyBytes(end)}
+	if r.start == nil {
+		r.start = []byte{}
+	}
+	for key := range o.items {
+		if r.contains([]byte(key)) {
+			delete(o.items, key)
+		}
+	}
+	o.ranges = append(o.ranges, r)
+}
+
+// iterator merges a snapshot of the overlay content with the given iterator of
+// the remote database.
+func (o *overlay) iterator(prefix []byte, start []byte, remote ethdb.Iterator) ethdb.Iterator {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+
+	var (
+		first = append(common.CopyBytes(prefix), start...)
+		keys  = make([]string, 0, len(o.items))
+	)
+	for key := range o.items {
+		if strings.HasPrefix(key, string(prefix)) && key >= string(first) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	it := &overlayIterator{
+		remote: remote,
+		keys:   make([][]byte, len(keys)),
+		values: make([][]byte, len(keys)),
+		ranges: slices.Clone(o.ranges),
+		pos:    -1,
+	}
+	for i, key := range keys {
+		it.keys[i], it.values[i] = []byte(key), o.items[key]
+	}
+	it.nextRemote()
+	return it
+}
+
+// overlayIterator walks over the merged key-value pairs of the overlay and the
+// remote database, the overlay taking precedence.
+type overlayIterator struct {
+	remote ethdb.Iterator
+	live   bool // Whether the remote iterator is positioned on an unconsumed item
+
+	keys   [][]byte // Overlay keys in the iterated range, sorted
+	values [][]byte // Overlay values, nil if the key was deleted
+	ranges []keyRange
+	pos    int // Position of the next overlay item to consume minus one
+
+	key   []byte
+	value []byte
+}
+
+// nextRemote advances the remote iterator to the next item not hidden by a
+// deleted range of the overlay.
+func (it *overlayIterator) nextRemote() {
+	for it.live = it.remote.Next(); it.live; it.live = it.remote.Next() {
+		key := it.remote.Key()
+		if !slices.ContainsFunc(it.ranges, func(r keyRange) bool { return r.contains(key) }) {
+			return
+		}
+	}
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *overlayIterator) Next() bool {
+	for {
+		var (
+			local = it.pos+1 < len(it.keys)
+			cmp   int
+		)
+		switch {
+		case !local && !it.live:
+			it.key, it.value = nil, nil
+			return false
+		case !local:
+			cmp = 1
+		case !it.live:
+			cmp = -1
+		default:
+			cmp = bytes.Compare(it.keys[it.pos+1], it.remote.Key())
+		}
+		if cmp > 0 {
+			// The remote item is next and isn't shadowed by the overlay
+			it.key = common.CopyBytes(it.remote.Key())
+			it.value = common.CopyBytes(it.remote.Value())
+			it.nextRemote()
+			return true
+		}
+		if cmp == 0 {
+			it.nextRemote() // Shadowed by the overlay item
+		}
+		it.pos++
+		if it.values[it.pos] == nil {
+			continue // Deleted in the overlay
+		}
+		it.key, it.value = it.keys[it.pos], it.values[it.pos]
+		return true
+	}
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *overlayIterator) Error() error {
+	return it.remote.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *overlayIterator) Key() []byte {
+	return it.key
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *overlayIterator) Value() []byte {
+	return it.value
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (it *overlayIterator) Release() {
+	it.remote.Release()
+	it.keys, it.values, it.live = nil, nil, false
+}
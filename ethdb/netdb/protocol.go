@@ -0,0 +1,161 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// The protocol is a simple request-response exchange of frames over a stream
+// connection. Each frame is prefixed with its length as a 4 byte big-endian
+// integer. A request frame consists of the operation code followed by the RLP
+// encoded request, a response frame of the status code followed by the RLP
+// encoded response, or the error message if the status is not statusOK.
+
+const (
+	opGet         = 0x01 // Retrieves or checks the presence of a set of keys
+	opWrite       = 0x02 // Applies a set of writes atomically
+	opDeleteRange = 0x03 // Deletes a range of keys
+	opIterate     = 0x04 // Retrieves a page of key-value pairs in order
+	opChanges     = 0x05 // Retrieves the keys modified since a version
+	opStat        = 0x06 // Retrieves the statistics of the database
+	opCompact     = 0x07 // Compacts a range of keys
+	opAncient     = 0x08 // Retrieves a sequence of ancient items
+	opAncients    = 0x09 // Retrieves the number of ancient items and the tail
+	opAncientSize = 0x0a // Retrieves the size of an ancient table
+)
+
+const (
+	statusOK          = 0x00 // Request served, the response follows
+	statusError       = 0x01 // Request failed, the error message follows
+	statusTooManyKeys = 0x02 // Range only partially deleted, see ethdb.ErrTooManyKeys
+)
+
+// maxFrameSize is the maximum accepted size of a frame, protecting against
+// allocating arbitrary amounts of memory on a corrupted stream.
+const maxFrameSize = 256 * 1024 * 1024
+
+type getRequest struct {
+	Keys     [][]byte
+	NoValues bool // Only check the presence of the keys
+}
+
+type getResponse struct {
+	Version uint64 // Version of the database before the keys were retrieved
+	Found   []bool
+	Values  [][]byte
+}
+
+type writeOp struct {
+	Key    []byte
+	Value  []byte
+	Delete bool
+}
+
+type writeRequest struct {
+	Ops []writeOp
+}
+
+type deleteRangeRequest struct {
+	Start []byte
+	End   []byte
+}
+
+type iterateRequest struct {
+	Prefix   []byte
+	Start    []byte
+	Count    uint64 // Maximum number of items to return
+	MaxBytes uint64 // Maximum size of the items to return, at least one is returned
+}
+
+type iterateResponse struct {
+	Keys   [][]byte
+	Values [][]byte
+	More   bool // Whether the iteration was cut short by the limits
+}
+
+type changesRequest struct {
+	Since uint64
+}
+
+type changesResponse struct {
+	Version uint64   // Current version of the database
+	Reset   bool     // Changes since the requested version are unknown
+	Keys    [][]byte // Keys modified since the requested version
+}
+
+type compactRequest struct {
+	Start    []byte
+	Limit    []byte
+	HasLimit bool // Whether the limit is set, nil means the end of the keyspace
+}
+
+type ancientRequest struct {
+	Kind     string
+	Start    uint64
+	Count    uint64
+	MaxBytes uint64
+}
+
+type ancientsResponse struct {
+	Items uint64
+	Tail  uint64
+}
+
+// writeFrame writes the code and the payload as a frame.
+func writeFrame(w io.Writer, code byte, payload []byte) error {
+	frame := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(1+len(payload)))
+	frame[4] = code
+	copy(frame[5:], payload)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readFrame reads a frame, returning its code and payload.
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size == 0 || size > maxFrameSize {
+		return 0, nil, fmt.Errorf("invalid frame size %d", size)
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return 0, nil, err
+	}
+	return frame[0], frame[1:], nil
+}
+
+// responseError converts a failed response to an error.
+func responseError(status byte, payload []byte) error {
+	switch status {
+	case statusTooManyKeys:
+		return ethdb.ErrTooManyKeys
+	case statusError:
+		return errors.New(string(payload))
+	default:
+		return fmt.Errorf("unknown response status %d", status)
+	}
+}
@@ -0,0 +1,489 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netdb
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// maxJournalSize is the number of modified keys retained for the cache
+	// invalidation of the clients. Clients lagging further behind drop their
+	// entire cache.
+	maxJournalSize = 1 << 17
+
+	// maxIterateCount and maxIterateBytes limit the size of an iteration page.
+	maxIterateCount = 16384
+	maxIterateBytes = 4 * 1024 * 1024
+)
+
+var errServerReadOnly = errors.New("database server is read-only")
+
+// journalEntry is a key modified in a version of the database.
+type journalEntry struct {
+	version uint64
+	key     string
+}
+
+// Server serves a database over the netdb protocol.
+//
+// The server keeps track of the modified keys to allow the clients to maintain
+// their read caches. All the writes to the database must thus go through the
+// database returned by Database, including the ones of the local process.
+type Server struct {
+	db       ethdb.Database
+	readonly bool
+
+	lock    sync.Mutex
+	version uint64         // Number of writes applied to the database
+	floor   uint64         // Oldest version the journal holds the changes since
+	journal []journalEntry // Keys modified after the floor version, in order
+
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer creates a server of the given database. A read-only server rejects
+// the writes of the clients, but still tracks the writes of the local process.
+func NewServer(db ethdb.Database, readonly bool) *Server {
+	return &Server{
+		db:        db,
+		readonly:  readonly,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Database returns the served database, tracking the writes for the cache
+// invalidation of the clients. Closing it stops the server as well.
+func (s *Server) Database() ethdb.Database {
+	return &trackedDB{Database: s.db, server: s}
+}
+
+// Serve accepts the connections on the listener until the server is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		listener.Close()
+		return net.ErrClosed
+	}
+	s.listeners[listener] = struct{}{}
+	s.lock.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.lock.Lock()
+			closed := s.closed
+			delete(s.listeners, listener)
+			s.lock.Unlock()
+
+			if closed {
+				return nil
+			}
+			return err
+		}
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.lock.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the server, closing all the listeners and connections. The served
+// database is left open.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn serves the requests of a connection until it's closed.
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+		conn.Close()
+	}()
+	var (
+		r = bufio.NewReader(conn)
+		w = bufio.NewWriter(conn)
+	)
+	for {
+		op, payload, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		resp, err := s.handle(op, payload)
+		if err == nil && resp != nil {
+			payload, err = rlp.EncodeToBytes(resp)
+		} else {
+			payload = nil
+		}
+		switch {
+		case errors.Is(err, ethdb.ErrTooManyKeys):
+			err = writeFrame(w, statusTooManyKeys, nil)
+		case err != nil:
+			err = writeFrame(w, statusError, []byte(err.Error()))
+		default:
+			err = writeFrame(w, statusOK, payload)
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			log.Debug("Failed to write netdb response", "remote", conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+}
+
+// handle serves a request, returning the response to be encoded.
+func (s *Server) handle(op byte, payload []byte) (interface{}, error) {
+	switch op {
+	case opGet:
+		var req getRequest
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		resp := &getResponse{
+			Version: s.currentVersion(),
+			Found:   make([]bool, len(req.Keys)),
+			Values:  make([][]byte, len(req.Keys)),
+		}
+		for i, key := range req.Keys {
+			if req.NoValues {
+				has, err := s.db.Has(key)
+				if err != nil {
+					return nil, err
+				}
+				resp.Found[i] = has
+				continue
+			}
+			// The error of a missing key is not distinguishable from the
+			// failures, so the presence is checked separately on errors.
+			value, err := s.db.Get(key)
+			if err != nil {
+				has, herr := s.db.Has(key)
+				if herr != nil {
+					return nil, herr
+				}
+				if has {
+					return nil, err
+				}
+				continue
+			}
+			resp.Found[i], resp.Values[i] = true, value
+		}
+		return resp, nil
+
+	case opWrite:
+		var req writeRequest
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		if s.readonly {
+			return nil, errServerReadOnly
+		}
+		batch := s.Database().NewBatch()
+		for _, op := range req.Ops {
+			var err error
+			if op.Delete {
+				err = batch.Delete(op.Key)
+			} else {
+				err = batch.Put(op.Key, op.Value)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := batch.Write(); err != nil {
+			return nil, err
+		}
+		return s.currentVersion(), nil
+
+	case opDeleteRange:
+		var req deleteRangeRequest
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		if s.readonly {
+			return nil, errServerReadOnly
+		}
+		if err := s.Database().DeleteRange(req.Start, req.End); err != nil {
+			return nil, err
+		}
+		return s.currentVersion(), nil
+
+	case opIterate:
+		var req iterateRequest
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		if req.Count == 0 || req.Count > maxIterateCount {
+			req.Count = maxIterateCount
+		}
+		if req.MaxBytes == 0 || req.MaxBytes > maxIterateBytes {
+			req.MaxBytes = maxIterateBytes
+		}
+		it := s.db.NewIterator(req.Prefix, req.Start)
+		defer it.Release()
+
+		var (
+			resp iterateResponse
+			size uint64
+		)
+		for it.Next() {
+			if uint64(len(resp.Keys)) >= req.Count || (len(resp.Keys) > 0 && size >= req.MaxBytes) {
+				resp.More = true
+				break
+			}
+			resp.Keys = append(resp.Keys, it.Key())
+			resp.Values = append(resp.Values, it.Value())
+			size += uint64(len(it.Key()) + len(it.Value()))
+		}
+		return &resp, it.Error()
+
+	case opChanges:
+		var req changesRequest
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return s.changes(req.Since), nil
+
+	case opStat:
+		return s.db.Stat()
+
+	case opCompact:
+		var req compactRequest
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		if s.readonly {
+			return nil, errServerReadOnly
+		}
+		limit := req.Limit
+		if !req.HasLimit {
+			limit = nil
+		}
+		return nil, s.db.Compact(req.Start, limit)
+
+	case opAncient:
+		var req ancientRequest
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		if req.Count == 1 && req.MaxBytes == 0 {
+			item, err := s.db.Ancient(req.Kind, req.Start)
+			if err != nil {
+				return nil, err
+			}
+			return [][]byte{item}, nil
+		}
+		return s.db.AncientRange(req.Kind, req.Start, req.Count, req.MaxBytes)
+
+	case opAncients:
+		var resp ancientsResponse
+		err := s.db.ReadAncients(func(op ethdb.AncientReaderOp) (err error) {
+			if resp.Items, err = op.Ancients(); err != nil {
+				return err
+			}
+			resp.Tail, err = op.Tail()
+			return err
+		})
+		return &resp, err
+
+	case opAncientSize:
+		var kind string
+		if err := rlp.DecodeBytes(payload, &kind); err != nil {
+			return nil, err
+		}
+		return s.db.AncientSize(kind)
+
+	default:
+		return nil, errors.New("unknown operation")
+	}
+}
+
+// currentVersion returns the number of writes applied to the database.
+func (s *Server) currentVersion() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.version
+}
+
+// record registers a write of the given keys, after it's been applied.
+func (s *Server) record(keys []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.version++
+	for _, key := range keys {
+		s.journal = append(s.journal, journalEntry{version: s.version, key: key})
+	}
+	if len(s.journal) > maxJournalSize {
+		half := len(s.journal) / 2
+		s.floor = s.journal[half-1].version
+		s.journal = append(s.journal[:0], s.journal[half:]...)
+	}
+}
+
+// recordReset registers a write of unknown keys, after it's been applied.
+func (s *Server) recordReset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.version++
+	s.floor = s.version
+	s.journal = s.journal[:0]
+}
+
+// changes returns the keys modified since the given version.
+func (s *Server) changes(since uint64) *changesResponse {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	resp := &changesResponse{Version: s.version}
+	if since < s.floor || since > s.version {
+		resp.Reset = true
+		return resp
+	}
+	start := sort.Search(len(s.journal), func(i int) bool {
+		return s.journal[i].version > since
+	})
+	for _, entry := range s.journal[start:] {
+		resp.Keys = append(resp.Keys, []byte(entry.key))
+	}
+	return resp
+}
+
+// trackedDB is the served database, reporting the writes to the server.
+type trackedDB struct {
+	ethdb.Database
+	server *Server
+}
+
+// Put inserts the given value into the key-value data store.
+func (db *trackedDB) Put(key []byte, value []byte) error {
+	if err := db.Database.Put(key, value); err != nil {
+		return err
+	}
+	db.server.record([]string{string(key)})
+	return nil
+}
+
+// Delete removes the key from the key-value data store.
+func (db *trackedDB) Delete(key []byte) error {
+	if err := db.Database.Delete(key); err != nil {
+		return err
+	}
+	db.server.record([]string{string(key)})
+	return nil
+}
+
+// DeleteRange deletes all of the keys (and values) in the range [start,end).
+func (db *trackedDB) DeleteRange(start, end []byte) error {
+	// The range might be partially deleted even on failure.
+	defer db.server.recordReset()
+	return db.Database.DeleteRange(start, end)
+}
+
+// NewBatch creates a write-only database that buffers changes to its host db
+// until a final write is called.
+func (db *trackedDB) NewBatch() ethdb.Batch {
+	return &trackedBatch{Batch: db.Database.NewBatch(), server: db.server}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (db *trackedDB) NewBatchWithSize(size int) ethdb.Batch {
+	return &trackedBatch{Batch: db.Database.NewBatchWithSize(size), server: db.server}
+}
+
+// Close stops the server and closes the database.
+func (db *trackedDB) Close() error {
+	db.server.Close()
+	return db.Database.Close()
+}
+
+// trackedBatch is a batch of the served database, reporting the written keys
+// to the server.
+type trackedBatch struct {
+	ethdb.Batch
+	server *Server
+	keys   []string
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *trackedBatch) Put(key []byte, value []byte) error {
+	b.keys = append(b.keys, string(key))
+	return b.Batch.Put(key, value)
+}
+
+// Delete inserts the key removal into the batch for later committing.
+func (b *trackedBatch) Delete(key []byte) error {
+	b.keys = append(b.keys, string(key))
+	return b.Batch.Delete(key)
+}
+
+// Write flushes any accumulated data to disk.
+func (b *trackedBatch) Write() error {
+	if err := b.Batch.Write(); err != nil {
+		return err
+	}
+	b.server.record(b.keys)
+	return nil
+}
+
+// Reset resets the batch for reuse.
+func (b *trackedBatch) Reset() {
+	b.Batch.Reset()
+	b.keys = b.keys[:0]
+}
@@ -172,55 +172,58 @@ func (b *Builder) Finalize() (common.Hash, error) {
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("error writing accumulator: %w", err)
 	}
-	// Get beginning of index entry to calculate block relative offset.
-	base := int64(b.written)
+	// Finally, write the block index entry.
+	if _, err := b.w.Write(TypeBlockIndex, blockIndex(*b.startNum, b.indexes, int64(b.written))); err != nil {
+		return common.Hash{}, fmt.Errorf("unable to write block index: %w", err)
+	}
+
+	return root, nil
+}
 
-	// Construct block index. Detailed format described in Builder
-	// documentation, but it is essentially encoded as:
-	// "start | index | index | ... | count"
+// blockIndex constructs the block index entry of an archive whose index entry
+// starts at base. Detailed format described in Builder documentation, but it is
+// essentially encoded as: "start | index | index | ... | count"
+func blockIndex(start uint64, indexes []uint64, base int64) []byte {
 	var (
-		count = len(b.indexes)
+		count = len(indexes)
 		index = make([]byte, 16+count*8)
 	)
-	binary.LittleEndian.PutUint64(index, *b.startNum)
+	binary.LittleEndian.PutUint64(index, start)
 	// Each offset is relative from the position it is encoded in the
 	// index. This means that even if the same block was to be included in
 	// the index twice (this would be invalid anyways), the relative offset
 	// would be different. The idea with this is that after reading a
 	// relative offset, the corresponding block can be quickly read by
 	// performing a seek relative to the current position.
-	for i, offset := range b.indexes {
+	for i, offset := range indexes {
 		relative := int64(offset) - base
 		binary.LittleEndian.PutUint64(index[8+i*8:], uint64(relative))
 	}
 	binary.LittleEndian.PutUint64(index[8+count*8:], uint64(count))
-
-	// Finally, write the block index entry.
-	if _, err := b.w.Write(TypeBlockIndex, index); err != nil {
-		return common.Hash{}, fmt.Errorf("unable to write block index: %w", err)
-	}
-
-	return root, nil
+	return index
 }
 
 // snappyWrite is a small helper to take care snappy encoding and writing an e2store entry.
 func (b *Builder) snappyWrite(typ uint16, in []byte) error {
-	var (
-		buf = b.buf
-		s   = b.snappy
-	)
+	n, err := snappyWrite(b.w, b.buf, b.snappy, typ, in)
+	b.written += n
+	return err
+}
+
+// snappyWrite snappy encodes the given value into buf and writes it as an
+// e2store entry, returning the number of bytes written.
+func snappyWrite(w *e2store.Writer, buf *bytes.Buffer, s *snappy.Writer, typ uint16, in []byte) (int, error) {
 	buf.Reset()
 	s.Reset(buf)
-	if _, err := b.snappy.Write(in); err != nil {
-		return fmt.Errorf("error snappy encoding: %w", err)
+	if _, err := s.Write(in); err != nil {
+		return 0, fmt.Errorf("error snappy encoding: %w", err)
 	}
 	if err := s.Flush(); err != nil {
-		return fmt.Errorf("error flushing snappy encoding: %w", err)
+		return 0, fmt.Errorf("error flushing snappy encoding: %w", err)
 	}
-	n, err := b.w.Write(typ, b.buf.Bytes())
-	b.written += n
+	n, err := w.Write(typ, buf.Bytes())
 	if err != nil {
-		return fmt.Errorf("error writing e2store entry: %w", err)
+		return n, fmt.Errorf("error writing e2store entry: %w", err)
 	}
-	return nil
+	return n, nil
 }
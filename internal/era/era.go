@@ -42,6 +42,8 @@ var (
 	TypeCompressedReceipts uint16 = 0x05
 	TypeTotalDifficulty    uint16 = 0x06
 	TypeAccumulator        uint16 = 0x07
+	TypeL2Accumulator      uint16 = 0x08
+	TypeL1Origin           uint16 = 0x09
 	TypeBlockIndex         uint16 = 0x3266
 
 	MaxEra1Size = 8192
@@ -53,9 +55,27 @@ func Filename(network string, epoch int, root common.Hash) string {
 	return fmt.Sprintf("%s-%05d-%s.era1", network, epoch, root.Hex()[2:10])
 }
 
+// L2Filename returns a recognizable L2 era file name for the specified epoch
+// and network.
+func L2Filename(network string, epoch int, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%s.eral2", network, epoch, root.Hex()[2:10])
+}
+
 // ReadDir reads all the era1 files in a directory for a given network.
 // Format: <network>-<epoch>-<hexroot>.era1
 func ReadDir(dir, network string) ([]string, error) {
+	return readDir(dir, network, ".era1")
+}
+
+// ReadL2Dir reads all the L2 era files in a directory for a given network.
+// Format: <network>-<epoch>-<hexroot>.eral2
+func ReadL2Dir(dir, network string) ([]string, error) {
+	return readDir(dir, network, ".eral2")
+}
+
+// readDir reads all the era files with the given extension in a directory for
+// a given network, ensuring there are no gaps between the epochs.
+func readDir(dir, network, ext string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
@@ -65,16 +85,16 @@ func ReadDir(dir, network string) ([]string, error) {
 		eras []string
 	)
 	for _, entry := range entries {
-		if path.Ext(entry.Name()) != ".era1" {
+		if path.Ext(entry.Name()) != ext {
 			continue
 		}
 		parts := strings.Split(entry.Name(), "-")
 		if len(parts) != 3 || parts[0] != network {
-			// Invalid era1 filename, skip.
+			// Invalid era filename, skip.
 			continue
 		}
 		if epoch, err := strconv.ParseUint(parts[1], 10, 64); err != nil {
-			return nil, fmt.Errorf("malformed era1 filename: %s", entry.Name())
+			return nil, fmt.Errorf("malformed era filename: %s", entry.Name())
 		} else if epoch != next {
 			return nil, fmt.Errorf("missing epoch %d", next)
 		}
@@ -90,7 +110,7 @@ type ReadAtSeekCloser interface {
 	io.Closer
 }
 
-// Era reads and Era1 file.
+// Era reads an Era1 or L2 era file.
 type Era struct {
 	f   ReadAtSeekCloser // backing era1 file
 	s   *e2store.Reader  // e2store reader over f
@@ -205,18 +225,52 @@ func (e *Era) GetReceiptsByNumber(num uint64) (types.Receipts, error) {
 	return receipts, nil
 }
 
-// Accumulator reads the accumulator entry in the Era1 file.
+// GetL1OriginByNumber returns the L1 origin of the given block number, or nil
+// if the block was archived without one.
+func (e *Era) GetL1OriginByNumber(num uint64) (*L1Origin, error) {
+	if !e.m.l2 {
+		return nil, errors.New("no l1 origins in era1 file")
+	}
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	// Skip over header, body and receipts.
+	off, err = e.s.SkipN(off, 3)
+	if err != nil {
+		return nil, err
+	}
+	return readL1Origin(e.s, off)
+}
+
+// Accumulator reads the accumulator entry in the Era1 or L2 era file.
 func (e *Era) Accumulator() (common.Hash, error) {
-	entry, err := e.s.Find(TypeAccumulator)
+	typ := TypeAccumulator
+	if e.m.l2 {
+		typ = TypeL2Accumulator
+	}
+	entry, err := e.s.Find(typ)
 	if err != nil {
 		return common.Hash{}, err
 	}
 	return common.BytesToHash(entry.Value), nil
 }
 
+// IsL2 reports whether the file is an L2 era file, which carries no total
+// difficulty but optional L1 origins.
+func (e *Era) IsL2() bool {
+	return e.m.l2
+}
+
 // InitialTD returns initial total difficulty before the difficulty of the
 // first block of the Era1 is applied.
 func (e *Era) InitialTD() (*big.Int, error) {
+	if e.m.l2 {
+		return nil, errors.New("no total difficulty in l2 era file")
+	}
 	var (
 		r      io.Reader
 		header types.Header
@@ -296,11 +350,29 @@ func newSnappyReader(e *e2store.Reader, expectedType uint16, off int64) (io.Read
 	return snappy.NewReader(r), int64(n), err
 }
 
+// readL1Origin reads the L1 origin entry at off, returning nil if the entry
+// there is of another type.
+func readL1Origin(e *e2store.Reader, off int64) (*L1Origin, error) {
+	typ, _, err := e.ReadMetadataAt(off)
+	if err != nil {
+		return nil, err
+	}
+	if typ != TypeL1Origin {
+		return nil, nil
+	}
+	var entry e2store.Entry
+	if _, err := e.ReadAt(&entry, off); err != nil {
+		return nil, err
+	}
+	return decodeL1Origin(entry.Value)
+}
+
 // metadata wraps the metadata in the block index.
 type metadata struct {
 	start  uint64
 	count  uint64
 	length int64
+	l2     bool // whether the file is an L2 era file
 }
 
 // readMetadata reads the metadata stored in an Era1 file's block index.
@@ -321,5 +393,18 @@ func readMetadata(f ReadAtSeekCloser) (m metadata, err error) {
 		return
 	}
 	m.start = binary.LittleEndian.Uint64(b[8:])
+
+	// The accumulator entry directly precedes the block index, its type tells
+	// the two era flavors apart.
+	if _, err = f.ReadAt(b[:2], m.length-24-int64(m.count*8)-8-common.HashLength); err != nil {
+		return
+	}
+	switch typ := binary.LittleEndian.Uint16(b); typ {
+	case TypeAccumulator:
+	case TypeL2Accumulator:
+		m.l2 = true
+	default:
+		err = fmt.Errorf("unexpected entry before block index: %d", typ)
+	}
 	return
 }
@@ -22,6 +22,7 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -82,6 +83,9 @@ func TestEra1Builder(t *testing.T) {
 		t.Fatalf("failed to open era: %v", err)
 	}
 	defer e.Close()
+	if e.IsL2() {
+		t.Fatal("era1 detected as l2 era")
+	}
 	it, err := NewRawIterator(e)
 	if err != nil {
 		t.Fatalf("failed to make iterator: %s", err)
@@ -154,6 +158,113 @@ func TestEra1Builder(t *testing.T) {
 	}
 }
 
+func TestL2Builder(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.CreateTemp(t.TempDir(), "eral2-test")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer f.Close()
+
+	var (
+		builder = NewL2Builder(f)
+		chain   = testchain{}
+		hashes  []common.Hash
+		origins []*L1Origin
+	)
+	for i := 0; i < 128; i++ {
+		chain.headers = append(chain.headers, mustEncode(&types.Header{Number: big.NewInt(int64(i))}))
+		chain.bodies = append(chain.bodies, mustEncode(&types.Body{Transactions: []*types.Transaction{types.NewTransaction(0, common.Address{byte(i)}, nil, 0, nil, nil)}}))
+		chain.receipts = append(chain.receipts, mustEncode(&types.Receipts{{CumulativeGasUsed: uint64(i)}}))
+		hashes = append(hashes, common.Hash{byte(i)})
+
+		// Leave every third block without an origin.
+		var origin *L1Origin
+		if i%3 != 0 {
+			origin = &L1Origin{Number: uint64(1000 + i/3), Hash: common.Hash{0xff, byte(i / 3)}}
+		}
+		origins = append(origins, origin)
+	}
+	for i := 0; i < len(chain.headers); i++ {
+		if err := builder.AddRLP(chain.headers[i], chain.bodies[i], chain.receipts[i], uint64(i), hashes[i], origins[i]); err != nil {
+			t.Fatalf("error adding entry: %v", err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("error finalizing era: %v", err)
+	}
+	if want, _ := ComputeL2Accumulator(hashes, origins); root != want {
+		t.Fatalf("accumulator mismatch: have %x, want %x", root, want)
+	}
+	if plain, _ := ComputeL2Accumulator(hashes, nil); root == plain {
+		t.Fatal("accumulator doesn't commit to l1 origins")
+	}
+
+	// Verify the archive contents.
+	e, err := Open(f.Name())
+	if err != nil {
+		t.Fatalf("failed to open era: %v", err)
+	}
+	defer e.Close()
+	if !e.IsL2() {
+		t.Fatal("l2 era not detected")
+	}
+	if acc, err := e.Accumulator(); err != nil || acc != root {
+		t.Fatalf("accumulator entry mismatch: have %x, want %x, err %v", acc, root, err)
+	}
+	if _, err := e.InitialTD(); err == nil {
+		t.Fatal("expected error reading total difficulty")
+	}
+	it, err := NewIterator(e)
+	if err != nil {
+		t.Fatalf("failed to make iterator: %s", err)
+	}
+	for i := uint64(0); i < uint64(len(chain.headers)); i++ {
+		if !it.Next() {
+			t.Fatalf("expected more entries")
+		}
+		if it.Error() != nil {
+			t.Fatalf("unexpected error %v", it.Error())
+		}
+		rawReceipts, err := io.ReadAll(it.inner.Receipts)
+		if err != nil {
+			t.Fatalf("error reading receipts from iterator: %v", err)
+		}
+		if !bytes.Equal(rawReceipts, chain.receipts[i]) {
+			t.Fatalf("mismatched receipts: want %s, got %s", chain.receipts[i], rawReceipts)
+		}
+		if _, err := it.TotalDifficulty(); err == nil {
+			t.Fatalf("expected error reading total difficulty %d", i)
+		}
+		origin, err := it.L1Origin()
+		if err != nil {
+			t.Fatalf("error reading l1 origin %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(origin, origins[i]) {
+			t.Fatalf("mismatched l1 origin %d: want %v, got %v", i, origins[i], origin)
+		}
+		origin, err = e.GetL1OriginByNumber(i)
+		if err != nil {
+			t.Fatalf("error reading l1 origin %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(origin, origins[i]) {
+			t.Fatalf("mismatched l1 origin %d: want %v, got %v", i, origins[i], origin)
+		}
+		header, err := e.GetHeaderByNumber(i)
+		if err != nil {
+			t.Fatalf("error reading header: %v", err)
+		}
+		if header.Number.Uint64() != i {
+			t.Fatalf("mismatched header number: want %d, got %d", i, header.Number)
+		}
+	}
+	if it.Next() {
+		t.Fatal("expected no more entries")
+	}
+}
+
 func TestEraFilename(t *testing.T) {
 	t.Parallel()
 
@@ -170,6 +281,9 @@ func TestEraFilename(t *testing.T) {
 			t.Errorf("test %d: invalid filename: want %s, got %s", i, tt.expected, got)
 		}
 	}
+	if got, want := L2Filename("mainnet", 1, common.Hash{1}), "mainnet-00001-01000000.eral2"; got != want {
+		t.Errorf("invalid l2 filename: want %s, got %s", want, got)
+	}
 }
 
 func mustEncode(obj any) []byte {
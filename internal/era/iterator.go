@@ -103,6 +103,9 @@ func (it *Iterator) BlockAndReceipts() (*types.Block, types.Receipts, error) {
 // TotalDifficulty returns the total difficulty for the iterator's current
 // position.
 func (it *Iterator) TotalDifficulty() (*big.Int, error) {
+	if it.inner.TotalDifficulty == nil {
+		return nil, errors.New("total difficulty must be non-nil")
+	}
 	td, err := io.ReadAll(it.inner.TotalDifficulty)
 	if err != nil {
 		return nil, err
@@ -110,6 +113,19 @@ func (it *Iterator) TotalDifficulty() (*big.Int, error) {
 	return new(big.Int).SetBytes(reverseOrder(td)), nil
 }
 
+// L1Origin returns the L1 origin for the iterator's current position, or nil
+// if the block was archived without one.
+func (it *Iterator) L1Origin() (*L1Origin, error) {
+	if it.inner.L1Origin == nil {
+		return nil, nil
+	}
+	origin, err := io.ReadAll(it.inner.L1Origin)
+	if err != nil {
+		return nil, err
+	}
+	return decodeL1Origin(origin)
+}
+
 // RawIterator reads an RLP-encode Era1 entries.
 type RawIterator struct {
 	e    *Era   // backing Era1
@@ -119,7 +135,8 @@ type RawIterator struct {
 	Header          io.Reader
 	Body            io.Reader
 	Receipts        io.Reader
-	TotalDifficulty io.Reader
+	TotalDifficulty io.Reader // nil for L2 era files
+	L1Origin        io.Reader // nil for Era1 files and blocks without origin
 }
 
 // NewRawIterator returns a new RawIterator instance. Next must be immediately
@@ -133,8 +150,9 @@ func NewRawIterator(e *Era) (*RawIterator, error) {
 
 // Next moves the iterator to the next block entry. It returns false when all
 // items have been read or an error has halted its progress. Header, Body,
-// Receipts, TotalDifficulty will be set to nil in the case returning false or
-// finding an error and should therefore no longer be read from.
+// Receipts, TotalDifficulty and L1Origin will be set to nil in the case
+// returning false or finding an error and should therefore no longer be read
+// from.
 func (it *RawIterator) Next() bool {
 	// Clear old errors.
 	it.err = nil
@@ -166,7 +184,21 @@ func (it *RawIterator) Next() bool {
 		return true
 	}
 	off += n
-	if it.TotalDifficulty, _, it.err = it.e.s.ReaderAt(TypeTotalDifficulty, off); it.err != nil {
+	if it.e.m.l2 {
+		// The L1 origin entry is optional, only pick it up if present.
+		var typ uint16
+		if typ, _, it.err = it.e.s.ReadMetadataAt(off); it.err != nil {
+			it.clear()
+			return true
+		}
+		it.L1Origin = nil
+		if typ == TypeL1Origin {
+			if it.L1Origin, _, it.err = it.e.s.ReaderAt(TypeL1Origin, off); it.err != nil {
+				it.clear()
+				return true
+			}
+		}
+	} else if it.TotalDifficulty, _, it.err = it.e.s.ReaderAt(TypeTotalDifficulty, off); it.err != nil {
 		it.clear()
 		return true
 	}
@@ -194,4 +226,5 @@ func (it *RawIterator) clear() {
 	it.Body = nil
 	it.Receipts = nil
 	it.TotalDifficulty = nil
+	it.L1Origin = nil
 }
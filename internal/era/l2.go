@@ -0,0 +1,241 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era/e2store"
+	"github.com/ethereum/go-ethereum/rlp"
+	ssz "github.com/ferranbt/fastssz"
+	"github.com/golang/snappy"
+)
+
+// L1Origin identifies the L1 block an L2 block was derived from.
+type L1Origin struct {
+	Number uint64      `json:"l1Number"`
+	Hash   common.Hash `json:"l1Hash"`
+}
+
+// l1OriginSize is the size of an encoded L1 origin entry.
+const l1OriginSize = 8 + common.HashLength
+
+// encode returns the L1 origin in its e2store entry format.
+func (o *L1Origin) encode() []byte {
+	b := make([]byte, l1OriginSize)
+	binary.LittleEndian.PutUint64(b, o.Number)
+	copy(b[8:], o.Hash[:])
+	return b
+}
+
+// decodeL1Origin decodes an L1 origin from its e2store entry format.
+func decodeL1Origin(b []byte) (*L1Origin, error) {
+	if len(b) != l1OriginSize {
+		return nil, fmt.Errorf("invalid l1 origin size: have %d, want %d", len(b), l1OriginSize)
+	}
+	return &L1Origin{
+		Number: binary.LittleEndian.Uint64(b),
+		Hash:   common.BytesToHash(b[8:]),
+	}, nil
+}
+
+// L2Builder is used to create L2 era archives of block data.
+//
+// L2 era files follow the structure of Era1 files, but don't carry the total
+// difficulty, which is meaningless for chains without proof-of-work history.
+// Instead, every block may be annotated with the L1 block it was derived from.
+//
+//	eral2 := Version | block-tuple* | other-entries* | L2Accumulator | BlockIndex
+//	block-tuple :=  CompressedHeader | CompressedBody | CompressedReceipts | L1Origin?
+//
+// The entries shared with Era1 are encoded identically, the new ones are:
+//
+//	L2Accumulator = { type: [0x08, 0x00], data: accumulator-root }
+//	L1Origin      = { type: [0x09, 0x00], data: uint64(l1-number) | l1-hash }
+//
+// The accumulator commits to the block hashes and their L1 origins, with the
+// fields of a missing origin left zero:
+//
+//	block-record := { block-hash: Bytes32, l1-number: Uint64, l1-hash: Bytes32 }
+//	accumulator  := hash_tree_root([]block-record, 8192)
+//
+// Files are named <network>-<epoch>-<hexroot>.eral2, see L2Filename.
+type L2Builder struct {
+	w        *e2store.Writer
+	startNum *uint64
+	indexes  []uint64
+	hashes   []common.Hash
+	origins  []*L1Origin
+	written  int
+
+	buf    *bytes.Buffer
+	snappy *snappy.Writer
+}
+
+// NewL2Builder returns a new L2Builder instance.
+func NewL2Builder(w io.Writer) *L2Builder {
+	buf := bytes.NewBuffer(nil)
+	return &L2Builder{
+		w:      e2store.NewWriter(w),
+		buf:    buf,
+		snappy: snappy.NewBufferedWriter(buf),
+	}
+}
+
+// Add writes the compressed block and receipts entries to the underlying
+// e2store file, followed by the L1 origin entry if origin is non-nil.
+func (b *L2Builder) Add(block *types.Block, receipts types.Receipts, origin *L1Origin) error {
+	eh, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return err
+	}
+	eb, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	er, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return err
+	}
+	return b.AddRLP(eh, eb, er, block.NumberU64(), block.Hash(), origin)
+}
+
+// AddRLP writes the compressed block and receipts entries to the underlying
+// e2store file, followed by the L1 origin entry if origin is non-nil.
+func (b *L2Builder) AddRLP(header, body, receipts []byte, number uint64, hash common.Hash, origin *L1Origin) error {
+	// Write version entry before first block.
+	if b.startNum == nil {
+		n, err := b.w.Write(TypeVersion, nil)
+		if err != nil {
+			return err
+		}
+		startNum := number
+		b.startNum = &startNum
+		b.written += n
+	}
+	if len(b.indexes) >= MaxEra1Size {
+		return fmt.Errorf("exceeds maximum batch size of %d", MaxEra1Size)
+	}
+	if want := *b.startNum + uint64(len(b.indexes)); number != want {
+		return fmt.Errorf("non-contiguous block: have %d, want %d", number, want)
+	}
+	b.indexes = append(b.indexes, uint64(b.written))
+	b.hashes = append(b.hashes, hash)
+	b.origins = append(b.origins, origin)
+
+	// Write block data.
+	if err := b.snappyWrite(TypeCompressedHeader, header); err != nil {
+		return err
+	}
+	if err := b.snappyWrite(TypeCompressedBody, body); err != nil {
+		return err
+	}
+	if err := b.snappyWrite(TypeCompressedReceipts, receipts); err != nil {
+		return err
+	}
+	if origin == nil {
+		return nil
+	}
+	n, err := b.w.Write(TypeL1Origin, origin.encode())
+	b.written += n
+	return err
+}
+
+// Finalize computes the accumulator and block index values, then writes the
+// corresponding e2store entries.
+func (b *L2Builder) Finalize() (common.Hash, error) {
+	if b.startNum == nil {
+		return common.Hash{}, errors.New("finalize called on empty builder")
+	}
+	root, err := ComputeL2Accumulator(b.hashes, b.origins)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error calculating accumulator root: %w", err)
+	}
+	n, err := b.w.Write(TypeL2Accumulator, root[:])
+	b.written += n
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error writing accumulator: %w", err)
+	}
+	if _, err := b.w.Write(TypeBlockIndex, blockIndex(*b.startNum, b.indexes, int64(b.written))); err != nil {
+		return common.Hash{}, fmt.Errorf("unable to write block index: %w", err)
+	}
+	return root, nil
+}
+
+// snappyWrite is a small helper to take care snappy encoding and writing an e2store entry.
+func (b *L2Builder) snappyWrite(typ uint16, in []byte) error {
+	n, err := snappyWrite(b.w, b.buf, b.snappy, typ, in)
+	b.written += n
+	return err
+}
+
+// ComputeL2Accumulator calculates the SSZ hash tree root of the L2 era
+// accumulator of block records. The origins are optional, but if given, there
+// must be one (possibly nil) per block hash.
+func ComputeL2Accumulator(hashes []common.Hash, origins []*L1Origin) (common.Hash, error) {
+	if origins != nil && len(hashes) != len(origins) {
+		return common.Hash{}, errors.New("must have equal number hashes as l1 origins")
+	}
+	if len(hashes) > MaxEra1Size {
+		return common.Hash{}, fmt.Errorf("too many records: have %d, max %d", len(hashes), MaxEra1Size)
+	}
+	hh := ssz.NewHasher()
+	for i := range hashes {
+		rec := blockRecord{Hash: hashes[i]}
+		if origins != nil && origins[i] != nil {
+			rec.Origin = *origins[i]
+		}
+		root, err := rec.HashTreeRoot()
+		if err != nil {
+			return common.Hash{}, err
+		}
+		hh.Append(root[:])
+	}
+	hh.MerkleizeWithMixin(0, uint64(len(hashes)), uint64(MaxEra1Size))
+	return hh.HashRoot()
+}
+
+// blockRecord is an individual record of the L2 era accumulator.
+type blockRecord struct {
+	Hash   common.Hash
+	Origin L1Origin
+}
+
+// GetTree completes the ssz.HashRoot interface, but is unused.
+func (r *blockRecord) GetTree() (*ssz.Node, error) {
+	return nil, nil
+}
+
+// HashTreeRoot ssz hashes the blockRecord object.
+func (r *blockRecord) HashTreeRoot() ([32]byte, error) {
+	return ssz.HashWithDefaultHasher(r)
+}
+
+// HashTreeRootWith ssz hashes the blockRecord object with a hasher.
+func (r *blockRecord) HashTreeRootWith(hh ssz.HashWalker) (err error) {
+	hh.PutBytes(r.Hash[:])
+	hh.PutUint64(r.Origin.Number)
+	hh.PutBytes(r.Origin.Hash[:])
+	hh.Merkleize(0)
+	return
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -53,6 +54,10 @@ import (
 // allowed to produce in order to speed up calculations.
 const estimateGasErrorRatio = 0.015
 
+// maxPrivateTxLifetime is the maximum number of blocks a private transaction may
+// wait for inclusion.
+const maxPrivateTxLifetime = 1024
+
 var errBlobTxNotSupported = errors.New("signing blob transactions not supported")
 
 // EthereumAPI provides an API to access Ethereum related information.
@@ -184,7 +189,7 @@ func NewTxPoolAPI(b Backend) *TxPoolAPI {
 }
 
 // Content returns the transactions contained within the transaction pool.
-func (api *TxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction {
+func (api *TxPoolAPI) Content(ctx context.Context) map[string]map[string]map[string]*RPCTransaction {
 	content := map[string]map[string]map[string]*RPCTransaction{
 		"pending": make(map[string]map[string]*RPCTransaction),
 		"queued":  make(map[string]map[string]*RPCTransaction),
@@ -193,6 +198,9 @@ func (api *TxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction
 	curHeader := api.b.CurrentHeader()
 	// Flatten the pending transactions
 	for account, txs := range pending {
+		if txs = visiblePoolTxs(ctx, api.b, txs); len(txs) == 0 {
+			continue
+		}
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
 			dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx, curHeader, api.b.ChainConfig())
@@ -201,6 +209,9 @@ func (api *TxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction
 	}
 	// Flatten the queued transactions
 	for account, txs := range queue {
+		if txs = visiblePoolTxs(ctx, api.b, txs); len(txs) == 0 {
+			continue
+		}
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
 			dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx, curHeader, api.b.ChainConfig())
@@ -211,9 +222,10 @@ func (api *TxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction
 }
 
 // ContentFrom returns the transactions contained within the transaction pool.
-func (api *TxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RPCTransaction {
+func (api *TxPoolAPI) ContentFrom(ctx context.Context, addr common.Address) map[string]map[string]*RPCTransaction {
 	content := make(map[string]map[string]*RPCTransaction, 2)
 	pending, queue := api.b.TxPoolContentFrom(addr)
+	pending, queue = visiblePoolTxs(ctx, api.b, pending), visiblePoolTxs(ctx, api.b, queue)
 	curHeader := api.b.CurrentHeader()
 
 	// Build the pending transactions
@@ -244,7 +256,7 @@ func (api *TxPoolAPI) Status() map[string]hexutil.Uint {
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
-func (api *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
+func (api *TxPoolAPI) Inspect(ctx context.Context) map[string]map[string]map[string]string {
 	content := map[string]map[string]map[string]string{
 		"pending": make(map[string]map[string]string),
 		"queued":  make(map[string]map[string]string),
@@ -260,6 +272,9 @@ func (api *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	}
 	// Flatten the pending transactions
 	for account, txs := range pending {
+		if txs = visiblePoolTxs(ctx, api.b, txs); len(txs) == 0 {
+			continue
+		}
 		dump := make(map[string]string)
 		for _, tx := range txs {
 			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
@@ -268,6 +283,9 @@ func (api *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	}
 	// Flatten the queued transactions
 	for account, txs := range queue {
+		if txs = visiblePoolTxs(ctx, api.b, txs); len(txs) == 0 {
+			continue
+		}
 		dump := make(map[string]string)
 		for _, tx := range txs {
 			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
@@ -1336,7 +1354,7 @@ func (api *TransactionAPI) GetTransactionByHash(ctx context.Context, hash common
 	found, tx, blockHash, blockNumber, index, err := api.b.GetTransaction(ctx, hash)
 	if !found {
 		// No finalized transaction, try to retrieve it from the pool
-		if tx := api.b.GetPoolTransaction(hash); tx != nil {
+		if tx := api.b.GetPoolTransaction(hash); tx != nil && api.poolTxVisible(ctx, hash) {
 			return NewRPCPendingTransaction(tx, api.b.CurrentHeader(), api.b.ChainConfig()), nil
 		}
 		if err == nil {
@@ -1356,7 +1374,7 @@ func (api *TransactionAPI) GetRawTransactionByHash(ctx context.Context, hash com
 	// Retrieve a finalized transaction, or a pooled otherwise
 	found, tx, _, _, _, err := api.b.GetTransaction(ctx, hash)
 	if !found {
-		if tx = api.b.GetPoolTransaction(hash); tx != nil {
+		if tx = api.b.GetPoolTransaction(hash); tx != nil && api.poolTxVisible(ctx, hash) {
 			return tx.MarshalBinary()
 		}
 		if err == nil {
@@ -1451,15 +1469,9 @@ func (api *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*ty
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
 func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
-	// If the transaction fee cap is already specified, ensure the
-	// fee of the given transaction is _reasonable_.
-	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
+	if err := checkSubmission(b, tx); err != nil {
 		return common.Hash{}, err
 	}
-	if !b.UnprotectedAllowed() && !tx.Protected() {
-		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
-		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
-	}
 	if err := b.SendTx(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
@@ -1480,6 +1492,21 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 	return tx.Hash(), nil
 }
 
+// checkSubmission runs the RPC specific sanity checks on a transaction before it
+// is submitted to the transaction pool.
+func checkSubmission(b Backend, tx *types.Transaction) error {
+	// If the transaction fee cap is already specified, ensure the
+	// fee of the given transaction is _reasonable_.
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
+		return err
+	}
+	if !b.UnprotectedAllowed() && !tx.Protected() {
+		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
+		return errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	return nil
+}
+
 // SendTransaction creates a transaction for the given argument, sign it and submit it to the
 // transaction pool.
 func (api *TransactionAPI) SendTransaction(ctx context.Context, args TransactionArgs) (common.Hash, error) {
@@ -1544,6 +1571,64 @@ func (api *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil
 	return SubmitTransaction(ctx, api.b, tx)
 }
 
+// SendPrivateRawTransaction will add the signed transaction to the transaction pool
+// without propagating it to the network. The transaction is dropped from the pool
+// if not included within maxBlocks blocks, defaulting to 25.
+func (api *TransactionAPI) SendPrivateRawTransaction(ctx context.Context, input hexutil.Bytes, maxBlocks *hexutil.Uint64) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	lifetime := uint64(txpool.DefaultPrivateTxLifetime)
+	if maxBlocks != nil {
+		lifetime = uint64(*maxBlocks)
+	}
+	if lifetime == 0 || lifetime > maxPrivateTxLifetime {
+		return common.Hash{}, fmt.Errorf("invalid private transaction lifetime %d, must be in [1, %d]", lifetime, maxPrivateTxLifetime)
+	}
+	if err := checkSubmission(api.b, tx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.b.SendPrivateTx(ctx, tx, lifetime); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted private transaction", "hash", tx.Hash().Hex(), "nonce", tx.Nonce(), "recipient", tx.To(), "lifetime", lifetime)
+	return tx.Hash(), nil
+}
+
+// poolTxVisible returns whether a pooled transaction may be revealed to the RPC
+// caller.
+func (api *TransactionAPI) poolTxVisible(ctx context.Context, hash common.Hash) bool {
+	return PrivateTxsVisible(ctx) || !api.b.IsPrivateTx(hash)
+}
+
+// PrivateTxsVisible returns whether private pool transactions may be revealed to
+// the RPC caller. Private transactions are only visible to local callers connected
+// over IPC or in-process.
+func PrivateTxsVisible(ctx context.Context) bool {
+	switch rpc.PeerInfoFromContext(ctx).Transport {
+	case "ipc", "":
+		return true
+	default:
+		return false
+	}
+}
+
+// visiblePoolTxs filters the private transactions out of the given pooled ones,
+// unless the RPC caller may see them.
+func visiblePoolTxs(ctx context.Context, b Backend, txs []*types.Transaction) []*types.Transaction {
+	if PrivateTxsVisible(ctx) {
+		return txs
+	}
+	visible := make([]*types.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if !b.IsPrivateTx(tx.Hash()) {
+			visible = append(visible, tx)
+		}
+	}
+	return visible
+}
+
 // Sign calculates an ECDSA signature for:
 // keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -1606,11 +1691,7 @@ func (api *TransactionAPI) SignTransaction(ctx context.Context, args Transaction
 	// no longer retains the blobs, only the blob hashes. In this step, we need
 	// to put back the blob(s).
 	if args.IsEIP4844() {
-		signed = signed.WithBlobTxSidecar(&types.BlobTxSidecar{
-			Blobs:       args.Blobs,
-			Commitments: args.Commitments,
-			Proofs:      args.Proofs,
-		})
+		signed = signed.WithBlobTxSidecar(types.NewBlobTxSidecar(args.sidecarVersion(), args.Blobs, args.Commitments, args.Proofs))
 	}
 	data, err := signed.MarshalBinary()
 	if err != nil {
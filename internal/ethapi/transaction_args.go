@@ -307,7 +307,8 @@ func (args *TransactionArgs) setBlobTxSidecar(ctx context.Context) error {
 	if args.Commitments != nil && len(args.Commitments) != n {
 		return fmt.Errorf("number of blobs and commitments mismatch (have=%d, want=%d)", len(args.Commitments), n)
 	}
-	if args.Proofs != nil && len(args.Proofs) != n {
+	// Proofs may either be blob proofs (one per blob), or EIP-7594 cell proofs.
+	if args.Proofs != nil && len(args.Proofs) != n && len(args.Proofs) != n*kzg4844.CellProofsPerBlob {
 		return fmt.Errorf("number of blobs and proofs mismatch (have=%d, want=%d)", len(args.Proofs), n)
 	}
 	if args.BlobHashes != nil && len(args.BlobHashes) != n {
@@ -332,6 +333,10 @@ func (args *TransactionArgs) setBlobTxSidecar(ctx context.Context) error {
 		}
 		args.Commitments = commitments
 		args.Proofs = proofs
+	} else if args.sidecarVersion() == types.BlobSidecarVersion1 {
+		if err := kzg4844.VerifyCellProofs(args.Blobs, args.Commitments, args.Proofs); err != nil {
+			return fmt.Errorf("failed to verify cell proofs: %v", err)
+		}
 	} else {
 		for i, b := range args.Blobs {
 			if err := kzg4844.VerifyBlobProof(&b, args.Commitments[i], args.Proofs[i]); err != nil {
@@ -357,6 +362,15 @@ func (args *TransactionArgs) setBlobTxSidecar(ctx context.Context) error {
 	return nil
 }
 
+// sidecarVersion returns the blob sidecar version implied by the number of
+// proofs supplied alongside the blobs.
+func (args *TransactionArgs) sidecarVersion() byte {
+	if len(args.Blobs) > 0 && len(args.Proofs) == len(args.Blobs)*kzg4844.CellProofsPerBlob {
+		return types.BlobSidecarVersion1
+	}
+	return types.BlobSidecarVersion0
+}
+
 // CallDefaults sanitizes the transaction arguments, often filling in zero values,
 // for the purpose of eth_call class of RPC methods.
 func (args *TransactionArgs) CallDefaults(globalGasCap uint64, baseFee *big.Int, chainID *big.Int) error {
@@ -527,11 +541,7 @@ func (args *TransactionArgs) ToTransaction(defaultType int) *types.Transaction {
 			BlobFeeCap: uint256.MustFromBig((*big.Int)(args.BlobFeeCap)),
 		}
 		if args.Blobs != nil {
-			data.(*types.BlobTx).Sidecar = &types.BlobTxSidecar{
-				Blobs:       args.Blobs,
-				Commitments: args.Commitments,
-				Proofs:      args.Proofs,
-			}
+			data.(*types.BlobTx).Sidecar = types.NewBlobTxSidecar(args.sidecarVersion(), args.Blobs, args.Commitments, args.Proofs)
 		}
 
 	case types.DynamicFeeTxType:
@@ -158,6 +158,22 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'setTxPoolThrottle',
+			call: 'admin_setTxPoolThrottle',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'setTxPoolThrottleOverride',
+			call: 'admin_setTxPoolThrottleOverride',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'releaseTxPoolThrottle',
+			call: 'admin_releaseTxPoolThrottle',
+			params: 2
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -172,6 +188,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'txPoolThrottles',
+			getter: 'admin_txPoolThrottles'
+		}),
 	]
 });
 `
@@ -404,6 +424,82 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceBlockStateDiff',
+			call: 'debug_traceBlockStateDiff',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'startTraceJob',
+			call: 'debug_startTraceJob',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'traceJobStatus',
+			call: 'debug_traceJobStatus',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'traceJobs',
+			call: 'debug_traceJobs',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'pauseTraceJob',
+			call: 'debug_pauseTraceJob',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'resumeTraceJob',
+			call: 'debug_resumeTraceJob',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'cancelTraceJob',
+			call: 'debug_cancelTraceJob',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'startTransactionSession',
+			call: 'debug_startTransactionSession',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'startCallSession',
+			call: 'debug_startCallSession',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'sessionStep',
+			call: 'debug_sessionStep',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'sessionStepOver',
+			call: 'debug_sessionStepOver',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'sessionContinue',
+			call: 'debug_sessionContinue',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'sessionInspect',
+			call: 'debug_sessionInspect',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'endSession',
+			call: 'debug_endSession',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -0,0 +1,193 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// errBundleTxReverted is returned if a bundled transaction reverts without
+	// being allowed to.
+	errBundleTxReverted = errors.New("transaction reverted")
+
+	// errBundleUnprofitable is returned if a bundle doesn't pay the fee recipient.
+	errBundleUnprofitable = errors.New("bundle does not pay the fee recipient")
+)
+
+// BundleSource is the source of transaction bundles for the block builder.
+type BundleSource interface {
+	// Bundles returns all the bundles eligible for inclusion into a block with
+	// the given number and timestamp.
+	Bundles(number uint64, time uint64) []*bundlepool.Bundle
+
+	// Report records the outcome of an attempt to include a bundle.
+	Report(hash common.Hash, payment *big.Int, err error)
+}
+
+// simulatedBundle is a bundle along with the coinbase payment it made when
+// executed against the pre-block state.
+type simulatedBundle struct {
+	bundle  *bundlepool.Bundle
+	hash    common.Hash
+	payment *big.Int
+}
+
+// commitBundles simulates all the bundles eligible for the sealing block against
+// the current state, then inserts the successful ones atomically into the block,
+// ordered by their coinbase payment. The outcome of every inclusion attempt is
+// reported back to the bundle source.
+func (miner *Miner) commitBundles(env *environment, interrupt *atomic.Int32) error {
+	miner.confMu.RLock()
+	source := miner.bundles
+	miner.confMu.RUnlock()
+
+	if source == nil {
+		return nil
+	}
+	bundles := source.Bundles(env.header.Number.Uint64(), env.header.Time)
+	if len(bundles) == 0 {
+		return nil
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	// Simulate every bundle on top of the current state, dropping the ones that
+	// fail outright, then rank the rest by the payment they make.
+	simulated := make([]*simulatedBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		hash := bundle.Hash()
+		payment, err := miner.simulateBundle(env, bundle)
+		if err != nil {
+			log.Debug("Bundle simulation failed", "hash", hash, "err", err)
+			source.Report(hash, nil, err)
+			continue
+		}
+		simulated = append(simulated, &simulatedBundle{bundle: bundle, hash: hash, payment: payment})
+	}
+	slices.SortStableFunc(simulated, func(a, b *simulatedBundle) int {
+		return b.payment.Cmp(a.payment)
+	})
+	// Insert the bundles in order. Earlier bundles might have invalidated later
+	// ones, so each bundle is re-executed and reverted as a whole on failure.
+	for _, sim := range simulated {
+		if interrupt != nil {
+			if signal := interrupt.Load(); signal != commitInterruptNone {
+				return signalToErr(signal)
+			}
+		}
+		payment, err := miner.commitBundle(env, sim.bundle)
+		if err != nil {
+			log.Debug("Bundle inclusion failed", "hash", sim.hash, "err", err)
+		} else {
+			log.Debug("Included bundle", "hash", sim.hash, "txs", len(sim.bundle.Txs), "payment", payment)
+		}
+		source.Report(sim.hash, payment, err)
+	}
+	return nil
+}
+
+// simulateBundle executes a bundle on top of the current state of the sealing
+// block and returns the coinbase payment it made. All state changes are rolled
+// back afterwards.
+func (miner *Miner) simulateBundle(env *environment, bundle *bundlepool.Bundle) (*big.Int, error) {
+	restore := env.checkpoint()
+	defer restore()
+
+	return miner.applyBundle(env, bundle)
+}
+
+// commitBundle inserts a bundle into the sealing block, returning the coinbase
+// payment it made. If any of the transactions fails, the entire bundle is
+// rolled back.
+func (miner *Miner) commitBundle(env *environment, bundle *bundlepool.Bundle) (*big.Int, error) {
+	restore := env.checkpoint()
+
+	payment, err := miner.applyBundle(env, bundle)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	return payment, nil
+}
+
+// applyBundle executes the transactions of a bundle in order on top of the
+// current state of the sealing block. It's the caller's responsibility to roll
+// back the environment on failure.
+func (miner *Miner) applyBundle(env *environment, bundle *bundlepool.Bundle) (*big.Int, error) {
+	before := env.state.GetBalance(env.coinbase).ToBig()
+
+	for i, tx := range bundle.Txs {
+		if tx.Protected() && !miner.chainConfig.IsEIP155(env.header.Number) {
+			return nil, fmt.Errorf("transaction %d: replay protected before EIP-155", i)
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		if receipt := env.receipts[len(env.receipts)-1]; receipt.Status == types.ReceiptStatusFailed && !bundle.CanRevert(tx.Hash()) {
+			return nil, fmt.Errorf("transaction %d: %w", i, errBundleTxReverted)
+		}
+	}
+	payment := new(big.Int).Sub(env.state.GetBalance(env.coinbase).ToBig(), before)
+	if payment.Sign() <= 0 {
+		return nil, errBundleUnprofitable
+	}
+	return payment, nil
+}
+
+// checkpoint switches the sealing environment over to a copy of its state and
+// returns a function that rolls the environment back to the original one. The
+// state is copied instead of snapshotted, as the journal is not retained across
+// transactions.
+func (env *environment) checkpoint() func() {
+	var (
+		state   = env.state
+		gas     = env.gasPool.Gas()
+		gasUsed = env.header.GasUsed
+		txs     = len(env.txs)
+		tcount  = env.tcount
+	)
+	env.setState(state.Copy())
+
+	return func() {
+		env.setState(state)
+		env.gasPool.SetGas(gas)
+		env.header.GasUsed = gasUsed
+		env.txs = env.txs[:txs]
+		env.receipts = env.receipts[:txs]
+		env.tcount = tcount
+	}
+}
+
+// setState replaces the state the sealing environment executes on.
+func (env *environment) setState(state *state.StateDB) {
+	env.state = state
+	env.evm.StateDB = state
+	env.witness = state.Witness()
+}
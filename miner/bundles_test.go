@@ -0,0 +1,131 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testBundleSource is a bundle source serving a static set of bundles and
+// recording the reported inclusion outcomes.
+type testBundleSource struct {
+	bundles  []*bundlepool.Bundle
+	payments map[common.Hash]*big.Int
+	errors   map[common.Hash]error
+}
+
+func (s *testBundleSource) Bundles(number uint64, time uint64) []*bundlepool.Bundle {
+	var bundles []*bundlepool.Bundle
+	for _, bundle := range s.bundles {
+		if bundle.Eligible(number, time) {
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles
+}
+
+func (s *testBundleSource) Report(hash common.Hash, payment *big.Int, err error) {
+	s.payments[hash], s.errors[hash] = payment, err
+}
+
+// Tests that bundles are inserted atomically at the top of the block, ordered
+// by their payment, and that conflicting or invalid bundles are rolled back.
+func TestCommitBundles(t *testing.T) {
+	b := newTestWorkerBackend(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	w := New(b, testConfig, ethash.NewFaker())
+
+	var (
+		signer    = types.LatestSigner(params.TestChainConfig)
+		recipient = common.HexToAddress("0xdeadbeef")
+	)
+	transfer := func(nonce uint64, tip int64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     nonce,
+			To:        &testUserAddress,
+			Value:     big.NewInt(1000),
+			Gas:       params.TxGas,
+			GasTipCap: big.NewInt(tip * params.GWei),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+		})
+	}
+	var (
+		// Pays less than the rich bundle and conflicts with it
+		cheap = &bundlepool.Bundle{Txs: []*types.Transaction{transfer(0, 1), transfer(1, 1)}, BlockNumber: 1}
+
+		// Pays the most, should be included first
+		rich = &bundlepool.Bundle{Txs: []*types.Transaction{transfer(0, 5)}, BlockNumber: 1}
+
+		// Second transaction has a nonce gap, the whole bundle must be dropped
+		gapped = &bundlepool.Bundle{Txs: []*types.Transaction{transfer(1, 3), transfer(3, 3)}, BlockNumber: 1}
+
+		// Targets a different block, must be ignored
+		future = &bundlepool.Bundle{Txs: []*types.Transaction{transfer(0, 9)}, BlockNumber: 2}
+	)
+	source := &testBundleSource{
+		bundles:  []*bundlepool.Bundle{cheap, rich, gapped, future},
+		payments: make(map[common.Hash]*big.Int),
+		errors:   make(map[common.Hash]error),
+	}
+	w.SetBundleSource(source)
+
+	result := w.generateWork(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   recipient,
+	}, false)
+	if result.err != nil {
+		t.Fatalf("failed to generate work: %v", result.err)
+	}
+	txs := result.block.Transactions()
+	if len(txs) != 1 || txs[0].Hash() != rich.Txs[0].Hash() {
+		t.Fatalf("unexpected block transactions: have %d, want rich bundle only", len(txs))
+	}
+	if err := source.errors[rich.Hash()]; err != nil {
+		t.Errorf("rich bundle failed: %v", err)
+	}
+	if want := new(big.Int).Mul(big.NewInt(5*params.GWei), big.NewInt(int64(params.TxGas))); source.payments[rich.Hash()].Cmp(want) != 0 {
+		t.Errorf("rich bundle payment mismatch: have %v, want %v", source.payments[rich.Hash()], want)
+	}
+	if err := source.errors[cheap.Hash()]; !errors.Is(err, core.ErrNonceTooLow) {
+		t.Errorf("cheap bundle error mismatch: have %v, want %v", err, core.ErrNonceTooLow)
+	}
+	if err := source.errors[gapped.Hash()]; !errors.Is(err, core.ErrNonceTooHigh) {
+		t.Errorf("gapped bundle error mismatch: have %v, want %v", err, core.ErrNonceTooHigh)
+	}
+	if _, ok := source.errors[future.Hash()]; ok {
+		t.Errorf("future bundle was attempted")
+	}
+	// The rolled back bundles must not have left any trace in the state
+	if nonce := result.stateDB.GetNonce(testBankAddress); nonce != 1 {
+		t.Errorf("bank nonce mismatch: have %d, want 1", nonce)
+	}
+	if used := result.block.GasUsed(); used != params.TxGas {
+		t.Errorf("gas used mismatch: have %d, want %d", used, params.TxGas)
+	}
+}
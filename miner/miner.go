@@ -71,6 +71,7 @@ type Miner struct {
 	engine      consensus.Engine
 	txpool      *txpool.TxPool
 	prio        []common.Address // A list of senders to prioritize
+	bundles     BundleSource     // Source of transaction bundles to include atomically
 	chain       *core.BlockChain
 	pending     *pending
 	pendingMu   sync.Mutex // Lock protects the pending block
@@ -117,6 +118,14 @@ func (miner *Miner) SetPrioAddresses(prio []common.Address) {
 	miner.confMu.Unlock()
 }
 
+// SetBundleSource sets the source of transaction bundles to insert at the top
+// of the built blocks.
+func (miner *Miner) SetBundleSource(bundles BundleSource) {
+	miner.confMu.Lock()
+	miner.bundles = bundles
+	miner.confMu.Unlock()
+}
+
 // SetGasCeil sets the gaslimit to strive for when mining blocks post 1559.
 // For pre-1559 blocks, it sets the ceiling.
 func (miner *Miner) SetGasCeil(ceil uint64) {
@@ -422,9 +422,12 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 }
 
 // fillTransactions retrieves the pending transactions from the txpool and fills them
-// into the given sealing block. The transaction selection and ordering strategy can
-// be customized with the plugin in the future.
+// into the given sealing block, after any bundles targeting it. The transaction
+// selection and ordering strategy can be customized with the plugin in the future.
 func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment) error {
+	if err := miner.commitBundles(env, interrupt); err != nil {
+		return err
+	}
 	miner.confMu.RLock()
 	tip := miner.config.GasPrice
 	prio := miner.prio
@@ -211,6 +211,16 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// DBReplica is the address of the chain database served by a primary node.
+	// If set, the chain database is accessed over the network instead of being
+	// opened from the data directory, and the writes to it are only kept in
+	// memory.
+	DBReplica string `toml:",omitempty"`
+
+	// DBServer is the listening address to serve the chain database on for the
+	// replica nodes. The database is not served if empty.
+	DBServer string `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
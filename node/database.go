@@ -18,10 +18,12 @@ package node
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/netdb"
 	"github.com/ethereum/go-ethereum/ethdb/pebble"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -114,3 +116,34 @@ func newPebbleDBDatabase(file string, cache int, handles int, namespace string,
 	}
 	return rawdb.NewDatabase(db), nil
 }
+
+// openReplicaDatabase connects to the chain database served by a primary node,
+// keeping the writes to it in memory.
+func openReplicaDatabase(addr string, cache int) (ethdb.Database, error) {
+	config := netdb.DefaultConfig
+	config.Cache = cache
+	config.Replica = true
+
+	db, err := netdb.New(addr, config)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Using remote database as a replica", "addr", addr)
+	return db, nil
+}
+
+// serveDatabase serves the database for the replica nodes on the given address.
+// The returned database tracks the writes for the replicas, and stops the server
+// when closed.
+func serveDatabase(db ethdb.Database, addr string) (ethdb.Database, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	server := netdb.NewServer(db, true)
+	go server.Serve(listener)
+
+	log.Info("Serving database for replicas", "addr", listener.Addr())
+	return server.Database(), nil
+}
@@ -739,9 +739,12 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 	}
 	var db ethdb.Database
 	var err error
-	if n.config.DataDir == "" {
+	switch {
+	case n.config.DBReplica != "":
+		db, err = openReplicaDatabase(n.config.DBReplica, cache)
+	case n.config.DataDir == "":
 		db, err = rawdb.NewDatabaseWithFreezer(memorydb.New(), "", namespace, readonly)
-	} else {
+	default:
 		db, err = openDatabase(openOptions{
 			Type:              n.config.DBEngine,
 			Directory:         n.ResolvePath(name),
@@ -752,6 +755,9 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 			ReadOnly:          readonly,
 		})
 	}
+	if err == nil && n.config.DBServer != "" && n.config.DBReplica == "" && !readonly {
+		db, err = serveDatabase(db, n.config.DBServer)
+	}
 	if err == nil {
 		db = n.wrapDatabase(db)
 	}
@@ -266,6 +266,30 @@ func (db *Database) Recover(target common.Hash) error {
 	return pdb.Recover(target)
 }
 
+// HistoricReader returns a reader for the state associated with the specified
+// root, which may be older than the states kept by the database as long as the
+// state histories leading to it are available, along with the root of the base
+// state to resolve the reads it doesn't cover from. It's only supported by
+// path-based database and will return an error for others.
+func (db *Database) HistoricReader(root common.Hash) (database.StateReader, common.Hash, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, common.Hash{}, errors.New("not supported")
+	}
+	return pdb.HistoricReader(root)
+}
+
+// HistoricIndexed reports whether the state histories are indexed, allowing the
+// historic states at any distance to be read cheaply through HistoricReader.
+// It's only supported by path-based database and will return false for others.
+func (db *Database) HistoricIndexed() bool {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return false
+	}
+	return pdb.HistoricIndexed()
+}
+
 // Recoverable returns the indicator if the specified state is enabled to be
 // recovered. It's only supported by path-based database and will return an
 // error for others.
@@ -277,6 +301,17 @@ func (db *Database) Recoverable(root common.Hash) (bool, error) {
 	return pdb.Recoverable(root), nil
 }
 
+// DropHistories removes all the state histories, leaving the persistent state
+// as the only available one. It's only supported by path-based database and
+// will return an error for others.
+func (db *Database) DropHistories() (int, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return 0, errors.New("not supported")
+	}
+	return pdb.DropHistories()
+}
+
 // Disable deactivates the database and invalidates all available state layers
 // as stale to prevent access to the persistent state, which is in the syncing
 // stage.
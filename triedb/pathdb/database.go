@@ -114,6 +114,7 @@ type Config struct {
 	CleanCacheSize  int    // Maximum memory allowance (in bytes) for caching clean nodes
 	WriteBufferSize int    // Maximum memory allowance (in bytes) for write buffer
 	ReadOnly        bool   // Flag whether the database is opened in read only mode.
+	StateIndex      bool   // Flag whether the state histories are indexed for historic state access
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -136,6 +137,9 @@ func (c *Config) fields() []interface{} {
 	list = append(list, "cache", common.StorageSize(c.CleanCacheSize))
 	list = append(list, "buffer", common.StorageSize(c.WriteBufferSize))
 	list = append(list, "history", c.StateHistory)
+	if c.StateIndex {
+		list = append(list, "index", true)
+	}
 	return list
 }
 
@@ -234,6 +238,9 @@ func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 	if err := db.repairHistory(); err != nil {
 		log.Crit("Failed to repair state history", "err", err)
 	}
+	if err := db.repairIndex(); err != nil {
+		log.Crit("Failed to repair state history index", "err", err)
+	}
 	// Disable database in case node is still in the initial state sync stage.
 	if rawdb.ReadSnapSyncStatusFlag(diskdb) == rawdb.StateSyncRunning && !db.readOnly {
 		if err := db.Disable(); err != nil {
@@ -287,6 +294,9 @@ func (db *Database) repairHistory() error {
 	}
 	// Truncate the extra state histories above in freezer in case it's not
 	// aligned with the disk layer. It might happen after a unclean shutdown.
+	if err := db.truncateIndexFromHead(id); err != nil {
+		log.Crit("Failed to unindex extra state histories", "err", err)
+	}
 	pruned, err := truncateFromHead(db.diskdb, db.freezer, id)
 	if err != nil {
 		log.Crit("Failed to truncate extra state histories", "err", err)
@@ -402,6 +412,12 @@ func (db *Database) Enable(root common.Hash) error {
 		if err := db.freezer.Reset(); err != nil {
 			return err
 		}
+		if err := deleteStateIndex(db.diskdb); err != nil {
+			return err
+		}
+		if db.config.StateIndex {
+			rawdb.WriteStateIndexHead(db.diskdb, 0)
+		}
 	}
 	// Re-construct a new disk layer backed by persistent state
 	// with **empty clean cache and node buffer**.
@@ -454,6 +470,9 @@ func (db *Database) Recover(root common.Hash) error {
 		db.tree.reset(dl)
 	}
 	rawdb.DeleteTrieJournal(db.diskdb)
+	if err := db.truncateIndexFromHead(dl.stateID()); err != nil {
+		return err
+	}
 	_, err := truncateFromHead(db.diskdb, db.freezer, dl.stateID())
 	if err != nil {
 		return err
@@ -496,6 +515,58 @@ func (db *Database) Recoverable(root common.Hash) bool {
 	}) == nil
 }
 
+// DropHistories removes all the state histories along with their index and
+// the lookups of the states they refer to, leaving the persistent state as the
+// oldest available one. It returns the number of dropped histories.
+func (db *Database) DropHistories() (int, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	// Short circuit if the mutation is not allowed.
+	if err := db.modifyAllowed(); err != nil {
+		return 0, err
+	}
+	if db.freezer == nil {
+		return 0, nil
+	}
+	tail, err := db.freezer.Tail()
+	if err != nil {
+		return 0, err
+	}
+	head, err := db.freezer.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	// Drop the index first, it's rebuilt from the remaining histories on
+	// the next start if the dropping is interrupted.
+	if rawdb.ReadStateIndexHead(db.diskdb) != nil {
+		if err := deleteStateIndex(db.diskdb); err != nil {
+			return 0, err
+		}
+	}
+	var (
+		pruned int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for tail < head {
+		// Truncate the histories in chunks to bound the memory usage
+		// of loading the metadata of dropped histories.
+		next := min(tail+10000, head)
+		n, err := truncateFromTail(db.diskdb, db.freezer, next)
+		if err != nil {
+			return pruned, err
+		}
+		pruned, tail = pruned+n, next
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Dropping state histories", "dropped", pruned, "left", head-tail, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	return pruned, nil
+}
+
 // Close closes the trie database and the held freezer.
 func (db *Database) Close() error {
 	db.lock.Lock()
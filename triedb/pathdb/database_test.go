@@ -122,13 +122,17 @@ type tester struct {
 }
 
 func newTester(t *testing.T, historyLimit uint64, isVerkle bool, layers int) *tester {
+	return newTesterWithConfig(t, &Config{
+		StateHistory:    historyLimit,
+		CleanCacheSize:  256 * 1024,
+		WriteBufferSize: 256 * 1024,
+	}, isVerkle, layers)
+}
+
+func newTesterWithConfig(t *testing.T, config *Config, isVerkle bool, layers int) *tester {
 	var (
 		disk, _ = rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
-		db      = New(disk, &Config{
-			StateHistory:    historyLimit,
-			CleanCacheSize:  256 * 1024,
-			WriteBufferSize: 256 * 1024,
-		}, isVerkle)
+		db      = New(disk, config, isVerkle)
 
 		obj = &tester{
 			db:           db,
@@ -231,10 +231,20 @@ func (dl *diskLayer) commit(bottom *diffLayer, force bool) (*diskLayer, error) {
 		oldest   uint64
 	)
 	if dl.db.freezer != nil {
-		err := writeHistory(dl.db.freezer, bottom)
+		h, err := writeHistory(dl.db.freezer, bottom)
 		if err != nil {
 			return nil, err
 		}
+		// Index the state history right away, keeping the index aligned with
+		// the freezer.
+		if dl.db.config.StateIndex {
+			batch := dl.db.diskdb.NewBatch()
+			indexHistory(batch, bottom.stateID(), h)
+			rawdb.WriteStateIndexHead(batch, bottom.stateID())
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+		}
 		// Determine if the persisted history object has exceeded the configured
 		// limitation, set the overflow as true if so.
 		tail, err := dl.db.freezer.Tail()
@@ -278,6 +288,15 @@ func (dl *diskLayer) commit(bottom *diffLayer, force bool) (*diskLayer, error) {
 	// To remove outdated history objects from the end, we set the 'tail' parameter
 	// to 'oldest-1' due to the offset between the freezer index and the history ID.
 	if overflow {
+		if ndl.db.config.StateIndex {
+			tail, err := ndl.db.freezer.Tail()
+			if err != nil {
+				return nil, err
+			}
+			if err := unindexHistories(ndl.db.diskdb, ndl.db.freezer, tail+1, oldest-1); err != nil {
+				return nil, err
+			}
+		}
 		pruned, err := truncateFromTail(ndl.db.diskdb, ndl.db.freezer, oldest-1)
 		if err != nil {
 			return nil, err
@@ -527,11 +527,12 @@ func readHistory(reader ethdb.AncientReader, id uint64) (*history, error) {
 	return &dec, nil
 }
 
-// writeHistory persists the state history with the provided state set.
-func writeHistory(writer ethdb.AncientWriter, dl *diffLayer) error {
+// writeHistory persists the state history with the provided state set and
+// returns the written history.
+func writeHistory(writer ethdb.AncientWriter, dl *diffLayer) (*history, error) {
 	// Short circuit if state set is not available.
 	if dl.states == nil {
-		return errors.New("state change set is not available")
+		return nil, errors.New("state change set is not available")
 	}
 	var (
 		start   = time.Now()
@@ -549,7 +550,7 @@ func writeHistory(writer ethdb.AncientWriter, dl *diffLayer) error {
 	historyBuildTimeMeter.UpdateSince(start)
 	log.Debug("Stored state history", "id", dl.stateID(), "block", dl.block, "data", dataSize, "index", indexSize, "elapsed", common.PrettyDuration(time.Since(start)))
 
-	return nil
+	return history, nil
 }
 
 // checkHistories retrieves a batch of meta objects with the specified range
@@ -0,0 +1,179 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The state history index stores the original values recorded by every state
+// history keyed by account/slot and history id, so that the value of a state
+// at an old state id can be found by looking up the first entry with a higher
+// id, without replaying the histories in between.
+//
+// When enabled, the index always covers all the state histories in the freezer:
+// it is extended along with the history writes and shrunk along with the history
+// truncations. The head of the index is tracked to detect and rebuild the index
+// after it has been disabled for a while.
+
+// indexHistory adds the original values of the states mutated by the given
+// state history into the index.
+func indexHistory(db ethdb.KeyValueWriter, id uint64, h *history) {
+	accounts, storages := h.stateSet()
+	for addrHash, blob := range accounts {
+		rawdb.WriteStateIndexAccount(db, addrHash, id, blob)
+	}
+	for addrHash, slots := range storages {
+		for slotHash, blob := range slots {
+			rawdb.WriteStateIndexStorage(db, addrHash, slotHash, id, blob)
+		}
+	}
+}
+
+// unindexHistory removes the original values of the states mutated by the given
+// state history from the index.
+func unindexHistory(db ethdb.KeyValueWriter, id uint64, h *history) {
+	accounts, storages := h.stateSet()
+	for addrHash := range accounts {
+		rawdb.DeleteStateIndexAccount(db, addrHash, id)
+	}
+	for addrHash, slots := range storages {
+		for slotHash := range slots {
+			rawdb.DeleteStateIndexStorage(db, addrHash, slotHash, id)
+		}
+	}
+}
+
+// deleteStateIndex drops the entire state history index, sparing the trie nodes
+// of a legacy hash-scheme database sharing its key prefix.
+func deleteStateIndex(db ethdb.Database) error {
+	return rawdb.DeleteStateIndex(db, rawdb.ReadStateScheme(db) == rawdb.HashScheme)
+}
+
+// indexHistories adds the state histories in range [first, last] into the index
+// and moves the index head to last.
+func indexHistories(db ethdb.KeyValueStore, freezer ethdb.AncientReader, first, last uint64) error {
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		batch  = db.NewBatch()
+	)
+	for id := first; id <= last; id++ {
+		h, err := readHistory(freezer, id)
+		if err != nil {
+			return err
+		}
+		indexHistory(batch, id, h)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			rawdb.WriteStateIndexHead(batch, id)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing state histories", "id", id, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	rawdb.WriteStateIndexHead(batch, last)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if last >= first {
+		log.Info("Indexed state histories", "first", first, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// unindexHistories removes the state histories in range [first, last] from the
+// index. The index head is left untouched.
+func unindexHistories(db ethdb.KeyValueStore, freezer ethdb.AncientReader, first, last uint64) error {
+	batch := db.NewBatch()
+	for id := first; id <= last; id++ {
+		h, err := readHistory(freezer, id)
+		if err != nil {
+			return err
+		}
+		unindexHistory(batch, id, h)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return batch.Write()
+}
+
+// truncateIndexFromHead removes the state histories above the given id from
+// the index, which must be done before truncating them from the freezer.
+func (db *Database) truncateIndexFromHead(nhead uint64) error {
+	if !db.config.StateIndex {
+		return nil
+	}
+	head := rawdb.ReadStateIndexHead(db.diskdb)
+	if head == nil || *head <= nhead {
+		return nil
+	}
+	if err := unindexHistories(db.diskdb, db.freezer, nhead+1, *head); err != nil {
+		return err
+	}
+	rawdb.WriteStateIndexHead(db.diskdb, nhead)
+	return nil
+}
+
+// repairIndex aligns the state history index with the state histories in the
+// freezer, indexing the missing histories or dropping the entire index if it's
+// disabled.
+func (db *Database) repairIndex() error {
+	if db.freezer == nil || db.readOnly {
+		return nil
+	}
+	head := rawdb.ReadStateIndexHead(db.diskdb)
+	if !db.config.StateIndex {
+		if head != nil {
+			if err := deleteStateIndex(db.diskdb); err != nil {
+				return err
+			}
+			log.Info("Dropped state history index")
+		}
+		return nil
+	}
+	tail, err := db.freezer.Tail()
+	if err != nil {
+		return err
+	}
+	last, err := db.freezer.Ancients()
+	if err != nil {
+		return err
+	}
+	// The index is rebuilt from scratch if it doesn't cover the oldest history,
+	// which means histories were truncated without maintaining the index.
+	if head == nil || *head < tail || *head > last {
+		if err := deleteStateIndex(db.diskdb); err != nil {
+			return err
+		}
+		return indexHistories(db.diskdb, db.freezer, tail+1, last)
+	}
+	return indexHistories(db.diskdb, db.freezer, *head+1, last)
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/triedb/database"
+)
+
+// maxHistoricReplay is the maximum number of state histories replayed in memory
+// to construct a reader for a historic state if the histories aren't indexed.
+var maxHistoricReplay = uint64(128)
+
+// historicReader is a state reader for a state below the disk layer. It holds
+// the original values of all the states mutated between the target state and
+// the disk layer, as recorded in the state histories, and reads the unmutated
+// states from the disk layer.
+type historicReader struct {
+	base     *reader
+	accounts map[common.Hash][]byte
+	storages map[common.Hash]map[common.Hash][]byte
+}
+
+// Account directly retrieves the account associated with a particular hash in
+// the slim data format.
+func (r *historicReader) Account(hash common.Hash) (*types.SlimAccount, error) {
+	blob, ok := r.accounts[hash]
+	if !ok {
+		return r.base.Account(hash)
+	}
+	return decodeSlimAccount(blob)
+}
+
+// Storage directly retrieves the storage data associated with a particular hash,
+// within a particular account.
+func (r *historicReader) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	if slots, ok := r.storages[accountHash]; ok {
+		if blob, ok := slots[storageHash]; ok {
+			return blob, nil
+		}
+	}
+	return r.base.Storage(accountHash, storageHash)
+}
+
+// indexedReader is a state reader for a state below the disk layer, looking up
+// the original values of the mutated states in the state history index and
+// reading the unmutated states from the disk layer.
+type indexedReader struct {
+	base *reader
+	db   ethdb.Iteratee
+	id   uint64
+}
+
+// Account directly retrieves the account associated with a particular hash in
+// the slim data format.
+func (r *indexedReader) Account(hash common.Hash) (*types.SlimAccount, error) {
+	blob, ok := rawdb.ReadStateIndexAccount(r.db, hash, r.id)
+	if !ok {
+		return r.base.Account(hash)
+	}
+	return decodeSlimAccount(blob)
+}
+
+// Storage directly retrieves the storage data associated with a particular hash,
+// within a particular account.
+func (r *indexedReader) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	blob, ok := rawdb.ReadStateIndexStorage(r.db, accountHash, storageHash, r.id)
+	if !ok {
+		return r.base.Storage(accountHash, storageHash)
+	}
+	return blob, nil
+}
+
+// decodeSlimAccount decodes the original value of an account recorded in the
+// state history, which is empty if the account didn't exist.
+func decodeSlimAccount(blob []byte) (*types.SlimAccount, error) {
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	account := new(types.SlimAccount)
+	if err := rlp.DecodeBytes(blob, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// HistoricIndexed reports whether the state histories are indexed up to the
+// disk layer, allowing historic states at any distance to be served cheaply.
+func (db *Database) HistoricIndexed() bool {
+	if db.freezer == nil || !db.config.StateIndex {
+		return false
+	}
+	head := rawdb.ReadStateIndexHead(db.diskdb)
+	return head != nil && *head >= db.tree.bottom().stateID()
+}
+
+// HistoricReader returns a reader for the state associated with the specified
+// root. Unlike StateReader, the state may be older than the disk layer as long
+// as the state histories leading from it to the disk layer are available.
+//
+// The reader only covers the states mutated since the requested state, reading
+// the others from the disk layer which may fail as the persistent state isn't
+// directly accessible; they must then be resolved from the tries of the
+// returned base state instead, which are identical for these states.
+//
+// If the state histories are indexed, the original values of the mutated states
+// are looked up in the index on demand. Otherwise they are all loaded in memory,
+// which is only done for states at most maxHistoricReplay histories below the
+// disk layer. The reader becomes stale once the disk layer is updated.
+func (db *Database) HistoricReader(root common.Hash) (database.StateReader, common.Hash, error) {
+	if layer := db.tree.get(root); layer != nil {
+		return &reader{layer: layer}, root, nil
+	}
+	if db.freezer == nil {
+		return nil, common.Hash{}, errors.New("state histories are not available")
+	}
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return nil, common.Hash{}, fmt.Errorf("state %#x is not available", root)
+	}
+	dl := db.tree.bottom()
+	if *id >= dl.stateID() {
+		return nil, common.Hash{}, fmt.Errorf("state %#x is not available", root)
+	}
+	if db.config.StateIndex {
+		tail, err := db.freezer.Tail()
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		if *id < tail {
+			return nil, common.Hash{}, fmt.Errorf("state %#x is not available, history pruned", root)
+		}
+		head := rawdb.ReadStateIndexHead(db.diskdb)
+		if head != nil && *head >= dl.stateID() {
+			return &indexedReader{base: &reader{layer: dl}, db: db.diskdb, id: *id}, dl.rootHash(), nil
+		}
+	}
+	// The histories are replayed without holding the database lock, not to stall
+	// the state updates. Concurrently truncated histories fail to be read, and a
+	// concurrently updated disk layer turns the reader stale.
+	if dl.stateID()-*id > maxHistoricReplay {
+		return nil, common.Hash{}, fmt.Errorf("state %#x is not available, too far below the disk layer", root)
+	}
+	r := &historicReader{
+		base:     &reader{layer: dl},
+		accounts: make(map[common.Hash][]byte),
+		storages: make(map[common.Hash]map[common.Hash][]byte),
+	}
+	// Apply the original values of the histories from the newest to the oldest,
+	// letting the oldest value of every state win.
+	next := dl.rootHash()
+	for n := dl.stateID(); n > *id; n-- {
+		h, err := readHistory(db.freezer, n)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		if h.meta.root != next {
+			return nil, common.Hash{}, fmt.Errorf("unexpected state history %d", n)
+		}
+		next = h.meta.parent
+
+		accounts, storages := h.stateSet()
+		maps.Copy(r.accounts, accounts)
+		for addrHash, slots := range storages {
+			if r.storages[addrHash] == nil {
+				r.storages[addrHash] = make(map[common.Hash][]byte)
+			}
+			maps.Copy(r.storages[addrHash], slots)
+		}
+	}
+	if next != root {
+		return nil, common.Hash{}, fmt.Errorf("state %#x is not canonical", root)
+	}
+	return r, dl.rootHash(), nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestHistoricReader(t *testing.T)        { testHistoricReader(t, false) }
+func TestIndexedHistoricReader(t *testing.T) { testHistoricReader(t, true) }
+
+func testHistoricReader(t *testing.T, index bool) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTesterWithConfig(t, &Config{
+		CleanCacheSize:  256 * 1024,
+		WriteBufferSize: 256 * 1024,
+		StateIndex:      index,
+	}, false, 12)
+	defer tester.release()
+
+	checkHistoricReaders(t, tester, 0, index)
+
+	// Unknown states are unavailable
+	if _, _, err := tester.db.HistoricReader(common.Hash{0x1}); err == nil {
+		t.Fatal("Unexpected reader for unknown state")
+	}
+	if have := tester.db.HistoricIndexed(); have != index {
+		t.Fatalf("Unexpected index status, want: %v, got: %v", index, have)
+	}
+	// Without the index, the states too far below the disk layer are unavailable
+	maxHistoricReplay = 1
+	defer func() {
+		maxHistoricReplay = 128
+	}()
+	if _, _, err := tester.db.HistoricReader(tester.roots[0]); (err == nil) != index {
+		t.Fatalf("Unexpected result for distant state, index: %v, err: %v", index, err)
+	}
+}
+
+func TestStateIndexMaintenance(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTesterWithConfig(t, &Config{
+		StateHistory:    6,
+		CleanCacheSize:  256 * 1024,
+		WriteBufferSize: 256 * 1024,
+		StateIndex:      true,
+	}, false, 16)
+	defer tester.release()
+
+	// The states with pruned histories are unavailable
+	tail, err := tester.db.freezer.Tail()
+	if err != nil {
+		t.Fatalf("Failed to retrieve history tail, err: %v", err)
+	}
+	if tail == 0 {
+		t.Fatal("No state history is pruned")
+	}
+	if _, _, err := tester.db.HistoricReader(tester.roots[tail-1]); err == nil {
+		t.Fatal("Unexpected reader for state with pruned history")
+	}
+	checkHistoricReaders(t, tester, int(tail), true)
+
+	// Roll back the disk layer, the index must follow the state histories
+	bottom := tester.bottomIndex()
+	if err := tester.db.Recover(tester.roots[bottom-2]); err != nil {
+		t.Fatalf("Failed to revert db, err: %v", err)
+	}
+	tester.roots = tester.roots[:bottom-1]
+	if head := rawdb.ReadStateIndexHead(tester.db.diskdb); head == nil || *head != tester.db.tree.bottom().stateID() {
+		t.Fatalf("Unexpected index head after rollback: %v", head)
+	}
+	checkHistoricReaders(t, tester, int(tail), true)
+
+	// Rebuild the index from scratch
+	deleteStateIndex(tester.db.diskdb)
+	if err := tester.db.repairIndex(); err != nil {
+		t.Fatalf("Failed to rebuild index, err: %v", err)
+	}
+	checkHistoricReaders(t, tester, int(tail), true)
+}
+
+// checkHistoricReaders verifies the historic readers of the tester states from
+// the given one up to the disk layer.
+func checkHistoricReaders(t *testing.T, tester *tester, first int, index bool) {
+	t.Helper()
+
+	bottom := tester.bottomIndex()
+	if bottom < first+2 {
+		t.Fatalf("Too few states below the disk layer: %d", bottom)
+	}
+	for i := first; i < len(tester.roots)-1; i++ {
+		root := tester.roots[i]
+		reader, base, err := tester.db.HistoricReader(root)
+		if err != nil {
+			t.Fatalf("Failed to open state %d, err: %v", i, err)
+		}
+		if want := tester.roots[max(i, bottom)]; base != want {
+			t.Fatalf("Unexpected base state for state %d, want: %x, got: %x", i, want, base)
+		}
+		if _, ok := reader.(*indexedReader); i < bottom && ok != index {
+			t.Fatalf("Unexpected reader type for state %d: %T", i, reader)
+		}
+		// The states the reader can't resolve must be unchanged in the base state
+		for addrHash, blob := range tester.snapAccounts[root] {
+			account, err := reader.Account(addrHash)
+			if err != nil {
+				if !bytes.Equal(tester.snapAccounts[base][addrHash], blob) {
+					t.Fatalf("Failed to read mutated account %x in state %d, err: %v", addrHash, i, err)
+				}
+				continue
+			}
+			have, _ := rlp.EncodeToBytes(account)
+			if !bytes.Equal(have, blob) {
+				t.Fatalf("Account %x is mismatched in state %d", addrHash, i)
+			}
+		}
+		// Accounts created after the state must be absent
+		for addrHash := range tester.snapAccounts[base] {
+			if _, ok := tester.snapAccounts[root][addrHash]; ok {
+				continue
+			}
+			account, err := reader.Account(addrHash)
+			if err != nil || account != nil {
+				t.Fatalf("Unexpected account %x in state %d, err: %v", addrHash, i, err)
+			}
+		}
+		for addrHash, slots := range tester.snapStorages[root] {
+			for slotHash, blob := range slots {
+				have, err := reader.Storage(addrHash, slotHash)
+				if err != nil {
+					if !bytes.Equal(tester.snapStorages[base][addrHash][slotHash], blob) {
+						t.Fatalf("Failed to read mutated slot %x of %x in state %d, err: %v", slotHash, addrHash, i, err)
+					}
+					continue
+				}
+				if !bytes.Equal(have, blob) {
+					t.Fatalf("Slot %x of %x is mismatched in state %d", slotHash, addrHash, i)
+				}
+			}
+		}
+	}
+}